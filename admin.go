@@ -0,0 +1,392 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requireAdmin schützt Admin-Routen über das ADMIN_TOKEN (Authorization: Bearer <token>)
+func (app *App) requireAdmin(c *gin.Context) {
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(app.adminToken)) != 1 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "nicht autorisiert"})
+		return
+	}
+	c.Next()
+}
+
+// handleAdminSkip markiert eine Challenge für ein Team als übersprungen
+// und liefert die URL der nächsten Challenge zurück
+func (app *App) handleAdminSkip(c *gin.Context) {
+	teamName := c.Param("team")
+	challengeID := c.Param("id")
+
+	var body struct {
+		Reason string `json:"reason" form:"reason"`
+	}
+	if err := c.ShouldBind(&body); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ungültiger Request-Body"})
+		return
+	}
+
+	teamPageID, err := app.findTeamPage(c.Request.Context(), teamName)
+	if err != nil || teamPageID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team nicht gefunden"})
+		return
+	}
+
+	unlock := app.teamLocks.lock(teamPageID)
+	defer unlock()
+
+	teamData, err := app.getTeamChallenges(c.Request.Context(), teamPageID)
+	if err != nil {
+		errorf("Fehler beim Abrufen der Challenges: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fehler beim Abrufen der Team-Daten"})
+		return
+	}
+
+	// Nur Challenges aus der Route des Teams dürfen übersprungen werden
+	if !routeContains(teamData, challengeID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Challenge gehört nicht zur Route des Teams"})
+		return
+	}
+
+	err = app.recordCompletion(c.Request.Context(), teamPageID, challengeID, true, body.Reason)
+	if errors.Is(err, errAlreadyCompleted) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Challenge " + challengeID + " ist bereits abgeschlossen"})
+		return
+	}
+	if err != nil {
+		errorf("Fehler beim Überspringen: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": app.userError("Fehler beim Überspringen", err)})
+		return
+	}
+
+	infof("Challenge %s für Team %s übersprungen: %s", challengeID, teamName, body.Reason)
+
+	from := app.positionChallengeID(c.Request.Context(), teamPageID, teamData, challengeID)
+	nextURL, err := app.findNextChallengeURL(c.Request.Context(), teamData, from)
+	if err != nil {
+		errorf("Fehler beim Ermitteln der nächsten Challenge: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{
+			"team":        teamName,
+			"challengeID": challengeID,
+			"skipped":     true,
+			"error":       app.userError("Nächste Challenge konnte nicht geladen werden", err),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"team":        teamName,
+		"challengeID": challengeID,
+		"skipped":     true,
+		"reason":      body.Reason,
+		"nextURL":     nextURL,
+		"finished":    nextURL == "",
+	})
+}
+
+// handleAdminGoto setzt ein Team direkt auf eine Challenge seiner Route und liefert deren URL.
+// Anders als beim Überspringen werden dabei auch spätere Abschlüsse zurückgenommen.
+func (app *App) handleAdminGoto(c *gin.Context) {
+	ctx := c.Request.Context()
+	teamName := c.Param("team")
+	challengeID := c.Param("id")
+
+	teamPageID, err := app.findTeamPage(ctx, teamName)
+	if err != nil || teamPageID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team nicht gefunden"})
+		return
+	}
+
+	target, err := app.getChallenge(ctx, challengeID)
+	if errors.Is(err, errChallengeNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unbekannte Challenge-ID: " + challengeID})
+		return
+	}
+	if err != nil {
+		errorf("Fehler beim Abrufen der Challenge: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fehler beim Abrufen der Challenge"})
+		return
+	}
+
+	unlock := app.teamLocks.lock(teamPageID)
+	defer unlock()
+
+	teamData, err := app.getTeamChallenges(ctx, teamPageID)
+	if err != nil {
+		errorf("Fehler beim Abrufen der Challenges: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fehler beim Abrufen der Team-Daten"})
+		return
+	}
+
+	targetPos := currentPosition(teamData, strconv.Itoa(target.ID))
+	if targetPos == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Challenge gehört nicht zur Route des Teams"})
+		return
+	}
+
+	previous, err := app.setTeamPosition(ctx, teamPageID, teamData, targetPos)
+	if err != nil {
+		errorf("Fehler beim Admin-Sprung: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": app.userError("Fehler beim Admin-Sprung", err)})
+		return
+	}
+	app.arrivals.record(teamPageID, strconv.Itoa(target.ID), time.Now())
+
+	infof("Team %s per Admin-Sprung auf Challenge %d gesetzt (vorher: %q)", teamName, target.ID, previous)
+	app.logEvent(teamName, previous, strconv.Itoa(target.ID), outcomeAdminGoto, true)
+
+	c.JSON(http.StatusOK, gin.H{
+		"team":      teamName,
+		"challenge": target,
+		"position":  targetPos,
+		"url":       target.URL,
+	})
+}
+
+// importResult beschreibt das Ergebnis einer CSV-Zeile beim Team-Import
+type importResult struct {
+	Row    int    `json:"row"`
+	Team   string `json:"team"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// handleAdminImportTeams legt Teams aus einem CSV-Upload an (Teamname, optional Aliases).
+// Das CSV wird zeilenweise gelesen, bereits vorhandene Teams werden übersprungen.
+func (app *App) handleAdminImportTeams(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var src io.Reader = c.Request.Body
+	if file, err := c.FormFile("file"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "CSV-Datei konnte nicht gelesen werden"})
+			return
+		}
+		defer f.Close()
+		src = f
+	}
+
+	// Duplikate nach denselben Regeln wie bei der Team-Suche erkennen, sonst wären
+	// z.B. "Team  Rakete" und "team rakete" zwei Teams, die niemand auseinanderhält
+	existing := make(map[string]bool)
+	teamNames, err := app.getAllTeamNames(ctx)
+	if err != nil && !errors.Is(err, errNoTeams) {
+		errorf("Fehler beim Abrufen der Teamnamen: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fehler beim Laden der Teamliste"})
+		return
+	}
+	for _, name := range teamNames {
+		existing[normalizeTeamName(name)] = true
+	}
+
+	reader := csv.NewReader(src)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var results []importResult
+	created := 0
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			results = append(results, importResult{Row: row, Status: "invalid", Error: err.Error()})
+			continue
+		}
+
+		name := strings.TrimSpace(record[0])
+		// Optionale Kopfzeile überspringen
+		if row == 1 && (strings.EqualFold(name, "team") || strings.EqualFold(name, "name")) {
+			continue
+		}
+
+		result := importResult{Row: row, Team: name}
+		var aliases []string
+		for _, alias := range record[1:] {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				aliases = append(aliases, alias)
+			}
+		}
+
+		validationErr := validateTeamName(name)
+		switch {
+		case validationErr != nil:
+			result.Status = "invalid"
+			result.Error = validationErr.Error()
+		case existing[normalizeTeamName(name)]:
+			result.Status = "duplicate"
+		default:
+			if _, _, err := app.createTeam(ctx, name, aliases); err != nil {
+				errorf("Fehler beim Import von Team %s: %v", name, err)
+				result.Status = "error"
+				result.Error = app.userError("Anlegen fehlgeschlagen", err)
+			} else {
+				result.Status = "created"
+				existing[normalizeTeamName(name)] = true
+				created++
+			}
+		}
+		results = append(results, result)
+	}
+
+	infof("Team-Import: %d von %d Zeilen angelegt", created, len(results))
+	c.JSON(http.StatusOK, gin.H{
+		"created": created,
+		"results": results,
+	})
+}
+
+// handleAdminRoute setzt die Reihenfolge der Challenges eines Teams neu.
+// Erwartet ein JSON-Array von Challenge-IDs in der gewünschten Reihenfolge.
+func (app *App) handleAdminRoute(c *gin.Context) {
+	ctx := c.Request.Context()
+	teamName := c.Param("team")
+
+	var ids []string
+	if err := c.ShouldBindJSON(&ids); err != nil || len(ids) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "JSON-Array mit Challenge-IDs erwartet"})
+		return
+	}
+
+	teamPageID, err := app.findTeamPage(ctx, teamName)
+	if err != nil || teamPageID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team nicht gefunden"})
+		return
+	}
+
+	unlock := app.teamLocks.lock(teamPageID)
+	defer unlock()
+
+	// Alle IDs müssen existieren und dürfen nur einmal vorkommen
+	seen := make(map[string]bool)
+	route := make([]*Challenge, 0, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Challenge " + id + " kommt mehrfach vor"})
+			return
+		}
+		seen[id] = true
+
+		challenge, err := app.getChallenge(ctx, id)
+		if errors.Is(err, errChallengeNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unbekannte Challenge-ID: " + id})
+			return
+		}
+		if err != nil {
+			errorf("Fehler beim Abrufen der Challenge: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Fehler beim Abrufen der Challenge"})
+			return
+		}
+		route = append(route, challenge)
+	}
+
+	// Challenges mit zirkulären Voraussetzungen könnte das Team nie erreichen
+	if all, err := app.getAllChallenges(ctx); err != nil {
+		errorf("Fehler beim Prüfen der Voraussetzungen: %v", err)
+	} else if cycle := findPrerequisiteCycle(all); cycle != nil {
+		for _, challenge := range route {
+			if cycle.contains(challenge.ID) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": cycle.Error(), "cycle": cycle.IDs})
+				return
+			}
+		}
+	}
+
+	if err := app.setTeamRoute(ctx, teamPageID, route); err != nil {
+		errorf("Fehler beim Setzen der Route: %v", err)
+		if errors.Is(err, errRouteTooLong) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": app.userError("Fehler beim Setzen der Route", err)})
+		return
+	}
+
+	infof("Route für Team %s neu gesetzt: %v", teamName, ids)
+	c.JSON(http.StatusOK, gin.H{
+		"team":  teamName,
+		"route": route,
+	})
+}
+
+// handleAdminDuplicateTeams listet Teams, deren Namen mehrfach vergeben sind
+func (app *App) handleAdminDuplicateTeams(c *gin.Context) {
+	duplicates, err := app.findDuplicateTeams(c.Request.Context())
+	if err != nil {
+		errorf("Fehler bei der Suche nach doppelten Teams: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fehler beim Laden der Teamliste"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"duplicates": duplicates,
+	})
+}
+
+// handleAdminDuplicateChallenges listet Challenge-IDs, die mehrfach vergeben sind
+func (app *App) handleAdminDuplicateChallenges(c *gin.Context) {
+	duplicates, err := app.findDuplicateChallengeIDs(c.Request.Context())
+	if err != nil {
+		errorf("Fehler bei der Suche nach doppelten Challenge-IDs: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fehler beim Laden der Challenges"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"duplicates": duplicates,
+	})
+}
+
+// handleAdminUndo macht den letzten Abschluss eines Teams rückgängig und liefert
+// die Challenge, an der das Team jetzt wieder steht
+func (app *App) handleAdminUndo(c *gin.Context) {
+	ctx := c.Request.Context()
+	teamName := c.Param("team")
+
+	teamPageID, err := app.findTeamPage(ctx, teamName)
+	if err != nil || teamPageID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team nicht gefunden"})
+		return
+	}
+
+	unlock := app.teamLocks.lock(teamPageID)
+	defer unlock()
+
+	num, err := app.undoLastCompletion(ctx, teamPageID)
+	if errors.Is(err, errNothingToUndo) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		errorf("Fehler beim Rückgängigmachen: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": app.userError("Fehler beim Rückgängigmachen", err)})
+		return
+	}
+
+	infof("Abschluss von Challenge %d für Team %s rückgängig gemacht", num, teamName)
+
+	current, err := app.getChallenge(ctx, strconv.Itoa(num))
+	if err != nil {
+		// Der Abschluss ist bereits entfernt, daher trotzdem Erfolg melden
+		errorf("Fehler beim Abrufen der Challenge: %v", err)
+		current = &Challenge{ID: num}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"team":    teamName,
+		"undone":  num,
+		"current": current,
+	})
+}
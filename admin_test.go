@@ -0,0 +1,449 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestAdminSkip(t *testing.T) {
+	tests := []struct {
+		name        string
+		completed   []string // vorher regulär abgeschlossene Challenges
+		path        string
+		wantStatus  int
+		wantNextURL string
+		wantSkipped int // Challenge, die danach als übersprungen markiert ist (0: keine)
+	}{
+		{
+			name:        "erste Challenge",
+			path:        "/admin/skip/Demo%20Team/1",
+			wantStatus:  http.StatusOK,
+			wantNextURL: demoChallengeURL + "demo-kirchturm",
+			wantSkipped: 1,
+		},
+		{
+			name:        "Reihenfolge der Team-Route",
+			path:        "/admin/skip/Die%20F%C3%BCchse/1",
+			wantStatus:  http.StatusOK,
+			wantNextURL: demoChallengeURL + "demo-stadtpark",
+			wantSkipped: 1,
+		},
+		{
+			name:        "letzte Challenge",
+			completed:   []string{"1", "2", "3"},
+			path:        "/admin/skip/Demo%20Team/4",
+			wantStatus:  http.StatusOK,
+			wantNextURL: "",
+			wantSkipped: 4,
+		},
+		{
+			name:       "bereits abgeschlossen",
+			completed:  []string{"1"},
+			path:       "/admin/skip/Demo%20Team/1",
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "nicht auf der Route",
+			path:       "/admin/skip/Demo%20Team/9",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "unbekanntes Team",
+			path:       "/admin/skip/Niemand/1",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			for _, id := range tt.completed {
+				if err := ta.recordCompletion(t.Context(), demoTeamPageID, id, false, ""); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			w := ta.admin(http.MethodPost, tt.path, `{"reason":"Station gesperrt"}`)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, erwartet %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			body := decodeJSON(t, w)
+			if body["nextURL"] != tt.wantNextURL {
+				t.Errorf("nextURL = %v, erwartet %q", body["nextURL"], tt.wantNextURL)
+			}
+			if body["finished"] != (tt.wantNextURL == "") {
+				t.Errorf("finished = %v", body["finished"])
+			}
+
+			teamPageID, _ := ta.findTeamPage(t.Context(), body["team"].(string))
+			page := ta.teamPage(t, teamPageID)
+			if !isSkipped(page, tt.wantSkipped) {
+				t.Errorf("Challenge %d ist nicht als übersprungen markiert", tt.wantSkipped)
+			}
+			if _, done := ta.completedChallenges(page)[tt.wantSkipped]; !done {
+				t.Errorf("Challenge %d ist nicht abgeschlossen", tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestAdminSkipUndo(t *testing.T) {
+	ta := newTestApp(t, nil)
+	before := teamScore(ta.teamPage(t, demoTeamPageID))
+
+	if w := ta.admin(http.MethodPost, "/admin/skip/Demo%20Team/1", ""); w.Code != http.StatusOK {
+		t.Fatalf("skip: %d %s", w.Code, w.Body.String())
+	}
+	if w := ta.admin(http.MethodPost, "/admin/undo/Demo%20Team", ""); w.Code != http.StatusOK {
+		t.Fatalf("undo: %d %s", w.Code, w.Body.String())
+	}
+
+	page := ta.teamPage(t, demoTeamPageID)
+	if _, done := ta.completedChallenges(page)[1]; done {
+		t.Error("Abschluss von Challenge 1 ist nach dem Undo noch gesetzt")
+	}
+	if isSkipped(page, 1) {
+		t.Error("Skip-Marker von Challenge 1 ist nach dem Undo noch gesetzt")
+	}
+	if score := teamScore(page); score != before {
+		t.Errorf("Score = %v, erwartet unverändert %v", score, before)
+	}
+}
+
+func TestAdminImportTeams(t *testing.T) {
+	csv := "team,aliases\n" +
+		"Neue Crew,Crew,NC\n" +
+		"demo team\n" +
+		"Team  Rakete\n" +
+		"team rakete\n" +
+		" ,egal\n" +
+		"DIE FÜCHSE\n"
+
+	want := []struct {
+		team   string
+		status string
+	}{
+		{"Neue Crew", "created"},
+		{"demo team", "duplicate"},
+		{"Team  Rakete", "created"},
+		{"team rakete", "duplicate"},
+		{"", "invalid"},
+		{"DIE FÜCHSE", "duplicate"},
+	}
+
+	ta := newTestApp(t, nil)
+	w := ta.admin(http.MethodPost, "/admin/import-teams", csv)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+	}
+	body := decodeJSON(t, w)
+	results := body["results"].([]any)
+	if len(results) != len(want) {
+		t.Fatalf("%d Ergebnisse, erwartet %d: %v", len(results), len(want), results)
+	}
+	for i, tt := range want {
+		result := results[i].(map[string]any)
+		if result["team"] != tt.team || result["status"] != tt.status {
+			t.Errorf("Zeile %v: %v/%v, erwartet %q/%q", result["row"], result["team"], result["status"], tt.team, tt.status)
+		}
+	}
+	if body["created"] != float64(2) {
+		t.Errorf("created = %v, erwartet 2", body["created"])
+	}
+
+	// Importierte Teams sind samt Aliases sofort auffindbar
+	pageID, name, err := ta.resolveTeam(t.Context(), "crew")
+	if err != nil || pageID == "" || name != "Neue Crew" {
+		t.Errorf("Alias crew = %q, %q, %v", pageID, name, err)
+	}
+}
+
+func TestAdminRoute(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantRoute  map[int]string
+	}{
+		{
+			name:       "neue Reihenfolge",
+			body:       `["3","1","4","2"]`,
+			wantStatus: http.StatusOK,
+			wantRoute:  map[int]string{1: "3", 2: "1", 3: "4", 4: "2"},
+		},
+		{
+			name:       "kürzere Route leert überzählige Slots",
+			body:       `["2","4"]`,
+			wantStatus: http.StatusOK,
+			wantRoute:  map[int]string{1: "2", 2: "4"},
+		},
+		{name: "Challenge doppelt", body: `["1","1"]`, wantStatus: http.StatusBadRequest},
+		{name: "unbekannte Challenge", body: `["1","9"]`, wantStatus: http.StatusBadRequest},
+		{name: "leere Route", body: `[]`, wantStatus: http.StatusBadRequest},
+		{name: "kein JSON-Array", body: `{"1":2}`, wantStatus: http.StatusBadRequest},
+		{name: "mehr Challenges als Slots", body: `["1","2","3","4","5","6"]`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			addDemoChallenge(ta.store, "demo-extra-5", 5)
+			addDemoChallenge(ta.store, "demo-extra-6", 6)
+
+			w := ta.admin(http.MethodPost, "/admin/route/Demo%20Team", tt.body)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, erwartet %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantRoute == nil {
+				return
+			}
+			route, err := ta.getTeamChallenges(t.Context(), demoTeamPageID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !maps.Equal(route, tt.wantRoute) {
+				t.Errorf("Route = %v, erwartet %v", route, tt.wantRoute)
+			}
+		})
+	}
+}
+
+func TestAdminRouteUnknownTeam(t *testing.T) {
+	ta := newTestApp(t, nil)
+	if w := ta.admin(http.MethodPost, "/admin/route/Niemand", `["1"]`); w.Code != http.StatusNotFound {
+		t.Errorf("Status = %d, erwartet 404", w.Code)
+	}
+}
+
+func TestAdminUndo(t *testing.T) {
+	base := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		completions map[int]time.Duration // Challenge → Abschluss nach base
+		score       float64
+		wantStatus  int
+		wantUndone  int
+		wantScore   float64
+	}{
+		{
+			name:        "jüngster Abschluss nach Zeitstempel",
+			completions: map[int]time.Duration{1: 0, 3: 2 * time.Hour, 2: time.Hour},
+			score:       45,
+			wantStatus:  http.StatusOK,
+			wantUndone:  3,
+			wantScore:   30,
+		},
+		{
+			name:        "einziger Abschluss",
+			completions: map[int]time.Duration{1: 0},
+			score:       10,
+			wantStatus:  http.StatusOK,
+			wantUndone:  1,
+			wantScore:   0,
+		},
+		{name: "nichts abgeschlossen", wantStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			props := notionapi.Properties{teamScoreProperty: notionapi.NumberProperty{Number: tt.score}}
+			for num, offset := range tt.completions {
+				at := notionapi.Date(base.Add(offset))
+				props[ta.completionProperty(num)] = notionapi.DateProperty{Date: &notionapi.DateObject{Start: &at}}
+			}
+			if _, err := ta.store.UpdatePage(t.Context(), demoTeamPageID, &notionapi.PageUpdateRequest{Properties: props}); err != nil {
+				t.Fatal(err)
+			}
+
+			w := ta.admin(http.MethodPost, "/admin/undo/Demo%20Team", "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, erwartet %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			body := decodeJSON(t, w)
+			if body["undone"] != float64(tt.wantUndone) {
+				t.Errorf("undone = %v, erwartet %d", body["undone"], tt.wantUndone)
+			}
+			if current, _ := body["current"].(map[string]any); current == nil || current["id"] != float64(tt.wantUndone) {
+				t.Errorf("current = %v, erwartet Challenge %d", body["current"], tt.wantUndone)
+			}
+
+			page := ta.teamPage(t, demoTeamPageID)
+			completed := ta.completedChallenges(page)
+			if _, done := completed[tt.wantUndone]; done {
+				t.Errorf("Challenge %d ist noch abgeschlossen", tt.wantUndone)
+			}
+			if len(completed) != len(tt.completions)-1 {
+				t.Errorf("%d Abschlüsse übrig, erwartet %d", len(completed), len(tt.completions)-1)
+			}
+			if score := teamScore(page); score != tt.wantScore {
+				t.Errorf("Score = %v, erwartet %v", score, tt.wantScore)
+			}
+		})
+	}
+}
+
+func TestAdminPositions(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want []teamPosition
+	}{
+		{
+			name: "gemischte Fortschritte",
+			want: []teamPosition{
+				{Team: "Demo Team", Position: 5, RouteLength: 4, Finished: true},
+				{Team: "Die Füchse", Position: 2, RouteLength: 4, ChallengeID: 3, ChallengeTitle: "Rätsel im Stadtpark"},
+				{Team: "Nachzügler", Position: 1, RouteLength: 1, ChallengeID: 4, ChallengeTitle: "Ziel am Rathaus"},
+			},
+		},
+		{
+			name: "Ziel nach einer Challenge",
+			env:  map[string]string{"FINISH_MODE": "count", "FINISH_COUNT": "1"},
+			want: []teamPosition{
+				{Team: "Demo Team", Position: 5, RouteLength: 4, Finished: true},
+				{Team: "Die Füchse", Position: 2, RouteLength: 4, ChallengeID: 3, ChallengeTitle: "Rätsel im Stadtpark", Finished: true},
+				{Team: "Nachzügler", Position: 1, RouteLength: 1, ChallengeID: 4, ChallengeTitle: "Ziel am Rathaus"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, tt.env)
+			addDemoTeam(ta.store, "Nachzügler", notionapi.Properties{
+				"Challenge1": &notionapi.RelationProperty{Relation: []notionapi.Relation{{ID: "demo-rathaus"}}},
+			})
+			for _, id := range []string{"1", "2", "3", "4"} {
+				if err := ta.recordCompletion(t.Context(), demoTeamPageID, id, false, ""); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := ta.recordCompletion(t.Context(), foxesTeamPageID, "1", false, ""); err != nil {
+				t.Fatal(err)
+			}
+
+			w := ta.admin(http.MethodGet, "/admin/positions", "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+			var body struct {
+				Teams []teamPosition `json:"teams"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(body.Teams, tt.want) {
+				t.Errorf("Positionen =\n%+v\nerwartet\n%+v", body.Teams, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdminGoto(t *testing.T) {
+	tests := []struct {
+		name          string
+		completed     []string // vor dem Sprung regulär abgeschlossen
+		path          string
+		wantStatus    int
+		wantURL       string
+		wantCompleted []int
+		wantSkipped   []int
+		wantScore     float64
+		wantFrom      string
+	}{
+		{
+			name:          "vorwärts",
+			path:          "/admin/goto/Demo%20Team/3",
+			wantStatus:    http.StatusOK,
+			wantURL:       demoChallengeURL + "demo-stadtpark",
+			wantCompleted: []int{1, 2},
+			wantSkipped:   []int{1, 2},
+		},
+		{
+			name:          "zurück",
+			completed:     []string{"1", "2", "3"},
+			path:          "/admin/goto/Demo%20Team/2",
+			wantStatus:    http.StatusOK,
+			wantURL:       demoChallengeURL + "demo-kirchturm",
+			wantCompleted: []int{1},
+			wantScore:     10,
+			wantFrom:      "3",
+		},
+		{
+			name:          "auf die aktuelle Challenge",
+			completed:     []string{"1"},
+			path:          "/admin/goto/Demo%20Team/2",
+			wantStatus:    http.StatusOK,
+			wantURL:       demoChallengeURL + "demo-kirchturm",
+			wantCompleted: []int{1},
+			wantScore:     10,
+			wantFrom:      "1",
+		},
+		{name: "unbekannte Challenge", path: "/admin/goto/Demo%20Team/9", wantStatus: http.StatusNotFound},
+		{name: "Challenge nicht auf der Route", path: "/admin/goto/Demo%20Team/5", wantStatus: http.StatusBadRequest},
+		{name: "unbekanntes Team", path: "/admin/goto/Niemand/2", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			addDemoChallenge(ta.store, "demo-extra-5", 5)
+			for _, id := range tt.completed {
+				if err := ta.recordCompletion(t.Context(), demoTeamPageID, id, false, ""); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var events bytes.Buffer
+			ta.events = newJSONEventLogger(&events)
+
+			w := ta.admin(http.MethodPost, tt.path, "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, erwartet %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				if events.Len() != 0 {
+					t.Errorf("Event trotz Fehler: %s", events.String())
+				}
+				return
+			}
+			if got := decodeJSON(t, w)["url"]; got != tt.wantURL {
+				t.Errorf("url = %v, erwartet %s", got, tt.wantURL)
+			}
+
+			page := ta.teamPage(t, demoTeamPageID)
+			completed := slices.Sorted(maps.Keys(ta.completedChallenges(page)))
+			if !slices.Equal(completed, tt.wantCompleted) {
+				t.Errorf("Abschlüsse = %v, erwartet %v", completed, tt.wantCompleted)
+			}
+			for _, num := range tt.wantSkipped {
+				if !isSkipped(page, num) {
+					t.Errorf("Challenge %d nicht als übersprungen markiert", num)
+				}
+			}
+			if got := teamScore(page); got != tt.wantScore {
+				t.Errorf("Score = %v, erwartet %v", got, tt.wantScore)
+			}
+
+			logged := readEvents(t, events.Bytes())
+			if len(logged) != 1 || logged[0].Outcome != outcomeAdminGoto || logged[0].From != tt.wantFrom || !logged[0].AdminAssisted {
+				t.Errorf("Events = %+v, erwartet admin_goto von %q", logged, tt.wantFrom)
+			}
+		})
+	}
+}
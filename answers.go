@@ -0,0 +1,245 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jomei/notionapi"
+)
+
+// Properties einer Challenge-Page für die Antwortprüfung
+const (
+	challengeAnswerProperty      = "Answer"      // erwartete Antwort (Rich-Text), leer bedeutet keine Prüfung
+	challengeHintProperty        = "Hint"        // optionaler Hinweis nach falschen Antworten
+	challengeMaxAttemptsProperty = "MaxAttempts" // Versuche pro Team, überschreibt ANSWER_MAX_ATTEMPTS
+)
+
+// answerAttemptTTL bestimmt, wie lange falsche Versuche eines Teams gezählt werden
+const answerAttemptTTL = 2 * time.Hour
+
+// Verhalten bei erreichtem Versuchslimit (ANSWER_LIMIT_ACTION)
+const (
+	answerLimitLockout = "lockout" // keine weiteren Versuche (Standard)
+	answerLimitHint    = "hint"    // Hinweis zeigen, weiter raten erlaubt
+	answerLimitReveal  = "reveal"  // Lösung zeigen
+)
+
+// answerConfig bündelt ANSWER_MAX_ATTEMPTS, ANSWER_HINT_AFTER und ANSWER_LIMIT_ACTION
+type answerConfig struct {
+	MaxAttempts int    // 0: unbegrenzt, sofern die Challenge nichts anderes vorgibt
+	HintAfter   int    // Hinweis nach so vielen falschen Antworten, 0: erst am Limit
+	LimitAction string // answerLimit*
+}
+
+// parseAnswerConfig liest die Einstellungen für die Antwortprüfung
+func parseAnswerConfig(maxAttempts, hintAfter, action string) (answerConfig, error) {
+	var cfg answerConfig
+	var err error
+	if maxAttempts != "" {
+		if cfg.MaxAttempts, err = strconv.Atoi(maxAttempts); err != nil || cfg.MaxAttempts < 0 {
+			return cfg, fmt.Errorf("ANSWER_MAX_ATTEMPTS muss eine nicht-negative ganze Zahl sein, ist aber %q", maxAttempts)
+		}
+	}
+	if hintAfter != "" {
+		if cfg.HintAfter, err = strconv.Atoi(hintAfter); err != nil || cfg.HintAfter < 0 {
+			return cfg, fmt.Errorf("ANSWER_HINT_AFTER muss eine nicht-negative ganze Zahl sein, ist aber %q", hintAfter)
+		}
+	}
+	switch action {
+	case "", answerLimitLockout:
+		cfg.LimitAction = answerLimitLockout
+	case answerLimitHint, answerLimitReveal:
+		cfg.LimitAction = action
+	default:
+		return cfg, fmt.Errorf("ANSWER_LIMIT_ACTION %q ist ungültig (lockout, hint, reveal)", action)
+	}
+	return cfg, nil
+}
+
+// readAnswerProperties liest Antwort, Hinweis und Versuchslimit einer Challenge-Page
+func readAnswerProperties(challenge *Challenge, page notionapi.Page) {
+	if p, ok := page.Properties[challengeAnswerProperty].(*notionapi.RichTextProperty); ok {
+		challenge.Answer = strings.TrimSpace(richTextPlain(p.RichText))
+	}
+	if p, ok := page.Properties[challengeHintProperty].(*notionapi.RichTextProperty); ok {
+		challenge.Hint = strings.TrimSpace(richTextPlain(p.RichText))
+	}
+	if p, ok := page.Properties[challengeMaxAttemptsProperty].(*notionapi.NumberProperty); ok && p.Number > 0 {
+		challenge.MaxAttempts = int(p.Number)
+	}
+}
+
+// answerMatches vergleicht ohne Groß-/Kleinschreibung und mehrfache Leerzeichen
+func answerMatches(input, expected string) bool {
+	return normalizeTeamName(input) == normalizeTeamName(expected)
+}
+
+// answerState beschreibt, was das Teamformular nach einer Antwort anzeigt
+type answerState struct {
+	Error        string
+	AttemptsLeft int // nur bei Limited gültig
+	Limited      bool
+	Hint         string
+	Revealed     string
+	LockedOut    bool
+}
+
+// evaluate leitet aus der Zahl falscher Versuche ab, was das Team zu sehen bekommt
+func (cfg answerConfig) evaluate(challenge *Challenge, wrong int) answerState {
+	var state answerState
+	limit := challenge.MaxAttempts
+	if limit == 0 {
+		limit = cfg.MaxAttempts
+	}
+
+	limitReached := limit > 0 && wrong >= limit
+	if limit > 0 {
+		state.Limited = true
+		state.AttemptsLeft = max(limit-wrong, 0)
+	}
+	if cfg.HintAfter > 0 && wrong >= cfg.HintAfter {
+		state.Hint = challenge.Hint
+	}
+	if limitReached {
+		switch cfg.LimitAction {
+		case answerLimitHint:
+			state.Hint = challenge.Hint
+			state.Limited = false
+		case answerLimitReveal:
+			state.Revealed = challenge.Answer
+			state.Limited = false
+		default:
+			state.LockedOut = true
+		}
+	}
+	return state
+}
+
+// answerAttempts zählt falsche Antworten pro Team und Challenge im Speicher.
+// Einträge verfallen ttl nach dem letzten Fehlversuch.
+type answerAttempts struct {
+	mu      sync.Mutex
+	entries map[string]answerAttempt
+	ttl     time.Duration
+}
+
+type answerAttempt struct {
+	wrong   int
+	expires time.Time
+}
+
+func newAnswerAttempts() *answerAttempts {
+	return &answerAttempts{entries: make(map[string]answerAttempt), ttl: answerAttemptTTL}
+}
+
+func answerAttemptKey(teamPageID, challengeID string) string {
+	return normalizePageID(teamPageID) + "|" + challengeID
+}
+
+// count liefert die bisherigen Fehlversuche
+func (a *answerAttempts) count(teamPageID, challengeID string) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	entry, ok := a.entries[answerAttemptKey(teamPageID, challengeID)]
+	if !ok || time.Now().After(entry.expires) {
+		return 0
+	}
+	return entry.wrong
+}
+
+// recordWrong zählt einen Fehlversuch und liefert die neue Anzahl
+func (a *answerAttempts) recordWrong(teamPageID, challengeID string) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	for key, entry := range a.entries {
+		if now.After(entry.expires) {
+			delete(a.entries, key)
+		}
+	}
+
+	key := answerAttemptKey(teamPageID, challengeID)
+	entry := a.entries[key]
+	entry.wrong++
+	entry.expires = now.Add(a.ttl)
+	a.entries[key] = entry
+	return entry.wrong
+}
+
+// reset verwirft die Fehlversuche nach einer richtigen Antwort
+func (a *answerAttempts) reset(teamPageID, challengeID string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.entries, answerAttemptKey(teamPageID, challengeID))
+}
+
+// checkAnswer prüft die Antwort auf die aktuelle Challenge. Bei einer falschen Antwort
+// wird das Formular mit verbleibenden Versuchen bzw. Hinweis erneut gezeigt, nach
+// erreichtem Limit mit ANSWER_LIMIT_ACTION=lockout eine Sperrseite. Liefert true,
+// wenn die Weiterleitung fortgesetzt werden darf. Ist die Challenge gerade nicht
+// ladbar, kommt die Seite zum erneuten Versuch statt einer ungeprüften Weiterleitung.
+func (app *App) checkAnswer(c *gin.Context, teamPageID, teamName, challengeID string) bool {
+	challenge, err := app.getChallenge(c.Request.Context(), challengeID)
+	if errors.Is(err, errChallengeNotFound) {
+		// Unbekannte Challenges haben keine Antwort
+		return true
+	}
+	if err != nil {
+		errorf("Antwort von Team %s auf Challenge %s nicht prüfbar: %v", teamName, challengeID, err)
+		app.renderRetry(c, challengeID, teamName)
+		return false
+	}
+	if challenge.Answer == "" {
+		return true
+	}
+
+	wrong := app.answerAttempts.count(teamPageID, challengeID)
+	state := app.answerConfig.evaluate(challenge, wrong)
+	if state.LockedOut {
+		app.renderAnswerLockout(c, teamName, challengeID)
+		return false
+	}
+
+	input := c.PostForm("answer")
+	if answerMatches(input, challenge.Answer) {
+		app.answerAttempts.reset(teamPageID, challengeID)
+		return true
+	}
+
+	// Eine leere Antwort (z.B. erster Aufruf) zählt nicht als Versuch
+	if strings.TrimSpace(input) != "" {
+		wrong = app.answerAttempts.recordWrong(teamPageID, challengeID)
+		state = app.answerConfig.evaluate(challenge, wrong)
+		infof("Falsche Antwort von Team %s auf Challenge %s (%d. Fehlversuch)", teamName, challengeID, wrong)
+		if state.LockedOut {
+			app.renderAnswerLockout(c, teamName, challengeID)
+			return false
+		}
+		state.Error = "Leider falsch, versucht es noch einmal."
+		app.logEvent(teamName, challengeID, "", outcomeWrongAnswer, false)
+	} else {
+		state.Error = "Bitte gebt eure Antwort ein."
+	}
+
+	c.Status(http.StatusUnprocessableEntity)
+	app.renderChallengeForm(c, challengeID, teamForm{Team: teamName, Answer: state})
+	return false
+}
+
+// renderAnswerLockout zeigt die Sperrseite nach zu vielen falschen Antworten
+func (app *App) renderAnswerLockout(c *gin.Context, teamName, challengeID string) {
+	app.logEvent(teamName, challengeID, "", outcomeLockedOut, false)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusForbidden)
+	app.templates.ExecuteTemplate(c.Writer, "error.html", gin.H{
+		"error": "Zu viele falsche Antworten – bitte wendet euch an die Orga.",
+	})
+}
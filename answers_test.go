@@ -0,0 +1,232 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestParseAnswerConfig(t *testing.T) {
+	tests := []struct {
+		maxAttempts, hintAfter, action string
+		want                           answerConfig
+		wantErr                        bool
+	}{
+		{want: answerConfig{LimitAction: answerLimitLockout}},
+		{maxAttempts: "3", hintAfter: "1", action: "reveal", want: answerConfig{MaxAttempts: 3, HintAfter: 1, LimitAction: answerLimitReveal}},
+		{maxAttempts: "0", action: "hint", want: answerConfig{LimitAction: answerLimitHint}},
+		{maxAttempts: "-1", wantErr: true},
+		{maxAttempts: "drei", wantErr: true},
+		{hintAfter: "-2", wantErr: true},
+		{action: "sperren", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseAnswerConfig(tt.maxAttempts, tt.hintAfter, tt.action)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseAnswerConfig(%q, %q, %q): Fehler = %v, erwartet Fehler = %v", tt.maxAttempts, tt.hintAfter, tt.action, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("parseAnswerConfig(%q, %q, %q) = %+v, erwartet %+v", tt.maxAttempts, tt.hintAfter, tt.action, got, tt.want)
+		}
+	}
+}
+
+func TestAnswerMatches(t *testing.T) {
+	tests := []struct {
+		input, expected string
+		want            bool
+	}{
+		{input: "Wasser", expected: "Wasser", want: true},
+		{input: "  wASSER ", expected: "Wasser", want: true},
+		{input: "altes   Wasser", expected: "Altes Wasser", want: true},
+		{input: "Wasserhahn", expected: "Wasser", want: false},
+		{input: "", expected: "Wasser", want: false},
+	}
+	for _, tt := range tests {
+		if got := answerMatches(tt.input, tt.expected); got != tt.want {
+			t.Errorf("answerMatches(%q, %q) = %v, erwartet %v", tt.input, tt.expected, got, tt.want)
+		}
+	}
+}
+
+func TestAnswerConfigEvaluate(t *testing.T) {
+	challenge := &Challenge{Answer: "Wasser", Hint: "Es ist nass"}
+	tests := []struct {
+		name        string
+		cfg         answerConfig
+		maxAttempts int // MaxAttempts der Challenge
+		wrong       int
+		want        answerState
+	}{
+		{name: "unbegrenzt", cfg: answerConfig{LimitAction: answerLimitLockout}, wrong: 5},
+		{name: "Versuche übrig", cfg: answerConfig{MaxAttempts: 3, LimitAction: answerLimitLockout}, wrong: 1, want: answerState{Limited: true, AttemptsLeft: 2}},
+		{name: "Hinweis ab dem ersten Fehler", cfg: answerConfig{MaxAttempts: 3, HintAfter: 1, LimitAction: answerLimitLockout}, wrong: 1, want: answerState{Limited: true, AttemptsLeft: 2, Hint: "Es ist nass"}},
+		{name: "Sperre am Limit", cfg: answerConfig{MaxAttempts: 3, LimitAction: answerLimitLockout}, wrong: 3, want: answerState{Limited: true, LockedOut: true}},
+		{name: "Hinweis am Limit", cfg: answerConfig{MaxAttempts: 2, LimitAction: answerLimitHint}, wrong: 2, want: answerState{Hint: "Es ist nass"}},
+		{name: "Lösung am Limit", cfg: answerConfig{MaxAttempts: 2, LimitAction: answerLimitReveal}, wrong: 4, want: answerState{Revealed: "Wasser"}},
+		{name: "Limit der Challenge hat Vorrang", cfg: answerConfig{MaxAttempts: 5, LimitAction: answerLimitLockout}, maxAttempts: 1, wrong: 1, want: answerState{Limited: true, LockedOut: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := *challenge
+			c.MaxAttempts = tt.maxAttempts
+			if got := tt.cfg.evaluate(&c, tt.wrong); got != tt.want {
+				t.Errorf("evaluate = %+v, erwartet %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnswerAttempts(t *testing.T) {
+	a := newAnswerAttempts()
+	if got := a.count("team-1", "1"); got != 0 {
+		t.Fatalf("count ohne Versuche = %d", got)
+	}
+	a.recordWrong("team-1", "1")
+	if got := a.recordWrong("TEAM1", "1"); got != 2 {
+		t.Errorf("recordWrong = %d, erwartet 2 (Page-ID ohne Bindestriche)", got)
+	}
+	a.recordWrong("team-1", "2")
+	if got := a.count("team-2", "1"); got != 0 {
+		t.Errorf("anderes Team: count = %d", got)
+	}
+
+	a.reset("team-1", "1")
+	if got := a.count("team-1", "1"); got != 0 {
+		t.Errorf("count nach reset = %d", got)
+	}
+	if got := a.count("team-1", "2"); got != 1 {
+		t.Errorf("reset hat andere Challenge verworfen: count = %d", got)
+	}
+
+	a.ttl = time.Millisecond
+	a.recordWrong("team-3", "1")
+	time.Sleep(5 * time.Millisecond)
+	if got := a.count("team-3", "1"); got != 0 {
+		t.Errorf("count nach Ablauf = %d", got)
+	}
+}
+
+func TestAnswerFlow(t *testing.T) {
+	type attempt struct {
+		answer     string
+		wantStatus int
+		wantBody   []string
+		notBody    []string
+	}
+	tests := []struct {
+		name     string
+		env      map[string]string
+		attempts []attempt
+	}{
+		{
+			name: "Sperre nach zwei Fehlversuchen",
+			env:  map[string]string{"ANSWER_MAX_ATTEMPTS": "2", "ANSWER_HINT_AFTER": "1"},
+			attempts: []attempt{
+				{answer: "", wantStatus: http.StatusUnprocessableEntity, wantBody: []string{"Bitte gebt eure Antwort ein.", "Attempts left: 2"}, notBody: []string{"Hint:"}},
+				{answer: "Feuer", wantStatus: http.StatusUnprocessableEntity, wantBody: []string{"Leider falsch", "Attempts left: 1", "Hint: Es ist nass"}},
+				{answer: "Erde", wantStatus: http.StatusForbidden, wantBody: []string{"Zu viele falsche Antworten"}},
+				{answer: "Wasser", wantStatus: http.StatusForbidden, wantBody: []string{"Zu viele falsche Antworten"}},
+			},
+		},
+		{
+			name: "Hinweis am Limit",
+			env:  map[string]string{"ANSWER_MAX_ATTEMPTS": "2", "ANSWER_LIMIT_ACTION": "hint"},
+			attempts: []attempt{
+				{answer: "Feuer", wantStatus: http.StatusUnprocessableEntity, wantBody: []string{"Attempts left: 1"}, notBody: []string{"Hint:"}},
+				{answer: "Erde", wantStatus: http.StatusUnprocessableEntity, wantBody: []string{"Hint: Es ist nass"}, notBody: []string{"Attempts left"}},
+				{answer: "Luft", wantStatus: http.StatusUnprocessableEntity, wantBody: []string{"Hint: Es ist nass"}},
+				{answer: " wasser ", wantStatus: http.StatusOK, wantBody: []string{demoChallengeURL + "demo-kirchturm"}},
+			},
+		},
+		{
+			name: "Lösung am Limit",
+			env:  map[string]string{"ANSWER_MAX_ATTEMPTS": "1", "ANSWER_LIMIT_ACTION": "reveal"},
+			attempts: []attempt{
+				{answer: "Feuer", wantStatus: http.StatusUnprocessableEntity, wantBody: []string{"The answer is: <strong>Wasser</strong>"}},
+				{answer: "Wasser", wantStatus: http.StatusOK, wantBody: []string{demoChallengeURL + "demo-kirchturm"}},
+			},
+		},
+		{
+			name: "ohne Limit",
+			attempts: []attempt{
+				{answer: "Feuer", wantStatus: http.StatusUnprocessableEntity, wantBody: []string{"Leider falsch"}, notBody: []string{"Attempts left", "Hint:"}},
+				{answer: "Wasser", wantStatus: http.StatusOK, wantBody: []string{demoChallengeURL + "demo-kirchturm"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, tt.env)
+			_, err := ta.store.UpdatePage(t.Context(), "demo-brunnen", &notionapi.PageUpdateRequest{Properties: notionapi.Properties{
+				challengeAnswerProperty: notionapi.RichTextProperty{RichText: demoText("Wasser")},
+				challengeHintProperty:   notionapi.RichTextProperty{RichText: demoText("Es ist nass")},
+			}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i, a := range tt.attempts {
+				w := ta.do(http.MethodPost, "/next/1", url.Values{"team": {"Demo Team"}, "answer": {a.answer}})
+				body := w.Body.String()
+				if w.Code != a.wantStatus {
+					t.Fatalf("Versuch %d (%q): Status = %d, erwartet %d:\n%s", i+1, a.answer, w.Code, a.wantStatus, body)
+				}
+				for _, want := range a.wantBody {
+					if !strings.Contains(body, want) {
+						t.Errorf("Versuch %d (%q): %q fehlt:\n%s", i+1, a.answer, want, body)
+					}
+				}
+				for _, unwanted := range a.notBody {
+					if strings.Contains(body, unwanted) {
+						t.Errorf("Versuch %d (%q): %q unerwartet", i+1, a.answer, unwanted)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAnswerCheckNotionError(t *testing.T) {
+	ta := newTestApp(t, nil)
+	if _, err := ta.store.UpdatePage(t.Context(), "demo-brunnen", &notionapi.PageUpdateRequest{Properties: notionapi.Properties{
+		challengeAnswerProperty: notionapi.RichTextProperty{RichText: demoText("Wasser")},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	var failing atomic.Bool
+	failing.Store(true)
+	ta.notion = failingChallengeNotion(ta, &failing, 1)
+
+	// Ohne ladbare Challenge ist keine Antwort prüfbar, auch keine falsche
+	for _, answer := range []string{"Feuer", "Wasser"} {
+		w := ta.do(http.MethodPost, "/next/1", url.Values{"team": {"Demo Team"}, "answer": {answer}})
+		if w.Code != http.StatusServiceUnavailable {
+			t.Fatalf("%q: Status = %d, erwartet %d:\n%s", answer, w.Code, http.StatusServiceUnavailable, w.Body.String())
+		}
+		if want := `<input type="hidden" name="answer" value="` + answer + `">`; !strings.Contains(w.Body.String(), want) {
+			t.Errorf("%q: Formular zum erneuten Versuch fehlt:\n%s", answer, w.Body.String())
+		}
+	}
+	if _, done := ta.completedChallenges(ta.teamPage(t, demoTeamPageID))[1]; done {
+		t.Fatal("Challenge 1 ohne geprüfte Antwort abgeschlossen")
+	}
+	if wrong := ta.answerAttempts.count(demoTeamPageID, "1"); wrong != 0 {
+		t.Errorf("%d Fehlversuche gezählt, erwartet 0", wrong)
+	}
+
+	failing.Store(false)
+	if w := ta.do(http.MethodPost, "/next/1", url.Values{"team": {"Demo Team"}, "answer": {"Feuer"}}); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("falsche Antwort nach Störung: Status = %d", w.Code)
+	}
+	if w := ta.do(http.MethodPost, "/next/1", url.Values{"team": {"Demo Team"}, "answer": {"Wasser"}}); !strings.Contains(w.Body.String(), demoChallengeURL+"demo-kirchturm") {
+		t.Errorf("richtige Antwort nach Störung: Status = %d:\n%s", w.Code, w.Body.String())
+	}
+}
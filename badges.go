@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jomei/notionapi"
+)
+
+// speedDemonPace ist der maximale durchschnittliche Abstand zwischen Abschlüssen für "Speed Demon"
+const speedDemonPace = 10 * time.Minute
+
+// speedDemonMinCompletions verhindert, dass zwei schnelle Abschlüsse schon reichen
+const speedDemonMinCompletions = 3
+
+// badge ist eine Auszeichnung, die ein Team verdient hat
+type badge struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// badgeProgress enthält alles, woraus Badges berechnet werden
+type badgeProgress struct {
+	Completed   int
+	Skipped     int
+	RouteLength int
+	Finished    bool
+	FinishRank  int         // 1 = erstes Team im Ziel, 0 = noch nicht im Ziel
+	Completions []time.Time // Abschlusszeitpunkte, aufsteigend
+}
+
+// badgeRule verknüpft ein Badge mit seiner Bedingung
+type badgeRule struct {
+	badge
+	earned func(p badgeProgress) bool
+}
+
+// badgeRules definiert alle Badges an einer Stelle, in der Reihenfolge der Ausgabe
+var badgeRules = []badgeRule{
+	{badge{"first_steps", "First Steps", "Erste Challenge abgeschlossen"}, func(p badgeProgress) bool {
+		return p.Completed >= 1
+	}},
+	{badge{"halfway", "Halfway There", "Mindestens die Hälfte der Route geschafft"}, func(p badgeProgress) bool {
+		return p.RouteLength > 0 && p.Completed*2 >= p.RouteLength
+	}},
+	{badge{"finisher", "Finisher", "Im Ziel angekommen"}, func(p badgeProgress) bool {
+		return p.Finished
+	}},
+	{badge{"first_finisher", "First Finisher", "Als erstes Team im Ziel"}, func(p badgeProgress) bool {
+		return p.FinishRank == 1
+	}},
+	{badge{"no_shortcuts", "No Shortcuts", "Im Ziel, ohne eine Challenge zu überspringen"}, func(p badgeProgress) bool {
+		return p.Finished && p.Skipped == 0
+	}},
+	{badge{"speed_demon", "Speed Demon", fmt.Sprintf("Im Schnitt höchstens %v pro Challenge", speedDemonPace)}, func(p badgeProgress) bool {
+		if len(p.Completions) < speedDemonMinCompletions {
+			return false
+		}
+		span := p.Completions[len(p.Completions)-1].Sub(p.Completions[0])
+		return span/time.Duration(len(p.Completions)-1) <= speedDemonPace
+	}},
+}
+
+// earnedBadges wertet alle Regeln für einen Fortschritt aus
+func earnedBadges(p badgeProgress) []badge {
+	badges := []badge{}
+	for _, rule := range badgeRules {
+		if rule.earned(p) {
+			badges = append(badges, rule.badge)
+		}
+	}
+	return badges
+}
+
+// handleAPITeamBadges liefert die verdienten Badges eines Teams
+func (app *App) handleAPITeamBadges(c *gin.Context) {
+	ctx := c.Request.Context()
+	teamName := c.Param("team")
+
+	teamPageID, err := app.findTeamPage(ctx, teamName)
+	if err != nil || teamPageID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team nicht gefunden"})
+		return
+	}
+
+	progress, err := app.getBadgeProgress(ctx, teamPageID)
+	if err != nil {
+		errorf("Fehler beim Berechnen der Badges: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fehler beim Abrufen der Team-Daten"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"team":   teamName,
+		"badges": earnedBadges(progress),
+	})
+}
+
+// getBadgeProgress lädt alle Team-Pages, da "First Finisher" die Zielzeiten aller Teams vergleicht
+func (app *App) getBadgeProgress(ctx context.Context, teamPageID string) (badgeProgress, error) {
+	pages, err := app.listTeamPages(ctx)
+	if err != nil {
+		return badgeProgress{}, err
+	}
+
+	var progress badgeProgress
+	found := false
+	var finishTimes []time.Time
+	var ownFinish time.Time
+	for i := range pages {
+		page := &pages[i]
+		finished, finishedAt := app.pageFinished(page)
+		if finished {
+			finishTimes = append(finishTimes, finishedAt)
+		}
+		if normalizePageID(string(page.ID)) != normalizePageID(teamPageID) {
+			continue
+		}
+
+		found = true
+		entry := app.teamProgress(page)
+		progress = badgeProgress{
+			Completed:   entry.Completed,
+			Skipped:     entry.Skipped,
+			RouteLength: routeLength(page),
+			Finished:    finished,
+		}
+		for _, at := range app.completedChallenges(page) {
+			progress.Completions = append(progress.Completions, at)
+		}
+		sort.Slice(progress.Completions, func(a, b int) bool { return progress.Completions[a].Before(progress.Completions[b]) })
+		ownFinish = finishedAt
+	}
+	if !found {
+		return badgeProgress{}, fmt.Errorf("team-page %s nicht in der Team-Datenbank", teamPageID)
+	}
+
+	if progress.Finished {
+		progress.FinishRank = 1
+		for _, at := range finishTimes {
+			if at.Before(ownFinish) {
+				progress.FinishRank++
+			}
+		}
+	}
+	return progress, nil
+}
+
+// pageFinished prüft, ob ein Team im Ziel ist, und liefert den Zeitpunkt des letzten Abschlusses
+func (app *App) pageFinished(page *notionapi.Page) (bool, time.Time) {
+	entry := app.teamProgress(page)
+	if app.finishMode == finishModeCount {
+		return entry.Completed >= app.finishCount, entry.LastCompletion
+	}
+	length := routeLength(page)
+	return length > 0 && entry.Completed >= length, entry.LastCompletion
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestEarnedBadges(t *testing.T) {
+	start := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	every := func(gap time.Duration, n int) []time.Time {
+		times := make([]time.Time, n)
+		for i := range times {
+			times[i] = start.Add(time.Duration(i) * gap)
+		}
+		return times
+	}
+
+	tests := []struct {
+		name     string
+		progress badgeProgress
+		want     []string
+	}{
+		{name: "noch nichts", progress: badgeProgress{RouteLength: 4}, want: []string{}},
+		{name: "erste Challenge", progress: badgeProgress{Completed: 1, RouteLength: 4, Completions: every(time.Minute, 1)}, want: []string{"first_steps"}},
+		{name: "halbe Route", progress: badgeProgress{Completed: 2, RouteLength: 4, Completions: every(time.Hour, 2)}, want: []string{"first_steps", "halfway"}},
+		{name: "ohne Route keine Hälfte", progress: badgeProgress{Completed: 1}, want: []string{"first_steps"}},
+		{
+			name:     "im Ziel mit Überspringen",
+			progress: badgeProgress{Completed: 4, Skipped: 1, RouteLength: 4, Finished: true, FinishRank: 2, Completions: every(time.Hour, 4)},
+			want:     []string{"first_steps", "halfway", "finisher"},
+		},
+		{
+			name:     "als Erstes im Ziel",
+			progress: badgeProgress{Completed: 4, RouteLength: 4, Finished: true, FinishRank: 1, Completions: every(time.Hour, 4)},
+			want:     []string{"first_steps", "halfway", "finisher", "first_finisher", "no_shortcuts"},
+		},
+		{
+			name:     "genau im Tempo",
+			progress: badgeProgress{Completed: 3, RouteLength: 8, Completions: every(speedDemonPace, 3)},
+			want:     []string{"first_steps", "speed_demon"},
+		},
+		{
+			name:     "knapp zu langsam",
+			progress: badgeProgress{Completed: 3, RouteLength: 8, Completions: every(speedDemonPace+time.Second, 3)},
+			want:     []string{"first_steps"},
+		},
+		{
+			name:     "zu wenige Abschlüsse für das Tempo",
+			progress: badgeProgress{Completed: 2, RouteLength: 8, Completions: every(time.Second, 2)},
+			want:     []string{"first_steps"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, b := range earnedBadges(tt.progress) {
+				got = append(got, b.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("earnedBadges = %v, erwartet %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPITeamBadges(t *testing.T) {
+	start := time.Now().Add(-2 * time.Hour)
+	ta := newTestApp(t, nil)
+	// Beide Teams im Ziel, Die Füchse eine Stunde früher
+	for _, team := range []struct {
+		pageID string
+		offset time.Duration
+	}{
+		{demoTeamPageID, time.Hour},
+		{foxesTeamPageID, 0},
+	} {
+		props := notionapi.Properties{}
+		for num := 1; num <= 4; num++ {
+			at := notionapi.Date(start.Add(team.offset + time.Duration(num)*time.Minute))
+			props[ta.completionProperty(num)] = notionapi.DateProperty{Date: &notionapi.DateObject{Start: &at}}
+		}
+		if _, err := ta.store.UpdatePage(t.Context(), team.pageID, &notionapi.PageUpdateRequest{Properties: props}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		team string
+		want []string
+	}{
+		{team: "Die%20F%C3%BCchse", want: []string{"first_steps", "halfway", "finisher", "first_finisher", "no_shortcuts", "speed_demon"}},
+		{team: "Demo%20Team", want: []string{"first_steps", "halfway", "finisher", "no_shortcuts", "speed_demon"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.team, func(t *testing.T) {
+			w := ta.do(http.MethodGet, "/api/teams/"+tt.team+"/badges", nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+			var body struct {
+				Badges []badge `json:"badges"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, b := range body.Badges {
+				got = append(got, b.ID)
+				if b.Name == "" || b.Description == "" {
+					t.Errorf("Badge %s ohne Name oder Beschreibung", b.ID)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Badges = %v, erwartet %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPITeamBadgesUnknownTeam(t *testing.T) {
+	ta := newTestApp(t, nil)
+	if w := ta.do(http.MethodGet, "/api/teams/Unbekannt/badges", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Status = %d, erwartet %d", w.Code, http.StatusNotFound)
+	}
+}
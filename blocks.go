@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"html/template"
+	"strings"
+
+	"github.com/jomei/notionapi"
+)
+
+// challengeDescription lädt den Inhalt einer Challenge-Page und rendert ihn als HTML.
+// Das Ergebnis wird pro Challenge im Cache gehalten.
+func (app *App) challengeDescription(ctx context.Context, challenge *Challenge) (template.HTML, error) {
+	if cached, ok := app.cache.cachedDescription(challenge.ID); ok {
+		return cached, nil
+	}
+
+	var blocks notionapi.Blocks
+	pagination := &notionapi.Pagination{PageSize: 100}
+	for {
+		result, err := app.getBlockChildren(ctx, challenge.PageID, pagination)
+		if err != nil {
+			return "", fmt.Errorf("fehler beim Laden der Challenge-Inhalte: %w", err)
+		}
+		blocks = append(blocks, result.Results...)
+
+		if !result.HasMore {
+			break
+		}
+		pagination.StartCursor = notionapi.Cursor(result.NextCursor)
+	}
+
+	rendered := renderBlocks(blocks)
+	app.cache.storeDescription(challenge.ID, rendered)
+	return rendered, nil
+}
+
+// renderBlocks wandelt Notion-Blocks in sicheres HTML um.
+// Unterstützt werden Absätze, Überschriften und Listen, alles andere wird übersprungen.
+func renderBlocks(blocks notionapi.Blocks) template.HTML {
+	var sb strings.Builder
+	openList := ""
+
+	closeList := func() {
+		if openList != "" {
+			sb.WriteString("</" + openList + ">")
+			openList = ""
+		}
+	}
+	startList := func(tag string) {
+		if openList != tag {
+			closeList()
+			sb.WriteString("<" + tag + ">")
+			openList = tag
+		}
+	}
+
+	for _, block := range blocks {
+		switch b := block.(type) {
+		case *notionapi.BulletedListItemBlock:
+			startList("ul")
+			sb.WriteString("<li>" + renderRichText(b.BulletedListItem.RichText) + "</li>")
+			continue
+		case *notionapi.NumberedListItemBlock:
+			startList("ol")
+			sb.WriteString("<li>" + renderRichText(b.NumberedListItem.RichText) + "</li>")
+			continue
+		}
+
+		closeList()
+		switch b := block.(type) {
+		case *notionapi.ParagraphBlock:
+			sb.WriteString("<p>" + renderRichText(b.Paragraph.RichText) + "</p>")
+		case *notionapi.Heading1Block:
+			sb.WriteString("<h2>" + renderRichText(b.Heading1.RichText) + "</h2>")
+		case *notionapi.Heading2Block:
+			sb.WriteString("<h3>" + renderRichText(b.Heading2.RichText) + "</h3>")
+		case *notionapi.Heading3Block:
+			sb.WriteString("<h4>" + renderRichText(b.Heading3.RichText) + "</h4>")
+		}
+	}
+	closeList()
+
+	// Alle Texte wurden escaped, daher ist das Ergebnis sicher
+	return template.HTML(sb.String())
+}
+
+// renderRichText escaped Rich-Text-Fragmente und übernimmt einfache Formatierungen
+func renderRichText(parts []notionapi.RichText) string {
+	var sb strings.Builder
+	for _, part := range parts {
+		text := html.EscapeString(part.PlainText)
+		if a := part.Annotations; a != nil {
+			if a.Code {
+				text = "<code>" + text + "</code>"
+			}
+			if a.Bold {
+				text = "<strong>" + text + "</strong>"
+			}
+			if a.Italic {
+				text = "<em>" + text + "</em>"
+			}
+		}
+		if strings.HasPrefix(part.Href, "https://") || strings.HasPrefix(part.Href, "http://") {
+			text = `<a href="` + html.EscapeString(part.Href) + `" target="_blank" rel="noopener">` + text + "</a>"
+		}
+		sb.WriteString(text)
+	}
+	return sb.String()
+}
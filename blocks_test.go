@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jomei/notionapi"
+)
+
+func paragraph(parts ...notionapi.RichText) *notionapi.ParagraphBlock {
+	return &notionapi.ParagraphBlock{Paragraph: notionapi.Paragraph{RichText: parts}}
+}
+
+func bullet(text string) *notionapi.BulletedListItemBlock {
+	return &notionapi.BulletedListItemBlock{BulletedListItem: notionapi.ListItem{RichText: demoText(text)}}
+}
+
+func numbered(text string) *notionapi.NumberedListItemBlock {
+	return &notionapi.NumberedListItemBlock{NumberedListItem: notionapi.ListItem{RichText: demoText(text)}}
+}
+
+func TestRenderBlocks(t *testing.T) {
+	tests := []struct {
+		name   string
+		blocks notionapi.Blocks
+		want   string
+	}{
+		{
+			name:   "Absatz wird escaped",
+			blocks: notionapi.Blocks{paragraph(demoText("<b>Löwen</b> & Köpfe")...)},
+			want:   "<p>&lt;b&gt;Löwen&lt;/b&gt; &amp; Köpfe</p>",
+		},
+		{
+			name: "Überschriften eine Ebene tiefer",
+			blocks: notionapi.Blocks{
+				&notionapi.Heading1Block{Heading1: notionapi.Heading{RichText: demoText("Eins")}},
+				&notionapi.Heading2Block{Heading2: notionapi.Heading{RichText: demoText("Zwei")}},
+				&notionapi.Heading3Block{Heading3: notionapi.Heading{RichText: demoText("Drei")}},
+			},
+			want: "<h2>Eins</h2><h3>Zwei</h3><h4>Drei</h4>",
+		},
+		{
+			name:   "Listen werden zusammengefasst",
+			blocks: notionapi.Blocks{bullet("a"), bullet("b"), numbered("1"), paragraph(demoText("Ende")...)},
+			want:   "<ul><li>a</li><li>b</li></ul><ol><li>1</li></ol><p>Ende</p>",
+		},
+		{
+			name:   "Liste am Ende wird geschlossen",
+			blocks: notionapi.Blocks{bullet("a")},
+			want:   "<ul><li>a</li></ul>",
+		},
+		{
+			name: "Formatierung und Links",
+			blocks: notionapi.Blocks{paragraph(
+				notionapi.RichText{PlainText: "fett", Annotations: &notionapi.Annotations{Bold: true}},
+				notionapi.RichText{PlainText: "Karte", Href: "https://example.org/?a=1&b=2"},
+				notionapi.RichText{PlainText: "böse", Href: "javascript:alert(1)"},
+			)},
+			want: `<p><strong>fett</strong><a href="https://example.org/?a=1&amp;b=2" target="_blank" rel="noopener">Karte</a>böse</p>`,
+		},
+		{
+			name:   "unbekannte Blocks werden übersprungen",
+			blocks: notionapi.Blocks{&notionapi.DividerBlock{}, paragraph(demoText("x")...)},
+			want:   "<p>x</p>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(renderBlocks(tt.blocks)); got != tt.want {
+				t.Errorf("renderBlocks =\n%s\nerwartet\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChallengeDescriptionOnForm(t *testing.T) {
+	for _, show := range []string{"true", "false"} {
+		t.Run(show, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"SHOW_CHALLENGE_DESCRIPTION": show})
+			ta.store.blocks["demo-brunnen"] = notionapi.Blocks{paragraph(demoText("Beschreibung aus Notion")...)}
+
+			w := ta.do(http.MethodGet, "/next/1", nil)
+			if got := strings.Contains(w.Body.String(), "<p>Beschreibung aus Notion</p>"); got != (show == "true") {
+				t.Errorf("Beschreibung angezeigt = %v", got)
+			}
+		})
+	}
+}
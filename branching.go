@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jomei/notionapi"
+)
+
+// challengeNextRulesProperty ist eine optionale Rich-Text-Property der Challenge-Page,
+// die die nächste Challenge von der Antwort abhängig macht. Eine Regel pro Zeile
+// (oder durch ";" getrennt), "*" steht für jede andere Antwort:
+//
+//	links => 5
+//	rechts => 6
+//	* => 7
+//
+// Die Ziele sollten auf der Route des Teams liegen, damit es danach linear weitergeht.
+const challengeNextRulesProperty = "NextRules"
+
+// nextRule verweist für eine Antwort auf die nächste Challenge
+type nextRule struct {
+	Answer      string
+	ChallengeID string
+}
+
+// parseNextRules liest die Regeln aus dem Text der NextRules-Property
+func parseNextRules(s string) ([]nextRule, error) {
+	var rules []nextRule
+	for _, line := range strings.FieldsFunc(s, func(r rune) bool { return r == '\n' || r == ';' }) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		answer, target, ok := strings.Cut(line, "=>")
+		answer, target = strings.TrimSpace(answer), strings.TrimSpace(target)
+		if !ok || answer == "" {
+			return nil, fmt.Errorf("regel %q hat nicht die Form \"Antwort => Challenge-ID\"", line)
+		}
+		if id, err := strconv.Atoi(target); err != nil || id < 1 {
+			return nil, fmt.Errorf("regel %q: %q ist keine Challenge-ID", line, target)
+		}
+		rules = append(rules, nextRule{Answer: answer, ChallengeID: target})
+	}
+	return rules, nil
+}
+
+// readNextRules liest die NextRules einer Challenge-Page. Fehlerhafte Regeln werden
+// gemeldet und komplett ignoriert, damit keine halbe Verzweigung greift.
+func readNextRules(challenge *Challenge, page notionapi.Page) {
+	p, ok := page.Properties[challengeNextRulesProperty].(*notionapi.RichTextProperty)
+	if !ok {
+		return
+	}
+	rules, err := parseNextRules(richTextPlain(p.RichText))
+	if err != nil {
+		warnf("Challenge %d: NextRules ignoriert, %v", challenge.ID, err)
+		return
+	}
+	challenge.NextRules = rules
+}
+
+// matchNextRule wählt die Challenge-ID zur Antwort. Exakte Treffer (wie bei answerMatches
+// ohne Groß-/Kleinschreibung) gehen vor "*"; ohne Treffer bleibt es bei der linearen Route.
+func matchNextRule(rules []nextRule, answer string) (string, bool) {
+	fallback := ""
+	for _, rule := range rules {
+		if rule.Answer == "*" {
+			if fallback == "" {
+				fallback = rule.ChallengeID
+			}
+			continue
+		}
+		if answerMatches(answer, rule.Answer) {
+			return rule.ChallengeID, true
+		}
+	}
+	return fallback, fallback != ""
+}
+
+// branchTarget liefert die Challenge, auf die die Antwort verzweigt (nil: lineare Route).
+// Eine unbekannte aktuelle Challenge hat keine NextRules; über ihre Position entscheidet
+// die lineare Route.
+func (app *App) branchTarget(ctx context.Context, challengeID, answer string) (*Challenge, error) {
+	current, err := app.getChallenge(ctx, challengeID)
+	if errors.Is(err, errChallengeNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Abrufen der Challenge %s: %w", challengeID, err)
+	}
+
+	targetID, ok := matchNextRule(current.NextRules, answer)
+	if !ok {
+		return nil, nil
+	}
+
+	target, err := app.getChallenge(ctx, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Abrufen des Verzweigungsziels %s: %w", targetID, err)
+	}
+	debugf("Antwort %q verzweigt von Challenge %s zu %s", answer, challengeID, targetID)
+	return target, nil
+}
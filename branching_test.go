@@ -0,0 +1,94 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseNextRules(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []nextRule
+		wantErr bool
+	}{
+		{in: ""},
+		{in: "links => 5", want: []nextRule{{Answer: "links", ChallengeID: "5"}}},
+		{
+			in:   " links => 5 ;rechts=>6\n\n* => 2 ",
+			want: []nextRule{{Answer: "links", ChallengeID: "5"}, {Answer: "rechts", ChallengeID: "6"}, {Answer: "*", ChallengeID: "2"}},
+		},
+		{in: "Altes Wasser => 3", want: []nextRule{{Answer: "Altes Wasser", ChallengeID: "3"}}},
+		{in: "links -> 5", wantErr: true},
+		{in: "=> 5", wantErr: true},
+		{in: "links => fünf", wantErr: true},
+		{in: "links => 0", wantErr: true},
+		{in: "links => 5; rechts", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseNextRules(tt.in)
+		if (err != nil) != tt.wantErr || !slices.Equal(got, tt.want) {
+			t.Errorf("parseNextRules(%q) = %v, %v; erwartet %v, Fehler = %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestMatchNextRule(t *testing.T) {
+	rules := []nextRule{{Answer: "*", ChallengeID: "2"}, {Answer: "links", ChallengeID: "5"}, {Answer: "rechts", ChallengeID: "6"}, {Answer: "*", ChallengeID: "7"}}
+	tests := []struct {
+		rules  []nextRule
+		answer string
+		want   string
+		wantOK bool
+	}{
+		{rules: rules, answer: "links", want: "5", wantOK: true},
+		{rules: rules, answer: "  RECHTS ", want: "6", wantOK: true},
+		{rules: rules, answer: "geradeaus", want: "2", wantOK: true},
+		{rules: rules, answer: "", want: "2", wantOK: true},
+		{rules: rules[1:3], answer: "geradeaus"},
+		{answer: "links"},
+	}
+	for _, tt := range tests {
+		got, ok := matchNextRule(tt.rules, tt.answer)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("matchNextRule(%v, %q) = %q, %v; erwartet %q, %v", tt.rules, tt.answer, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestAnswerBranching(t *testing.T) {
+	tests := []struct {
+		name      string
+		rules     string // NextRules des Brunnens ("": keine)
+		challenge string
+		answer    string
+		wantSlug  string // Ziel der Weiterleitung
+	}{
+		{name: "ohne Regeln linear", challenge: "1", answer: "links", wantSlug: "demo-kirchturm"},
+		{name: "passende Antwort", rules: "links => 3; rechts => 4", challenge: "1", answer: "links", wantSlug: "demo-stadtpark"},
+		{name: "Antwort normalisiert", rules: "links => 3; rechts => 4", challenge: "1", answer: " RECHTS ", wantSlug: "demo-rathaus"},
+		{name: "keine Regel passt", rules: "links => 3; rechts => 4", challenge: "1", answer: "geradeaus", wantSlug: "demo-kirchturm"},
+		{name: "Auffangregel", rules: "links => 3; * => 4", challenge: "1", answer: "geradeaus", wantSlug: "demo-rathaus"},
+		{name: "fehlerhafte Regeln linear", rules: "links => 3; rechts", challenge: "1", answer: "links", wantSlug: "demo-kirchturm"},
+		{name: "Regeln anderer Challenge", rules: "* => 4", challenge: "2", answer: "links", wantSlug: "demo-stadtpark"},
+		{name: "unbekannte aktuelle Challenge", challenge: "9", answer: "links", wantSlug: "demo-brunnen"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			if tt.rules != "" {
+				setNextRules(t, ta, "demo-brunnen", tt.rules)
+			}
+
+			w := ta.do(http.MethodPost, "/next/"+tt.challenge, url.Values{"team": {"Demo Team"}, "answer": {tt.answer}})
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+			if want := demoChallengeURL + tt.wantSlug; !strings.Contains(w.Body.String(), want) {
+				t.Errorf("Weiterleitung auf %s fehlt:\n%s", want, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Admin-Bypass-Tokens erlauben Organisatoren, ein Team an den Schutzmechanismen der
+// Einreichung vorbei weiterzuleiten. Sie sind an Team und Challenge gebunden, laufen ab
+// und werden mit dem ADMIN_TOKEN signiert.
+const (
+	bypassFormField       = "bypass"
+	defaultBypassTokenTTL = 15 * time.Minute
+	maxBypassTokenTTL     = 24 * time.Hour
+)
+
+var (
+	errBypassInvalid = errors.New("ungültiges Bypass-Token")
+	errBypassExpired = errors.New("Bypass-Token ist abgelaufen")
+)
+
+// signBypassToken erzeugt ein Token der Form <payload>.<signatur> (beides base64url)
+func signBypassToken(secret, team, challengeID string, expires time.Time) string {
+	payload := strings.Join([]string{normalizeTeamName(team), challengeID, strconv.FormatInt(expires.Unix(), 10)}, "|")
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(bypassSignature(secret, encoded))
+}
+
+func bypassSignature(secret, encodedPayload string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(encodedPayload))
+	return mac.Sum(nil)
+}
+
+// verifyBypassToken prüft Signatur, Ablauf sowie Team und Challenge des Tokens
+func verifyBypassToken(secret, token, team, challengeID string, now time.Time) error {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok || secret == "" {
+		return errBypassInvalid
+	}
+	gotSig, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(gotSig, bypassSignature(secret, encoded)) {
+		return errBypassInvalid
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return errBypassInvalid
+	}
+	parts := strings.Split(string(payload), "|")
+	if len(parts) != 3 || parts[0] != normalizeTeamName(team) || parts[1] != challengeID {
+		return errBypassInvalid
+	}
+	expires, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return errBypassInvalid
+	}
+	if now.After(time.Unix(expires, 0)) {
+		return errBypassExpired
+	}
+	return nil
+}
+
+// handleAdminBypassToken stellt ein Bypass-Token für ein Team und eine Challenge aus.
+// Die Gültigkeit kann per ?ttl= (z.B. 30m) angepasst werden.
+func (app *App) handleAdminBypassToken(c *gin.Context) {
+	teamName := c.Param("team")
+	challengeID := c.Param("id")
+
+	ttl := defaultBypassTokenTTL
+	if v := c.Query("ttl"); v != "" {
+		parsed, err := time.ParseDuration(v)
+		if err != nil || parsed <= 0 || parsed > maxBypassTokenTTL {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("ttl muss zwischen 1s und %v liegen", maxBypassTokenTTL)})
+			return
+		}
+		ttl = parsed
+	}
+
+	page, err := app.findTeam(c.Request.Context(), teamName)
+	if err != nil || page == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team nicht gefunden"})
+		return
+	}
+	// Geprüft wird das Token gegen den Namen aus Notion (siehe resolveTeam)
+	teamName = teamTitle(page, teamName)
+
+	expires := time.Now().Add(ttl)
+	infof("Bypass-Token für Team %s, Challenge %s ausgestellt (gültig bis %s)", teamName, challengeID, expires.Format(time.RFC3339))
+	c.JSON(http.StatusOK, gin.H{
+		"team":        teamName,
+		"challengeID": challengeID,
+		"token":       signBypassToken(app.adminToken, teamName, challengeID, expires),
+		"field":       bypassFormField,
+		"expiresAt":   expires,
+	})
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyBypassToken(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	valid := signBypassToken("geheim", "Demo Team", "3", now.Add(time.Minute))
+	payload, _, _ := strings.Cut(valid, ".")
+
+	tests := []struct {
+		name    string
+		secret  string
+		token   string
+		team    string
+		id      string
+		now     time.Time
+		wantErr error
+	}{
+		{name: "gültig", secret: "geheim", token: valid, team: "Demo Team", id: "3", now: now},
+		{name: "Teamname anders geschrieben", secret: "geheim", token: valid, team: "demo  team", id: "3", now: now},
+		{name: "anderes Team", secret: "geheim", token: valid, team: "Die Füchse", id: "3", now: now, wantErr: errBypassInvalid},
+		{name: "andere Challenge", secret: "geheim", token: valid, team: "Demo Team", id: "4", now: now, wantErr: errBypassInvalid},
+		{name: "abgelaufen", secret: "geheim", token: valid, team: "Demo Team", id: "3", now: now.Add(2 * time.Minute), wantErr: errBypassExpired},
+		{name: "anderes Secret", secret: "anders", token: valid, team: "Demo Team", id: "3", now: now, wantErr: errBypassInvalid},
+		{name: "ohne Secret", secret: "", token: valid, team: "Demo Team", id: "3", now: now, wantErr: errBypassInvalid},
+		{name: "Signatur manipuliert", secret: "geheim", token: payload + ".AAAA", team: "Demo Team", id: "3", now: now, wantErr: errBypassInvalid},
+		{name: "kein Token", secret: "geheim", token: "irgendwas", team: "Demo Team", id: "3", now: now, wantErr: errBypassInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := verifyBypassToken(tt.secret, tt.token, tt.team, tt.id, tt.now); err != tt.wantErr {
+				t.Errorf("verifyBypassToken = %v, erwartet %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBypassAdvance(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      func(t *testing.T, ta *testApp) string
+		wantStatus int
+		wantDone   bool
+	}{
+		{
+			name: "ausgestelltes Token",
+			token: func(t *testing.T, ta *testApp) string {
+				w := ta.admin(http.MethodPost, "/admin/bypass/demo%20team/1", "")
+				if w.Code != http.StatusOK {
+					return ""
+				}
+				token, _ := decodeJSON(t, w)["token"].(string)
+				return token
+			},
+			wantStatus: http.StatusOK,
+			wantDone:   true,
+		},
+		{
+			name: "für eine andere Challenge",
+			token: func(t *testing.T, ta *testApp) string {
+				return signBypassToken(testAdminToken, "Demo Team", "2", time.Now().Add(time.Minute))
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "abgelaufen",
+			token: func(t *testing.T, ta *testApp) string {
+				return signBypassToken(testAdminToken, "Demo Team", "1", time.Now().Add(-time.Minute))
+			},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mit Bestätigungsschritt: nur der Bypass speichert ohne confirm=yes
+			ta := newTestApp(t, map[string]string{"CONFIRM_ADVANCE": "true"})
+			var events bytes.Buffer
+			ta.events = newJSONEventLogger(&events)
+
+			token := tt.token(t, ta)
+			if token == "" {
+				t.Fatal("kein Token ausgestellt")
+			}
+			w := ta.do(http.MethodPost, "/next/1", url.Values{"team": {"Demo Team"}, bypassFormField: {token}})
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, erwartet %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			_, done := ta.completedChallenges(ta.teamPage(t, demoTeamPageID))[1]
+			if done != tt.wantDone {
+				t.Errorf("Abschluss gespeichert = %v, erwartet %v", done, tt.wantDone)
+			}
+			if tt.wantDone {
+				logged := readEvents(t, events.Bytes())
+				if len(logged) != 1 || !logged[0].AdminAssisted {
+					t.Errorf("Events = %+v, erwartet eine admin-unterstützte Weiterleitung", logged)
+				}
+			}
+		})
+	}
+}
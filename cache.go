@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jomei/notionapi"
+)
+
+// cacheTTL bestimmt, wie lange Teamliste und Challenges aus dem Cache kommen
+const cacheTTL = 5 * time.Minute
+
+// ttlCache ist ein kleiner, nebenläufig sicherer Key-Value-Cache.
+// Alle Einträge laufen gemeinsam ab; ttl 0 bedeutet ohne Ablauf.
+type ttlCache[K comparable, V any] struct {
+	mu       sync.RWMutex
+	ttl      time.Duration
+	entries  map[K]V
+	loadedAt time.Time
+}
+
+func newTTLCache[K comparable, V any](ttl time.Duration) *ttlCache[K, V] {
+	return &ttlCache[K, V]{ttl: ttl}
+}
+
+// expired muss unter Lock aufgerufen werden
+func (tc *ttlCache[K, V]) expired() bool {
+	return tc.entries == nil || (tc.ttl > 0 && time.Since(tc.loadedAt) > tc.ttl)
+}
+
+func (tc *ttlCache[K, V]) get(key K) (V, bool) {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	if tc.expired() {
+		var zero V
+		return zero, false
+	}
+	value, ok := tc.entries[key]
+	return value, ok
+}
+
+func (tc *ttlCache[K, V]) set(key K, value V) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if tc.expired() {
+		tc.entries = make(map[K]V)
+		tc.loadedAt = time.Now()
+	}
+	tc.entries[key] = value
+}
+
+// replace ersetzt den kompletten Inhalt, z.B. nach dem Laden der ganzen DB
+func (tc *ttlCache[K, V]) replace(entries map[K]V) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	tc.entries = entries
+	tc.loadedAt = time.Now()
+}
+
+func (tc *ttlCache[K, V]) clear() {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	tc.entries = nil
+}
+
+// appCache hält häufig gelesene Notion-Daten im Speicher.
+// Jeder Cache hat seinen eigenen Lock, Zugriffe laufen nur über die Methoden.
+type appCache struct {
+	teamNames    *ttlCache[string, []string]
+	challenges   *ttlCache[int, *Challenge]
+	slugs        *ttlCache[string, *Challenge]
+	descriptions *ttlCache[int, template.HTML]
+	// challengeList enthält die komplette Challenge-DB (nur nach loadChallenges)
+	challengeList *ttlCache[string, []*Challenge]
+	// teamOptions ist das gerenderte Team-Dropdown, versioniert nach Teamliste
+	teamOptions *teamOptionsCache
+	// teamCodeMissing merkt sich, dass die Team-DB keine "Code" Property hat
+	teamCodeMissing *ttlCache[string, bool]
+}
+
+// teamNamesKey ist der einzige Schlüssel im Teamnamen-Cache
+const teamNamesKey = "all"
+
+// challengeListKey ist der einzige Schlüssel im Cache der kompletten Challenge-Liste
+const challengeListKey = "all"
+
+func newAppCache() *appCache {
+	return &appCache{
+		teamNames:       newTTLCache[string, []string](cacheTTL),
+		challenges:      newTTLCache[int, *Challenge](cacheTTL),
+		slugs:           newTTLCache[string, *Challenge](cacheTTL),
+		descriptions:    newTTLCache[int, template.HTML](0),
+		challengeList:   newTTLCache[string, []*Challenge](cacheTTL),
+		teamOptions:     &teamOptionsCache{},
+		teamCodeMissing: newTTLCache[string, bool](cacheTTL),
+	}
+}
+
+// cachedTeamNames liefert die Teamliste, solange sie nicht abgelaufen ist
+func (ac *appCache) cachedTeamNames() ([]string, bool) {
+	return ac.teamNames.get(teamNamesKey)
+}
+
+func (ac *appCache) storeTeamNames(names []string) {
+	ac.teamNames.set(teamNamesKey, names)
+	ac.teamOptions.update(names)
+}
+
+// teamOptionsHTML liefert das Dropdown-Fragment der zuletzt gespeicherten Teamliste mit selectedTeam vorausgewählt
+func (ac *appCache) teamOptionsHTML(selectedTeam string) template.HTML {
+	return selectTeamOption(ac.teamOptions.fragment(), selectedTeam)
+}
+
+// invalidateTeamNames verwirft die Teamliste, z.B. nach dem Anlegen neuer Teams
+func (ac *appCache) invalidateTeamNames() {
+	ac.teamNames.clear()
+}
+
+// teamCodeAbsent meldet, ob die letzte Suche nach einem Join-Code die "Code" Property vermisst hat
+func (ac *appCache) teamCodeAbsent() bool {
+	missing, ok := ac.teamCodeMissing.get(teamCodeProperty)
+	return ok && missing
+}
+
+func (ac *appCache) storeTeamCodeAbsent() {
+	ac.teamCodeMissing.set(teamCodeProperty, true)
+}
+
+// cachedChallenge liefert eine Challenge aus der id→Page Map
+func (ac *appCache) cachedChallenge(id int) (*Challenge, bool) {
+	return ac.challenges.get(id)
+}
+
+func (ac *appCache) storeChallenge(challenge *Challenge) {
+	ac.challenges.set(challenge.ID, challenge)
+	if challenge.Slug != "" {
+		ac.slugs.set(challenge.Slug, challenge)
+	}
+}
+
+// cachedSlug liefert eine Challenge aus der slug→Page Map
+func (ac *appCache) cachedSlug(slug string) (*Challenge, bool) {
+	return ac.slugs.get(slug)
+}
+
+func (ac *appCache) storeChallenges(challenges map[int]*Challenge) {
+	slugs := make(map[string]*Challenge)
+	for _, challenge := range challenges {
+		if challenge.Slug != "" {
+			slugs[challenge.Slug] = challenge
+		}
+	}
+	list := make([]*Challenge, 0, len(challenges))
+	for _, challenge := range challenges {
+		list = append(list, challenge)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+
+	ac.challenges.replace(challenges)
+	ac.slugs.replace(slugs)
+	ac.challengeList.set(challengeListKey, list)
+}
+
+// cachedChallengeList liefert alle Challenges nach ID sortiert, solange die Liste nicht abgelaufen ist
+func (ac *appCache) cachedChallengeList() ([]*Challenge, bool) {
+	return ac.challengeList.get(challengeListKey)
+}
+
+// cachedDescription liefert das gerenderte HTML einer Challenge-Beschreibung
+func (ac *appCache) cachedDescription(id int) (template.HTML, bool) {
+	return ac.descriptions.get(id)
+}
+
+func (ac *appCache) storeDescription(id int, description template.HTML) {
+	ac.descriptions.set(id, description)
+}
+
+// clearAll leert alle Caches und liefert deren Namen
+func (ac *appCache) clearAll() []string {
+	ac.teamNames.clear()
+	ac.challenges.clear()
+	ac.slugs.clear()
+	ac.descriptions.clear()
+	ac.challengeList.clear()
+	ac.teamOptions.reset()
+	ac.teamCodeMissing.clear()
+	return []string{"teamNames", "challenges", "slugs", "descriptions", "challengeList", "teamOptions", "teamCodeMissing"}
+}
+
+// handleAdminClearCache verwirft alle In-Memory-Caches, z.B. nach Änderungen in Notion
+// während des Events. Der nächste Zugriff lädt die Daten neu aus Notion. Vergebene
+// MVP-Ideen sind kein Cache, sondern Eventzustand, und bleiben erhalten.
+func (app *App) handleAdminClearCache(c *gin.Context) {
+	cleared := app.cache.clearAll()
+	if app.pendingWrites.clear() {
+		cleared = append(cleared, "optimisticWrites")
+	}
+	infof("Caches geleert: %s", strings.Join(cleared, ", "))
+	c.JSON(http.StatusOK, gin.H{"cleared": cleared})
+}
+
+// listChallengePages holt alle Pages aller Challenge-DBs (in der Reihenfolge von CHALLENGES_DB_IDS)
+func (app *App) listChallengePages(ctx context.Context) ([]notionapi.Page, error) {
+	var pages []notionapi.Page
+	for _, dbID := range app.challengeDBIDs {
+		query := &notionapi.DatabaseQueryRequest{PageSize: 100}
+		for {
+			result, err := app.queryDatabase(ctx, dbID, query)
+			if err != nil {
+				return nil, fmt.Errorf("fehler beim Abfragen der Challenge-Datenbank %s: %w", dbID, err)
+			}
+			pages = append(pages, result.Results...)
+
+			if !result.HasMore {
+				break
+			}
+			query.StartCursor = result.NextCursor
+		}
+	}
+	return pages, nil
+}
+
+// loadChallenges liest alle Challenge-DBs und füllt die gemeinsame id→Page Map.
+// Kommt eine ID mehrfach vor, gewinnt die erste Page und es wird gewarnt.
+func (app *App) loadChallenges(ctx context.Context) (map[int]*Challenge, error) {
+	pages, err := app.listChallengePages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	challenges := make(map[int]*Challenge)
+	for _, page := range pages {
+		if _, ok := challengeNumber(page); !ok {
+			continue
+		}
+		challenge, err := app.readChallenge(ctx, page)
+		if err != nil {
+			return nil, err
+		}
+		if existing, ok := challenges[challenge.ID]; ok {
+			warnf("Challenge-ID %d ist doppelt vergeben (Pages %s in DB %s und %s in DB %s), nutze %s",
+				challenge.ID, existing.PageID, existing.DatabaseID, challenge.PageID, challenge.DatabaseID, existing.PageID)
+			continue
+		}
+		challenges[challenge.ID] = challenge
+	}
+
+	app.cache.storeChallenges(challenges)
+	return challenges, nil
+}
+
+// getAllChallenges liefert alle Challenges nach ID sortiert, bevorzugt aus dem Cache
+func (app *App) getAllChallenges(ctx context.Context) ([]*Challenge, error) {
+	if cached, ok := app.cache.cachedChallengeList(); ok {
+		return cached, nil
+	}
+	if _, err := app.loadChallenges(ctx); err != nil {
+		return nil, err
+	}
+	list, _ := app.cache.cachedChallengeList()
+	return list, nil
+}
+
+// warmUp lädt Teamliste und Challenges vorab in den Cache.
+// Fehler werden nur geloggt, damit der Server trotzdem startet.
+func (app *App) warmUp(ctx context.Context) {
+	start := time.Now()
+
+	teamNames, err := app.getAllTeamNames(ctx)
+	if err != nil {
+		warnf("Warm-up: Fehler beim Laden der Teamliste: %v", err)
+	}
+
+	challenges, err := app.loadChallenges(ctx)
+	if err != nil {
+		warnf("Warm-up: Fehler beim Laden der Challenges: %v", err)
+	}
+
+	infof("Warm-up abgeschlossen in %v (%d Teams, %d Challenges)", time.Since(start), len(teamNames), len(challenges))
+}
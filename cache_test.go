@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestWarmUp(t *testing.T) {
+	tests := []struct {
+		name      string
+		runs      int
+		failing   bool // Notion liefert beim Warm-up nur Fehler
+		wantCalls bool // Lesen nach dem Warm-up braucht noch Notion-Aufrufe
+	}{
+		{name: "einmal", runs: 1},
+		{name: "wiederholt", runs: 3},
+		{name: "Notion nicht erreichbar", runs: 1, failing: true, wantCalls: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			ctx := context.Background()
+
+			if tt.failing {
+				ta.notion = &stubNotion{notionService: ta.store, query: func(context.Context, string, *notionapi.DatabaseQueryRequest) (*notionapi.DatabaseQueryResponse, error) {
+					return nil, notionError(http.StatusServiceUnavailable, "service_unavailable", "down")
+				}}
+			}
+			for i := 0; i < tt.runs; i++ {
+				ta.warmUp(ctx)
+			}
+
+			counting := &countingNotion{notionService: ta.store}
+			ta.notion = counting
+			names, err := ta.getAllTeamNames(ctx)
+			if err != nil || len(names) != 2 {
+				t.Fatalf("Teamliste = %v, %v", names, err)
+			}
+			for _, id := range []string{"1", "2", "3", "4"} {
+				if _, err := ta.getChallenge(ctx, id); err != nil {
+					t.Fatalf("Challenge %s: %v", id, err)
+				}
+			}
+			if _, err := ta.getChallengeBySlug(ctx, "demo-rathaus"); err != nil {
+				t.Fatalf("Slug: %v", err)
+			}
+			all, err := ta.getAllChallenges(ctx)
+			if err != nil || len(all) != 4 {
+				t.Fatalf("Challenges = %d, %v", len(all), err)
+			}
+
+			if calls := counting.calls.Load(); (calls > 0) != tt.wantCalls {
+				t.Errorf("%d Notion-Aufrufe nach dem Warm-up", calls)
+			}
+		})
+	}
+}
+
+func TestTTLCache(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		age     time.Duration // Alter der Einträge beim Lesen
+		clear   bool
+		wantHit bool
+	}{
+		{name: "frisch", ttl: time.Minute, wantHit: true},
+		{name: "abgelaufen", ttl: time.Minute, age: 2 * time.Minute},
+		{name: "ohne Ablauf", ttl: 0, age: 24 * time.Hour, wantHit: true},
+		{name: "geleert", ttl: time.Minute, clear: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := newTTLCache[string, int](tt.ttl)
+			tc.set("a", 1)
+			tc.loadedAt = tc.loadedAt.Add(-tt.age)
+			if tt.clear {
+				tc.clear()
+			}
+			if got, ok := tc.get("a"); ok != tt.wantHit || (ok && got != 1) {
+				t.Errorf("get = %d, %v, erwartet Treffer %v", got, ok, tt.wantHit)
+			}
+		})
+	}
+}
+
+func TestTTLCacheSetAfterExpiry(t *testing.T) {
+	tc := newTTLCache[string, int](time.Minute)
+	tc.set("alt", 1)
+	tc.loadedAt = tc.loadedAt.Add(-2 * time.Minute)
+
+	// Nach dem Ablauf beginnt set einen neuen Stand, alte Einträge tauchen nicht wieder auf
+	tc.set("neu", 2)
+	if _, ok := tc.get("alt"); ok {
+		t.Error("abgelaufener Eintrag ist nach set wieder sichtbar")
+	}
+	if got, ok := tc.get("neu"); !ok || got != 2 {
+		t.Errorf("neu = %d, %v", got, ok)
+	}
+}
+
+// TestAppCacheConcurrent greift aus vielen Goroutinen gleichzeitig auf alle Caches zu;
+// mit go test -race meldet der Race-Detector ungeschützte Zugriffe.
+func TestAppCacheConcurrent(t *testing.T) {
+	ac := newAppCache()
+	mvpUsed, err := newMVPUsedSet("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ideas := []mvpIdea{{Name: "Solarlampe"}, {Name: "Faltboot"}, {Name: "Wasserfilter"}}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				id := i % 10
+				ac.storeTeamNames([]string{fmt.Sprintf("Team %d", g), "Demo Team"})
+				ac.cachedTeamNames()
+				ac.teamOptionsHTML("Demo Team")
+
+				ac.storeChallenge(&Challenge{ID: id, Slug: fmt.Sprintf("slug-%d", id)})
+				ac.cachedChallenge(id)
+				ac.cachedSlug(fmt.Sprintf("slug-%d", id))
+				ac.storeChallenges(map[int]*Challenge{id: {ID: id}})
+				ac.cachedChallengeList()
+
+				ac.storeDescription(id, "<p>Beschreibung</p>")
+				ac.cachedDescription(id)
+
+				mvpUsed.assign(ideas, 2)
+				mvpUsed.distribution(ideas)
+
+				if i%50 == 0 {
+					ac.clearAll()
+					ac.invalidateTeamNames()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestAdminClearCache(t *testing.T) {
+	tests := []struct {
+		name string
+		// read liest über die App, change ändert danach die Daten direkt im Demo-Speicher
+		read   func(ta *testApp) string
+		change func(ta *testApp)
+		before string // Ergebnis nach der Änderung, aber vor dem Leeren (Cache)
+		after  string // Ergebnis nach dem Leeren (neu aus Notion)
+	}{
+		{
+			name: "Challenges",
+			read: func(ta *testApp) string {
+				challenge, err := ta.getChallenge(context.Background(), "1")
+				if err != nil {
+					return err.Error()
+				}
+				return challenge.Title
+			},
+			change: func(ta *testApp) {
+				ta.store.mu.Lock()
+				defer ta.store.mu.Unlock()
+				ta.store.pages["demo-brunnen"].Properties["Name"] = &notionapi.TitleProperty{Title: demoText("Neuer Brunnen")}
+			},
+			before: "Der alte Brunnen",
+			after:  "Neuer Brunnen",
+		},
+		{
+			name: "Teamliste im Formular",
+			read: func(ta *testApp) string {
+				w := ta.do(http.MethodGet, "/next/1", nil)
+				return fmt.Sprint(strings.Contains(w.Body.String(), `<option value="Neue Crew">`))
+			},
+			change: func(ta *testApp) { addDemoTeam(ta.store, "Neue Crew") },
+			before: "false",
+			after:  "true",
+		},
+		{
+			name: "vergebene MVPs bleiben",
+			read: func(ta *testApp) string { return ta.mvpUsed.teamMVP(demoTeamPageID) },
+			change: func(ta *testApp) {
+				ta.mvpUsed.assignTeam(demoTeamPageID, "Solarlampe", ta.availableMVPIdeas())
+			},
+			before: "Solarlampe",
+			after:  "Solarlampe",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			tt.read(ta)
+			tt.change(ta)
+
+			if got := tt.read(ta); got != tt.before {
+				t.Errorf("vor dem Leeren = %q, erwartet %q", got, tt.before)
+			}
+
+			w := ta.admin(http.MethodPost, "/admin/cache/clear", "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+			cleared := fmt.Sprint(decodeJSON(t, w)["cleared"])
+			for _, name := range []string{"teamNames", "challenges", "descriptions", "teamOptions"} {
+				if !strings.Contains(cleared, name) {
+					t.Errorf("%s fehlt in cleared: %s", name, cleared)
+				}
+			}
+
+			if got := tt.read(ta); got != tt.after {
+				t.Errorf("nach dem Leeren = %q, erwartet %q", got, tt.after)
+			}
+		})
+	}
+}
+
+func TestAdminClearCacheRequiresToken(t *testing.T) {
+	ta := newTestApp(t, nil)
+	if w := ta.do(http.MethodPost, "/admin/cache/clear", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("Status = %d, erwartet 401", w.Code)
+	}
+}
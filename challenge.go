@@ -0,0 +1,548 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jomei/notionapi"
+)
+
+// errChallengeNotFound wird geliefert, wenn keine Challenge mit der ID existiert
+var errChallengeNotFound = errors.New("challenge nicht gefunden")
+
+// challengeSlugProperty enthält den optionalen Text-Slug einer Challenge (z.B. "river-crossing")
+const challengeSlugProperty = "Slug"
+
+// challengePointsProperty enthält die Punkte, die eine Challenge einbringt
+const challengePointsProperty = "Points"
+
+// challengeRedirectTemplateProperty nennt ein eigenes Template (aus templates/) für die
+// Weiterleitung zu dieser Challenge, z.B. "redirect-video.html"
+const challengeRedirectTemplateProperty = "RedirectTemplate"
+
+// challengeTeaserProperty enthält einen kurzen Vorgeschmack, der auf dem Teamformular erscheint
+const challengeTeaserProperty = "Teaser"
+
+// defaultRedirectTemplate ist die Weiterleitungsseite ohne eigenes Template
+const defaultRedirectTemplate = "redirect.html"
+
+// Challenge fasst die Daten einer Challenge-Page zusammen
+type Challenge struct {
+	ID               int           `json:"id"`
+	PageID           string        `json:"-"`
+	Title            string        `json:"title"`
+	Slug             string        `json:"slug,omitempty"`
+	Points           float64       `json:"points"`
+	Description      string        `json:"description,omitempty"`
+	Teaser           string        `json:"teaser,omitempty"`
+	DatabaseID       string        `json:"-"`
+	ImageURL         string        `json:"imageURL,omitempty"`
+	URL              string        `json:"url"`
+	NextURL          string        `json:"nextURL"`
+	Prerequisites    []string      `json:"-"`
+	RedirectTemplate string        `json:"-"`
+	Answer           string        `json:"-"`
+	Hint             string        `json:"-"`
+	MaxAttempts      int           `json:"-"`
+	NextRules        []nextRule    `json:"-"`
+	MinDwell         time.Duration `json:"-"`
+}
+
+// handleAPIChallenge liefert die Details einer Challenge als JSON
+func (app *App) handleAPIChallenge(c *gin.Context) {
+	challenge, err := app.getChallenge(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, errChallengeNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Challenge nicht gefunden"})
+		return
+	}
+	if err != nil {
+		errorf("Fehler beim Abrufen der Challenge: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fehler beim Abrufen der Challenge"})
+		return
+	}
+
+	c.JSON(http.StatusOK, challenge)
+}
+
+// orderEntry ist eine Zeile der kanonischen Challenge-Reihenfolge
+type orderEntry struct {
+	Position int    `json:"position"`
+	ID       int    `json:"id"`
+	Title    string `json:"title"`
+	URL      string `json:"url"`
+}
+
+// handleAPIOrder liefert alle Challenges aufsteigend nach ID, unabhängig von den Routen der Teams,
+// z.B. als Vorlage für die Beschilderung
+func (app *App) handleAPIOrder(c *gin.Context) {
+	challenges, err := app.getAllChallenges(c.Request.Context())
+	if err != nil {
+		errorf("Fehler beim Laden der Challenges: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fehler beim Laden der Challenges"})
+		return
+	}
+
+	order := make([]orderEntry, len(challenges))
+	for i, challenge := range challenges {
+		order[i] = orderEntry{Position: i + 1, ID: challenge.ID, Title: challenge.Title, URL: challenge.URL}
+	}
+	c.JSON(http.StatusOK, gin.H{"challenges": order})
+}
+
+// maxSearchResults begrenzt die Treffer von /api/challenges/search und /api/teams/search
+const maxSearchResults = 20
+
+// handleAPIChallengeSearch sucht Challenges, deren Titel die Anfrage enthält (ohne Groß-/Kleinschreibung)
+func (app *App) handleAPIChallengeSearch(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Suchbegriff q fehlt"})
+		return
+	}
+
+	challenges, err := app.getAllChallenges(c.Request.Context())
+	if err != nil {
+		errorf("Fehler beim Laden der Challenges: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fehler beim Laden der Challenges"})
+		return
+	}
+
+	results, truncated := searchChallenges(challenges, query, maxSearchResults)
+	c.JSON(http.StatusOK, gin.H{
+		"query":     query,
+		"results":   results,
+		"truncated": truncated,
+	})
+}
+
+// searchChallenges filtert Challenges nach Titel und liefert höchstens limit Treffer
+func searchChallenges(challenges []*Challenge, query string, limit int) ([]*Challenge, bool) {
+	query = strings.ToLower(query)
+	results := []*Challenge{}
+	for _, challenge := range challenges {
+		if !strings.Contains(strings.ToLower(challenge.Title), query) {
+			continue
+		}
+		if len(results) == limit {
+			return results, true
+		}
+		results = append(results, challenge)
+	}
+	return results, false
+}
+
+// getChallenge sucht eine Challenge über ihre numerische ID in der Challenge-DB.
+// Alles außer einer ganzen Zahl ist keine gültige ID (errChallengeNotFound).
+func (app *App) getChallenge(ctx context.Context, id string) (*Challenge, error) {
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, errChallengeNotFound
+	}
+	num := float64(n)
+
+	if cached, ok := app.cache.cachedChallenge(n); ok {
+		return cached, nil
+	}
+
+	// Suche Challenge mit dieser ID (Number Property), Fallback auf "ID" (groß).
+	// Bei mehreren Challenge-DBs gewinnt die erste in CHALLENGES_DB_IDS.
+	var lastErr error
+	rejected := false
+	for _, dbID := range app.challengeDBIDs {
+		for _, prop := range []string{"id", "ID"} {
+			filter := &notionapi.DatabaseQueryRequest{
+				Filter: &notionapi.PropertyFilter{
+					Property: prop,
+					Number: &notionapi.NumberFilterCondition{
+						Equals: &num,
+					},
+				},
+			}
+
+			result, err := app.queryDatabase(ctx, dbID, filter)
+			if isMissingPropertyError(err) {
+				// Die DB nutzt die andere Schreibweise der ID-Property oder eine Formel bzw.
+				// ein Rollup, worauf Notion keinen number-Filter anwendet
+				rejected = true
+				continue
+			}
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			if len(result.Results) > 1 {
+				pageIDs := make([]string, 0, len(result.Results))
+				for _, page := range result.Results {
+					pageIDs = append(pageIDs, string(page.ID))
+				}
+				warnf("Challenge-ID %s ist mehrfach vergeben (Pages: %s), nutze die erste", id, strings.Join(pageIDs, ", "))
+			}
+			if len(result.Results) > 0 {
+				challenge, err := app.readChallenge(ctx, result.Results[0])
+				if err != nil {
+					return nil, err
+				}
+				app.cache.storeChallenge(challenge)
+				return challenge, nil
+			}
+		}
+	}
+
+	if rejected && lastErr == nil {
+		// IDs aus Formeln und Rollups findet nur challengeNumber in der vollständigen Liste
+		challenges, err := app.getAllChallenges(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, challenge := range challenges {
+			if challenge.ID == n {
+				return challenge, nil
+			}
+		}
+	}
+
+	if lastErr != nil {
+		return nil, fmt.Errorf("fehler beim Abfragen der Challenge-Datenbank: %w", lastErr)
+	}
+	return nil, errChallengeNotFound
+}
+
+// isMissingPropertyError erkennt die Antwort von Notion auf einen Filter über eine
+// Property, die es in der Datenbank nicht gibt (400 validation_error)
+func isMissingPropertyError(err error) bool {
+	var apiErr *notionapi.Error
+	return errors.As(err, &apiErr) && apiErr.Status == http.StatusBadRequest &&
+		apiErr.Code == "validation_error" && strings.Contains(strings.ToLower(apiErr.Message), "property")
+}
+
+// getChallengeBySlug sucht eine Challenge über ihre "Slug" Property
+func (app *App) getChallengeBySlug(ctx context.Context, slug string) (*Challenge, error) {
+	if cached, ok := app.cache.cachedSlug(slug); ok {
+		return cached, nil
+	}
+
+	for _, dbID := range app.challengeDBIDs {
+		result, err := app.queryDatabase(ctx, dbID, &notionapi.DatabaseQueryRequest{
+			Filter: &notionapi.PropertyFilter{
+				Property: challengeSlugProperty,
+				RichText: &notionapi.TextFilterCondition{
+					Equals: slug,
+				},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("fehler beim Abfragen der Challenge-Datenbank: %w", err)
+		}
+		if len(result.Results) > 0 {
+			challenge, err := app.readChallenge(ctx, result.Results[0])
+			if err != nil {
+				return nil, err
+			}
+			app.cache.storeChallenge(challenge)
+			return challenge, nil
+		}
+	}
+	return nil, errChallengeNotFound
+}
+
+// parseDBIDs zerlegt eine kommagetrennte Liste von Datenbank-IDs (Duplikate entfallen)
+func parseDBIDs(s string) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, id := range strings.Split(s, ",") {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[normalizePageID(id)] {
+			continue
+		}
+		seen[normalizePageID(id)] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// resolveChallengeParam übersetzt den :id Parameter in eine numerische Challenge-ID.
+// Rein numerische Werte sind immer IDs, alles andere wird als Slug gesucht.
+func (app *App) resolveChallengeParam(ctx context.Context, param string) (string, error) {
+	if _, err := strconv.Atoi(param); err == nil {
+		return param, nil
+	}
+
+	challenge, err := app.getChallengeBySlug(ctx, param)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(challenge.ID), nil
+}
+
+// readChallenge baut eine Challenge aus einer Page und lädt gekürzte Relations nach
+func (app *App) readChallenge(ctx context.Context, page notionapi.Page) (*Challenge, error) {
+	challenge := app.challengeFromPage(page)
+	if err := app.loadPrerequisites(ctx, challenge, page); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// challengeFromPage extrahiert die Challenge-Daten aus den Properties einer Page
+func (app *App) challengeFromPage(page notionapi.Page) *Challenge {
+	challenge := &Challenge{
+		PageID:     string(page.ID),
+		DatabaseID: string(page.Parent.DatabaseID),
+		Title:      pageTitle(page),
+		URL:        app.formatNotionURL(string(page.ID)),
+	}
+
+	if num, ok := challengeNumber(page); ok {
+		challenge.ID = num
+		challenge.NextURL = fmt.Sprintf("/next/%d", num)
+	}
+
+	// Challenges ohne "Points" Property zählen 0 Punkte
+	if p, ok := page.Properties[challengePointsProperty].(*notionapi.NumberProperty); ok {
+		challenge.Points = p.Number
+	}
+
+	if p, ok := page.Properties[challengeSlugProperty].(*notionapi.RichTextProperty); ok {
+		challenge.Slug = strings.TrimSpace(richTextPlain(p.RichText))
+	}
+
+	challenge.Prerequisites = prerequisitePageIDs(page)
+	readAnswerProperties(challenge, page)
+	readNextRules(challenge, page)
+	readMinDwell(challenge, page)
+
+	switch p := page.Properties[challengeRedirectTemplateProperty].(type) {
+	case *notionapi.RichTextProperty:
+		challenge.RedirectTemplate = strings.TrimSpace(richTextPlain(p.RichText))
+	case *notionapi.SelectProperty:
+		challenge.RedirectTemplate = p.Select.Name
+	}
+
+	if p, ok := page.Properties[challengeTeaserProperty].(*notionapi.RichTextProperty); ok {
+		challenge.Teaser = strings.TrimSpace(richTextPlain(p.RichText))
+	}
+
+	for _, name := range []string{"Description", "Beschreibung"} {
+		if p, ok := page.Properties[name].(*notionapi.RichTextProperty); ok {
+			challenge.Description = richTextPlain(p.RichText)
+			break
+		}
+	}
+
+	for _, name := range []string{"Image", "Bild"} {
+		switch p := page.Properties[name].(type) {
+		case *notionapi.FilesProperty:
+			for _, f := range p.Files {
+				if f.External != nil && f.External.URL != "" {
+					challenge.ImageURL = f.External.URL
+				} else if f.File != nil && f.File.URL != "" {
+					challenge.ImageURL = f.File.URL
+				}
+				if challenge.ImageURL != "" {
+					break
+				}
+			}
+		case *notionapi.URLProperty:
+			challenge.ImageURL = p.URL
+		}
+		if challenge.ImageURL != "" {
+			break
+		}
+	}
+
+	return challenge
+}
+
+// challengeNumber liest die numerische Challenge-ID aus der "id" bzw. "ID" Property.
+// Neben Number-Properties werden auch Formeln und Rollups mit Zahlenwert akzeptiert.
+func challengeNumber(page notionapi.Page) (int, bool) {
+	for _, name := range []string{"id", "ID"} {
+		prop, exists := page.Properties[name]
+		if !exists {
+			continue
+		}
+		if num, ok := propertyNumber(prop); ok {
+			return int(num), true
+		}
+		warnf("Challenge-Page %s: Property %q hat unerwarteten Typ %T, ID wird ignoriert", page.ID, name, prop)
+	}
+	return 0, false
+}
+
+// propertyNumber liest einen Zahlenwert aus Number-, Formula- oder Rollup-Properties
+func propertyNumber(prop notionapi.Property) (float64, bool) {
+	switch p := prop.(type) {
+	case *notionapi.NumberProperty:
+		return p.Number, true
+	case *notionapi.FormulaProperty:
+		switch p.Formula.Type {
+		case notionapi.FormulaTypeNumber:
+			return p.Formula.Number, true
+		case notionapi.FormulaTypeString:
+			// z.B. eine Formel, die die ID als Text liefert
+			num, err := strconv.ParseFloat(strings.TrimSpace(p.Formula.String), 64)
+			return num, err == nil
+		}
+	case *notionapi.RollupProperty:
+		switch p.Rollup.Type {
+		case notionapi.RollupTypeNumber:
+			return p.Rollup.Number, true
+		case notionapi.RollupTypeArray:
+			// "Show original" liefert ein Array; nur ein einzelner Wert ist eindeutig
+			if len(p.Rollup.Array) == 1 {
+				return propertyNumber(p.Rollup.Array[0])
+			}
+		}
+	}
+	return 0, false
+}
+
+// defaultChallengeURLTemplate ist das Standardformat für CHALLENGE_URL_TEMPLATE.
+// {id} wird durch die Page-ID ohne Bindestriche ersetzt, {uuid} durch die ID mit Bindestrichen.
+const defaultChallengeURLTemplate = "https://marcbaumholz.notion.site/{id}"
+
+// formatNotionURL baut die öffentliche URL einer Challenge-Page aus dem Template
+func (app *App) formatNotionURL(pageID string) string {
+	return expandURLTemplate(app.challengeURLTemplate, pageID)
+}
+
+func expandURLTemplate(tmpl, pageID string) string {
+	// Entferne Bindestriche aus der ID für die URL
+	return strings.NewReplacer(
+		"{id}", strings.ReplaceAll(pageID, "-", ""),
+		"{uuid}", pageID,
+	).Replace(tmpl)
+}
+
+// validateURLTemplate prüft CHALLENGE_URL_TEMPLATE beim Start
+func validateURLTemplate(tmpl string) error {
+	if !strings.Contains(tmpl, "{id}") && !strings.Contains(tmpl, "{uuid}") {
+		return fmt.Errorf("CHALLENGE_URL_TEMPLATE %q muss {id} oder {uuid} enthalten", tmpl)
+	}
+	u, err := url.Parse(expandURLTemplate(tmpl, "00000000-0000-0000-0000-000000000000"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("CHALLENGE_URL_TEMPLATE %q ergibt keine gültige http(s)-URL", tmpl)
+	}
+	return nil
+}
+
+// richTextPlain setzt alle Rich-Text-Fragmente zu einem String zusammen
+func richTextPlain(parts []notionapi.RichText) string {
+	var sb strings.Builder
+	for _, part := range parts {
+		sb.WriteString(part.PlainText)
+	}
+	return sb.String()
+}
+
+// parseStartChallengeID liest START_CHALLENGE_ID (leer: niedrigste Challenge-ID)
+func parseStartChallengeID(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	id, err := strconv.Atoi(s)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("START_CHALLENGE_ID muss eine positive ganze Zahl sein, ist aber %q", s)
+	}
+	return id, nil
+}
+
+// resolveStartChallenge bestimmt die Start-Challenge für den Link auf der Startseite.
+// Eine konfigurierte ID muss existieren, sonst wird die niedrigste Challenge-ID verwendet.
+func (app *App) resolveStartChallenge(ctx context.Context, configuredID int) (*Challenge, error) {
+	if configuredID > 0 {
+		challenge, err := app.getChallenge(ctx, strconv.Itoa(configuredID))
+		if err != nil {
+			return nil, fmt.Errorf("START_CHALLENGE_ID %d: %w", configuredID, err)
+		}
+		return challenge, nil
+	}
+
+	challenges, err := app.loadChallenges(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var start *Challenge
+	for _, challenge := range challenges {
+		if start == nil || challenge.ID < start.ID {
+			start = challenge
+		}
+	}
+	if start == nil {
+		return nil, errChallengeNotFound
+	}
+	return start, nil
+}
+
+// redirectTemplateName normalisiert den Template-Namen einer Challenge ("video" → "video.html")
+func redirectTemplateName(challenge *Challenge) string {
+	name := challenge.RedirectTemplate
+	if name == "" {
+		return defaultRedirectTemplate
+	}
+	if !strings.HasSuffix(name, ".html") {
+		name += ".html"
+	}
+	return name
+}
+
+// redirectTemplate liefert das Weiterleitungs-Template einer Challenge.
+// Unbekannte Templates fallen auf redirect.html zurück, statt beim Rendern zu scheitern.
+func (app *App) redirectTemplate(challenge *Challenge) string {
+	name := redirectTemplateName(challenge)
+	if app.templates.Lookup(name) == nil {
+		warnf("Challenge %d: RedirectTemplate %q existiert nicht, nutze %s", challenge.ID, name, defaultRedirectTemplate)
+		return defaultRedirectTemplate
+	}
+	return name
+}
+
+// duplicateChallenge beschreibt mehrere Challenge-Pages mit derselben numerischen ID
+type duplicateChallenge struct {
+	ID      int      `json:"id"`
+	PageIDs []string `json:"pageIDs"`
+}
+
+// findDuplicateChallengeIDs durchsucht alle Challenge-DBs nach doppelt vergebenen IDs
+func (app *App) findDuplicateChallengeIDs(ctx context.Context) ([]duplicateChallenge, error) {
+	pages, err := app.listChallengePages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int][]string)
+	for _, page := range pages {
+		if num, ok := challengeNumber(page); ok {
+			byID[num] = append(byID[num], string(page.ID))
+		}
+	}
+
+	var duplicates []duplicateChallenge
+	for id, pageIDs := range byID {
+		if len(pageIDs) > 1 {
+			duplicates = append(duplicates, duplicateChallenge{ID: id, PageIDs: pageIDs})
+		}
+	}
+	sort.Slice(duplicates, func(i, j int) bool { return duplicates[i].ID < duplicates[j].ID })
+	return duplicates, nil
+}
+
+// warnDuplicateChallengeIDs loggt beim Start eine Warnung für doppelte Challenge-IDs
+func (app *App) warnDuplicateChallengeIDs(ctx context.Context) {
+	duplicates, err := app.findDuplicateChallengeIDs(ctx)
+	if err != nil {
+		errorf("Prüfung auf doppelte Challenge-IDs fehlgeschlagen: %v", err)
+		return
+	}
+	for _, d := range duplicates {
+		warnf("Challenge-ID %d kommt %d-mal vor (Pages: %s)", d.ID, len(d.PageIDs), strings.Join(d.PageIDs, ", "))
+	}
+}
@@ -0,0 +1,647 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestAPIChallenge(t *testing.T) {
+	missingProperty := notionError(http.StatusBadRequest, "validation_error", "Could not find property with name or id: id")
+
+	tests := []struct {
+		name       string
+		id         string
+		queryErr   error // Fehler jeder gefilterten Abfrage (nil: Demo-Speicher), ungefiltert ist die DB leer
+		wantStatus int
+		wantTitle  string
+	}{
+		{name: "bekannte ID", id: "2", wantStatus: http.StatusOK, wantTitle: "Blick vom Kirchturm"},
+		{name: "unbekannte ID", id: "99", wantStatus: http.StatusNotFound},
+		{name: "keine Zahl", id: "brunnen", wantStatus: http.StatusNotFound},
+		{name: "Zahl mit Suffix", id: "1abc", wantStatus: http.StatusNotFound},
+		{name: "Dezimalzahl", id: "1.5", wantStatus: http.StatusNotFound},
+		{name: "nur fehlende ID-Properties", id: "1", queryErr: missingProperty, wantStatus: http.StatusNotFound},
+		{name: "Notion nicht erreichbar", id: "1", queryErr: notionError(http.StatusServiceUnavailable, "service_unavailable", "down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			if tt.queryErr != nil {
+				ta.notion = &stubNotion{notionService: ta.store, query: func(_ context.Context, _ string, req *notionapi.DatabaseQueryRequest) (*notionapi.DatabaseQueryResponse, error) {
+					if req.Filter == nil {
+						return &notionapi.DatabaseQueryResponse{}, nil
+					}
+					return nil, tt.queryErr
+				}}
+			}
+
+			w := ta.do(http.MethodGet, "/api/challenges/"+tt.id, nil)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, erwartet %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantTitle != "" {
+				if title := decodeJSON(t, w)["title"]; title != tt.wantTitle {
+					t.Errorf("title = %v, erwartet %q", title, tt.wantTitle)
+				}
+			}
+		})
+	}
+}
+
+func TestExpandURLTemplate(t *testing.T) {
+	const pageID = "1a2b3c4d-0000-1111-2222-333344445555"
+	tests := []struct {
+		tmpl string
+		want string
+	}{
+		{tmpl: defaultChallengeURLTemplate, want: "https://marcbaumholz.notion.site/1a2b3c4d000011112222333344445555"},
+		{tmpl: "https://www.notion.so/{uuid}", want: "https://www.notion.so/" + pageID},
+		{tmpl: "https://hunt.example.org/c/{id}?from=qr", want: "https://hunt.example.org/c/1a2b3c4d000011112222333344445555?from=qr"},
+	}
+	for _, tt := range tests {
+		if got := expandURLTemplate(tt.tmpl, pageID); got != tt.want {
+			t.Errorf("expandURLTemplate(%q) = %q, erwartet %q", tt.tmpl, got, tt.want)
+		}
+	}
+}
+
+func TestValidateURLTemplate(t *testing.T) {
+	tests := []struct {
+		tmpl    string
+		wantErr bool
+	}{
+		{tmpl: defaultChallengeURLTemplate},
+		{tmpl: "http://localhost:8080/next/{uuid}"},
+		{tmpl: "https://example.org/challenge", wantErr: true},
+		{tmpl: "/next/{id}", wantErr: true},
+		{tmpl: "ftp://example.org/{id}", wantErr: true},
+		{tmpl: "https:///{id}", wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := validateURLTemplate(tt.tmpl); (err != nil) != tt.wantErr {
+			t.Errorf("validateURLTemplate(%q) = %v", tt.tmpl, err)
+		}
+	}
+}
+
+func TestChallengeURLTemplateConfig(t *testing.T) {
+	ta := newTestApp(t, map[string]string{"CHALLENGE_URL_TEMPLATE": "https://hunt.example.org/c/{id}"})
+	challenge, err := ta.getChallenge(context.Background(), "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://hunt.example.org/c/demokirchturm"; challenge.URL != want {
+		t.Errorf("URL = %q, erwartet %q", challenge.URL, want)
+	}
+}
+
+func TestResolveChallengeParam(t *testing.T) {
+	tests := []struct {
+		param   string
+		want    string
+		wantErr error
+	}{
+		{param: "3", want: "3"},
+		{param: "demo-stadtpark", want: "3"},
+		{param: "demo-rathaus", want: "4"},
+		{param: "gibt-es-nicht", wantErr: errChallengeNotFound},
+	}
+
+	ta := newTestApp(t, nil)
+	for _, tt := range tests {
+		t.Run(tt.param, func(t *testing.T) {
+			got, err := ta.resolveChallengeParam(context.Background(), tt.param)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("resolveChallengeParam(%q) = %q, %v", tt.param, got, err)
+			}
+		})
+	}
+}
+
+func TestAdvanceBySlug(t *testing.T) {
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantNext   string
+	}{
+		{path: "/next/demo-brunnen", wantStatus: http.StatusOK, wantNext: demoChallengeURL + "demo-kirchturm"},
+		{path: "/next/1", wantStatus: http.StatusOK, wantNext: demoChallengeURL + "demo-kirchturm"},
+		{path: "/next/unbekannt", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			w := ta.do(http.MethodPost, tt.path, url.Values{"team": {"Demo Team"}})
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, erwartet %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantNext != "" && !strings.Contains(w.Body.String(), tt.wantNext) {
+				t.Errorf("Weiterleitung auf %s fehlt", tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestResolveStartChallenge(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantURL string
+		wantErr bool
+	}{
+		{name: "niedrigste ID", wantURL: demoChallengeURL + "demo-brunnen"},
+		{name: "konfiguriert", env: map[string]string{"START_CHALLENGE_ID": "3"}, wantURL: demoChallengeURL + "demo-stadtpark"},
+		{name: "konfigurierte ID fehlt", env: map[string]string{"START_CHALLENGE_ID": "9"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, tt.env)
+			start, err := ta.resolveStartChallenge(t.Context(), ta.startChallengeID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveStartChallenge: %v", err)
+			}
+			if err != nil {
+				return
+			}
+			if start.URL != tt.wantURL {
+				t.Errorf("URL = %q, erwartet %q", start.URL, tt.wantURL)
+			}
+
+			// main übernimmt die URL für den Begin-Link der Startseite
+			ta.startURL = start.URL
+			home := ta.do(http.MethodGet, "/", nil).Body.String()
+			if want := `class="start" href="` + tt.wantURL + `"`; !strings.Contains(home, want) {
+				t.Errorf("%s fehlt auf der Startseite:\n%s", want, home)
+			}
+		})
+	}
+}
+
+func TestParseStartChallengeID(t *testing.T) {
+	for _, tt := range []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{in: "", want: 0},
+		{in: "4", want: 4},
+		{in: "0", wantErr: true},
+		{in: "start", wantErr: true},
+	} {
+		got, err := parseStartChallengeID(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("parseStartChallengeID(%q) = %d, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestChallengeNumber(t *testing.T) {
+	tests := []struct {
+		name   string
+		prop   notionapi.Property
+		want   int
+		wantOK bool
+	}{
+		{name: "Number", prop: &notionapi.NumberProperty{Number: 3}, want: 3, wantOK: true},
+		{name: "Formel mit Zahl", prop: &notionapi.FormulaProperty{Formula: notionapi.Formula{Type: notionapi.FormulaTypeNumber, Number: 4}}, want: 4, wantOK: true},
+		{name: "Formel mit Text", prop: &notionapi.FormulaProperty{Formula: notionapi.Formula{Type: notionapi.FormulaTypeString, String: " 5 "}}, want: 5, wantOK: true},
+		{name: "Formel mit anderem Text", prop: &notionapi.FormulaProperty{Formula: notionapi.Formula{Type: notionapi.FormulaTypeString, String: "fünf"}}},
+		{name: "Rollup mit Zahl", prop: &notionapi.RollupProperty{Rollup: notionapi.Rollup{Type: notionapi.RollupTypeNumber, Number: 6}}, want: 6, wantOK: true},
+		{
+			name:   "Rollup mit einem Wert",
+			prop:   &notionapi.RollupProperty{Rollup: notionapi.Rollup{Type: notionapi.RollupTypeArray, Array: notionapi.PropertyArray{&notionapi.NumberProperty{Number: 7}}}},
+			want:   7,
+			wantOK: true,
+		},
+		{
+			name: "Rollup mit mehreren Werten",
+			prop: &notionapi.RollupProperty{Rollup: notionapi.Rollup{Type: notionapi.RollupTypeArray, Array: notionapi.PropertyArray{
+				&notionapi.NumberProperty{Number: 7}, &notionapi.NumberProperty{Number: 8},
+			}}},
+		},
+		{name: "Text", prop: &notionapi.RichTextProperty{RichText: demoText("8")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := notionapi.Page{ID: "test", Properties: notionapi.Properties{"id": tt.prop}}
+			got, ok := challengeNumber(page)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("challengeNumber = %d, %v, erwartet %d, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestTeamChallengesWithFormulaID(t *testing.T) {
+	ta := newTestApp(t, nil)
+	ta.store.mu.Lock()
+	ta.store.add(demoChallengesDBID, "demo-formel", notionapi.Properties{
+		"Name": &notionapi.TitleProperty{Title: demoText("Formel")},
+		"ID":   &notionapi.FormulaProperty{Formula: notionapi.Formula{Type: notionapi.FormulaTypeNumber, Number: 7}},
+	})
+	ta.store.mu.Unlock()
+	teamPageID := addDemoTeam(ta.store, "Formelteam", notionapi.Properties{
+		"Challenge1": &notionapi.RelationProperty{Relation: []notionapi.Relation{{ID: "demo-formel"}}},
+		"Challenge2": &notionapi.RelationProperty{Relation: []notionapi.Relation{{ID: "demo-brunnen"}}},
+	})
+
+	route, err := ta.getTeamChallenges(t.Context(), teamPageID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[int]string{1: "7", 2: "1"}; !maps.Equal(route, want) {
+		t.Errorf("Route = %v, erwartet %v", route, want)
+	}
+}
+
+func TestGetChallengeWithFormulaID(t *testing.T) {
+	tests := []struct {
+		name string
+		id   notionapi.Property
+	}{
+		{name: "Formel mit Zahl", id: &notionapi.FormulaProperty{Formula: notionapi.Formula{Type: notionapi.FormulaTypeNumber, Number: 7}}},
+		{name: "Formel mit Text", id: &notionapi.FormulaProperty{Formula: notionapi.Formula{Type: notionapi.FormulaTypeString, String: "7"}}},
+		{name: "Rollup", id: &notionapi.RollupProperty{Rollup: notionapi.Rollup{Type: notionapi.RollupTypeNumber, Number: 7}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			ta.store.mu.Lock()
+			ta.store.add(demoChallengesDBID, "demo-formel", notionapi.Properties{
+				"Name": &notionapi.TitleProperty{Title: demoText("Formel")},
+				"ID":   tt.id,
+			})
+			ta.store.mu.Unlock()
+
+			challenge, err := ta.getChallenge(t.Context(), "7")
+			if err != nil || challenge.PageID != "demo-formel" {
+				t.Fatalf("getChallenge(7) = %+v, %v", challenge, err)
+			}
+			if _, err := ta.getChallenge(t.Context(), "99"); !errors.Is(err, errChallengeNotFound) {
+				t.Errorf("getChallenge(99): %v, erwartet errChallengeNotFound", err)
+			}
+
+			next, err := ta.findNextChallenge(t.Context(), map[int]string{1: "1", 2: "7"}, "1")
+			if err != nil || next == nil || next.ID != 7 {
+				t.Errorf("findNextChallenge = %+v, %v; erwartet Challenge 7", next, err)
+			}
+			if w := ta.do(http.MethodGet, "/api/challenges/7", nil); w.Code != http.StatusOK {
+				t.Errorf("/api/challenges/7: Status = %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSearchChallenges(t *testing.T) {
+	challenges := []*Challenge{
+		{ID: 1, Title: "Der alte Brunnen"},
+		{ID: 2, Title: "Blick vom Kirchturm"},
+		{ID: 3, Title: "Rätsel im Stadtpark"},
+		{ID: 4, Title: "Ziel am Rathaus"},
+	}
+	tests := []struct {
+		query         string
+		limit         int
+		wantIDs       []int
+		wantTruncated bool
+	}{
+		{query: "brunnen", limit: 10, wantIDs: []int{1}},
+		{query: "RÄTSEL", limit: 10, wantIDs: []int{3}},
+		{query: "l", limit: 10, wantIDs: []int{1, 2, 3, 4}},
+		{query: "i", limit: 2, wantIDs: []int{2, 3}, wantTruncated: true},
+		{query: "e", limit: 4, wantIDs: []int{1, 3, 4}},
+		{query: "Schloss", limit: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			results, truncated := searchChallenges(challenges, tt.query, tt.limit)
+			var ids []int
+			for _, challenge := range results {
+				ids = append(ids, challenge.ID)
+			}
+			if !slices.Equal(ids, tt.wantIDs) || truncated != tt.wantTruncated {
+				t.Errorf("Treffer = %v (gekürzt %v), erwartet %v (%v)", ids, truncated, tt.wantIDs, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestAPIChallengeSearch(t *testing.T) {
+	ta := newTestApp(t, nil)
+	for id := 5; id < 5+maxSearchResults; id++ {
+		addDemoChallenge(ta.store, fmt.Sprintf("demo-extra-%d", id), id)
+	}
+
+	tests := []struct {
+		query         string
+		wantStatus    int
+		wantCount     int
+		wantTruncated bool
+	}{
+		{query: "kirch", wantStatus: http.StatusOK, wantCount: 1},
+		{query: "challenge", wantStatus: http.StatusOK, wantCount: maxSearchResults, wantTruncated: false},
+		{query: "e", wantStatus: http.StatusOK, wantCount: maxSearchResults, wantTruncated: true},
+		{query: "  ", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := ta.do(http.MethodGet, "/api/challenges/search?q="+url.QueryEscape(tt.query), nil)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, erwartet %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			body := decodeJSON(t, w)
+			results := body["results"].([]any)
+			if len(results) != tt.wantCount || body["truncated"] != tt.wantTruncated {
+				t.Errorf("%d Treffer (gekürzt %v), erwartet %d (%v)", len(results), body["truncated"], tt.wantCount, tt.wantTruncated)
+			}
+			for _, result := range results {
+				result := result.(map[string]any)
+				if result["id"] == nil || result["url"] == "" {
+					t.Errorf("Treffer ohne ID oder URL: %v", result)
+				}
+			}
+		})
+	}
+}
+
+func TestRedirectTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		property notionapi.Property // RedirectTemplate von Challenge 2 (nil: keine Property)
+		wantBody string
+	}{
+		{name: "ohne Property", wantBody: "Redirecting..."},
+		{name: "eigenes Template", property: &notionapi.RichTextProperty{RichText: demoText("video")}, wantBody: "VIDEO " + demoChallengeURL + "demo-kirchturm"},
+		{name: "mit Endung als Select", property: &notionapi.SelectProperty{Select: notionapi.Option{Name: "video.html"}}, wantBody: "VIDEO "},
+		{name: "unbekanntes Template", property: &notionapi.RichTextProperty{RichText: demoText("fehlt")}, wantBody: "Redirecting..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			template.Must(ta.templates.New("video.html").Parse(`VIDEO {{.url}}`))
+			if tt.property != nil {
+				ta.store.mu.Lock()
+				ta.store.pages["demo-kirchturm"].Properties[challengeRedirectTemplateProperty] = tt.property
+				ta.store.mu.Unlock()
+			}
+
+			w := ta.advance("1", "Demo Team")
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("%q fehlt:\n%s", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestTeaserOnForm(t *testing.T) {
+	tests := []struct {
+		name        string
+		challengeID string
+		wantTeaser  string
+	}{
+		{name: "mit Teaser", challengeID: "1", wantTeaser: "Zählt die Löwenköpfe am Brunnen auf dem Marktplatz."},
+		{name: "leerer Teaser", challengeID: "5"},
+		{name: "ohne Property", challengeID: "6"},
+		{name: "unbekannte Challenge", challengeID: "9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			addDemoChallenge(ta.store, "demo-leer", 5, notionapi.Properties{
+				challengeTeaserProperty: &notionapi.RichTextProperty{RichText: demoText("   ")},
+			})
+			addDemoChallenge(ta.store, "demo-ohne", 6)
+
+			w := ta.do(http.MethodGet, "/next/"+tt.challengeID, nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+			body := w.Body.String()
+			if got := strings.Contains(body, `<div class="teaser">`); got != (tt.wantTeaser != "") {
+				t.Errorf("Teaser-Block = %v, erwartet %v", got, tt.wantTeaser != "")
+			}
+			if tt.wantTeaser != "" && !strings.Contains(body, tt.wantTeaser) {
+				t.Errorf("Teaser %q fehlt:\n%s", tt.wantTeaser, body)
+			}
+		})
+	}
+}
+
+func TestParseDBIDs(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{in: ""},
+		{in: "abc", want: []string{"abc"}},
+		{in: " abc , def ,", want: []string{"abc", "def"}},
+		{in: "ab-cd,ABCD,efgh", want: []string{"ab-cd", "efgh"}},
+	}
+	for _, tt := range tests {
+		if got := parseDBIDs(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("parseDBIDs(%q) = %v, erwartet %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMultipleChallengeDBs(t *testing.T) {
+	const zoneDBID = "demo-zone-2"
+	newZoneApp := func(t *testing.T) *testApp {
+		ta := newTestApp(t, nil)
+		ta.challengeDBIDs = []string{demoChallengesDBID, zoneDBID}
+		ta.store.mu.Lock()
+		ta.store.databases[zoneDBID] = nil
+		ta.store.add(zoneDBID, "zone-leuchtturm", notionapi.Properties{
+			"Name": &notionapi.TitleProperty{Title: demoText("Am Leuchtturm")},
+			"id":   &notionapi.NumberProperty{Number: 8},
+		})
+		// Kollision mit Challenge 2 aus der ersten DB
+		ta.store.add(zoneDBID, "zone-doppelt", notionapi.Properties{
+			"Name": &notionapi.TitleProperty{Title: demoText("Doppelte Zwei")},
+			"id":   &notionapi.NumberProperty{Number: 2},
+		})
+		ta.store.mu.Unlock()
+		return ta
+	}
+
+	tests := []struct {
+		id        string
+		wantTitle string
+		wantDB    string
+	}{
+		{id: "1", wantTitle: "Der alte Brunnen", wantDB: demoChallengesDBID},
+		{id: "8", wantTitle: "Am Leuchtturm", wantDB: zoneDBID},
+		{id: "2", wantTitle: "Blick vom Kirchturm", wantDB: demoChallengesDBID},
+	}
+	for _, tt := range tests {
+		t.Run("getChallenge "+tt.id, func(t *testing.T) {
+			ta := newZoneApp(t)
+			challenge, err := ta.getChallenge(t.Context(), tt.id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if challenge.Title != tt.wantTitle || challenge.DatabaseID != tt.wantDB {
+				t.Errorf("Challenge %s = %q aus %s, erwartet %q aus %s", tt.id, challenge.Title, challenge.DatabaseID, tt.wantTitle, tt.wantDB)
+			}
+		})
+	}
+
+	t.Run("getAllChallenges", func(t *testing.T) {
+		ta := newZoneApp(t)
+		all, err := ta.getAllChallenges(t.Context())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []int
+		for _, challenge := range all {
+			ids = append(ids, challenge.ID)
+			if challenge.ID == 2 && challenge.DatabaseID != demoChallengesDBID {
+				t.Errorf("Kollision: Challenge 2 aus %s, erwartet die erste DB", challenge.DatabaseID)
+			}
+		}
+		if want := []int{1, 2, 3, 4, 8}; !slices.Equal(ids, want) {
+			t.Errorf("IDs = %v, erwartet %v", ids, want)
+		}
+	})
+}
+
+func TestFindDuplicateChallengeIDs(t *testing.T) {
+	tests := []struct {
+		name  string
+		extra map[string]int // zusätzliche Challenge-Pages neben den Demo-Challenges
+		want  map[int]int    // ID → Anzahl Pages
+	}{
+		{name: "keine Duplikate", extra: map[string]int{"neu-5": 5}, want: map[int]int{}},
+		{name: "eine doppelte ID", extra: map[string]int{"zweiter-brunnen": 1}, want: map[int]int{1: 2}},
+		{
+			name:  "mehrere doppelte IDs",
+			extra: map[string]int{"kopie-a": 3, "kopie-b": 3, "kopie-c": 4},
+			want:  map[int]int{3: 3, 4: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			for pageID, id := range tt.extra {
+				addDemoChallenge(ta.store, pageID, id)
+			}
+
+			w := ta.admin(http.MethodGet, "/admin/duplicate-challenges", "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+			var body struct {
+				Duplicates []duplicateChallenge `json:"duplicates"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[int]int)
+			for _, d := range body.Duplicates {
+				got[d.ID] = len(d.PageIDs)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("Duplikate = %v, erwartet %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetChallengeWarnsOnDuplicateID(t *testing.T) {
+	ta := newTestApp(t, nil)
+	addDemoChallenge(ta.store, "zweiter-brunnen", 1)
+
+	var challenge *Challenge
+	out := captureLog(t, "warn", "text", func() {
+		var err error
+		if challenge, err = ta.getChallenge(t.Context(), "1"); err != nil {
+			t.Error(err)
+		}
+	})
+	if challenge == nil || challenge.ID != 1 {
+		t.Fatalf("Challenge = %+v", challenge)
+	}
+	for _, want := range []string{"Challenge-ID 1 ist mehrfach vergeben", "demo-brunnen", "zweiter-brunnen"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("%q fehlt in der Warnung:\n%s", want, out)
+		}
+	}
+}
+
+func TestAPIOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		extra      map[string]int // zusätzliche Challenges (Page-ID → ID)
+		queryErr   error
+		wantStatus int
+		wantIDs    []int
+	}{
+		{name: "Demo-Challenges", wantStatus: http.StatusOK, wantIDs: []int{1, 2, 3, 4}},
+		{name: "später angelegte Challenges einsortiert", extra: map[string]int{"demo-zehn": 10, "demo-null": 0, "demo-fuenf": 5}, wantStatus: http.StatusOK, wantIDs: []int{0, 1, 2, 3, 4, 5, 10}},
+		{name: "Notion nicht erreichbar", queryErr: notionError(http.StatusServiceUnavailable, "service_unavailable", "down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			for pageID, id := range tt.extra {
+				addDemoChallenge(ta.store, pageID, id)
+			}
+			if tt.queryErr != nil {
+				ta.notion = &stubNotion{notionService: ta.store, query: func(context.Context, string, *notionapi.DatabaseQueryRequest) (*notionapi.DatabaseQueryResponse, error) {
+					return nil, tt.queryErr
+				}}
+			}
+
+			w := ta.do(http.MethodGet, "/api/order", nil)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, erwartet %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantIDs == nil {
+				return
+			}
+
+			var resp struct {
+				Challenges []orderEntry `json:"challenges"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			var ids []int
+			for i, entry := range resp.Challenges {
+				ids = append(ids, entry.ID)
+				if entry.Position != i+1 {
+					t.Errorf("Challenge %d: position = %d, erwartet %d", entry.ID, entry.Position, i+1)
+				}
+				if entry.Title == "" || entry.URL == "" {
+					t.Errorf("Challenge %d ohne Titel oder URL: %+v", entry.ID, entry)
+				}
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("Reihenfolge = %v, erwartet %v", ids, tt.wantIDs)
+			}
+			if first := resp.Challenges[0]; tt.extra == nil && (first.Title != "Der alte Brunnen" || first.URL != ta.formatNotionURL("demo-brunnen")) {
+				t.Errorf("erste Challenge = %+v", first)
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+// Property-Namen der Abschluss-Markierungen auf der Team-Page.
+// Das Completed-Format kann über COMPLETION_PROPERTY_FORMAT überschrieben werden.
+const (
+	defaultCompletionPropertyFormat = "Completed_%d"
+	skippedPropertyFormat           = "Skipped_%d"
+	teamScoreProperty               = "Score"
+)
+
+// validateCompletionFormat stellt sicher, dass das Format genau ein %d enthält
+func validateCompletionFormat(format string) error {
+	verbs := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		if i+1 >= len(format) {
+			return fmt.Errorf("COMPLETION_PROPERTY_FORMAT %q endet mit einem einzelnen %%", format)
+		}
+		i++
+		switch format[i] {
+		case '%':
+			// Escaptes Prozentzeichen
+		case 'd':
+			verbs++
+		default:
+			return fmt.Errorf("COMPLETION_PROPERTY_FORMAT %q enthält ungültiges Verb %%%c", format, format[i])
+		}
+	}
+	if verbs != 1 {
+		return fmt.Errorf("COMPLETION_PROPERTY_FORMAT %q muss genau ein %%d enthalten", format)
+	}
+	return nil
+}
+
+// completionProperty liefert den Property-Namen des Abschluss-Markers einer Challenge
+func (app *App) completionProperty(num int) string {
+	return fmt.Sprintf(app.completionFormat, num)
+}
+
+// completionNumber erkennt einen Abschluss-Marker und liefert die Challenge-Nummer
+func (app *App) completionNumber(propName string) (int, bool) {
+	var num int
+	if _, err := fmt.Sscanf(propName, app.completionFormat, &num); err != nil {
+		return 0, false
+	}
+	// Sscanf ignoriert Text nach dem Verb, daher Gegenprobe
+	return num, app.completionProperty(num) == propName
+}
+
+// errAlreadyCompleted wird geliefert, wenn eine bereits abgeschlossene Challenge übersprungen werden soll
+var errAlreadyCompleted = errors.New("challenge ist bereits abgeschlossen")
+
+// recordCompletion markiert eine Challenge auf der Team-Page als abgeschlossen.
+// Bei skipped wird zusätzlich ein "Skipped"-Marker mit der Begründung gesetzt,
+// damit sich übersprungene Challenges von normal gelösten unterscheiden lassen.
+// Beim ersten regulären Abschluss werden die Punkte der Challenge zum Score addiert.
+//
+// Überspringen ist nur für offene Challenges erlaubt (sonst errAlreadyCompleted):
+// Ein Skip schreibt so immer genau Completed- und Skipped-Marker ohne Punkte, und
+// undoLastCompletion kann beides wieder entfernen, ohne den Score anzufassen.
+func (app *App) recordCompletion(ctx context.Context, teamPageID, challengeID string, skipped bool, reason string) error {
+	num, err := strconv.Atoi(challengeID)
+	if err != nil {
+		return fmt.Errorf("ungültige Challenge-ID %q: %w", challengeID, err)
+	}
+
+	if skipped {
+		completed, err := app.getCompletions(ctx, teamPageID)
+		if err != nil {
+			return fmt.Errorf("fehler beim Lesen der Abschlüsse: %w", err)
+		}
+		if _, done := completed[num]; done {
+			return errAlreadyCompleted
+		}
+	}
+
+	now := notionapi.Date(time.Now())
+	props := notionapi.Properties{
+		app.completionProperty(num): notionapi.DateProperty{
+			Date: &notionapi.DateObject{Start: &now},
+		},
+	}
+
+	if skipped {
+		if reason == "" {
+			reason = "übersprungen"
+		}
+		props[fmt.Sprintf(skippedPropertyFormat, num)] = notionapi.RichTextProperty{
+			RichText: []notionapi.RichText{{Text: &notionapi.Text{Content: reason}}},
+		}
+	} else if score, ok := app.scoreAfterCompletion(ctx, teamPageID, challengeID, num); ok {
+		props[teamScoreProperty] = notionapi.NumberProperty{Number: score}
+	}
+
+	_, err = app.updatePage(ctx, teamPageID, &notionapi.PageUpdateRequest{
+		Properties: props,
+	})
+	if err != nil {
+		return fmt.Errorf("fehler beim Speichern des Abschlusses: %w", err)
+	}
+
+	return nil
+}
+
+// scoreAfterCompletion berechnet den neuen Score eines Teams. Liefert false, wenn sich
+// der Score nicht ändert (Challenge bereits abgeschlossen oder ohne Punkte).
+func (app *App) scoreAfterCompletion(ctx context.Context, teamPageID, challengeID string, num int) (float64, bool) {
+	challenge, err := app.getChallenge(ctx, challengeID)
+	if err != nil || challenge.Points == 0 {
+		return 0, false
+	}
+
+	page, err := app.getPage(ctx, teamPageID)
+	if err != nil {
+		errorf("Fehler beim Lesen des Scores: %v", err)
+		return 0, false
+	}
+	if _, done := app.completedChallenges(page)[num]; done {
+		return 0, false
+	}
+
+	return teamScore(page) + challenge.Points, true
+}
+
+// teamScore liest den gespeicherten Score einer Team-Page (ohne Property: 0)
+func teamScore(page *notionapi.Page) float64 {
+	if p, ok := page.Properties[teamScoreProperty].(*notionapi.NumberProperty); ok {
+		return p.Number
+	}
+	return 0
+}
+
+// completedChallenges liest alle Abschluss-Marker einer Team-Page (Challenge-ID → Zeitpunkt)
+func (app *App) completedChallenges(page *notionapi.Page) map[int]time.Time {
+	completed := make(map[int]time.Time)
+	for propName, prop := range page.Properties {
+		num, ok := app.completionNumber(propName)
+		if !ok {
+			continue
+		}
+		if p, ok := prop.(*notionapi.DateProperty); ok && p.Date != nil && p.Date.Start != nil {
+			completed[num] = time.Time(*p.Date.Start)
+		}
+	}
+	return completed
+}
+
+// getCompletions lädt die Team-Page und liefert ihre Abschluss-Marker
+func (app *App) getCompletions(ctx context.Context, teamPageID string) (map[int]time.Time, error) {
+	page, err := app.getPage(ctx, teamPageID)
+	if err != nil {
+		return nil, err
+	}
+	return app.completedChallenges(page), nil
+}
+
+// Finish-Modi für FINISH_MODE
+const (
+	finishModeAll   = "all"
+	finishModeCount = "count"
+)
+
+// parseFinishCondition liest FINISH_MODE und FINISH_COUNT
+func parseFinishCondition(mode, count string) (string, int, error) {
+	switch mode {
+	case "", finishModeAll:
+		return finishModeAll, 0, nil
+	case finishModeCount:
+		n, err := strconv.Atoi(count)
+		if err != nil || n < 1 {
+			return "", 0, fmt.Errorf("FINISH_COUNT muss bei FINISH_MODE=count eine positive ganze Zahl sein, ist aber %q", count)
+		}
+		return finishModeCount, n, nil
+	default:
+		return "", 0, fmt.Errorf("FINISH_MODE %q ist ungültig (all, count)", mode)
+	}
+}
+
+// finishReached prüft die Zielbedingung im Modus "count".
+// Im Modus "all" ist ein Team erst fertig, wenn es keine nächste Challenge gibt.
+func (app *App) finishReached(ctx context.Context, teamPageID string) bool {
+	if app.finishMode != finishModeCount {
+		return false
+	}
+
+	completed, err := app.getCompletions(ctx, teamPageID)
+	if err != nil {
+		errorf("Fehler beim Prüfen der Zielbedingung: %v", err)
+		return false
+	}
+	return len(completed) >= app.finishCount
+}
+
+// errNothingToUndo wird geliefert, wenn ein Team noch keine Challenge abgeschlossen hat
+var errNothingToUndo = errors.New("keine abgeschlossene Challenge zum Rückgängigmachen")
+
+// undoLastCompletion entfernt den jüngsten Abschluss-Marker (nach Zeitstempel) einer Team-Page
+// samt Skipped-Marker und zieht die Punkte wieder ab. Liefert die ID der betroffenen Challenge.
+func (app *App) undoLastCompletion(ctx context.Context, teamPageID string) (int, error) {
+	page, err := app.getPage(ctx, teamPageID)
+	if err != nil {
+		return 0, fmt.Errorf("fehler beim Laden der Team-Page: %w", err)
+	}
+
+	lastNum, lastAt, found := 0, time.Time{}, false
+	for num, at := range app.completedChallenges(page) {
+		if !found || at.After(lastAt) {
+			lastNum, lastAt, found = num, at, true
+		}
+	}
+	if !found {
+		return 0, errNothingToUndo
+	}
+
+	skippedProp := fmt.Sprintf(skippedPropertyFormat, lastNum)
+	props := notionapi.Properties{
+		app.completionProperty(lastNum): notionapi.DateProperty{Date: nil},
+	}
+
+	skipped := isSkipped(page, lastNum)
+	if skipped {
+		props[skippedProp] = notionapi.RichTextProperty{RichText: []notionapi.RichText{}}
+	}
+
+	// Übersprungene Challenges haben keine Punkte eingebracht
+	if !skipped {
+		if challenge, err := app.getChallenge(ctx, strconv.Itoa(lastNum)); err == nil && challenge.Points != 0 {
+			props[teamScoreProperty] = notionapi.NumberProperty{Number: teamScore(page) - challenge.Points}
+		}
+	}
+
+	if _, err := app.updatePage(ctx, teamPageID, &notionapi.PageUpdateRequest{Properties: props}); err != nil {
+		return 0, fmt.Errorf("fehler beim Entfernen des Abschlusses: %w", err)
+	}
+
+	return lastNum, nil
+}
+
+// gotoSkipReason markiert Challenges, die durch einen Admin-Sprung als erledigt gelten
+const gotoSkipReason = "Admin-Sprung"
+
+// setTeamPosition setzt ein Team auf die Routen-Position targetPos: Alle Challenges davor
+// gelten als übersprungen (ohne Punkte), die Abschlüsse ab targetPos werden samt Punkten
+// entfernt. Liefert die Challenge, an der das Team laut Markern vorher zuletzt war.
+func (app *App) setTeamPosition(ctx context.Context, teamPageID string, route map[int]string, targetPos int) (string, error) {
+	page, err := app.getPage(ctx, teamPageID)
+	if err != nil {
+		return "", fmt.Errorf("fehler beim Laden der Team-Page: %w", err)
+	}
+
+	completed := app.completedChallenges(page)
+	previous := route[markerPosition(route, completed)]
+
+	now := notionapi.Date(time.Now())
+	score, scoreChanged := teamScore(page), false
+	props := notionapi.Properties{}
+	for pos, id := range route {
+		num, err := strconv.Atoi(id)
+		if err != nil {
+			continue
+		}
+		_, done := completed[num]
+		switch {
+		case pos < targetPos && !done:
+			props[app.completionProperty(num)] = notionapi.DateProperty{Date: &notionapi.DateObject{Start: &now}}
+			props[fmt.Sprintf(skippedPropertyFormat, num)] = notionapi.RichTextProperty{
+				RichText: []notionapi.RichText{{Text: &notionapi.Text{Content: gotoSkipReason}}},
+			}
+		case pos >= targetPos && done:
+			props[app.completionProperty(num)] = notionapi.DateProperty{Date: nil}
+			if isSkipped(page, num) {
+				props[fmt.Sprintf(skippedPropertyFormat, num)] = notionapi.RichTextProperty{RichText: []notionapi.RichText{}}
+			} else if challenge, err := app.getChallenge(ctx, id); err == nil && challenge.Points != 0 {
+				score -= challenge.Points
+				scoreChanged = true
+			}
+		}
+	}
+	if scoreChanged {
+		props[teamScoreProperty] = notionapi.NumberProperty{Number: score}
+	}
+	if len(props) == 0 {
+		return previous, nil
+	}
+
+	if _, err := app.updatePage(ctx, teamPageID, &notionapi.PageUpdateRequest{Properties: props}); err != nil {
+		return "", fmt.Errorf("fehler beim Setzen der Position: %w", err)
+	}
+	return previous, nil
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestValidateCompletionFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantErr bool
+	}{
+		{format: defaultCompletionPropertyFormat},
+		{format: "Erledigt %d"},
+		{format: "%d%% fertig"},
+		{format: "Erledigt", wantErr: true},
+		{format: "%d und %d", wantErr: true},
+		{format: "Erledigt %s", wantErr: true},
+		{format: "Erledigt %d %", wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := validateCompletionFormat(tt.format); (err != nil) != tt.wantErr {
+			t.Errorf("validateCompletionFormat(%q) = %v", tt.format, err)
+		}
+	}
+}
+
+func TestCompletionNumber(t *testing.T) {
+	tests := []struct {
+		format string
+		prop   string
+		want   int
+		wantOK bool
+	}{
+		{format: defaultCompletionPropertyFormat, prop: "Completed_3", want: 3, wantOK: true},
+		{format: defaultCompletionPropertyFormat, prop: "Completed_3x", wantOK: false},
+		{format: defaultCompletionPropertyFormat, prop: "Skipped_3", wantOK: false},
+		{format: "Erledigt %d", prop: "Erledigt 12", want: 12, wantOK: true},
+		{format: "Erledigt %d", prop: "Completed_12", wantOK: false},
+		{format: "%d%% fertig", prop: "4% fertig", want: 4, wantOK: true},
+	}
+	for _, tt := range tests {
+		app := &App{completionFormat: tt.format}
+		got, ok := app.completionNumber(tt.prop)
+		if ok != tt.wantOK || (ok && got != tt.want) {
+			t.Errorf("%q mit %q = %d, %v", tt.prop, tt.format, got, ok)
+		}
+	}
+}
+
+func TestCompletionPropertyFormat(t *testing.T) {
+	ta := newTestApp(t, map[string]string{"COMPLETION_PROPERTY_FORMAT": "Erledigt %d"})
+	if w := ta.advance("1", "Demo Team"); w.Code != http.StatusOK {
+		t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+	}
+
+	page := ta.teamPage(t, demoTeamPageID)
+	if _, ok := page.Properties["Erledigt 1"]; !ok {
+		t.Error("Abschluss steht nicht in der konfigurierten Property \"Erledigt 1\"")
+	}
+	if _, ok := page.Properties["Completed_1"]; ok {
+		t.Error("Abschluss steht zusätzlich in Completed_1")
+	}
+}
+
+func TestParseFinishCondition(t *testing.T) {
+	tests := []struct {
+		mode, count string
+		wantMode    string
+		wantCount   int
+		wantErr     bool
+	}{
+		{mode: "", wantMode: finishModeAll},
+		{mode: "all", count: "3", wantMode: finishModeAll},
+		{mode: "count", count: "5", wantMode: finishModeCount, wantCount: 5},
+		{mode: "count", count: "", wantErr: true},
+		{mode: "count", count: "0", wantErr: true},
+		{mode: "some", count: "2", wantErr: true},
+	}
+	for _, tt := range tests {
+		mode, count, err := parseFinishCondition(tt.mode, tt.count)
+		if (err != nil) != tt.wantErr || mode != tt.wantMode || count != tt.wantCount {
+			t.Errorf("parseFinishCondition(%q, %q) = %q, %d, %v", tt.mode, tt.count, mode, count, err)
+		}
+	}
+}
+
+func TestFinishCondition(t *testing.T) {
+	tests := []struct {
+		name         string
+		env          map[string]string
+		wantFinished []bool // je Abschluss der Route 1-2-3-4, ob die Zielseite kommt
+	}{
+		{
+			name:         "alle Challenges",
+			env:          map[string]string{"FINISH_MODE": "all"},
+			wantFinished: []bool{false, false, false, true},
+		},
+		{
+			name:         "zwei von vier",
+			env:          map[string]string{"FINISH_MODE": "count", "FINISH_COUNT": "2"},
+			wantFinished: []bool{false, true},
+		},
+		{
+			name:         "Anzahl über der Route",
+			env:          map[string]string{"FINISH_MODE": "count", "FINISH_COUNT": "9"},
+			wantFinished: []bool{false, false, false, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, tt.env)
+			for i, want := range tt.wantFinished {
+				id := strconv.Itoa(i + 1)
+				w := ta.advance(id, "Demo Team")
+				if w.Code != http.StatusOK {
+					t.Fatalf("Challenge %s: Status = %d: %s", id, w.Code, w.Body.String())
+				}
+				if got := strings.Contains(w.Body.String(), "<title>Scavenger Hunt Completed!</title>"); got != want {
+					t.Errorf("Challenge %s: Zielseite = %v, erwartet %v", id, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFinishedStats(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        map[string]string
+		noPoints   bool // alle Challenges ohne Punkte, das Team hat keinen Score
+		route      int  // Anzahl der Abschlüsse bis zur Zielseite
+		want       []string
+		wantAbsent []string
+	}{
+		{
+			name:       "ohne FINISH_MESSAGE",
+			route:      4,
+			want:       []string{"<strong>4</strong><span>challenges completed</span>", "<strong>70</strong><span>points</span>"},
+			wantAbsent: []string{`<div class="message">Treffpunkt`},
+		},
+		{
+			name:  "mit FINISH_MESSAGE",
+			env:   map[string]string{"FINISH_MESSAGE": "Treffpunkt: Rathaus um 18 Uhr"},
+			route: 4,
+			want:  []string{`<div class="message">Treffpunkt: Rathaus um 18 Uhr</div>`, "<strong>4</strong>"},
+		},
+		{
+			name:  "Ziel nach zwei Challenges",
+			env:   map[string]string{"FINISH_MODE": "count", "FINISH_COUNT": "2"},
+			route: 2,
+			want:  []string{"<strong>2</strong><span>challenges completed</span>", "<strong>30</strong><span>points</span>"},
+		},
+		{
+			name:       "ohne Punkte",
+			noPoints:   true,
+			route:      4,
+			want:       []string{"<strong>4</strong><span>challenges completed</span>"},
+			wantAbsent: []string{"<span>points</span>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, tt.env)
+			if tt.noPoints {
+				ta.store.mu.Lock()
+				for _, pageID := range []string{"demo-brunnen", "demo-kirchturm", "demo-stadtpark", "demo-rathaus"} {
+					delete(ta.store.pages[pageID].Properties, challengePointsProperty)
+				}
+				ta.store.mu.Unlock()
+			}
+
+			var w *httptest.ResponseRecorder
+			for i := 1; i <= tt.route; i++ {
+				if w = ta.advance(strconv.Itoa(i), "Demo Team"); w.Code != http.StatusOK {
+					t.Fatalf("Challenge %d: Status = %d: %s", i, w.Code, w.Body.String())
+				}
+			}
+			body := w.Body.String()
+			if !strings.Contains(body, "<title>Scavenger Hunt Completed!</title>") {
+				t.Fatalf("keine Zielseite:\n%s", body)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("%q fehlt:\n%s", want, body)
+				}
+			}
+			for _, absent := range tt.wantAbsent {
+				if strings.Contains(body, absent) {
+					t.Errorf("%q sollte fehlen", absent)
+				}
+			}
+		})
+	}
+}
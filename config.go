@@ -0,0 +1,302 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config bündelt die gesamte Laufzeitkonfiguration der Anwendung
+type Config struct {
+	NotionToken          string
+	TeamsDBID            string
+	ChallengeDBIDs       []string
+	AdminToken           string
+	Debug                bool
+	Production           bool
+	LogLevel             string
+	LogFormat            string
+	RedirectDelay        int
+	CompletionFormat     string
+	ShowDescription      bool
+	ChallengeURLTemplate string
+	Warmup               bool
+	ConfirmAdvance       bool
+	Honeypot             bool
+	FinishMessage        string
+	Confetti             bool
+	FinishRedirectURL    string
+	FinishRedirectDelay  int
+	FeatureMVP           bool
+	MVPIdeas             []mvpIdea
+	MVPStateFile         string
+	FeatureLeaderboard   bool
+	FeatureRegistration  bool
+	TeamNotFoundAction   string
+	EmptyRouteAction     string
+	FuzzyMaxDistance     int
+	FuzzyAutoAccept      bool
+	TeamLookupRetry      time.Duration
+	MatchMode            string
+	MatchPipeline        []string
+	TeamInputMode        string
+	PositionMode         string
+	EventWindow          eventWindow
+	RememberTeam         bool
+	SessionSecret        string
+	EventPIN             string
+	OptimisticWrites     bool
+	TeamLock             bool
+	Answers              answerConfig
+	DefaultLanguage      string
+	FinishMode           string
+	FinishCount          int
+	StartChallengeID     int
+	BaseURL              string
+	DemoMode             bool
+	AllowedOrigins       []string
+	TrustedProxies       []string
+	EventLog             string
+	UpdatesBuffer        int
+	AccessLog            bool
+	Metrics              bool
+	MaxConcurrent        int
+	NotionMaxConcurrent  int
+	QueueTimeout         time.Duration
+	Server               serverConfig
+	Port                 string
+	Values               map[string]string // gesetzte, nicht geheime Werte für /admin/config-template
+}
+
+// configSource liefert Werte nach Name der Umgebungsvariable.
+// Gesetzte Umgebungsvariablen haben Vorrang vor den Werten aus CONFIG_FILE.
+type configSource map[string]string
+
+func (s configSource) get(name string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return s[name]
+}
+
+// loadConfig baut die Konfiguration aus CONFIG_FILE (optional, YAML oder JSON)
+// und den Umgebungsvariablen und prüft alle Werte
+func loadConfig() (*Config, error) {
+	src := configSource{}
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		values, err := readConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+		src = values
+	}
+
+	cfg := &Config{
+		NotionToken:         src.get("NOTION_TOKEN"),
+		TeamsDBID:           src.get("TEAMS_DB_ID"),
+		AdminToken:          src.get("ADMIN_TOKEN"),
+		Debug:               src.get("DEBUG") == "true",
+		Production:          src.get("PRODUCTION") == "true",
+		LogLevel:            src.get("LOG_LEVEL"),
+		LogFormat:           src.get("LOG_FORMAT"),
+		ShowDescription:     src.get("SHOW_CHALLENGE_DESCRIPTION") == "true",
+		Warmup:              src.get("WARMUP") == "true",
+		ConfirmAdvance:      src.get("CONFIRM_ADVANCE") == "true",
+		Honeypot:            src.get("HONEYPOT") == "true",
+		FinishMessage:       src.get("FINISH_MESSAGE"),
+		Confetti:            src.get("CONFETTI") != "false",
+		FeatureMVP:          featureEnabled(src.get("FEATURE_MVP")),
+		FeatureLeaderboard:  featureEnabled(src.get("FEATURE_LEADERBOARD")),
+		FeatureRegistration: src.get("FEATURE_REGISTRATION") == "true",
+		FuzzyAutoAccept:     src.get("FUZZY_AUTO_ACCEPT") != "false",
+		BaseURL:             src.get("PUBLIC_BASE_URL"),
+		EventLog:            src.get("EVENT_LOG"),
+		Port:                src.get("PORT"),
+		DemoMode:            src.get("DEMO_MODE") == "true",
+		RememberTeam:        src.get("REMEMBER_TEAM") == "true",
+		SessionSecret:       src.get("SESSION_SECRET"),
+		EventPIN:            strings.TrimSpace(src.get("EVENT_PIN")),
+		OptimisticWrites:    src.get("OPTIMISTIC_WRITES") == "true",
+		TeamLock:            src.get("TEAM_LOCK") != "false",
+		Metrics:             src.get("METRICS") == "true",
+		MVPStateFile:        src.get("MVP_STATE_FILE"),
+	}
+	if cfg.Port == "" {
+		cfg.Port = "8080"
+	}
+	cfg.Values = effectiveConfigValues(src)
+
+	// Große Events verteilen Challenges nach Zonen auf mehrere DBs (CHALLENGES_DB_IDS)
+	cfg.ChallengeDBIDs = parseDBIDs(src.get("CHALLENGES_DB_IDS"))
+	if len(cfg.ChallengeDBIDs) == 0 {
+		cfg.ChallengeDBIDs = parseDBIDs(src.get("CHALLENGES_DB_ID"))
+	}
+	// Im Demo-Modus kommen Teams und Challenges aus dem Speicher, Notion-Zugangsdaten entfallen
+	if cfg.DemoMode {
+		cfg.TeamsDBID = demoTeamsDBID
+		cfg.ChallengeDBIDs = []string{demoChallengesDBID}
+	}
+	if (!cfg.DemoMode && cfg.NotionToken == "") || cfg.TeamsDBID == "" || len(cfg.ChallengeDBIDs) == 0 {
+		return nil, fmt.Errorf("NOTION_TOKEN, TEAMS_DB_ID und CHALLENGES_DB_ID (oder CHALLENGES_DB_IDS) müssen gesetzt sein")
+	}
+
+	var err error
+	if cfg.RedirectDelay, err = parseRedirectDelay(src.get("REDIRECT_DELAY_SECONDS")); err != nil {
+		return nil, err
+	}
+
+	cfg.CompletionFormat = src.get("COMPLETION_PROPERTY_FORMAT")
+	if cfg.CompletionFormat == "" {
+		cfg.CompletionFormat = defaultCompletionPropertyFormat
+	}
+	if err := validateCompletionFormat(cfg.CompletionFormat); err != nil {
+		return nil, err
+	}
+
+	if cfg.FuzzyMaxDistance, err = parseFuzzyDistance(src.get("FUZZY_MATCH_DISTANCE")); err != nil {
+		return nil, err
+	}
+	if cfg.TeamLookupRetry, err = parseTeamLookupRetry(src.get("TEAM_LOOKUP_RETRY")); err != nil {
+		return nil, err
+	}
+	if cfg.TeamInputMode, err = parseTeamInputMode(src.get("TEAM_INPUT_MODE")); err != nil {
+		return nil, err
+	}
+	if cfg.MatchMode, err = parseMatchMode(src.get("MATCH_MODE")); err != nil {
+		return nil, err
+	}
+	if cfg.MatchPipeline, err = parseMatchPipeline(src.get("TEAM_MATCH_PIPELINE")); err != nil {
+		return nil, err
+	}
+	if cfg.PositionMode, err = parsePositionMode(src.get("POSITION_MODE")); err != nil {
+		return nil, err
+	}
+	if cfg.EventWindow, err = parseEventWindow(src.get("EVENT_START"), src.get("EVENT_END")); err != nil {
+		return nil, err
+	}
+	if cfg.FinishMode, cfg.FinishCount, err = parseFinishCondition(src.get("FINISH_MODE"), src.get("FINISH_COUNT")); err != nil {
+		return nil, err
+	}
+	if cfg.FinishRedirectURL, cfg.FinishRedirectDelay, err = parseFinishRedirect(src.get("FINISH_REDIRECT_URL"), src.get("FINISH_REDIRECT_DELAY")); err != nil {
+		return nil, err
+	}
+	if cfg.Server, err = parseServerConfig(src.get); err != nil {
+		return nil, err
+	}
+	if cfg.AccessLog, err = parseAccessLog(src.get("ACCESS_LOG")); err != nil {
+		return nil, err
+	}
+	if cfg.MaxConcurrent, cfg.QueueTimeout, err = parseConcurrencyLimit(src.get("MAX_CONCURRENT_REQUESTS"), src.get("REQUEST_QUEUE_TIMEOUT")); err != nil {
+		return nil, err
+	}
+	if cfg.NotionMaxConcurrent, err = parseNotionConcurrency(src.get("NOTION_MAX_CONCURRENT")); err != nil {
+		return nil, err
+	}
+	if cfg.TeamNotFoundAction, err = parseTeamNotFoundAction(src.get("TEAM_NOT_FOUND_ACTION")); err != nil {
+		return nil, err
+	}
+	if cfg.EmptyRouteAction, err = parseEmptyRouteAction(src.get("EMPTY_ROUTE_ACTION")); err != nil {
+		return nil, err
+	}
+	if cfg.MVPIdeas, err = loadMVPIdeas(src.get("MVP_FILE")); err != nil {
+		return nil, err
+	}
+	if cfg.Answers, err = parseAnswerConfig(src.get("ANSWER_MAX_ATTEMPTS"), src.get("ANSWER_HINT_AFTER"), src.get("ANSWER_LIMIT_ACTION")); err != nil {
+		return nil, err
+	}
+	if cfg.DefaultLanguage, err = parseDefaultLanguage(src.get("DEFAULT_LANGUAGE")); err != nil {
+		return nil, err
+	}
+	if cfg.StartChallengeID, err = parseStartChallengeID(src.get("START_CHALLENGE_ID")); err != nil {
+		return nil, err
+	}
+	if cfg.UpdatesBuffer, err = parseUpdatesBuffer(src.get("UPDATES_BUFFER")); err != nil {
+		return nil, err
+	}
+	if cfg.AllowedOrigins, err = parseAllowedOrigins(src.get("ALLOWED_ORIGINS")); err != nil {
+		return nil, err
+	}
+	if cfg.TrustedProxies, err = parseTrustedProxies(src.get("TRUSTED_PROXIES")); err != nil {
+		return nil, err
+	}
+
+	cfg.ChallengeURLTemplate = src.get("CHALLENGE_URL_TEMPLATE")
+	if cfg.ChallengeURLTemplate == "" && cfg.DemoMode {
+		cfg.ChallengeURLTemplate = demoChallengeURLTemplate(cfg.BaseURL, cfg.Port)
+	}
+	if cfg.ChallengeURLTemplate == "" {
+		cfg.ChallengeURLTemplate = defaultChallengeURLTemplate
+	}
+	if err := validateURLTemplate(cfg.ChallengeURLTemplate); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// readConfigFile liest eine flache YAML- oder JSON-Datei, deren Schlüssel den
+// Namen der Umgebungsvariablen entsprechen (z.B. REDIRECT_DELAY_SECONDS: 5).
+// Listen werden kommagetrennt übernommen, z.B. für CHALLENGES_DB_IDS.
+func readConfigFile(path string) (configSource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Lesen von CONFIG_FILE: %w", err)
+	}
+
+	raw := map[string]any{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &raw)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("CONFIG_FILE %q muss auf .yaml, .yml oder .json enden", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Parsen von CONFIG_FILE %q: %w", path, err)
+	}
+
+	src := make(configSource, len(raw))
+	for key, value := range raw {
+		s, err := configValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("CONFIG_FILE: %s %w", key, err)
+		}
+		src[strings.ToUpper(key)] = s
+	}
+	return src, nil
+}
+
+// configValue wandelt einen Wert aus der Konfigurationsdatei in die Textform der Umgebungsvariable
+func configValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			s, err := configValue(item)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, s)
+		}
+		return strings.Join(parts, ","), nil
+	default:
+		return "", fmt.Errorf("hat einen nicht unterstützten Typ %T", value)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writeConfigFile legt eine Konfigurationsdatei mit dem Namen name im Temp-Verzeichnis an
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    configSource
+		wantErr bool
+	}{
+		{
+			name:    "YAML",
+			file:    "hunt.yaml",
+			content: "NOTION_TOKEN: secret\nREDIRECT_DELAY_SECONDS: 5\nCONFIRM_ADVANCE: true\n",
+			want:    configSource{"NOTION_TOKEN": "secret", "REDIRECT_DELAY_SECONDS": "5", "CONFIRM_ADVANCE": "true"},
+		},
+		{
+			name:    "JSON",
+			file:    "hunt.json",
+			content: `{"TEAMS_DB_ID": "teams", "REDIRECT_DELAY_SECONDS": 2.5, "FINISH_MESSAGE": null}`,
+			want:    configSource{"TEAMS_DB_ID": "teams", "REDIRECT_DELAY_SECONDS": "2.5", "FINISH_MESSAGE": ""},
+		},
+		{
+			name:    "Listen und kleingeschriebene Schlüssel",
+			file:    "hunt.yml",
+			content: "challenges_db_ids:\n  - zone-a\n  - zone-b\n",
+			want:    configSource{"CHALLENGES_DB_IDS": "zone-a,zone-b"},
+		},
+		{name: "unbekannte Endung", file: "hunt.toml", content: "NOTION_TOKEN = 'x'", wantErr: true},
+		{name: "kaputtes JSON", file: "hunt.json", content: `{"NOTION_TOKEN":`, wantErr: true},
+		{name: "verschachtelter Wert", file: "hunt.yaml", content: "SERVER:\n  PORT: 80\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readConfigFile(writeConfigFile(t, tt.file, tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readConfigFile: Fehler = %v, erwartet Fehler = %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !maps.Equal(got, tt.want) {
+				t.Errorf("readConfigFile = %v, erwartet %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadConfigFileMissing(t *testing.T) {
+	if _, err := readConfigFile(filepath.Join(t.TempDir(), "fehlt.yaml")); err == nil {
+		t.Error("fehlende Datei ohne Fehler")
+	}
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	file := writeConfigFile(t, "hunt.yaml", `
+NOTION_TOKEN: aus-der-datei
+TEAMS_DB_ID: teams-datei
+CHALLENGES_DB_IDS: [zone-a, zone-b]
+REDIRECT_DELAY_SECONDS: 7
+FINISH_MESSAGE: Bis bald
+`)
+
+	tests := []struct {
+		name             string
+		env              map[string]string
+		wantToken        string
+		wantDelay        int
+		wantMessage      string
+		wantChallengeDBs []string
+	}{
+		{
+			name:             "nur Datei",
+			wantToken:        "aus-der-datei",
+			wantDelay:        7,
+			wantMessage:      "Bis bald",
+			wantChallengeDBs: []string{"zone-a", "zone-b"},
+		},
+		{
+			name:             "Umgebung überschreibt Datei",
+			env:              map[string]string{"NOTION_TOKEN": "aus-der-umgebung", "REDIRECT_DELAY_SECONDS": "1", "CHALLENGES_DB_IDS": "zone-c"},
+			wantToken:        "aus-der-umgebung",
+			wantDelay:        1,
+			wantMessage:      "Bis bald",
+			wantChallengeDBs: []string{"zone-c"},
+		},
+		{
+			name:             "leere Variable zählt als nicht gesetzt",
+			env:              map[string]string{"FINISH_MESSAGE": ""},
+			wantToken:        "aus-der-datei",
+			wantDelay:        7,
+			wantMessage:      "Bis bald",
+			wantChallengeDBs: []string{"zone-a", "zone-b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Werte aus der Umgebung des Testlaufs dürfen die Datei nicht überdecken
+			for _, name := range []string{"NOTION_TOKEN", "TEAMS_DB_ID", "CHALLENGES_DB_ID", "CHALLENGES_DB_IDS", "REDIRECT_DELAY_SECONDS", "FINISH_MESSAGE", "DEMO_MODE"} {
+				t.Setenv(name, "")
+			}
+			t.Setenv("CONFIG_FILE", file)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			cfg, err := loadConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.NotionToken != tt.wantToken || cfg.TeamsDBID != "teams-datei" {
+				t.Errorf("NotionToken = %q, TeamsDBID = %q", cfg.NotionToken, cfg.TeamsDBID)
+			}
+			if cfg.RedirectDelay != tt.wantDelay {
+				t.Errorf("RedirectDelay = %d, erwartet %d", cfg.RedirectDelay, tt.wantDelay)
+			}
+			if cfg.FinishMessage != tt.wantMessage {
+				t.Errorf("FinishMessage = %q, erwartet %q", cfg.FinishMessage, tt.wantMessage)
+			}
+			if !slices.Equal(cfg.ChallengeDBIDs, tt.wantChallengeDBs) {
+				t.Errorf("ChallengeDBIDs = %v, erwartet %v", cfg.ChallengeDBIDs, tt.wantChallengeDBs)
+			}
+		})
+	}
+}
+
+func TestLoadConfigInvalidFileValue(t *testing.T) {
+	// Werte aus der Datei werden genauso geprüft wie Umgebungsvariablen
+	t.Setenv("DEMO_MODE", "true")
+	t.Setenv("REDIRECT_DELAY_SECONDS", "")
+	t.Setenv("CONFIG_FILE", writeConfigFile(t, "hunt.json", `{"REDIRECT_DELAY_SECONDS": -3}`))
+	if _, err := loadConfig(); err == nil {
+		t.Error("negatives REDIRECT_DELAY_SECONDS aus der Datei ohne Fehler")
+	}
+}
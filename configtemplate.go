@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// configOption beschreibt eine Einstellung für die Konfigurationsvorlage
+type configOption struct {
+	Key         string
+	Default     string
+	Description string
+	Secret      bool // Wert erscheint nie in der Vorlage
+}
+
+// configOptions listet alle Einstellungen in der Reihenfolge der Vorlage.
+// Neue Einstellungen in loadConfig gehören auch hierher.
+var configOptions = []configOption{
+	{Key: "NOTION_TOKEN", Description: "Token der Notion-Integration", Secret: true},
+	{Key: "TEAMS_DB_ID", Description: "ID der Team-Datenbank"},
+	{Key: "CHALLENGES_DB_ID", Description: "ID der Challenge-Datenbank"},
+	{Key: "CHALLENGES_DB_IDS", Description: "Mehrere Challenge-Datenbanken, kommagetrennt (statt CHALLENGES_DB_ID)"},
+	{Key: "ADMIN_TOKEN", Description: "Bearer-Token für /admin, leer: Admin-Routen aus", Secret: true},
+	{Key: "PORT", Default: "8080", Description: "HTTP-Port"},
+	{Key: "PUBLIC_BASE_URL", Description: "Öffentliche Basis-URL, z.B. für QR-Codes"},
+	{Key: "DEMO_MODE", Default: "false", Description: "Teams und Challenges aus dem Speicher statt aus Notion"},
+	{Key: "DEBUG", Default: "false", Description: "Template-Vorschau unter /debug/template/:name"},
+	{Key: "PRODUCTION", Default: "false", Description: "Fehlerdetails nur ins Log, nicht an Besucher"},
+	{Key: "LOG_LEVEL", Default: "info", Description: "debug, info, warn oder error"},
+	{Key: "LOG_FORMAT", Default: "text", Description: "text oder json"},
+	{Key: "ACCESS_LOG", Default: "on", Description: "Eine Log-Zeile pro Request (on, off)"},
+	{Key: "EVENT_LOG", Description: "Event-Log: leer (aus), stdout oder Dateipfad"},
+	{Key: "UPDATES_BUFFER", Default: strconv.Itoa(defaultUpdatesBuffer), Description: "Events für /api/updates, 0 schaltet den Endpunkt ab"},
+	{Key: "METRICS", Default: "false", Description: "Prometheus-Metriken unter /metrics"},
+	{Key: "CHALLENGE_URL_TEMPLATE", Default: defaultChallengeURLTemplate, Description: "URL einer Challenge, {id} ohne und {uuid} mit Bindestrichen"},
+	{Key: "COMPLETION_PROPERTY_FORMAT", Default: defaultCompletionPropertyFormat, Description: "Property-Name der Abschluss-Marker, genau ein %d"},
+	{Key: "REDIRECT_DELAY_SECONDS", Default: "0", Description: "Wartezeit vor der Weiterleitung zur nächsten Challenge"},
+	{Key: "SHOW_CHALLENGE_DESCRIPTION", Default: "false", Description: "Beschreibung der Challenge im Teamformular"},
+	{Key: "WARMUP", Default: "false", Description: "Caches beim Start füllen"},
+	{Key: "CONFIRM_ADVANCE", Default: "false", Description: "Abschluss erst nach Bestätigung"},
+	{Key: "HONEYPOT", Default: "false", Description: "Verstecktes Formularfeld gegen Bots"},
+	{Key: "START_CHALLENGE_ID", Description: "Challenge für den Start-Link, leer: niedrigste ID"},
+	{Key: "POSITION_MODE", Default: positionModeRelation, Description: "relation, fallback oder markers"},
+	{Key: "FINISH_MODE", Default: finishModeAll, Description: "all oder count"},
+	{Key: "FINISH_COUNT", Description: "Anzahl Challenges bis zum Ziel bei FINISH_MODE=count"},
+	{Key: "FINISH_MESSAGE", Description: "Zusätzlicher Text auf der Zielseite"},
+	{Key: "CONFETTI", Default: "true", Description: "Konfetti auf der Zielseite"},
+	{Key: "FINISH_REDIRECT_URL", Description: "Weiterleitung von der Zielseite (URL oder Pfad)"},
+	{Key: "FINISH_REDIRECT_DELAY", Default: strconv.Itoa(defaultFinishRedirectDelay), Description: "Sekunden bis zur Weiterleitung von der Zielseite"},
+	{Key: "EMPTY_ROUTE_ACTION", Default: emptyRouteNotice, Description: "Teams ohne Route: notice oder finished"},
+	{Key: "EVENT_START", Description: "Beginn des Events (RFC 3339)"},
+	{Key: "EVENT_END", Description: "Ende des Events (RFC 3339)"},
+	{Key: "EVENT_PIN", Description: "PIN vor dem Teamformular", Secret: true},
+	{Key: "REMEMBER_TEAM", Default: "false", Description: "Team per Cookie merken"},
+	{Key: "SESSION_SECRET", Description: "Schlüssel für signierte Cookies, leer: zufällig pro Start", Secret: true},
+	{Key: "TEAM_INPUT_MODE", Default: teamInputDropdown, Description: "dropdown oder search"},
+	{Key: "MATCH_MODE", Default: matchModePrefix, Description: "Teilsuche: prefix oder substring"},
+	{Key: "TEAM_MATCH_PIPELINE", Default: strings.Join(defaultMatchPipeline, ","), Description: "Reihenfolge der Team-Suche"},
+	{Key: "FUZZY_MATCH_DISTANCE", Default: "0", Description: "Erlaubte Tippfehler, 0: aus"},
+	{Key: "FUZZY_AUTO_ACCEPT", Default: "true", Description: "Einzelnen unscharfen Treffer direkt übernehmen"},
+	{Key: "TEAM_LOOKUP_RETRY", Description: "Erneuter Versuch der Team-Suche nach dieser Dauer, z.B. 500ms"},
+	{Key: "TEAM_NOT_FOUND_ACTION", Default: teamNotFoundError, Description: "error, link oder redirect"},
+	{Key: "FEATURE_REGISTRATION", Default: "false", Description: "Selbstregistrierung von Teams"},
+	{Key: "FEATURE_LEADERBOARD", Default: "true", Description: "Leaderboard"},
+	{Key: "FEATURE_MVP", Default: "true", Description: "MVP-Generator"},
+	{Key: "MVP_FILE", Description: "JSON-Datei mit MVP-Ideen, leer: eingebaute Liste"},
+	{Key: "MVP_STATE_FILE", Description: "Datei für vergebene MVP-Ideen, leer: nur im Speicher"},
+	{Key: "ANSWER_MAX_ATTEMPTS", Default: "0", Description: "Versuche pro Antwort, 0: unbegrenzt"},
+	{Key: "ANSWER_HINT_AFTER", Default: "0", Description: "Hinweis nach so vielen Fehlversuchen, 0: nie"},
+	{Key: "ANSWER_LIMIT_ACTION", Default: answerLimitLockout, Description: "lockout, hint oder reveal"},
+	{Key: "DEFAULT_LANGUAGE", Default: defaultLanguage, Description: "en oder de"},
+	{Key: "OPTIMISTIC_WRITES", Default: "false", Description: "Eigene Schreibzugriffe sofort sichtbar machen"},
+	{Key: "TEAM_LOCK", Default: "true", Description: "Weiterleitungen pro Team nacheinander abarbeiten"},
+	{Key: "MAX_CONCURRENT_REQUESTS", Default: "0", Description: "Gleichzeitige Requests, 0: unbegrenzt"},
+	{Key: "REQUEST_QUEUE_TIMEOUT", Default: defaultQueueTimeout.String(), Description: "Maximale Wartezeit in der Request-Warteschlange"},
+	{Key: "NOTION_MAX_CONCURRENT", Default: "0", Description: "Gleichzeitige Notion-Aufrufe, 0: unbegrenzt"},
+	{Key: "ALLOWED_ORIGINS", Description: "CORS-Origins, kommagetrennt oder *"},
+	{Key: "TRUSTED_PROXIES", Description: "Reverse-Proxies (IP oder CIDR, kommagetrennt), deren X-Forwarded-For gilt, leer: keine"},
+	{Key: "TLS_CERT_FILE", Description: "TLS-Zertifikat (zusammen mit TLS_KEY_FILE)"},
+	{Key: "TLS_KEY_FILE", Description: "TLS-Schlüssel (zusammen mit TLS_CERT_FILE)"},
+	{Key: "HTTP_IDLE_TIMEOUT", Default: defaultIdleTimeout.String(), Description: "Idle-Timeout für Keep-Alive-Verbindungen"},
+	{Key: "HTTP_READ_HEADER_TIMEOUT", Default: defaultReadHeaderTimeout.String(), Description: "Zeit zum Lesen der Request-Header"},
+	{Key: "HTTP_MAX_HEADER_BYTES", Default: strconv.Itoa(defaultMaxHeaderBytes), Description: "Maximale Größe der Request-Header"},
+	{Key: "HTTP2_MAX_CONCURRENT_STREAMS", Default: strconv.Itoa(defaultMaxConcurrentStreams), Description: "Gleichzeitige HTTP/2-Streams pro Verbindung"},
+}
+
+// effectiveConfigValues liefert die gesetzten Werte aller nicht geheimen Einstellungen
+func effectiveConfigValues(src configSource) map[string]string {
+	values := make(map[string]string)
+	for _, option := range configOptions {
+		if v := src.get(option.Key); v != "" && !option.Secret {
+			values[option.Key] = v
+		}
+	}
+	return values
+}
+
+// templateValue liefert den Wert einer Einstellung für die Vorlage: gesetzt vor Standard, Secrets immer leer
+func templateValue(option configOption, values map[string]string) string {
+	if option.Secret {
+		return ""
+	}
+	if v, ok := values[option.Key]; ok {
+		return v
+	}
+	return option.Default
+}
+
+// renderConfigYAML baut eine CONFIG_FILE-Vorlage mit einem Kommentar pro Einstellung
+func renderConfigYAML(values map[string]string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("# Konfigurationsvorlage für CONFIG_FILE. Gesetzte Umgebungsvariablen haben Vorrang.\n")
+	buf.WriteString("# Secrets sind absichtlich leer und sollten per Umgebungsvariable gesetzt werden.\n")
+	for _, option := range configOptions {
+		comment := option.Description
+		switch {
+		case option.Secret:
+			comment += " (Secret)"
+		case option.Default != "":
+			comment += fmt.Sprintf(" (Standard: %s)", option.Default)
+		}
+		// JSON-Strings sind gültige YAML-Strings, so bleiben Sonderzeichen wie ":" oder "#" unverfälscht
+		value, _ := json.Marshal(templateValue(option, values))
+		fmt.Fprintf(&buf, "\n# %s\n%s: %s\n", comment, option.Key, value)
+	}
+	return buf.Bytes()
+}
+
+// handleAdminConfigTemplate liefert eine Konfigurationsdatei mit allen Einstellungen als Download:
+// gesetzte Werte der laufenden Instanz, sonst die Standardwerte, Secrets immer leer.
+// ?format=json liefert dasselbe als flaches JSON-Objekt (ohne Kommentare).
+func (app *App) handleAdminConfigTemplate(c *gin.Context) {
+	switch c.DefaultQuery("format", "yaml") {
+	case "yaml":
+		c.Header("Content-Disposition", `attachment; filename="config.yaml"`)
+		c.Data(http.StatusOK, "application/yaml; charset=utf-8", renderConfigYAML(app.configValues))
+	case "json":
+		values := make(map[string]string, len(configOptions))
+		for _, option := range configOptions {
+			values[option.Key] = templateValue(option, app.configValues)
+		}
+		c.Header("Content-Disposition", `attachment; filename="config.json"`)
+		c.IndentedJSON(http.StatusOK, values)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format muss yaml oder json sein"})
+	}
+}
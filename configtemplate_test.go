@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestConfigOptionsUnique(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, option := range configOptions {
+		if seen[option.Key] {
+			t.Errorf("%s doppelt in configOptions", option.Key)
+		}
+		seen[option.Key] = true
+		if option.Description == "" {
+			t.Errorf("%s ohne Beschreibung", option.Key)
+		}
+		if option.Secret && option.Default != "" {
+			t.Errorf("Secret %s mit Standardwert", option.Key)
+		}
+	}
+}
+
+func TestRenderConfigYAML(t *testing.T) {
+	values := map[string]string{
+		"FINISH_MESSAGE": `Weiter: #1 "Ziel"`,
+		"PORT":           "9090",
+		"NOTION_TOKEN":   "ntn_geheim",
+	}
+	got, err := readConfigFile(writeConfigFile(t, "config.yaml", string(renderConfigYAML(values))))
+	if err != nil {
+		t.Fatalf("Vorlage nicht lesbar: %v", err)
+	}
+	for _, option := range configOptions {
+		if want := templateValue(option, values); got[option.Key] != want {
+			t.Errorf("%s = %q, erwartet %q", option.Key, got[option.Key], want)
+		}
+	}
+	if got["NOTION_TOKEN"] != "" || got["FINISH_MESSAGE"] != values["FINISH_MESSAGE"] {
+		t.Errorf("NOTION_TOKEN = %q, FINISH_MESSAGE = %q", got["NOTION_TOKEN"], got["FINISH_MESSAGE"])
+	}
+}
+
+func TestAdminConfigTemplate(t *testing.T) {
+	ta := newTestApp(t, map[string]string{"FINISH_MESSAGE": "Bis bald: #2", "SESSION_SECRET": "sitzung-geheim"})
+
+	tests := []struct {
+		format          string
+		wantFile        string
+		wantContentType string
+	}{
+		{wantFile: "config.yaml", wantContentType: "application/yaml"},
+		{format: "yaml", wantFile: "config.yaml", wantContentType: "application/yaml"},
+		{format: "json", wantFile: "config.json", wantContentType: "application/json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wantFile+" "+tt.format, func(t *testing.T) {
+			path := "/admin/config-template"
+			if tt.format != "" {
+				path += "?format=" + tt.format
+			}
+			w := ta.admin(http.MethodGet, path, "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+			if got := w.Header().Get("Content-Disposition"); !strings.Contains(got, tt.wantFile) {
+				t.Errorf("Content-Disposition = %q", got)
+			}
+			if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.wantContentType) {
+				t.Errorf("Content-Type = %q", got)
+			}
+			for _, secret := range []string{testAdminToken, "sitzung-geheim"} {
+				if strings.Contains(w.Body.String(), secret) {
+					t.Errorf("Secret %q in der Vorlage", secret)
+				}
+			}
+
+			// Die Vorlage ergibt wieder eine gültige Konfiguration mit den Standardwerten
+			for _, option := range configOptions {
+				t.Setenv(option.Key, "")
+			}
+			t.Setenv("CONFIG_FILE", writeConfigFile(t, tt.wantFile, w.Body.String()))
+			cfg, err := loadConfig()
+			if err != nil {
+				t.Fatalf("Vorlage ergibt keine gültige Konfiguration: %v", err)
+			}
+			if !cfg.DemoMode || cfg.FinishMessage != "Bis bald: #2" || cfg.SessionSecret != "" {
+				t.Errorf("DemoMode = %v, FinishMessage = %q, SessionSecret = %q", cfg.DemoMode, cfg.FinishMessage, cfg.SessionSecret)
+			}
+			if cfg.Port != "8080" || cfg.RedirectDelay != 0 || !cfg.Confetti || cfg.FinishRedirectURL != "" ||
+				cfg.UpdatesBuffer != defaultUpdatesBuffer || !slices.Equal(cfg.MatchPipeline, defaultMatchPipeline) {
+				t.Errorf("Standardwerte nicht übernommen: %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestAdminConfigTemplateJSONKeys(t *testing.T) {
+	ta := newTestApp(t, nil)
+	w := ta.admin(http.MethodGet, "/admin/config-template?format=json", "")
+	var values map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &values); err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != len(configOptions) {
+		t.Errorf("%d Schlüssel, erwartet %d", len(values), len(configOptions))
+	}
+	if w := ta.admin(http.MethodGet, "/admin/config-template?format=toml", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("format=toml: Status = %d, erwartet %d", w.Code, http.StatusBadRequest)
+	}
+}
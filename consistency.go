@@ -0,0 +1,138 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+// optimisticWindow begrenzt, wie lange eigene Schreibvorgänge gelesene Pages überlagern.
+// Notion liefert direkt nach einem Update gelegentlich noch den alten Stand.
+const optimisticWindow = 30 * time.Second
+
+// pendingWrites merkt sich erfolgreich geschriebene Properties pro Page (OPTIMISTIC_WRITES).
+// Nur bestätigte Schreibvorgänge werden vermerkt, fehlgeschlagene bleiben unsichtbar.
+// Ein nil-Wert schaltet die Überlagerung ab.
+type pendingWrites struct {
+	mu    sync.Mutex
+	pages map[string]map[string]pendingProperty
+}
+
+type pendingProperty struct {
+	value     notionapi.Property
+	writtenAt time.Time
+}
+
+func newPendingWrites() *pendingWrites {
+	return &pendingWrites{pages: make(map[string]map[string]pendingProperty)}
+}
+
+// record vermerkt die Properties eines erfolgreichen Updates
+func (pw *pendingWrites) record(pageID string, props notionapi.Properties) {
+	if pw == nil {
+		return
+	}
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+
+	key := normalizePageID(pageID)
+	if pw.pages[key] == nil {
+		pw.pages[key] = make(map[string]pendingProperty)
+	}
+	now := time.Now()
+	for name, prop := range props {
+		pw.pages[key][name] = pendingProperty{value: readFormProperty(prop), writtenAt: now}
+	}
+}
+
+// clear verwirft alle vermerkten Schreibstände und meldet, ob die Überlagerung aktiv ist
+func (pw *pendingWrites) clear() bool {
+	if pw == nil {
+		return false
+	}
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+
+	pw.pages = make(map[string]map[string]pendingProperty)
+	return true
+}
+
+// apply überschreibt die Properties einer gelesenen Page mit Schreibständen aus dem
+// optimisticWindow. Ältere Einträge werden dabei verworfen.
+func (pw *pendingWrites) apply(page *notionapi.Page) {
+	if pw == nil || page == nil {
+		return
+	}
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+
+	key := normalizePageID(string(page.ID))
+	pending := pw.pages[key]
+	if len(pending) == 0 {
+		return
+	}
+
+	// Properties ersetzen statt verändern, die Map kann aus einem Cache stammen
+	props := make(notionapi.Properties, len(page.Properties)+len(pending))
+	for name, prop := range page.Properties {
+		props[name] = prop
+	}
+	overlaid := 0
+	for name, p := range pending {
+		if time.Since(p.writtenAt) > optimisticWindow {
+			delete(pending, name)
+			continue
+		}
+		props[name] = p.value
+		overlaid++
+	}
+	if len(pending) == 0 {
+		delete(pw.pages, key)
+	}
+	if overlaid > 0 {
+		page.Properties = props
+	}
+}
+
+// readFormProperty wandelt geschriebene Properties (Werte, nur Text.Content)
+// in die gelesene Form (Pointer, mit PlainText), wie sie die Notion-API liefert.
+// Genutzt vom Demo-Speicher und für optimistische Schreibstände.
+func readFormProperty(prop notionapi.Property) notionapi.Property {
+	switch p := prop.(type) {
+	case notionapi.TitleProperty:
+		p.Title = withPlainText(p.Title)
+		return &p
+	case *notionapi.TitleProperty:
+		return &notionapi.TitleProperty{ID: p.ID, Type: p.Type, Title: withPlainText(p.Title)}
+	case notionapi.RichTextProperty:
+		p.RichText = withPlainText(p.RichText)
+		return &p
+	case *notionapi.RichTextProperty:
+		return &notionapi.RichTextProperty{ID: p.ID, Type: p.Type, RichText: withPlainText(p.RichText)}
+	case notionapi.NumberProperty:
+		return &p
+	case notionapi.DateProperty:
+		return &p
+	case notionapi.RelationProperty:
+		return &p
+	case notionapi.SelectProperty:
+		return &p
+	case notionapi.CheckboxProperty:
+		return &p
+	case notionapi.URLProperty:
+		return &p
+	}
+	return prop
+}
+
+func withPlainText(parts []notionapi.RichText) []notionapi.RichText {
+	result := make([]notionapi.RichText, len(parts))
+	for i, part := range parts {
+		if part.PlainText == "" && part.Text != nil {
+			part.PlainText = part.Text.Content
+		}
+		result[i] = part
+	}
+	return result
+}
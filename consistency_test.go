@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+// staleNotion bestätigt Updates, ohne sie sofort sichtbar zu machen (wie Notion kurz nach
+// einem Schreibvorgang), oder lässt sie mit failUpdate scheitern
+type staleNotion struct {
+	notionService
+	failUpdate error
+}
+
+func (s *staleNotion) UpdatePage(ctx context.Context, pageID string, req *notionapi.PageUpdateRequest) (*notionapi.Page, error) {
+	if s.failUpdate != nil {
+		return nil, s.failUpdate
+	}
+	return s.notionService.GetPage(ctx, pageID)
+}
+
+func TestOptimisticWrites(t *testing.T) {
+	tests := []struct {
+		name       string
+		optimistic string
+		failUpdate error
+		wantErr    bool
+		wantDone   bool
+	}{
+		{name: "ohne OPTIMISTIC_WRITES veralteter Stand", optimistic: "false"},
+		{name: "eigener Schreibstand überlagert", optimistic: "true", wantDone: true},
+		{name: "fehlgeschlagenes Update bleibt unsichtbar", optimistic: "true", failUpdate: errors.New("notion nicht erreichbar"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"OPTIMISTIC_WRITES": tt.optimistic})
+			ta.notion = &staleNotion{notionService: ta.store, failUpdate: tt.failUpdate}
+
+			err := ta.recordCompletion(t.Context(), demoTeamPageID, "1", false, "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("recordCompletion: %v", err)
+			}
+
+			completed, err := ta.getCompletions(t.Context(), demoTeamPageID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, done := completed[1]; done != tt.wantDone {
+				t.Errorf("Abschluss sichtbar = %v, erwartet %v", done, tt.wantDone)
+			}
+			// Der Demo-Speicher selbst hat das Update nie gesehen
+			if _, done := ta.completedChallenges(ta.teamPage(t, demoTeamPageID))[1]; done {
+				t.Error("Update ist doch im Speicher gelandet")
+			}
+		})
+	}
+}
+
+func TestPendingWritesApply(t *testing.T) {
+	newPage := func() *notionapi.Page {
+		return &notionapi.Page{ID: "team-1", Properties: notionapi.Properties{
+			teamScoreProperty: &notionapi.NumberProperty{Number: 10},
+		}}
+	}
+	written := notionapi.Properties{teamScoreProperty: notionapi.NumberProperty{Number: 30}}
+
+	tests := []struct {
+		name      string
+		pw        *pendingWrites
+		pageID    string
+		age       time.Duration // Alter des Schreibstands
+		wantScore float64
+	}{
+		{name: "abgeschaltet", pw: nil, pageID: "team-1", wantScore: 10},
+		{name: "frischer Schreibstand", pw: newPendingWrites(), pageID: "team-1", wantScore: 30},
+		{name: "Page-ID anders geschrieben", pw: newPendingWrites(), pageID: "TEAM1", wantScore: 30},
+		{name: "andere Page", pw: newPendingWrites(), pageID: "team-2", wantScore: 10},
+		{name: "außerhalb des Fensters", pw: newPendingWrites(), pageID: "team-1", age: optimisticWindow + time.Second, wantScore: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.pw.record(tt.pageID, written)
+			if tt.pw != nil && tt.age > 0 {
+				for name, p := range tt.pw.pages[normalizePageID(tt.pageID)] {
+					p.writtenAt = time.Now().Add(-tt.age)
+					tt.pw.pages[normalizePageID(tt.pageID)][name] = p
+				}
+			}
+
+			original := newPage()
+			page := *original
+			tt.pw.apply(&page)
+			if got := teamScore(&page); got != tt.wantScore {
+				t.Errorf("Score = %v, erwartet %v", got, tt.wantScore)
+			}
+			// Die gelesene Property-Map (z.B. aus einem Cache) bleibt unverändert
+			if teamScore(original) != 10 {
+				t.Error("Properties der Original-Page verändert")
+			}
+			if tt.age > 0 && len(tt.pw.pages) != 0 {
+				t.Error("abgelaufener Schreibstand nicht verworfen")
+			}
+		})
+	}
+}
+
+func TestPendingWritesClear(t *testing.T) {
+	var disabled *pendingWrites
+	if disabled.clear() {
+		t.Error("clear ohne OPTIMISTIC_WRITES meldet aktiv")
+	}
+
+	pw := newPendingWrites()
+	pw.record("team-1", notionapi.Properties{teamScoreProperty: notionapi.NumberProperty{Number: 30}})
+	if !pw.clear() || len(pw.pages) != 0 {
+		t.Error("clear hat die Schreibstände nicht verworfen")
+	}
+}
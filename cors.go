@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseAllowedOrigins liest ALLOWED_ORIGINS (kommagetrennt, z.B. https://app.example.org).
+// "*" erlaubt jede Origin; leer bedeutet nur Same-Origin.
+func parseAllowedOrigins(s string) ([]string, error) {
+	var origins []string
+	for _, origin := range strings.Split(s, ",") {
+		origin = strings.TrimRight(strings.TrimSpace(origin), "/")
+		if origin == "" {
+			continue
+		}
+		if origin != "*" {
+			u, err := url.Parse(origin)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" {
+				return nil, fmt.Errorf("ALLOWED_ORIGINS enthält ungültige Origin %q (erwartet z.B. https://app.example.org)", origin)
+			}
+		}
+		origins = append(origins, origin)
+	}
+	return origins, nil
+}
+
+// corsMiddleware setzt die CORS-Header für erlaubte Origins und beantwortet Preflight-Requests.
+// Fremde Origins bekommen keine CORS-Header, der Browser blockiert die Antwort dann selbst.
+func corsMiddleware(origins []string) gin.HandlerFunc {
+	allowAll := false
+	allowed := make(map[string]bool, len(origins))
+	for _, origin := range origins {
+		if origin == "*" {
+			allowAll = true
+		}
+		allowed[origin] = true
+	}
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		c.Writer.Header().Add("Vary", "Origin")
+
+		if origin == "" || (!allowAll && !allowed[origin]) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		c.Header("Access-Control-Allow-Origin", origin)
+		if !preflight {
+			c.Next()
+			return
+		}
+
+		headers := c.GetHeader("Access-Control-Request-Headers")
+		if headers == "" {
+			headers = "Content-Type, Authorization"
+		}
+		c.Header("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", headers)
+		c.Header("Access-Control-Max-Age", "600")
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
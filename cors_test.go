@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestParseAllowedOrigins(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{in: "", want: nil},
+		{in: "https://app.example.org", want: []string{"https://app.example.org"}},
+		{in: " https://a.example.org/ , http://localhost:3000,", want: []string{"https://a.example.org", "http://localhost:3000"}},
+		{in: "*", want: []string{"*"}},
+		{in: "app.example.org", wantErr: true},
+		{in: "ftp://app.example.org", wantErr: true},
+		{in: "https://app.example.org/api", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseAllowedOrigins(tt.in)
+		if (err != nil) != tt.wantErr || !slices.Equal(got, tt.want) {
+			t.Errorf("parseAllowedOrigins(%q) = %v, %v, erwartet %v (Fehler: %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestCORS(t *testing.T) {
+	const frontend = "https://frontend.example.org"
+	tests := []struct {
+		name        string
+		allowed     string
+		method      string
+		origin      string
+		preflight   bool
+		wantStatus  int
+		wantAllowed string // erwarteter Access-Control-Allow-Origin
+	}{
+		{name: "erlaubte Origin", allowed: frontend, method: http.MethodGet, origin: frontend, wantStatus: http.StatusOK, wantAllowed: frontend},
+		{name: "fremde Origin", allowed: frontend, method: http.MethodGet, origin: "https://evil.example.org", wantStatus: http.StatusOK},
+		{name: "ohne Origin", allowed: frontend, method: http.MethodGet, wantStatus: http.StatusOK},
+		{name: "Wildcard", allowed: "*", method: http.MethodGet, origin: "https://irgendwo.example.org", wantStatus: http.StatusOK, wantAllowed: "https://irgendwo.example.org"},
+		{name: "Preflight erlaubt", allowed: frontend, method: http.MethodOptions, origin: frontend, preflight: true, wantStatus: http.StatusNoContent, wantAllowed: frontend},
+		{name: "Preflight fremd", allowed: frontend, method: http.MethodOptions, origin: "https://evil.example.org", preflight: true, wantStatus: http.StatusForbidden},
+		{name: "ohne ALLOWED_ORIGINS nur Same-Origin", method: http.MethodGet, origin: frontend, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"ALLOWED_ORIGINS": tt.allowed})
+			req := httptest.NewRequest(tt.method, "/api/challenges/1", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+				req.Header.Set("Access-Control-Request-Headers", "X-Custom")
+			}
+			w := httptest.NewRecorder()
+			ta.handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, erwartet %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantAllowed {
+				t.Errorf("Access-Control-Allow-Origin = %q, erwartet %q", got, tt.wantAllowed)
+			}
+			if tt.preflight && tt.wantAllowed != "" {
+				if got := w.Header().Get("Access-Control-Allow-Headers"); got != "X-Custom" {
+					t.Errorf("Access-Control-Allow-Headers = %q", got)
+				}
+				if w.Header().Get("Access-Control-Allow-Methods") == "" {
+					t.Error("Access-Control-Allow-Methods fehlt")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// templateSamples enthält Beispieldaten für die Template-Vorschau
+func templateSamples() map[string]gin.H {
+	now := time.Now()
+	return map[string]gin.H{
+		"home.html": {
+			"featureMVP":         true,
+			"featureLeaderboard": true,
+			"startURL":           "https://example.notion.site/start",
+		},
+		"teamform.html": {
+			"challengeID":    "3",
+			"teamOptions":    selectTeamOption(renderTeamOptions([]string{"Die Entdecker", "Schatzsucher", "Team Rakete"}), "Schatzsucher"),
+			"selectedTeam":   "Schatzsucher",
+			"description":    template.HTML("<p>Findet den <strong>alten Baum</strong> am Flussufer.</p><ul><li>Foto machen</li><li>Rätsel lösen</li></ul>"),
+			"teaser":         "Wo das Wasser rauscht, wartet ein alter Freund …",
+			"honeypot":       true,
+			"honeypotKey":    honeypotField,
+			"answerRequired": true,
+			"answer": answerState{
+				Error:        "Leider falsch, versucht es noch einmal.",
+				Limited:      true,
+				AttemptsLeft: 2,
+				Hint:         "Zählt die Fenster im Turm.",
+			},
+		},
+		"confirm.html": {
+			"challengeID": "3",
+			"team":        "Die Entdecker",
+			"lang":        defaultLanguage,
+		},
+		"redirect.html": {
+			"url":   "https://example.notion.site/challenge",
+			"team":  "Die Entdecker",
+			"delay": 30,
+			"lang":  defaultLanguage,
+		},
+		"finished.html": {
+			"team":          "Die Entdecker",
+			"message":       "Kommt zur Siegerehrung um 18 Uhr am Lagerfeuer!",
+			"completed":     8,
+			"score":         75,
+			"confetti":      true,
+			"redirectURL":   "/leaderboard",
+			"redirectDelay": 10,
+			"lang":          defaultLanguage,
+		},
+		"error.html": {
+			"error":       "Team nicht gefunden",
+			"suggestions": []string{"Die Entdecker", "Die Entdeckerinnen"},
+			"challengeID": "3",
+			"registerURL": registerURL("Die Entdeker", "3"),
+		},
+		"pin.html": {
+			"next":  "/next/3",
+			"error": "Falsche PIN, bitte erneut versuchen",
+		},
+		"notyet.html": {
+			"challengeID": "3",
+			"team":        "Die Entdecker",
+			"seconds":     95,
+			"lang":        defaultLanguage,
+		},
+		"noroute.html": {
+			"team": "Die Entdecker",
+			"lang": defaultLanguage,
+		},
+		"register.html": {
+			"name":        "Die Entdecker",
+			"challengeID": "3",
+			"code":        "K7M2QX",
+		},
+		"mvpgenerator.html": {
+			"mvp":   mvpIdea{Name: "Portable Water Filter", Image: "https://example.org/mvp/water-filter.jpg"},
+			"mvps":  []mvpIdea{{Name: "Portable Water Filter", Image: "https://example.org/mvp/water-filter.jpg"}, {Name: "DIY Kite"}, {Name: "Mini Greenhouse"}},
+			"count": 3,
+		},
+		"leaderboard.html": {
+			"Entries": []leaderboardEntry{
+				{Rank: 1, Team: "Die Entdecker", Division: "U12", Score: 50, Completed: 5, LastCompletion: now},
+				{Rank: 2, Team: "Schatzsucher", Division: "U16", Score: 35, Completed: 4, Skipped: 1, LastCompletion: now},
+				{Rank: 3, Team: "Team Rakete", Division: "U12", Score: 20, Completed: 2, LastCompletion: now},
+			},
+			"Divisions": []string{"U12", "U16"},
+			"Page":      leaderboardPage{Page: 2, PageSize: 3, TotalPages: 3, Total: 9, PrevPage: 1, NextPage: 3, Paginated: true},
+			"Frozen":    true,
+			"FrozenAt":  now,
+		},
+	}
+}
+
+// handleDebugTemplate rendert ein Template mit Beispieldaten (nur bei DEBUG=true registriert)
+func (app *App) handleDebugTemplate(c *gin.Context) {
+	name := c.Param("name")
+	if !strings.HasSuffix(name, ".html") {
+		name += ".html"
+	}
+
+	data, ok := templateSamples()[name]
+	if !ok || app.templates.Lookup(name) == nil {
+		c.String(http.StatusNotFound, "Unbekanntes Template: %s", name)
+		return
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := app.templates.ExecuteTemplate(c.Writer, name, data); err != nil {
+		app.templateFailed(c, err)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDebugTemplate(t *testing.T) {
+	tests := []struct {
+		name       string
+		debug      string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "Debug aus", debug: "false", path: "/debug/template/teamform.html", wantStatus: http.StatusNotFound},
+		{name: "ohne Endung", debug: "true", path: "/debug/template/teamform", wantStatus: http.StatusOK, wantBody: "Schatzsucher"},
+		{name: "unbekanntes Template", debug: "true", path: "/debug/template/fehlt.html", wantStatus: http.StatusNotFound, wantBody: "Unbekanntes Template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"DEBUG": tt.debug})
+			w := ta.do(http.MethodGet, tt.path, nil)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, erwartet %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("%q fehlt in der Antwort:\n%s", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestDebugTemplateSamples(t *testing.T) {
+	ta := newTestApp(t, map[string]string{"DEBUG": "true"})
+	for name := range templateSamples() {
+		t.Run(name, func(t *testing.T) {
+			w := ta.do(http.MethodGet, "/debug/template/"+name, nil)
+			// Scheitert das Template mittendrin, ist der Status schon gesendet
+			if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "Template-Fehler") {
+				t.Errorf("Status = %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestDebugTemplateErrorDetails(t *testing.T) {
+	for _, production := range []bool{false, true} {
+		t.Run("PRODUCTION="+strconv.FormatBool(production), func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"DEBUG": "true", "PRODUCTION": strconv.FormatBool(production)})
+			template.Must(ta.templates.New("teamform.html").Parse(`{{template "fehlt"}}`))
+
+			w := ta.do(http.MethodGet, "/debug/template/teamform.html", nil)
+			body := w.Body.String()
+			if w.Code != http.StatusInternalServerError || !strings.Contains(body, "Template-Fehler") {
+				t.Fatalf("Status = %d: %s", w.Code, body)
+			}
+			if got := strings.Contains(body, `no such template "fehlt"`); got == production {
+				t.Errorf("Details sichtbar = %v bei PRODUCTION=%v: %s", got, production, body)
+			}
+		})
+	}
+}
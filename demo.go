@@ -0,0 +1,431 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+// Feste IDs der Demo-Datenbanken für DEMO_MODE
+const (
+	demoTeamsDBID      = "demo-teams"
+	demoChallengesDBID = "demo-challenges"
+)
+
+// demoChallengeURLTemplate leitet im Demo-Modus direkt auf das Formular der nächsten
+// Challenge weiter. Die Page-IDs der Demo-Challenges sind gleichzeitig ihre Slugs.
+func demoChallengeURLTemplate(baseURL, port string) string {
+	if baseURL == "" {
+		baseURL = "http://localhost:" + port
+	}
+	return strings.TrimRight(baseURL, "/") + "/next/{uuid}"
+}
+
+// demoStore implementiert notionService im Speicher. Unterstützt werden die Filter,
+// die die Anwendung selbst verwendet (Text- und Zahlenvergleiche auf Properties).
+type demoStore struct {
+	mu        sync.Mutex
+	pages     map[string]*notionapi.Page
+	databases map[string][]string // Datenbank-ID → Page-IDs in Anlagereihenfolge
+	blocks    map[string]notionapi.Blocks
+	nextID    int
+}
+
+// newDemoStore legt den Speicher mit Beispiel-Challenges und zwei Teams an.
+// "Demo Team" (Code DEMO01) und "Die Füchse" (Code FUCHS1) laufen die Challenges
+// in unterschiedlicher Reihenfolge; jeder andere Teamname ist unbekannt.
+func newDemoStore() *demoStore {
+	s := &demoStore{
+		pages:     make(map[string]*notionapi.Page),
+		databases: map[string][]string{demoTeamsDBID: nil, demoChallengesDBID: nil},
+		blocks:    make(map[string]notionapi.Blocks),
+	}
+
+	challenges := []struct {
+		slug   string
+		id     int
+		title  string
+		teaser string
+		points float64
+	}{
+		{"demo-brunnen", 1, "Der alte Brunnen", "Zählt die Löwenköpfe am Brunnen auf dem Marktplatz.", 10},
+		{"demo-kirchturm", 2, "Blick vom Kirchturm", "Welche Uhrzeit zeigt die Turmuhr, wenn ihr oben ankommt?", 20},
+		{"demo-stadtpark", 3, "Rätsel im Stadtpark", "Findet die Bank mit der Messingplakette.", 15},
+		{"demo-rathaus", 4, "Ziel am Rathaus", "Das letzte Rätsel wartet vor dem Rathaus.", 25},
+	}
+	for _, ch := range challenges {
+		s.add(demoChallengesDBID, ch.slug, notionapi.Properties{
+			"Name":                  &notionapi.TitleProperty{Title: demoText(ch.title)},
+			"id":                    &notionapi.NumberProperty{Number: float64(ch.id)},
+			challengePointsProperty: &notionapi.NumberProperty{Number: ch.points},
+			challengeSlugProperty:   &notionapi.RichTextProperty{RichText: demoText(ch.slug)},
+			challengeTeaserProperty: &notionapi.RichTextProperty{RichText: demoText(ch.teaser)},
+		})
+		s.blocks[ch.slug] = notionapi.Blocks{
+			&notionapi.ParagraphBlock{
+				BasicBlock: notionapi.BasicBlock{Object: "block", Type: notionapi.BlockTypeParagraph},
+				Paragraph:  notionapi.Paragraph{RichText: demoText(ch.teaser)},
+			},
+		}
+	}
+
+	teams := []struct {
+		name    string
+		code    string
+		aliases string
+		route   []string
+	}{
+		{"Demo Team", "DEMO01", "Demo", []string{"demo-brunnen", "demo-kirchturm", "demo-stadtpark", "demo-rathaus"}},
+		{"Die Füchse", "FUCHS1", "Füchse", []string{"demo-brunnen", "demo-stadtpark", "demo-kirchturm", "demo-rathaus"}},
+	}
+	for _, team := range teams {
+		props := notionapi.Properties{
+			teamTitleProperty:   &notionapi.TitleProperty{Title: demoText(team.name)},
+			teamCodeProperty:    &notionapi.RichTextProperty{RichText: demoText(team.code)},
+			teamAliasesProperty: &notionapi.RichTextProperty{RichText: demoText(team.aliases)},
+			teamScoreProperty:   &notionapi.NumberProperty{},
+		}
+		// Ein freier Slot mehr als nötig, damit /admin/route die Route verlängern kann
+		for pos := 1; pos <= len(team.route)+1; pos++ {
+			relation := &notionapi.RelationProperty{Relation: []notionapi.Relation{}}
+			if pos <= len(team.route) {
+				relation.Relation = append(relation.Relation, notionapi.Relation{ID: notionapi.PageID(team.route[pos-1])})
+			}
+			props[fmt.Sprintf("Challenge%d", pos)] = relation
+		}
+		s.add(demoTeamsDBID, "", props)
+	}
+
+	return s
+}
+
+// add legt eine Page an; ohne ID wird eine fortlaufende vergeben. Aufrufer halten s.mu oder sind newDemoStore.
+func (s *demoStore) add(dbID, pageID string, props notionapi.Properties) *notionapi.Page {
+	if pageID == "" {
+		s.nextID++
+		pageID = fmt.Sprintf("demo-page-%d", s.nextID)
+	}
+	now := time.Now()
+	page := &notionapi.Page{
+		Object:         "page",
+		ID:             notionapi.ObjectID(pageID),
+		CreatedTime:    now,
+		LastEditedTime: now,
+		Parent:         notionapi.Parent{Type: notionapi.ParentTypeDatabaseID, DatabaseID: notionapi.DatabaseID(dbID)},
+		Properties:     notionapi.Properties{},
+	}
+	for name, prop := range props {
+		page.Properties[name] = readFormProperty(prop)
+	}
+	s.pages[pageID] = page
+	s.databases[dbID] = append(s.databases[dbID], pageID)
+	return page
+}
+
+func (s *demoStore) QueryDatabase(_ context.Context, dbID string, req *notionapi.DatabaseQueryRequest) (*notionapi.DatabaseQueryResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pageIDs, ok := s.databases[dbID]
+	if !ok {
+		return nil, demoNotFound("Datenbank", dbID)
+	}
+	if req != nil && req.Filter != nil {
+		if err := s.validateFilter(pageIDs, req.Filter); err != nil {
+			return nil, err
+		}
+	}
+
+	var matches []notionapi.Page
+	for _, id := range pageIDs {
+		page := s.pages[id]
+		if req == nil || req.Filter == nil || demoMatches(page, req.Filter) {
+			matches = append(matches, copyPage(page))
+		}
+	}
+
+	// Cursor ist der Index des ersten Treffers der nächsten Seite
+	start, size := 0, 100
+	if req != nil {
+		if req.StartCursor != "" {
+			start, _ = strconv.Atoi(string(req.StartCursor))
+		}
+		if req.PageSize > 0 && req.PageSize < size {
+			size = req.PageSize
+		}
+	}
+	start = min(start, len(matches))
+	end := min(start+size, len(matches))
+
+	resp := &notionapi.DatabaseQueryResponse{Object: "list", Results: matches[start:end]}
+	if end < len(matches) {
+		resp.HasMore = true
+		resp.NextCursor = notionapi.Cursor(strconv.Itoa(end))
+	}
+	return resp, nil
+}
+
+func (s *demoStore) GetPage(_ context.Context, pageID string) (*notionapi.Page, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	page, ok := s.pages[pageID]
+	if !ok {
+		return nil, demoNotFound("Page", pageID)
+	}
+	result := copyPage(page)
+	return &result, nil
+}
+
+func (s *demoStore) UpdatePage(_ context.Context, pageID string, req *notionapi.PageUpdateRequest) (*notionapi.Page, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	page, ok := s.pages[pageID]
+	if !ok {
+		return nil, demoNotFound("Page", pageID)
+	}
+	// Properties werden ersetzt statt verändert, damit ausgegebene Kopien stabil bleiben
+	props := make(notionapi.Properties, len(page.Properties)+len(req.Properties))
+	for name, prop := range page.Properties {
+		props[name] = prop
+	}
+	for name, prop := range req.Properties {
+		props[name] = readFormProperty(prop)
+	}
+	page.Properties = props
+	page.LastEditedTime = time.Now()
+
+	result := copyPage(page)
+	return &result, nil
+}
+
+func (s *demoStore) CreatePage(_ context.Context, req *notionapi.PageCreateRequest) (*notionapi.Page, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dbID := string(req.Parent.DatabaseID)
+	if _, ok := s.databases[dbID]; !ok {
+		return nil, demoNotFound("Datenbank", dbID)
+	}
+	result := copyPage(s.add(dbID, "", req.Properties))
+	return &result, nil
+}
+
+func (s *demoStore) GetBlockChildren(_ context.Context, blockID string, _ *notionapi.Pagination) (*notionapi.GetChildrenResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.pages[blockID]; !ok {
+		return nil, demoNotFound("Block", blockID)
+	}
+	return &notionapi.GetChildrenResponse{Object: "list", Results: s.blocks[blockID]}, nil
+}
+
+// GetDatabase leitet das Schema aus den Properties der vorhandenen Pages ab
+func (s *demoStore) GetDatabase(_ context.Context, dbID string) (*notionapi.Database, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pageIDs, ok := s.databases[dbID]
+	if !ok {
+		return nil, demoNotFound("Datenbank", dbID)
+	}
+
+	configs := notionapi.PropertyConfigs{}
+	for _, id := range pageIDs {
+		for name, prop := range s.pages[id].Properties {
+			switch prop.(type) {
+			case *notionapi.TitleProperty:
+				configs[name] = &notionapi.TitlePropertyConfig{Type: notionapi.PropertyConfigTypeTitle}
+			case *notionapi.RichTextProperty:
+				configs[name] = &notionapi.RichTextPropertyConfig{Type: notionapi.PropertyConfigTypeRichText}
+			case *notionapi.NumberProperty:
+				configs[name] = &notionapi.NumberPropertyConfig{Type: notionapi.PropertyConfigTypeNumber}
+			case *notionapi.RelationProperty:
+				configs[name] = &notionapi.RelationPropertyConfig{Type: notionapi.PropertyConfigTypeRelation}
+			case *notionapi.DateProperty:
+				configs[name] = &notionapi.DatePropertyConfig{Type: notionapi.PropertyConfigTypeDate}
+			}
+		}
+	}
+	return &notionapi.Database{Object: "database", ID: notionapi.ObjectID(dbID), Properties: configs}, nil
+}
+
+// GetPropertyItems liefert eine Relation-Property seitenweise wie der Property-Endpunkt,
+// mit notionRelationLimit Einträgen pro Seite. Der Cursor ist der Index des nächsten Eintrags.
+func (s *demoStore) GetPropertyItems(_ context.Context, pageID, propertyID, cursor string) (*propertyItemList, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	page, ok := s.pages[pageID]
+	if !ok {
+		return nil, demoNotFound("Page", pageID)
+	}
+	var relations []notionapi.Relation
+	found := false
+	for name, prop := range page.Properties {
+		if relation, ok := prop.(*notionapi.RelationProperty); ok && (string(relation.ID) == propertyID || name == propertyID) {
+			relations, found = relation.Relation, true
+			break
+		}
+	}
+	if !found {
+		return nil, demoNotFound("Property", propertyID)
+	}
+
+	start, _ := strconv.Atoi(cursor)
+	start = min(max(start, 0), len(relations))
+	end := min(start+notionRelationLimit, len(relations))
+	list := &propertyItemList{}
+	for _, relation := range relations[start:end] {
+		list.Results = append(list.Results, propertyItem{Relation: relation})
+	}
+	if end < len(relations) {
+		list.HasMore = true
+		list.NextCursor = strconv.Itoa(end)
+	}
+	return list, nil
+}
+
+// demoNotFound bildet die 404-Antwort der Notion-API nach
+func demoNotFound(kind, id string) error {
+	return &notionapi.Error{
+		Object:  "error",
+		Status:  http.StatusNotFound,
+		Code:    "object_not_found",
+		Message: fmt.Sprintf("%s %s existiert im Demo-Speicher nicht", kind, id),
+	}
+}
+
+// demoValidationError bildet die 400-Antwort der Notion-API auf einen ungültigen Filter nach
+func demoValidationError(message string) error {
+	return &notionapi.Error{
+		Object:  "error",
+		Status:  http.StatusBadRequest,
+		Code:    "validation_error",
+		Message: message,
+	}
+}
+
+// copyPage kopiert eine Page samt Property-Map, damit Aufrufer den Speicher nicht verändern
+func copyPage(page *notionapi.Page) notionapi.Page {
+	result := *page
+	result.Properties = make(notionapi.Properties, len(page.Properties))
+	for name, prop := range page.Properties {
+		result.Properties[name] = prop
+	}
+	return result
+}
+
+// demoText baut Rich-Text so, wie ihn die Notion-API beim Lesen liefert
+func demoText(s string) []notionapi.RichText {
+	return []notionapi.RichText{{Type: "text", Text: &notionapi.Text{Content: s}, PlainText: s}}
+}
+
+// demoMatches wertet einen Query-Filter auf einer Page aus
+func demoMatches(page *notionapi.Page, filter notionapi.Filter) bool {
+	switch f := filter.(type) {
+	case *notionapi.PropertyFilter:
+		return demoMatchesProperty(page, *f)
+	case notionapi.PropertyFilter:
+		return demoMatchesProperty(page, f)
+	case notionapi.OrCompoundFilter:
+		for _, sub := range f {
+			if demoMatches(page, sub) {
+				return true
+			}
+		}
+		return false
+	case notionapi.AndCompoundFilter:
+		for _, sub := range f {
+			if !demoMatches(page, sub) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// validateFilter lehnt Filter wie die Notion-API mit 400 validation_error ab, wenn die
+// Property in der Datenbank fehlt oder ein number-Filter keine Number-Property trifft
+// (z.B. eine Formel oder ein Rollup)
+func (s *demoStore) validateFilter(pageIDs []string, filter notionapi.Filter) error {
+	var f notionapi.PropertyFilter
+	switch ff := filter.(type) {
+	case *notionapi.PropertyFilter:
+		f = *ff
+	case notionapi.PropertyFilter:
+		f = ff
+	case notionapi.OrCompoundFilter:
+		for _, sub := range ff {
+			if err := s.validateFilter(pageIDs, sub); err != nil {
+				return err
+			}
+		}
+		return nil
+	case notionapi.AndCompoundFilter:
+		for _, sub := range ff {
+			if err := s.validateFilter(pageIDs, sub); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return nil
+	}
+
+	for _, id := range pageIDs {
+		prop, ok := s.pages[id].Properties[f.Property]
+		if !ok {
+			continue
+		}
+		if _, isNumber := prop.(*notionapi.NumberProperty); f.Number != nil && !isNumber {
+			return demoValidationError(fmt.Sprintf("The property %q does not match the filter type number.", f.Property))
+		}
+		return nil
+	}
+	return demoValidationError(fmt.Sprintf("Could not find property with name or id: %s", f.Property))
+}
+
+func demoMatchesProperty(page *notionapi.Page, f notionapi.PropertyFilter) bool {
+	prop, ok := page.Properties[f.Property]
+	if !ok {
+		return false
+	}
+
+	if f.Number != nil {
+		p, ok := prop.(*notionapi.NumberProperty)
+		return ok && (f.Number.Equals == nil || p.Number == *f.Number.Equals)
+	}
+
+	// Titel filtert notionapi ebenfalls über rich_text
+	cond := f.RichText
+	if cond == nil {
+		return false
+	}
+
+	var text string
+	switch p := prop.(type) {
+	case *notionapi.TitleProperty:
+		text = richTextPlain(p.Title)
+	case *notionapi.RichTextProperty:
+		text = richTextPlain(p.RichText)
+	default:
+		return false
+	}
+	switch {
+	case cond.Equals != "":
+		return text == cond.Equals
+	case cond.Contains != "":
+		return strings.Contains(strings.ToLower(text), strings.ToLower(cond.Contains))
+	}
+	return true
+}
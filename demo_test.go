@@ -0,0 +1,162 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestDemoFlow(t *testing.T) {
+	tests := []struct {
+		team      string
+		pageID    string
+		wantRoute []string // Slugs der Challenges nach jedem Abschluss, "" für die Zielseite
+	}{
+		{
+			team:      "Demo Team",
+			pageID:    demoTeamPageID,
+			wantRoute: []string{"demo-kirchturm", "demo-stadtpark", "demo-rathaus", ""},
+		},
+		{
+			team:      "Die Füchse",
+			pageID:    foxesTeamPageID,
+			wantRoute: []string{"demo-stadtpark", "demo-kirchturm", "demo-rathaus", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.team, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			// Jede Challenge wird dort abgeschlossen, wohin die vorige weitergeleitet hat
+			current := "1"
+			for step, wantSlug := range tt.wantRoute {
+				w := ta.advance(current, tt.team)
+				if w.Code != http.StatusOK {
+					t.Fatalf("Schritt %d: Status = %d: %s", step+1, w.Code, w.Body.String())
+				}
+				body := w.Body.String()
+				if wantSlug == "" {
+					if !strings.Contains(body, "<title>Scavenger Hunt Completed!</title>") {
+						t.Fatalf("Schritt %d: keine Zielseite:\n%s", step+1, body)
+					}
+					break
+				}
+				if !strings.Contains(body, demoChallengeURL+wantSlug) {
+					t.Fatalf("Schritt %d: keine Weiterleitung nach %s:\n%s", step+1, wantSlug, body)
+				}
+				challenge, err := ta.getChallengeBySlug(t.Context(), wantSlug)
+				if err != nil {
+					t.Fatal(err)
+				}
+				current = strconv.Itoa(challenge.ID)
+			}
+
+			if got := len(ta.completedChallenges(ta.teamPage(t, tt.pageID))); got != 4 {
+				t.Errorf("%d Abschlüsse gespeichert, erwartet 4", got)
+			}
+		})
+	}
+}
+
+func TestDemoFlowUnknownTeam(t *testing.T) {
+	ta := newTestApp(t, nil)
+	w := ta.advance("1", "Unbekannte Crew")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Team nicht gefunden") {
+		t.Errorf("Status = %d, erwartet Fehlerseite:\n%s", w.Code, w.Body.String())
+	}
+}
+
+func TestDemoStore(t *testing.T) {
+	ctx := t.Context()
+	s := newDemoStore()
+	three := 3.0
+
+	seven := 7.0
+	s.mu.Lock()
+	s.add(demoChallengesDBID, "demo-formel", notionapi.Properties{
+		"Name": &notionapi.TitleProperty{Title: demoText("Formel")},
+		"ID":   &notionapi.FormulaProperty{Formula: notionapi.Formula{Type: notionapi.FormulaTypeNumber, Number: 7}},
+	})
+	s.mu.Unlock()
+
+	titleIs := func(name string) *notionapi.DatabaseQueryRequest {
+		return &notionapi.DatabaseQueryRequest{Filter: &notionapi.PropertyFilter{
+			Property: teamTitleProperty,
+			RichText: &notionapi.TextFilterCondition{Equals: name},
+		}}
+	}
+	numberIs := func(property string, num *float64) *notionapi.DatabaseQueryRequest {
+		return &notionapi.DatabaseQueryRequest{Filter: &notionapi.PropertyFilter{
+			Property: property,
+			Number:   &notionapi.NumberFilterCondition{Equals: num},
+		}}
+	}
+
+	tests := []struct {
+		name      string
+		dbID      string
+		req       *notionapi.DatabaseQueryRequest
+		wantCount int
+		wantMore  bool
+		wantErr   int // Status des Notion-Fehlers (0: kein Fehler)
+	}{
+		{name: "alle Challenges", dbID: demoChallengesDBID, wantCount: 5},
+		{name: "Team nach Name", dbID: demoTeamsDBID, req: titleIs("Die Füchse"), wantCount: 1},
+		{name: "unbekanntes Team", dbID: demoTeamsDBID, req: titleIs("Niemand"), wantCount: 0},
+		{name: "Challenge nach Nummer", dbID: demoChallengesDBID, req: numberIs("id", &three), wantCount: 1},
+		{name: "Seitenweise", dbID: demoChallengesDBID, req: &notionapi.DatabaseQueryRequest{PageSize: 3}, wantCount: 3, wantMore: true},
+		{name: "unbekannte Datenbank", dbID: "gibt-es-nicht", wantErr: http.StatusNotFound},
+		// Notion lehnt number-Filter auf Formeln und Filter auf fehlende Properties ab
+		{name: "Nummer aus Formel", dbID: demoChallengesDBID, req: numberIs("ID", &seven), wantErr: http.StatusBadRequest},
+		{name: "unbekannte Property", dbID: demoTeamsDBID, req: numberIs("Nummer", &three), wantErr: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := s.QueryDatabase(ctx, tt.dbID, tt.req)
+			if (err != nil) != (tt.wantErr != 0) {
+				t.Fatalf("QueryDatabase: %v", err)
+			}
+			if tt.wantErr != 0 {
+				var notionErr *notionapi.Error
+				if !errors.As(err, &notionErr) || notionErr.Status != tt.wantErr {
+					t.Errorf("Fehler = %v, erwartet %d wie die Notion-API", err, tt.wantErr)
+				}
+				return
+			}
+			if len(resp.Results) != tt.wantCount || resp.HasMore != tt.wantMore {
+				t.Errorf("%d Treffer (HasMore %v), erwartet %d (%v)", len(resp.Results), resp.HasMore, tt.wantCount, tt.wantMore)
+			}
+		})
+	}
+}
+
+func TestDemoStorePagesAreCopies(t *testing.T) {
+	ctx := t.Context()
+	s := newDemoStore()
+
+	page, err := s.GetPage(ctx, demoTeamPageID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	delete(page.Properties, teamTitleProperty)
+	if page, _ = s.GetPage(ctx, demoTeamPageID); page.Properties[teamTitleProperty] == nil {
+		t.Error("Änderung an der gelesenen Page hat den Speicher verändert")
+	}
+
+	if _, err := s.UpdatePage(ctx, demoTeamPageID, &notionapi.PageUpdateRequest{Properties: notionapi.Properties{
+		teamScoreProperty: notionapi.NumberProperty{Number: 42},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	if page, _ = s.GetPage(ctx, demoTeamPageID); teamScore(page) != 42 {
+		t.Errorf("Score nach UpdatePage = %v, erwartet 42", teamScore(page))
+	}
+
+	if _, err := s.GetPage(ctx, "gibt-es-nicht"); err == nil {
+		t.Error("unbekannte Page ohne Fehler")
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jomei/notionapi"
+)
+
+// diagnoseCheck ist ein einzelner Prüfschritt im Diagnose-Report
+type diagnoseCheck struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+// handleAdminDiagnose prüft Verbindung und Schema der Notion-Datenbanken
+func (app *App) handleAdminDiagnose(c *gin.Context) {
+	checks := app.diagnose(c.Request.Context())
+
+	ok := true
+	for _, check := range checks {
+		ok = ok && check.Passed
+	}
+
+	status := http.StatusOK
+	if !ok {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, gin.H{
+		"ok":     ok,
+		"checks": checks,
+	})
+}
+
+// diagnose führt alle Prüfungen aus. Nachfolgende Prüfungen einer DB entfallen,
+// wenn die DB selbst nicht erreichbar ist.
+func (app *App) diagnose(ctx context.Context) []diagnoseCheck {
+	var checks []diagnoseCheck
+	add := func(name string, passed bool, hint string) {
+		check := diagnoseCheck{Name: name, Passed: passed}
+		if !passed {
+			check.Hint = hint
+		}
+		checks = append(checks, check)
+	}
+
+	// Team-DB
+	teamsDB, err := app.getDatabase(ctx, app.teamsDBID)
+	add("teams_db_reachable", err == nil,
+		fmt.Sprintf("TEAMS_DB_ID prüfen und die Datenbank mit der Integration teilen (%v)", err))
+	if err == nil {
+		add("teams_title_property", hasPropertyType(teamsDB.Properties, notionapi.PropertyConfigTypeTitle),
+			"Die Team-DB braucht eine Titel-Property mit dem Teamnamen")
+		add("teams_route_properties", hasRouteProperties(teamsDB.Properties),
+			"Die Team-DB braucht Relation-Properties Challenge1, Challenge2, ... zur Challenge-DB")
+
+		result, err := app.queryDatabase(ctx, app.teamsDBID, &notionapi.DatabaseQueryRequest{PageSize: 1})
+		add("teams_exist", err == nil && len(result.Results) > 0,
+			"In der Team-DB ist noch kein Team angelegt")
+
+		if pages, err := app.listTeamPages(ctx); err == nil {
+			var ambiguous []string
+			for i := range pages {
+				for _, num := range ambiguousRouteSlots(&pages[i]) {
+					ambiguous = append(ambiguous, fmt.Sprintf("%s: Challenge%d", pageTitle(pages[i]), num))
+				}
+			}
+			add("teams_single_challenge_per_slot", len(ambiguous) == 0,
+				fmt.Sprintf("Jede ChallengeN-Relation darf nur eine Challenge enthalten, es zählt sonst die erste: %s", strings.Join(ambiguous, ", ")))
+		}
+	}
+
+	// Challenge-DBs (bei mehreren DBs tragen die Prüfungen die DB-ID im Namen)
+	allReachable := true
+	for _, dbID := range app.challengeDBIDs {
+		suffix := ""
+		if len(app.challengeDBIDs) > 1 {
+			suffix = "[" + dbID + "]"
+		}
+
+		challengeDB, err := app.getDatabase(ctx, dbID)
+		add("challenges_db_reachable"+suffix, err == nil,
+			fmt.Sprintf("CHALLENGES_DB_ID prüfen und die Datenbank mit der Integration teilen (%v)", err))
+		if err != nil {
+			allReachable = false
+			continue
+		}
+
+		hasID := false
+		for _, name := range []string{"id", "ID"} {
+			if prop, ok := challengeDB.Properties[name]; ok && prop.GetType() == notionapi.PropertyConfigTypeNumber {
+				hasID = true
+			}
+		}
+		add("challenges_id_property"+suffix, hasID,
+			"Die Challenge-DB braucht eine Number-Property \"id\" (oder \"ID\")")
+
+		result, err := app.queryDatabase(ctx, dbID, &notionapi.DatabaseQueryRequest{PageSize: 1})
+		add("challenges_exist"+suffix, err == nil && len(result.Results) > 0,
+			"In der Challenge-DB ist noch keine Challenge angelegt")
+	}
+
+	if allReachable {
+		if challenges, err := app.getAllChallenges(ctx); err == nil {
+			var missing []string
+			for _, challenge := range challenges {
+				if name := redirectTemplateName(challenge); app.templates.Lookup(name) == nil {
+					missing = append(missing, fmt.Sprintf("%d: %s", challenge.ID, name))
+				}
+			}
+			add("challenges_redirect_templates", len(missing) == 0,
+				fmt.Sprintf("Unbekannte RedirectTemplate-Werte (Fallback auf %s): %s", defaultRedirectTemplate, strings.Join(missing, ", ")))
+
+			cycle := findPrerequisiteCycle(challenges)
+			add("challenges_prerequisite_cycles", cycle == nil,
+				fmt.Sprintf("Prerequisites bzw. NextRules so ändern, dass sich Challenges nicht gegenseitig voraussetzen oder im Kreis verzweigen (%v)", cycle))
+		}
+	}
+
+	return checks
+}
+
+// hasPropertyType prüft, ob das Schema eine Property des Typs enthält
+func hasPropertyType(props notionapi.PropertyConfigs, typ notionapi.PropertyConfigType) bool {
+	for _, prop := range props {
+		if prop.GetType() == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRouteProperties prüft, ob das Team-Schema mindestens eine ChallengeN-Relation hat
+func hasRouteProperties(props notionapi.PropertyConfigs) bool {
+	for name, prop := range props {
+		if strings.HasPrefix(name, "Challenge") && prop.GetType() == notionapi.PropertyConfigTypeRelation {
+			return true
+		}
+	}
+	return false
+}
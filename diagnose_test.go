@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestAdminDiagnose(t *testing.T) {
+	// withoutProperty entfernt eine Property aus dem Schema einer Datenbank
+	withoutProperty := func(dbID, name string) func(*stubNotion) {
+		return func(s *stubNotion) {
+			s.database = func(ctx context.Context, id string) (*notionapi.Database, error) {
+				db, err := s.notionService.GetDatabase(ctx, id)
+				if err == nil && id == dbID {
+					delete(db.Properties, name)
+				}
+				return db, err
+			}
+		}
+	}
+
+	tests := []struct {
+		name       string
+		stub       func(*stubNotion)
+		wantStatus int
+		wantFailed []string
+		wantAbsent []string // Prüfungen, die gar nicht erst laufen
+	}{
+		{name: "alles eingerichtet", wantStatus: http.StatusOK},
+		{
+			name:       "Team-DB ohne Titel",
+			stub:       withoutProperty(demoTeamsDBID, teamTitleProperty),
+			wantStatus: http.StatusServiceUnavailable,
+			wantFailed: []string{"teams_title_property"},
+		},
+		{
+			name:       "Challenge-DB ohne id",
+			stub:       withoutProperty(demoChallengesDBID, "id"),
+			wantStatus: http.StatusServiceUnavailable,
+			wantFailed: []string{"challenges_id_property"},
+		},
+		{
+			name: "Team-DB nicht geteilt",
+			stub: func(s *stubNotion) {
+				s.database = func(ctx context.Context, id string) (*notionapi.Database, error) {
+					if id == demoTeamsDBID {
+						return nil, notionError(http.StatusNotFound, "object_not_found", "nicht geteilt")
+					}
+					return s.notionService.GetDatabase(ctx, id)
+				}
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantFailed: []string{"teams_db_reachable"},
+			wantAbsent: []string{"teams_title_property", "teams_exist"},
+		},
+		{
+			name: "keine Teams angelegt",
+			stub: func(s *stubNotion) {
+				s.query = func(ctx context.Context, id string, req *notionapi.DatabaseQueryRequest) (*notionapi.DatabaseQueryResponse, error) {
+					if id == demoTeamsDBID {
+						return &notionapi.DatabaseQueryResponse{Object: "list"}, nil
+					}
+					return s.notionService.QueryDatabase(ctx, id, req)
+				}
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantFailed: []string{"teams_exist"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			stub := &stubNotion{notionService: ta.store}
+			if tt.stub != nil {
+				tt.stub(stub)
+			}
+			ta.notion = stub
+
+			w := ta.admin(http.MethodGet, "/admin/diagnose", "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, erwartet %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			checks := make(map[string]map[string]any)
+			for _, check := range decodeJSON(t, w)["checks"].([]any) {
+				check := check.(map[string]any)
+				checks[check["name"].(string)] = check
+			}
+			failed := make(map[string]bool)
+			for _, name := range tt.wantFailed {
+				failed[name] = true
+			}
+			for name, check := range checks {
+				if check["passed"] == failed[name] {
+					t.Errorf("%s: passed = %v", name, check["passed"])
+				}
+				if failed[name] && check["hint"] == nil {
+					t.Errorf("%s: Hinweis fehlt", name)
+				}
+			}
+			for _, name := range tt.wantFailed {
+				if checks[name] == nil {
+					t.Errorf("Prüfung %s fehlt", name)
+				}
+			}
+			for _, name := range tt.wantAbsent {
+				if checks[name] != nil {
+					t.Errorf("Prüfung %s lief trotz unerreichbarer DB", name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jomei/notionapi"
+)
+
+// challengeMinDwellProperty ist die optionale Mindestaufenthaltsdauer (Number, Sekunden)
+// einer Challenge. Erst danach kann ein Team von dieser Station weiter.
+const challengeMinDwellProperty = "MinDwellSeconds"
+
+// arrivalTTL begrenzt, wie lange Ankunftszeiten im Speicher bleiben (länger als jedes Event)
+const arrivalTTL = 12 * time.Hour
+
+// readMinDwell liest MinDwellSeconds einer Challenge-Page
+func readMinDwell(challenge *Challenge, page notionapi.Page) {
+	if p, ok := page.Properties[challengeMinDwellProperty].(*notionapi.NumberProperty); ok && p.Number > 0 {
+		challenge.MinDwell = time.Duration(p.Number * float64(time.Second))
+	}
+}
+
+// dwellRemaining liefert die verbleibende Wartezeit an einer Station (0: darf weiter)
+func dwellRemaining(arrived time.Time, minDwell time.Duration, now time.Time) time.Duration {
+	return max(arrived.Add(minDwell).Sub(now), 0)
+}
+
+// arrivals merkt sich pro Team und Challenge, wann das Team dorthin weitergeleitet wurde.
+// Die Zeiten liegen nur im Speicher: Nach einem Neustart gibt es keine Wartezeit.
+type arrivals struct {
+	mu      sync.Mutex
+	entries map[string]time.Time
+}
+
+func newArrivals() *arrivals {
+	return &arrivals{entries: make(map[string]time.Time)}
+}
+
+// record hält die Ankunft eines Teams an einer Challenge fest und räumt alte Einträge ab
+func (a *arrivals) record(teamPageID, challengeID string, at time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for key, arrived := range a.entries {
+		if at.Sub(arrived) > arrivalTTL {
+			delete(a.entries, key)
+		}
+	}
+	a.entries[answerAttemptKey(teamPageID, challengeID)] = at
+}
+
+// get liefert die Ankunftszeit, sofern bekannt
+func (a *arrivals) get(teamPageID, challengeID string) (time.Time, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	arrived, ok := a.entries[answerAttemptKey(teamPageID, challengeID)]
+	return arrived, ok
+}
+
+// checkDwell prüft die Mindestaufenthaltsdauer der aktuellen Challenge. Ist sie noch
+// nicht erreicht, wird die "Noch nicht"-Seite gerendert und false geliefert.
+// Ohne bekannte Ankunft (erste Challenge, Neustart) darf das Team weiter.
+func (app *App) checkDwell(c *gin.Context, teamPageID, teamName, challengeID, lang string) bool {
+	challenge, err := app.getChallenge(c.Request.Context(), challengeID)
+	if err != nil || challenge.MinDwell == 0 {
+		return true
+	}
+	arrived, ok := app.arrivals.get(teamPageID, challengeID)
+	if !ok {
+		return true
+	}
+
+	remaining := dwellRemaining(arrived, challenge.MinDwell, time.Now())
+	if remaining == 0 {
+		return true
+	}
+
+	infof("Team %s will Challenge %s zu früh verlassen, noch %v", teamName, challengeID, remaining.Round(time.Second))
+	app.logEvent(teamName, challengeID, "", outcomeTooEarly, false)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusTooEarly)
+	if err := app.templates.ExecuteTemplate(c.Writer, "notyet.html", gin.H{
+		"challengeID": challengeID,
+		"team":        teamName,
+		"answer":      c.PostForm("answer"),
+		"seconds":     int(remaining.Round(time.Second) / time.Second),
+		"lang":        lang,
+	}); err != nil {
+		app.templateFailed(c, err)
+	}
+	return false
+}
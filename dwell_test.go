@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestDwellRemaining(t *testing.T) {
+	arrived := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		minDwell time.Duration
+		now      time.Time
+		want     time.Duration
+	}{
+		{minDwell: time.Minute, now: arrived, want: time.Minute},
+		{minDwell: time.Minute, now: arrived.Add(45 * time.Second), want: 15 * time.Second},
+		{minDwell: time.Minute, now: arrived.Add(time.Minute)},
+		{minDwell: time.Minute, now: arrived.Add(time.Hour)},
+		{minDwell: 0, now: arrived},
+	}
+	for _, tt := range tests {
+		if got := dwellRemaining(arrived, tt.minDwell, tt.now); got != tt.want {
+			t.Errorf("dwellRemaining(%v, %v) = %v, erwartet %v", tt.minDwell, tt.now.Sub(arrived), got, tt.want)
+		}
+	}
+}
+
+func TestArrivals(t *testing.T) {
+	a := newArrivals()
+	start := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	if _, ok := a.get("team-1", "2"); ok {
+		t.Fatal("Ankunft ohne record")
+	}
+	a.record("team-1", "2", start)
+	if got, ok := a.get("TEAM1", "2"); !ok || !got.Equal(start) {
+		t.Errorf("get (Page-ID ohne Bindestriche) = %v, %v", got, ok)
+	}
+	if _, ok := a.get("team-1", "3"); ok {
+		t.Error("Ankunft an anderer Challenge")
+	}
+
+	// Eine spätere Ankunft räumt Einträge älter als arrivalTTL ab
+	a.record("team-2", "2", start.Add(arrivalTTL+time.Minute))
+	if _, ok := a.get("team-1", "2"); ok {
+		t.Error("abgelaufene Ankunft nicht entfernt")
+	}
+}
+
+func TestMinDwell(t *testing.T) {
+	tests := []struct {
+		name        string
+		arrivedAgo  time.Duration // Ankunft an Challenge 2 (0: keine bekannt)
+		viaRedirect bool          // Ankunft durch Weiterleitung von Challenge 1
+		wantStatus  int
+		wantBody    string
+	}{
+		{name: "direkt nach der Weiterleitung", viaRedirect: true, wantStatus: http.StatusTooEarly, wantBody: `<div class="remaining" id="remaining">60 s</div>`},
+		{name: "zu früh", arrivedAgo: 45 * time.Second, wantStatus: http.StatusTooEarly, wantBody: `<div class="remaining" id="remaining">15 s</div>`},
+		{name: "rechtzeitig", arrivedAgo: 2 * time.Minute, wantStatus: http.StatusOK, wantBody: demoChallengeURL + "demo-stadtpark"},
+		{name: "Ankunft unbekannt", wantStatus: http.StatusOK, wantBody: demoChallengeURL + "demo-stadtpark"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			var events bytes.Buffer
+			ta.events = newJSONEventLogger(&events)
+			_, err := ta.store.UpdatePage(t.Context(), "demo-kirchturm", &notionapi.PageUpdateRequest{
+				Properties: notionapi.Properties{challengeMinDwellProperty: notionapi.NumberProperty{Number: 60}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.viaRedirect {
+				ta.advance("1", "Demo Team")
+			}
+			if tt.arrivedAgo > 0 {
+				ta.arrivals.record(demoTeamPageID, "2", time.Now().Add(-tt.arrivedAgo))
+			}
+
+			w := ta.do(http.MethodPost, "/next/2", url.Values{"team": {"Demo Team"}, "answer": {"Turm"}})
+			body := w.Body.String()
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, erwartet %d:\n%s", w.Code, tt.wantStatus, body)
+			}
+			if !strings.Contains(body, tt.wantBody) {
+				t.Errorf("%q fehlt:\n%s", tt.wantBody, body)
+			}
+
+			logged := readEvents(t, events.Bytes())
+			tooEarly := logged[len(logged)-1].Outcome == outcomeTooEarly
+			if tooEarly != (tt.wantStatus == http.StatusTooEarly) {
+				t.Errorf("letztes Event = %+v", logged[len(logged)-1])
+			}
+			if !tooEarly {
+				return
+			}
+			for _, want := range []string{
+				`<input type="hidden" name="team" value="Demo Team">`,
+				`<input type="hidden" name="answer" value="Turm">`,
+			} {
+				if !strings.Contains(body, want) {
+					t.Errorf("%q fehlt:\n%s", want, body)
+				}
+			}
+			if _, done := ta.completedChallenges(ta.teamPage(t, demoTeamPageID))[2]; done {
+				t.Error("Challenge 2 trotz Wartezeit abgeschlossen")
+			}
+		})
+	}
+}
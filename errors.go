@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// userError liefert die Fehlermeldung für Besucher. Mit PRODUCTION=true bleibt es
+// bei der allgemeinen Meldung, sonst werden die Details aus err angehängt.
+// Die Details gehören in jedem Fall ins Log (errorf beim Aufrufer).
+func (app *App) userError(msg string, err error) string {
+	if app.production || err == nil {
+		return msg
+	}
+	return fmt.Sprintf("%s: %v", msg, err)
+}
+
+// templateFailed meldet einen fehlgeschlagenen Template-Aufruf
+func (app *App) templateFailed(c *gin.Context, err error) {
+	errorf("Template-Fehler: %v", err)
+	c.String(http.StatusInternalServerError, "%s", app.userError("Template-Fehler", err))
+}
+
+// isJSONPath erkennt Pfade, deren Clients JSON erwarten (/api und /admin)
+func isJSONPath(path string) bool {
+	for _, prefix := range []string{"/api", "/admin"} {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// handleNotFound beantwortet unbekannte Pfade: unter /api und /admin mit {"error": ...},
+// sonst mit der gestalteten Fehlerseite statt Gins Klartext-404
+func (app *App) handleNotFound(c *gin.Context) {
+	if isJSONPath(c.Request.URL.Path) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unbekannter Pfad: " + c.Request.URL.Path})
+		return
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusNotFound)
+	if err := app.templates.ExecuteTemplate(c.Writer, "error.html", gin.H{
+		"error": "Seite nicht gefunden",
+	}); err != nil {
+		app.templateFailed(c, err)
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestUserError(t *testing.T) {
+	tests := []struct {
+		production bool
+		err        error
+		want       string
+	}{
+		{production: false, err: errors.New("token abgelaufen"), want: "Fehler beim Laden: token abgelaufen"},
+		{production: true, err: errors.New("token abgelaufen"), want: "Fehler beim Laden"},
+		{production: false, err: nil, want: "Fehler beim Laden"},
+	}
+	for _, tt := range tests {
+		app := &App{production: tt.production}
+		if got := app.userError("Fehler beim Laden", tt.err); got != tt.want {
+			t.Errorf("userError(production=%v, %v) = %q, erwartet %q", tt.production, tt.err, got, tt.want)
+		}
+	}
+}
+
+// internalDetail steht in jedem Notion-Fehler der folgenden Tests und darf in Produktion nie beim Besucher ankommen
+const internalDetail = "integration ntn_geheim has no access"
+
+// brokenTeamPageNotion lässt das Laden von Team-Pages scheitern, Abfragen funktionieren
+type brokenTeamPageNotion struct {
+	notionService
+}
+
+func (n *brokenTeamPageNotion) GetPage(ctx context.Context, pageID string) (*notionapi.Page, error) {
+	if pageID == demoTeamPageID {
+		return nil, notionError(http.StatusForbidden, "restricted_resource", internalDetail)
+	}
+	return n.notionService.GetPage(ctx, pageID)
+}
+
+func TestProductionErrorDetails(t *testing.T) {
+	failingQueries := func(ta *testApp) {
+		ta.notion = &stubNotion{notionService: ta.store, query: func(context.Context, string, *notionapi.DatabaseQueryRequest) (*notionapi.DatabaseQueryResponse, error) {
+			return nil, notionError(http.StatusForbidden, "restricted_resource", internalDetail)
+		}}
+	}
+	tests := []struct {
+		name       string
+		setup      func(ta *testApp)
+		request    func(ta *testApp) *httptest.ResponseRecorder
+		wantText   string
+		wantStatus int // die Weiterleitung zeigt error.html mit Status 200
+	}{
+		{
+			name:       "Leaderboard",
+			setup:      failingQueries,
+			request:    func(ta *testApp) *httptest.ResponseRecorder { return ta.do(http.MethodGet, "/leaderboard", nil) },
+			wantText:   "Fehler beim Laden des Leaderboards",
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "Teamformular",
+			setup:      failingQueries,
+			request:    func(ta *testApp) *httptest.ResponseRecorder { return ta.do(http.MethodGet, "/next/1", nil) },
+			wantText:   "Fehler beim Laden der Teamliste",
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "Weiterleitung",
+			setup:      func(ta *testApp) { ta.notion = &brokenTeamPageNotion{notionService: ta.store} },
+			request:    func(ta *testApp) *httptest.ResponseRecorder { return ta.advance("1", "Demo Team") },
+			wantText:   "Fehler beim Abrufen der Team-Daten",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Admin-API",
+			setup:      failingQueries,
+			request:    func(ta *testApp) *httptest.ResponseRecorder { return ta.admin(http.MethodGet, "/admin/positions", "") },
+			wantText:   "Fehler beim Ermitteln der Team-Positionen",
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		for _, production := range []bool{false, true} {
+			t.Run(tt.name+"/PRODUCTION="+strconv.FormatBool(production), func(t *testing.T) {
+				ta := newTestApp(t, map[string]string{"PRODUCTION": strconv.FormatBool(production)})
+				tt.setup(ta)
+
+				w := tt.request(ta)
+				body := w.Body.String()
+				if w.Code != tt.wantStatus {
+					t.Errorf("Status = %d, erwartet %d", w.Code, tt.wantStatus)
+				}
+				if !strings.Contains(body, tt.wantText) {
+					t.Errorf("%q fehlt:\n%s", tt.wantText, body)
+				}
+				if got := strings.Contains(body, internalDetail); got == production {
+					t.Errorf("Details sichtbar = %v bei PRODUCTION=%v:\n%s", got, production, body)
+				}
+			})
+		}
+	}
+}
+
+func TestIsJSONPath(t *testing.T) {
+	tests := map[string]bool{
+		"/api":                true,
+		"/api/":               true,
+		"/api/teams/x/badges": true,
+		"/admin":              true,
+		"/admin/gibtsnicht":   true,
+		"/apidoc":             false,
+		"/administration":     false,
+		"/next/1":             false,
+		"/":                   false,
+	}
+	for path, want := range tests {
+		if got := isJSONPath(path); got != want {
+			t.Errorf("isJSONPath(%q) = %v, erwartet %v", path, got, want)
+		}
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	tests := []struct {
+		method   string
+		path     string
+		wantJSON bool
+	}{
+		{method: http.MethodGet, path: "/api/gibtsnicht", wantJSON: true},
+		{method: http.MethodGet, path: "/api", wantJSON: true},
+		{method: http.MethodPost, path: "/api/updates", wantJSON: true},
+		{method: http.MethodGet, path: "/admin/gibtsnicht", wantJSON: true},
+		{method: http.MethodGet, path: "/gibtsnicht"},
+		{method: http.MethodGet, path: "/apidoc"},
+		{method: http.MethodGet, path: "/next/1/weiter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			w := ta.do(tt.method, tt.path, nil)
+			if w.Code != http.StatusNotFound {
+				t.Fatalf("Status = %d, erwartet %d: %s", w.Code, http.StatusNotFound, w.Body.String())
+			}
+
+			contentType := w.Header().Get("Content-Type")
+			if tt.wantJSON {
+				if !strings.HasPrefix(contentType, "application/json") {
+					t.Errorf("Content-Type = %q", contentType)
+				}
+				if body := decodeJSON(t, w); body["error"] != "Unbekannter Pfad: "+tt.path {
+					t.Errorf("error = %v", body["error"])
+				}
+				return
+			}
+			if !strings.HasPrefix(contentType, "text/html") || !strings.Contains(w.Body.String(), "Seite nicht gefunden") {
+				t.Errorf("keine Fehlerseite (Content-Type %q):\n%s", contentType, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// minETACompletions ist die Mindestzahl an Abschlüssen für eine Schätzung:
+// Erst ab zwei Zeitstempeln gibt es einen Abstand, aus dem sich ein Tempo ableiten lässt.
+const minETACompletions = 2
+
+// teamETA ist die geschätzte Zielzeit eines Teams. Ohne ausreichende Daten
+// bleiben Tempo und Zielzeit null.
+type teamETA struct {
+	Team            string     `json:"team"`
+	Completed       int        `json:"completed"`
+	Remaining       int        `json:"remaining"`
+	Finished        bool       `json:"finished"`
+	PaceSeconds     *float64   `json:"paceSeconds"`
+	EstimatedFinish *time.Time `json:"estimatedFinish"`
+}
+
+// handleAPITeamETA schätzt, wann ein Team bei gleichbleibendem Tempo fertig ist
+func (app *App) handleAPITeamETA(c *gin.Context) {
+	ctx := c.Request.Context()
+	teamName := c.Param("team")
+
+	teamPageID, err := app.findTeamPage(ctx, teamName)
+	if err != nil || teamPageID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team nicht gefunden"})
+		return
+	}
+
+	eta, err := app.getTeamETA(ctx, teamPageID)
+	if err != nil {
+		errorf("Fehler beim Schätzen der Zielzeit: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fehler beim Abrufen der Team-Daten"})
+		return
+	}
+	eta.Team = teamName
+
+	c.JSON(http.StatusOK, eta)
+}
+
+// getTeamETA zählt abgeschlossene und offene Challenges der Route und schätzt daraus die Zielzeit
+func (app *App) getTeamETA(ctx context.Context, teamPageID string) (*teamETA, error) {
+	page, err := app.getPage(ctx, teamPageID)
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Laden der Team-Page: %w", err)
+	}
+
+	challenges, err := app.getAllChallenges(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byPageID := make(map[string]*Challenge, len(challenges))
+	for _, challenge := range challenges {
+		byPageID[normalizePageID(challenge.PageID)] = challenge
+	}
+
+	completed := app.completedChallenges(page)
+	remaining := 0
+	for _, challenge := range routeChallenges(*page, byPageID) {
+		if _, done := completed[challenge.ID]; !done {
+			remaining++
+		}
+	}
+	// Im Modus "count" endet die Jagd schon nach FINISH_COUNT Abschlüssen
+	if app.finishMode == finishModeCount {
+		remaining = min(remaining, max(app.finishCount-len(completed), 0))
+	}
+
+	times := make([]time.Time, 0, len(completed))
+	for _, at := range completed {
+		times = append(times, at)
+	}
+
+	eta := &teamETA{Completed: len(completed), Remaining: remaining, Finished: remaining == 0}
+	if pace, finish, ok := estimateFinish(times, remaining); ok {
+		seconds := pace.Seconds()
+		eta.PaceSeconds = &seconds
+		eta.EstimatedFinish = &finish
+	}
+	return eta, nil
+}
+
+// estimateFinish berechnet den durchschnittlichen Abstand zwischen den Abschlüssen und
+// schreibt ihn ab dem letzten Abschluss für die offenen Challenges fort
+func estimateFinish(times []time.Time, remaining int) (time.Duration, time.Time, bool) {
+	if len(times) < minETACompletions || remaining == 0 {
+		return 0, time.Time{}, false
+	}
+
+	sorted := append([]time.Time(nil), times...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+	first, last := sorted[0], sorted[len(sorted)-1]
+
+	pace := last.Sub(first) / time.Duration(len(sorted)-1)
+	return pace, last.Add(pace * time.Duration(remaining)), true
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestEstimateFinish(t *testing.T) {
+	base := time.Date(2026, 6, 14, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		times      []time.Duration // Abschlüsse relativ zu base
+		remaining  int
+		wantPace   time.Duration
+		wantFinish time.Duration
+		wantOK     bool
+	}{
+		{name: "keine Abschlüsse", remaining: 4},
+		{name: "ein Abschluss", times: []time.Duration{0}, remaining: 3},
+		{name: "alles erledigt", times: []time.Duration{0, 10 * time.Minute}, remaining: 0},
+		{
+			name:       "zwei Abschlüsse",
+			times:      []time.Duration{0, 10 * time.Minute},
+			remaining:  2,
+			wantPace:   10 * time.Minute,
+			wantFinish: 30 * time.Minute,
+			wantOK:     true,
+		},
+		{
+			name:       "unsortiert mit ungleichem Tempo",
+			times:      []time.Duration{30 * time.Minute, 0, 10 * time.Minute},
+			remaining:  1,
+			wantPace:   15 * time.Minute,
+			wantFinish: 45 * time.Minute,
+			wantOK:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var times []time.Time
+			for _, offset := range tt.times {
+				times = append(times, base.Add(offset))
+			}
+			pace, finish, ok := estimateFinish(times, tt.remaining)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, erwartet %v", ok, tt.wantOK)
+			}
+			if ok && (pace != tt.wantPace || !finish.Equal(base.Add(tt.wantFinish))) {
+				t.Errorf("estimateFinish = %v, %v, erwartet %v, %v", pace, finish, tt.wantPace, base.Add(tt.wantFinish))
+			}
+		})
+	}
+}
+
+func TestAPITeamETA(t *testing.T) {
+	base := time.Date(2026, 6, 14, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		env           map[string]string
+		completions   map[int]time.Duration
+		wantRemaining float64
+		wantFinished  bool
+		wantPace      any // Sekunden oder nil
+		wantFinish    any // RFC3339 oder nil
+	}{
+		{name: "noch nichts abgeschlossen", wantRemaining: 4},
+		{name: "zu wenig Daten", completions: map[int]time.Duration{1: 0}, wantRemaining: 3},
+		{
+			name:          "genug Daten",
+			completions:   map[int]time.Duration{1: 0, 2: 20 * time.Minute},
+			wantRemaining: 2,
+			wantPace:      float64(1200),
+			wantFinish:    "2026-06-14T11:00:00Z",
+		},
+		{
+			name:          "FINISH_COUNT verkürzt die Route",
+			env:           map[string]string{"FINISH_MODE": "count", "FINISH_COUNT": "3"},
+			completions:   map[int]time.Duration{1: 0, 2: 20 * time.Minute},
+			wantRemaining: 1,
+			wantPace:      float64(1200),
+			wantFinish:    "2026-06-14T10:40:00Z",
+		},
+		{
+			name:         "fertig",
+			completions:  map[int]time.Duration{1: 0, 2: time.Minute, 3: 2 * time.Minute, 4: 3 * time.Minute},
+			wantFinished: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, tt.env)
+			props := notionapi.Properties{}
+			for num, offset := range tt.completions {
+				at := notionapi.Date(base.Add(offset))
+				props[ta.completionProperty(num)] = notionapi.DateProperty{Date: &notionapi.DateObject{Start: &at}}
+			}
+			if _, err := ta.store.UpdatePage(t.Context(), demoTeamPageID, &notionapi.PageUpdateRequest{Properties: props}); err != nil {
+				t.Fatal(err)
+			}
+
+			w := ta.do(http.MethodGet, "/api/teams/Demo%20Team/eta", nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+			body := decodeJSON(t, w)
+			if body["remaining"] != tt.wantRemaining || body["finished"] != tt.wantFinished {
+				t.Errorf("remaining = %v, finished = %v", body["remaining"], body["finished"])
+			}
+			if body["completed"] != float64(len(tt.completions)) {
+				t.Errorf("completed = %v, erwartet %d", body["completed"], len(tt.completions))
+			}
+			if body["paceSeconds"] != tt.wantPace || body["estimatedFinish"] != tt.wantFinish {
+				t.Errorf("paceSeconds = %v, estimatedFinish = %v, erwartet %v, %v", body["paceSeconds"], body["estimatedFinish"], tt.wantPace, tt.wantFinish)
+			}
+		})
+	}
+}
+
+func TestAPITeamETAUnknownTeam(t *testing.T) {
+	ta := newTestApp(t, nil)
+	if w := ta.do(http.MethodGet, "/api/teams/Niemand/eta", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Status = %d, erwartet 404", w.Code)
+	}
+}
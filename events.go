@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Ergebnisse einer Einreichung im Event-Log
+const (
+	outcomeAdvanced     = "advanced"
+	outcomeFinished     = "finished"
+	outcomeBlocked      = "blocked"
+	outcomeTeamNotFound = "team_not_found"
+	outcomeRejected     = "rejected"
+	outcomeAdminGoto    = "admin_goto"
+	outcomeInactive     = "inactive"
+	outcomeNoRoute      = "no_route"
+	outcomeWrongAnswer  = "wrong_answer"
+	outcomeLockedOut    = "locked_out"
+	outcomeTooEarly     = "too_early"
+)
+
+// advancementEvent ist ein Eintrag im Event-Log (eine JSON-Zeile pro Einreichung)
+type advancementEvent struct {
+	Time    time.Time `json:"time"`
+	Team    string    `json:"team"`
+	From    string    `json:"from"`
+	To      string    `json:"to,omitempty"`
+	Outcome string    `json:"outcome"`
+	// AdminAssisted markiert Einreichungen über ein Admin-Bypass-Token
+	AdminAssisted bool `json:"adminAssisted,omitempty"`
+}
+
+// EventLogger schreibt Einreichungen in ein Append-only-Log, getrennt vom normalen Logging
+type EventLogger interface {
+	Log(event advancementEvent) error
+}
+
+// jsonEventLogger schreibt Events als JSON Lines in einen Writer (Datei, stdout, Buffer)
+type jsonEventLogger struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func newJSONEventLogger(w io.Writer) *jsonEventLogger {
+	return &jsonEventLogger{enc: json.NewEncoder(w)}
+}
+
+func (l *jsonEventLogger) Log(event advancementEvent) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.enc.Encode(event)
+}
+
+// nopEventLogger verwirft alle Events (EVENT_LOG nicht gesetzt)
+type nopEventLogger struct{}
+
+func (nopEventLogger) Log(advancementEvent) error { return nil }
+
+// newEventLogger liest EVENT_LOG: leer (aus), "stdout" oder ein Dateipfad, an den angehängt wird
+func newEventLogger(target string) (EventLogger, error) {
+	switch target {
+	case "":
+		return nopEventLogger{}, nil
+	case "stdout":
+		return newJSONEventLogger(os.Stdout), nil
+	}
+
+	f, err := os.OpenFile(target, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("EVENT_LOG %q kann nicht geöffnet werden: %w", target, err)
+	}
+	return newJSONEventLogger(f), nil
+}
+
+// logEvent schreibt ein Event; Fehler beim Schreiben werden nur geloggt
+func (app *App) logEvent(team, from, to, outcome string, adminAssisted bool) {
+	err := app.events.Log(advancementEvent{
+		Time:          time.Now().UTC(),
+		Team:          team,
+		From:          from,
+		To:            to,
+		Outcome:       outcome,
+		AdminAssisted: adminAssisted,
+	})
+	if err != nil {
+		errorf("Fehler beim Schreiben des Event-Logs: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readEvents liest die JSON-Zeilen eines Event-Logs
+func readEvents(t *testing.T, data []byte) []advancementEvent {
+	t.Helper()
+	var events []advancementEvent
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if line == "" {
+			continue
+		}
+		var event advancementEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("Zeile %q: %v", line, err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestEventLogFlow(t *testing.T) {
+	ta := newTestApp(t, map[string]string{"HONEYPOT": "true"})
+	var buf bytes.Buffer
+	ta.events = newJSONEventLogger(&buf)
+
+	ta.advance("1", "Niemand")
+	ta.do(http.MethodPost, "/next/1", url.Values{"team": {"Demo Team"}, honeypotField: {"spam"}})
+	for _, id := range []string{"1", "2", "3", "4"} {
+		ta.advance(id, "Demo Team")
+	}
+
+	want := []advancementEvent{
+		{Team: "Niemand", From: "1", Outcome: outcomeTeamNotFound},
+		{Team: "Demo Team", From: "1", Outcome: outcomeRejected},
+		{Team: "Demo Team", From: "1", To: "2", Outcome: outcomeAdvanced},
+		{Team: "Demo Team", From: "2", To: "3", Outcome: outcomeAdvanced},
+		{Team: "Demo Team", From: "3", To: "4", Outcome: outcomeAdvanced},
+		{Team: "Demo Team", From: "4", Outcome: outcomeFinished},
+	}
+	got := readEvents(t, buf.Bytes())
+	if len(got) != len(want) {
+		t.Fatalf("%d Events, erwartet %d: %+v", len(got), len(want), got)
+	}
+	for i, event := range got {
+		if event.Time.IsZero() {
+			t.Errorf("Event %d ohne Zeitstempel", i)
+		}
+		event.Time = want[i].Time
+		if event != want[i] {
+			t.Errorf("Event %d = %+v, erwartet %+v", i, event, want[i])
+		}
+	}
+}
+
+func TestNewEventLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	if err := os.WriteFile(path, []byte(`{"team":"alt","from":"1","outcome":"advanced"}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	logger, err := newEventLogger(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.Log(advancementEvent{Team: "neu", From: "2", Outcome: outcomeFinished}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Die Datei wird fortgeschrieben, nicht überschrieben
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := readEvents(t, data)
+	if len(events) != 2 || events[0].Team != "alt" || events[1].Team != "neu" {
+		t.Errorf("Events = %+v", events)
+	}
+
+	if _, err := newEventLogger(filepath.Join(t.TempDir(), "fehlt", "events.jsonl")); err == nil {
+		t.Error("kein Fehler für ein nicht anlegbares EVENT_LOG")
+	}
+}
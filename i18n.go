@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jomei/notionapi"
+)
+
+// teamLanguageProperty enthält die bevorzugte Sprache eines Teams (Select oder Rich-Text, z.B. "de")
+const teamLanguageProperty = "Language"
+
+// defaultLanguage gilt, wenn weder DEFAULT_LANGUAGE noch Team oder Browser eine Sprache liefern
+const defaultLanguage = "en"
+
+// translations enthält die Texte der Seiten, die nach dem Auflösen des Teams gerendert werden.
+// Fehlt ein Text in einer Sprache, wird Englisch genutzt.
+var translations = map[string]map[string]string{
+	"en": {
+		"redirect.title":     "Redirecting...",
+		"redirect.heading":   "✨ Next Challenge Found!",
+		"redirect.manual":    "If the redirect does not work:",
+		"redirect.link":      "Click here",
+		"finished.title":     "Scavenger Hunt Completed!",
+		"finished.heading":   "Congratulations!",
+		"finished.mastered":  "You successfully mastered all challenges!",
+		"finished.completed": "The treasure hunt is completed.",
+		"finished.stat":      "challenges completed",
+		"finished.points":    "points",
+		"finished.back":      "Back to Start",
+		"finished.redirect":  "Continuing automatically in",
+		"finished.continue":  "Continue",
+		"confirm.title":      "Challenge %s - Confirm",
+		"confirm.heading":    "You're about to complete Challenge %s",
+		"confirm.proceed":    "Yes, proceed →",
+		"confirm.cancel":     "Cancel",
+		"noroute.title":      "No challenges",
+		"noroute.heading":    "No challenges yet",
+		"noroute.message":    "Your team has no challenges configured. Please see an organizer.",
+		"noroute.back":       "Back",
+		"notyet.title":       "Not yet",
+		"notyet.heading":     "Not so fast!",
+		"notyet.message":     "This station has a minimum time. Please stay a little longer.",
+		"notyet.remaining":   "Time remaining:",
+		"notyet.retry":       "Continue now",
+	},
+	"de": {
+		"redirect.title":     "Weiterleitung ...",
+		"redirect.heading":   "✨ Nächste Challenge gefunden!",
+		"redirect.manual":    "Falls die Weiterleitung nicht klappt:",
+		"redirect.link":      "Hier klicken",
+		"finished.title":     "Schatzsuche geschafft!",
+		"finished.heading":   "Glückwunsch!",
+		"finished.mastered":  "Ihr habt alle Challenges gemeistert!",
+		"finished.completed": "Die Schatzsuche ist abgeschlossen.",
+		"finished.stat":      "Challenges abgeschlossen",
+		"finished.points":    "Punkte",
+		"finished.back":      "Zurück zum Start",
+		"finished.redirect":  "Automatische Weiterleitung in",
+		"finished.continue":  "Weiter",
+		"confirm.title":      "Challenge %s – Bestätigen",
+		"confirm.heading":    "Ihr schließt gleich Challenge %s ab",
+		"confirm.proceed":    "Ja, weiter →",
+		"confirm.cancel":     "Abbrechen",
+		"noroute.title":      "Keine Challenges",
+		"noroute.heading":    "Noch keine Challenges",
+		"noroute.message":    "Eurem Team sind noch keine Challenges zugewiesen. Bitte wendet euch an die Orga.",
+		"noroute.back":       "Zurück",
+		"notyet.title":       "Noch nicht",
+		"notyet.heading":     "Nicht so schnell!",
+		"notyet.message":     "An dieser Station gibt es eine Mindestzeit. Bitte bleibt noch etwas.",
+		"notyet.remaining":   "Verbleibende Zeit:",
+		"notyet.retry":       "Jetzt weiter",
+	},
+}
+
+// translate liefert den Text zu key in lang (Fallback Englisch, dann der Schlüssel selbst).
+// Weitere Argumente werden per fmt.Sprintf eingesetzt.
+func translate(lang, key string, args ...any) string {
+	text, ok := translations[lang][key]
+	if !ok {
+		if text, ok = translations[defaultLanguage][key]; !ok {
+			return key
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+// normalizeLanguage macht aus "de-DE", "DE" oder "Deutsch" einen unterstützten Sprachcode ("" wenn unbekannt)
+func normalizeLanguage(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "deutsch", "german":
+		return "de"
+	case "englisch", "english":
+		return "en"
+	}
+	if i := strings.IndexAny(s, "-_"); i > 0 {
+		s = s[:i]
+	}
+	if _, ok := translations[s]; ok {
+		return s
+	}
+	return ""
+}
+
+// parseDefaultLanguage liest DEFAULT_LANGUAGE
+func parseDefaultLanguage(s string) (string, error) {
+	if s == "" {
+		return defaultLanguage, nil
+	}
+	lang := normalizeLanguage(s)
+	if lang == "" {
+		return "", fmt.Errorf("DEFAULT_LANGUAGE %q wird nicht unterstützt (en, de)", s)
+	}
+	return lang, nil
+}
+
+// acceptLanguage wählt die erste unterstützte Sprache aus dem Accept-Language Header
+func acceptLanguage(header string) string {
+	for _, part := range strings.Split(header, ",") {
+		tag, _, _ := strings.Cut(part, ";")
+		if lang := normalizeLanguage(tag); lang != "" {
+			return lang
+		}
+	}
+	return ""
+}
+
+// teamLanguage liest die "Language" Property einer Team-Page ("" wenn nicht gesetzt)
+func teamLanguage(page *notionapi.Page) string {
+	switch p := page.Properties[teamLanguageProperty].(type) {
+	case *notionapi.SelectProperty:
+		return normalizeLanguage(p.Select.Name)
+	case *notionapi.RichTextProperty:
+		return normalizeLanguage(richTextPlain(p.RichText))
+	}
+	return ""
+}
+
+// languageFor bestimmt die Sprache für ein Team: Team-Page vor Accept-Language vor DEFAULT_LANGUAGE.
+// Fehler beim Laden der Team-Page sind nicht fatal.
+func (app *App) languageFor(ctx context.Context, c *gin.Context, teamPageID string) string {
+	if teamPageID != "" {
+		if page, err := app.getPage(ctx, teamPageID); err != nil {
+			errorf("Fehler beim Laden der Team-Sprache: %v", err)
+		} else if lang := teamLanguage(page); lang != "" {
+			return lang
+		}
+	}
+	if lang := acceptLanguage(c.GetHeader("Accept-Language")); lang != "" {
+		return lang
+	}
+	return app.defaultLanguage
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestNormalizeLanguage(t *testing.T) {
+	tests := map[string]string{
+		"de":       "de",
+		"DE":       "de",
+		"de-AT":    "de",
+		"en_GB":    "en",
+		" Deutsch": "de",
+		"English":  "en",
+		"fr":       "",
+		"":         "",
+	}
+	for in, want := range tests {
+		if got := normalizeLanguage(in); got != want {
+			t.Errorf("normalizeLanguage(%q) = %q, erwartet %q", in, got, want)
+		}
+	}
+}
+
+func TestAcceptLanguage(t *testing.T) {
+	tests := map[string]string{
+		"de-DE,de;q=0.9,en;q=0.8": "de",
+		"fr-FR, en-US;q=0.7":      "en",
+		"fr, it":                  "",
+		"":                        "",
+	}
+	for header, want := range tests {
+		if got := acceptLanguage(header); got != want {
+			t.Errorf("acceptLanguage(%q) = %q, erwartet %q", header, got, want)
+		}
+	}
+}
+
+func TestParseDefaultLanguage(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "", want: defaultLanguage},
+		{in: "de", want: "de"},
+		{in: "German", want: "de"},
+		{in: "fr", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseDefaultLanguage(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseDefaultLanguage(%q) = %q, %v; erwartet %q, Fehler = %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		lang, key string
+		args      []any
+		want      string
+	}{
+		{lang: "de", key: "noroute.back", want: "Zurück"},
+		{lang: "en", key: "noroute.back", want: "Back"},
+		{lang: "fr", key: "noroute.back", want: "Back"},
+		{lang: "de", key: "confirm.heading", args: []any{"3"}, want: "Ihr schließt gleich Challenge 3 ab"},
+		{lang: "de", key: "gibt.es.nicht", want: "gibt.es.nicht"},
+	}
+	for _, tt := range tests {
+		if got := translate(tt.lang, tt.key, tt.args...); got != tt.want {
+			t.Errorf("translate(%q, %q) = %q, erwartet %q", tt.lang, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestTeamLanguage(t *testing.T) {
+	tests := []struct {
+		name           string
+		language       notionapi.Property // Language-Property des Demo-Teams (nil: keine)
+		acceptLanguage string
+		defaultLang    string // DEFAULT_LANGUAGE
+		want           string
+	}{
+		{name: "ohne Vorgabe", want: "en"},
+		{name: "DEFAULT_LANGUAGE", defaultLang: "de", want: "de"},
+		{name: "Browser vor DEFAULT_LANGUAGE", acceptLanguage: "de-DE,de;q=0.9", want: "de"},
+		{name: "Team als Select", language: notionapi.SelectProperty{Select: notionapi.Option{Name: "de"}}, want: "de"},
+		{name: "Team als Rich-Text", language: notionapi.RichTextProperty{RichText: demoText("Deutsch")}, want: "de"},
+		{name: "Team vor Browser", language: notionapi.SelectProperty{Select: notionapi.Option{Name: "en"}}, acceptLanguage: "de", defaultLang: "de", want: "en"},
+		{name: "unbekannte Team-Sprache", language: notionapi.SelectProperty{Select: notionapi.Option{Name: "Klingonisch"}}, acceptLanguage: "de", want: "de"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"DEFAULT_LANGUAGE": tt.defaultLang})
+			if tt.language != nil {
+				_, err := ta.store.UpdatePage(t.Context(), demoTeamPageID, &notionapi.PageUpdateRequest{
+					Properties: notionapi.Properties{teamLanguageProperty: tt.language},
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/next/1", strings.NewReader(url.Values{"team": {"Demo Team"}}.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			w := ta.withCookies(req, nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+			if heading := translate(tt.want, "redirect.heading"); !strings.Contains(w.Body.String(), heading) {
+				t.Errorf("%q fehlt:\n%s", heading, w.Body.String())
+			}
+		})
+	}
+}
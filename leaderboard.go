@@ -0,0 +1,325 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jomei/notionapi"
+)
+
+// maxLeaderboardPageSize begrenzt ?pageSize=, damit große Events nicht alles auf einmal laden
+const maxLeaderboardPageSize = 50
+
+// teamDivisionProperty ordnet ein Team einer Division zu (Select oder Rich-Text, z.B. Altersgruppe)
+const teamDivisionProperty = "Division"
+
+// defaultDivision sammelt Teams ohne Division
+const defaultDivision = "General"
+
+// leaderboardEntry ist eine Zeile im Leaderboard
+type leaderboardEntry struct {
+	Rank           int
+	Team           string
+	Division       string
+	Score          float64
+	Completed      int
+	Skipped        int
+	LastCompletion time.Time
+}
+
+// leaderboardPage enthält die Seiten-Metadaten für die Prev/Next-Links im Template
+type leaderboardPage struct {
+	Page       int
+	PageSize   int
+	TotalPages int
+	Total      int
+	PrevPage   int
+	NextPage   int
+	Paginated  bool
+}
+
+// leaderboardDivision ist das Teil-Leaderboard einer Division mit eigener Rangfolge
+type leaderboardDivision struct {
+	Name    string
+	Entries []leaderboardEntry
+}
+
+// leaderboardFreeze hält einen eingefrorenen Stand des Leaderboards im Speicher,
+// damit die Endwertung bei der Siegerehrung stabil bleibt
+type leaderboardFreeze struct {
+	mu       sync.RWMutex
+	entries  []leaderboardEntry
+	frozenAt time.Time
+}
+
+// snapshot liefert den eingefrorenen Stand (false, wenn nicht eingefroren)
+func (f *leaderboardFreeze) snapshot() ([]leaderboardEntry, time.Time, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.entries, f.frozenAt, f.entries != nil
+}
+
+func (f *leaderboardFreeze) freeze(entries []leaderboardEntry) time.Time {
+	if entries == nil {
+		entries = []leaderboardEntry{}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.entries = entries
+	f.frozenAt = time.Now()
+	return f.frozenAt
+}
+
+func (f *leaderboardFreeze) unfreeze() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.entries = nil
+}
+
+// handleLeaderboard zeigt alle Teams sortiert nach abgeschlossenen Challenges.
+// Ist das Leaderboard eingefroren, wird der gespeicherte Stand gezeigt.
+func (app *App) handleLeaderboard(c *gin.Context) {
+	entries, frozenAt, frozen := app.frozenLeaderboard.snapshot()
+	if !frozen {
+		var err error
+		entries, err = app.getLeaderboard(c.Request.Context())
+		if err != nil {
+			errorf("Fehler beim Laden des Leaderboards: %v", err)
+			c.String(http.StatusInternalServerError, "%s", app.userError("Fehler beim Laden des Leaderboards", err))
+			return
+		}
+	}
+
+	// ?division= zeigt nur eine Division mit eigener Rangfolge, ?grouped=true alle Divisionen getrennt
+	divisions := divisionNames(entries)
+	division := c.Query("division")
+	var groups []leaderboardDivision
+	if division != "" {
+		entries = filterDivision(entries, division)
+	} else if c.Query("grouped") == "true" {
+		groups = groupDivisions(entries)
+	}
+
+	// Ohne ?pageSize= bleibt es bei der vollständigen Liste (kleine Events)
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.Query("pageSize"))
+	entries, meta := paginateLeaderboard(entries, page, pageSize)
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := app.templates.ExecuteTemplate(c.Writer, "leaderboard.html", gin.H{
+		"Entries":   entries,
+		"Page":      meta,
+		"Frozen":    frozen,
+		"FrozenAt":  frozenAt,
+		"Divisions": divisions,
+		"Division":  division,
+		"Groups":    groups,
+	}); err != nil {
+		app.templateFailed(c, err)
+	}
+}
+
+// handleAdminFreeze friert den aktuellen Stand des Leaderboards ein
+func (app *App) handleAdminFreeze(c *gin.Context) {
+	entries, err := app.getLeaderboard(c.Request.Context())
+	if err != nil {
+		errorf("Fehler beim Laden des Leaderboards: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": app.userError("Fehler beim Laden des Leaderboards", err)})
+		return
+	}
+
+	frozenAt := app.frozenLeaderboard.freeze(entries)
+	infof("Leaderboard mit %d Teams eingefroren", len(entries))
+	c.JSON(http.StatusOK, gin.H{"frozen": true, "frozenAt": frozenAt, "teams": len(entries)})
+}
+
+// handleAdminUnfreeze hebt das Einfrieren auf, das Leaderboard ist wieder live
+func (app *App) handleAdminUnfreeze(c *gin.Context) {
+	app.frozenLeaderboard.unfreeze()
+	infof("Leaderboard wieder live")
+	c.JSON(http.StatusOK, gin.H{"frozen": false})
+}
+
+// teamProgress zählt Abschlüsse und übersprungene Challenges einer Team-Page.
+// Gemeinsame Grundlage für Leaderboard und /api/progress.
+func (app *App) teamProgress(page *notionapi.Page) leaderboardEntry {
+	entry := leaderboardEntry{Team: pageTitle(*page), Division: teamDivision(page), Score: teamScore(page)}
+	for propName, prop := range page.Properties {
+		switch p := prop.(type) {
+		case *notionapi.DateProperty:
+			if _, ok := app.completionNumber(propName); !ok || p.Date == nil || p.Date.Start == nil {
+				continue
+			}
+			entry.Completed++
+			if t := time.Time(*p.Date.Start); t.After(entry.LastCompletion) {
+				entry.LastCompletion = t
+			}
+		case *notionapi.RichTextProperty:
+			if strings.HasPrefix(propName, "Skipped_") && len(p.RichText) > 0 {
+				entry.Skipped++
+			}
+		}
+	}
+	return entry
+}
+
+// getLeaderboard liest die Abschluss-Markierungen aller Team-Pages und sortiert die Teams
+func (app *App) getLeaderboard(ctx context.Context) ([]leaderboardEntry, error) {
+	pages, err := app.listTeamPages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []leaderboardEntry
+	for i := range pages {
+		entry := app.teamProgress(&pages[i])
+		if entry.Team == "" {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	rankLeaderboard(entries)
+	return entries, nil
+}
+
+// rankLeaderboard sortiert die Einträge und vergibt die Ränge.
+// Höherer Score zuerst, dann mehr Challenges, bei Gleichstand gewinnt der frühere letzte Abschluss.
+func rankLeaderboard(entries []leaderboardEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Completed != b.Completed {
+			return a.Completed > b.Completed
+		}
+		if !a.LastCompletion.Equal(b.LastCompletion) {
+			return a.LastCompletion.Before(b.LastCompletion)
+		}
+		return a.Team < b.Team
+	})
+
+	for i := range entries {
+		entries[i].Rank = i + 1
+	}
+}
+
+// teamDivision liest die Division einer Team-Page, Teams ohne Division landen in defaultDivision
+func teamDivision(page *notionapi.Page) string {
+	var division string
+	switch p := page.Properties[teamDivisionProperty].(type) {
+	case *notionapi.SelectProperty:
+		division = p.Select.Name
+	case *notionapi.RichTextProperty:
+		division = richTextPlain(p.RichText)
+	}
+	if division = strings.TrimSpace(division); division == "" {
+		return defaultDivision
+	}
+	return division
+}
+
+// filterDivision liefert die Teams einer Division (ohne Groß-/Kleinschreibung) mit Rängen innerhalb der Division.
+// Die Einträge sind bereits sortiert, daher genügt das Neu-Nummerieren.
+func filterDivision(entries []leaderboardEntry, division string) []leaderboardEntry {
+	filtered := []leaderboardEntry{}
+	for _, entry := range entries {
+		if strings.EqualFold(entry.Division, division) {
+			entry.Rank = len(filtered) + 1
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// divisionNames liefert alle Divisionen alphabetisch, defaultDivision zuletzt
+func divisionNames(entries []leaderboardEntry) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, entry := range entries {
+		if !seen[entry.Division] {
+			seen[entry.Division] = true
+			names = append(names, entry.Division)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if (names[i] == defaultDivision) != (names[j] == defaultDivision) {
+			return names[j] == defaultDivision
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// groupDivisions teilt das Leaderboard in Teil-Leaderboards je Division
+func groupDivisions(entries []leaderboardEntry) []leaderboardDivision {
+	names := divisionNames(entries)
+	groups := make([]leaderboardDivision, 0, len(names))
+	for _, name := range names {
+		groups = append(groups, leaderboardDivision{Name: name, Entries: filterDivision(entries, name)})
+	}
+	return groups
+}
+
+// paginateLeaderboard schneidet die gewünschte Seite aus dem sortierten Leaderboard.
+// pageSize <= 0 liefert die vollständige Liste.
+func paginateLeaderboard(entries []leaderboardEntry, page, pageSize int) ([]leaderboardEntry, leaderboardPage) {
+	total := len(entries)
+	if pageSize <= 0 {
+		return entries, leaderboardPage{Page: 1, PageSize: total, TotalPages: 1, Total: total}
+	}
+	if pageSize > maxLeaderboardPageSize {
+		pageSize = maxLeaderboardPageSize
+	}
+
+	totalPages := (total + pageSize - 1) / pageSize
+	if totalPages == 0 {
+		totalPages = 1
+	}
+	if page < 1 {
+		page = 1
+	}
+	if page > totalPages {
+		page = totalPages
+	}
+
+	start := (page - 1) * pageSize
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+
+	meta := leaderboardPage{
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+		Total:      total,
+		Paginated:  true,
+	}
+	if page > 1 {
+		meta.PrevPage = page - 1
+	}
+	if page < totalPages {
+		meta.NextPage = page + 1
+	}
+
+	return entries[start:end], meta
+}
+
+// pageTitle liefert den Titel einer Notion-Page (die Titel-Property hat keinen festen Namen)
+func pageTitle(page notionapi.Page) string {
+	for _, prop := range page.Properties {
+		if titleProp, ok := prop.(*notionapi.TitleProperty); ok && len(titleProp.Title) > 0 {
+			return titleProp.Title[0].PlainText
+		}
+	}
+	return ""
+}
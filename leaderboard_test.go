@@ -0,0 +1,347 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestPaginateLeaderboard(t *testing.T) {
+	entries := make([]leaderboardEntry, 7)
+	for i := range entries {
+		entries[i] = leaderboardEntry{Rank: i + 1, Team: fmt.Sprintf("Team %d", i+1)}
+	}
+
+	tests := []struct {
+		name     string
+		page     int
+		pageSize int
+		wantRank []int
+		wantMeta leaderboardPage
+	}{
+		{
+			name:     "ohne pageSize alles",
+			page:     3,
+			wantRank: []int{1, 2, 3, 4, 5, 6, 7},
+			wantMeta: leaderboardPage{Page: 1, PageSize: 7, TotalPages: 1, Total: 7},
+		},
+		{
+			name:     "erste Seite",
+			page:     1,
+			pageSize: 3,
+			wantRank: []int{1, 2, 3},
+			wantMeta: leaderboardPage{Page: 1, PageSize: 3, TotalPages: 3, Total: 7, NextPage: 2, Paginated: true},
+		},
+		{
+			name:     "mittlere Seite",
+			page:     2,
+			pageSize: 3,
+			wantRank: []int{4, 5, 6},
+			wantMeta: leaderboardPage{Page: 2, PageSize: 3, TotalPages: 3, Total: 7, PrevPage: 1, NextPage: 3, Paginated: true},
+		},
+		{
+			name:     "letzte Seite unvollständig",
+			page:     3,
+			pageSize: 3,
+			wantRank: []int{7},
+			wantMeta: leaderboardPage{Page: 3, PageSize: 3, TotalPages: 3, Total: 7, PrevPage: 2, Paginated: true},
+		},
+		{
+			name:     "Seite hinter dem Ende",
+			page:     9,
+			pageSize: 3,
+			wantRank: []int{7},
+			wantMeta: leaderboardPage{Page: 3, PageSize: 3, TotalPages: 3, Total: 7, PrevPage: 2, Paginated: true},
+		},
+		{
+			name:     "Seite vor dem Anfang",
+			page:     0,
+			pageSize: 5,
+			wantRank: []int{1, 2, 3, 4, 5},
+			wantMeta: leaderboardPage{Page: 1, PageSize: 5, TotalPages: 2, Total: 7, NextPage: 2, Paginated: true},
+		},
+		{
+			name:     "pageSize über dem Maximum",
+			page:     1,
+			pageSize: maxLeaderboardPageSize + 10,
+			wantRank: []int{1, 2, 3, 4, 5, 6, 7},
+			wantMeta: leaderboardPage{Page: 1, PageSize: maxLeaderboardPageSize, TotalPages: 1, Total: 7, Paginated: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, meta := paginateLeaderboard(entries, tt.page, tt.pageSize)
+			var ranks []int
+			for _, entry := range got {
+				ranks = append(ranks, entry.Rank)
+			}
+			if fmt.Sprint(ranks) != fmt.Sprint(tt.wantRank) {
+				t.Errorf("Ränge = %v, erwartet %v", ranks, tt.wantRank)
+			}
+			if meta != tt.wantMeta {
+				t.Errorf("Seite = %+v, erwartet %+v", meta, tt.wantMeta)
+			}
+		})
+	}
+}
+
+func TestPaginateLeaderboardEmpty(t *testing.T) {
+	got, meta := paginateLeaderboard(nil, 2, 10)
+	if len(got) != 0 || meta.TotalPages != 1 || meta.Page != 1 {
+		t.Errorf("leeres Leaderboard: %v %+v", got, meta)
+	}
+}
+
+func TestLeaderboardPageLinks(t *testing.T) {
+	ta := newTestApp(t, map[string]string{"FEATURE_LEADERBOARD": "true"})
+
+	w := ta.do(http.MethodGet, "/leaderboard?pageSize=1&page=1", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+	}
+	for _, want := range []string{"Page 1 of 2", `href="?page=2&pageSize=1"`} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("%q fehlt:\n%s", want, w.Body.String())
+		}
+	}
+}
+
+func TestScoreAccumulation(t *testing.T) {
+	tests := []struct {
+		name      string
+		completed []string
+		want      float64
+	}{
+		{name: "keine Abschlüsse", want: 0},
+		{name: "eine Challenge", completed: []string{"1"}, want: 10},
+		{name: "mehrere Challenges", completed: []string{"1", "2", "3"}, want: 45},
+		{name: "doppelter Abschluss zählt einmal", completed: []string{"2", "2"}, want: 20},
+		{name: "Challenge ohne Punkte", completed: []string{"1", "5"}, want: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			addDemoChallenge(ta.store, "demo-ohne-punkte", 5)
+			for _, id := range tt.completed {
+				if err := ta.recordCompletion(t.Context(), demoTeamPageID, id, false, ""); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := teamScore(ta.teamPage(t, demoTeamPageID)); got != tt.want {
+				t.Errorf("Score = %v, erwartet %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLeaderboardSortsByScore(t *testing.T) {
+	ta := newTestApp(t, nil)
+	// Beide Teams mit zwei Challenges: Demo Team 10 + 15, Die Füchse 25 + 20 Punkte
+	for _, completion := range []struct{ team, id string }{
+		{demoTeamPageID, "1"}, {demoTeamPageID, "3"}, {foxesTeamPageID, "4"}, {foxesTeamPageID, "2"},
+	} {
+		if err := ta.recordCompletion(t.Context(), completion.team, completion.id, false, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := ta.getLeaderboard(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, entry := range entries {
+		got = append(got, fmt.Sprintf("%d. %s %v", entry.Rank, entry.Team, entry.Score))
+	}
+	want := []string{"1. Die Füchse 45", "2. Demo Team 25"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Leaderboard = %v, erwartet %v", got, want)
+	}
+}
+
+func TestRankLeaderboardScoreFirst(t *testing.T) {
+	entries := []leaderboardEntry{
+		{Team: "Viele Stationen", Score: 20, Completed: 4},
+		{Team: "Viele Punkte", Score: 50, Completed: 2},
+		{Team: "Ohne Punkte", Completed: 6},
+	}
+	rankLeaderboard(entries)
+
+	want := []string{"Viele Punkte", "Viele Stationen", "Ohne Punkte"}
+	for i, entry := range entries {
+		if entry.Team != want[i] || entry.Rank != i+1 {
+			t.Errorf("Platz %d = %s (Rang %d), erwartet %s", i+1, entry.Team, entry.Rank, want[i])
+		}
+	}
+}
+
+func TestLeaderboardFreeze(t *testing.T) {
+	ta := newTestApp(t, map[string]string{"FEATURE_LEADERBOARD": "true"})
+	record := func(teamPageID, id string) {
+		t.Helper()
+		if err := ta.recordCompletion(t.Context(), teamPageID, id, false, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// leader liefert das erste Team der Tabelle und ob der eingefrorene Stand gezeigt wird
+	leader := func() (string, string) {
+		t.Helper()
+		w := ta.do(http.MethodGet, "/leaderboard", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+		}
+		body := w.Body.String()
+		demo, foxes := strings.Index(body, "<td>Demo Team</td>"), strings.Index(body, "<td>Die Füchse</td>")
+		if demo < 0 || foxes < 0 {
+			t.Fatalf("Teams fehlen im Leaderboard:\n%s", body)
+		}
+		if demo < foxes {
+			return "Demo Team", body
+		}
+		return "Die Füchse", body
+	}
+
+	record(demoTeamPageID, "1")
+	if w := ta.admin(http.MethodPost, "/admin/freeze", ""); w.Code != http.StatusOK {
+		t.Fatalf("freeze: %d %s", w.Code, w.Body.String())
+	}
+
+	// Die Füchse überholen nach dem Einfrieren, das Leaderboard bleibt stehen
+	record(foxesTeamPageID, "4")
+	ta.cache.clearAll() // damit nicht der Cache, sondern das Einfrieren den alten Stand hält
+	if got, body := leader(); got != "Demo Team" || strings.Contains(body, "<td>25</td>") {
+		t.Errorf("eingefroren: Erster = %s, erwartet Demo Team ohne neue Punkte", got)
+	}
+
+	if w := ta.admin(http.MethodPost, "/admin/unfreeze", ""); w.Code != http.StatusOK {
+		t.Fatalf("unfreeze: %d %s", w.Code, w.Body.String())
+	}
+	if got, _ := leader(); got != "Die Füchse" {
+		t.Errorf("nach dem Auftauen: Erster = %s, erwartet Die Füchse", got)
+	}
+}
+
+func TestLeaderboardFreezeEmpty(t *testing.T) {
+	// Auch ein leerer Stand gilt als eingefroren
+	var f leaderboardFreeze
+	f.freeze(nil)
+	if entries, _, frozen := f.snapshot(); !frozen || len(entries) != 0 {
+		t.Errorf("snapshot = %v, %v", entries, frozen)
+	}
+	f.unfreeze()
+	if _, _, frozen := f.snapshot(); frozen {
+		t.Error("nach unfreeze noch eingefroren")
+	}
+}
+
+func TestTeamDivision(t *testing.T) {
+	tests := []struct {
+		name string
+		prop notionapi.Property
+		want string
+	}{
+		{name: "Select", prop: &notionapi.SelectProperty{Select: notionapi.Option{Name: "U12"}}, want: "U12"},
+		{name: "Rich-Text", prop: &notionapi.RichTextProperty{RichText: demoText(" U16 ")}, want: "U16"},
+		{name: "leer", prop: &notionapi.RichTextProperty{RichText: demoText("  ")}, want: defaultDivision},
+		{name: "ohne Property", want: defaultDivision},
+	}
+	for _, tt := range tests {
+		page := &notionapi.Page{Properties: notionapi.Properties{}}
+		if tt.prop != nil {
+			page.Properties[teamDivisionProperty] = tt.prop
+		}
+		if got := teamDivision(page); got != tt.want {
+			t.Errorf("%s: teamDivision = %q, erwartet %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGroupDivisions(t *testing.T) {
+	entries := []leaderboardEntry{
+		{Rank: 1, Team: "Adler", Division: "U16"},
+		{Rank: 2, Team: "Bären", Division: defaultDivision},
+		{Rank: 3, Team: "Dachse", Division: "U12"},
+		{Rank: 4, Team: "Eulen", Division: "U16"},
+	}
+
+	if got := divisionNames(entries); !slices.Equal(got, []string{"U12", "U16", defaultDivision}) {
+		t.Errorf("divisionNames = %v", got)
+	}
+
+	var got []string
+	for _, group := range groupDivisions(entries) {
+		for _, entry := range group.Entries {
+			got = append(got, fmt.Sprintf("%s: %d. %s", group.Name, entry.Rank, entry.Team))
+		}
+	}
+	want := []string{"U12: 1. Dachse", "U16: 1. Adler", "U16: 2. Eulen", "General: 1. Bären"}
+	if !slices.Equal(got, want) {
+		t.Errorf("groupDivisions = %v, erwartet %v", got, want)
+	}
+
+	// Der Filter ignoriert Groß-/Kleinschreibung und lässt das Original unverändert
+	if filtered := filterDivision(entries, "u16"); len(filtered) != 2 || filtered[1].Team != "Eulen" || filtered[1].Rank != 2 {
+		t.Errorf("filterDivision(u16) = %+v", filtered)
+	}
+	if entries[3].Rank != 4 {
+		t.Errorf("filterDivision hat den Rang im Original geändert: %+v", entries[3])
+	}
+}
+
+// leaderboardRowPattern liest Überschriften und Zeilen aus dem gerenderten Leaderboard
+var leaderboardRowPattern = regexp.MustCompile(`<h2>([^<]+)</h2>|<td class="rank">(\d+)</td>\s*<td>([^<]+)</td>`)
+
+func TestLeaderboardDivisions(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "", want: []string{"1. Die Füchse", "2. Demo Team", "3. Eulen"}},
+		{query: "?division=u12", want: []string{"1. Demo Team", "2. Eulen"}},
+		{query: "?division=General", want: []string{"1. Die Füchse"}},
+		{query: "?division=U99"},
+		{query: "?grouped=true", want: []string{"## U12", "1. Demo Team", "2. Eulen", "## General", "1. Die Füchse"}},
+	}
+
+	ta := newTestApp(t, nil)
+	_, err := ta.store.UpdatePage(t.Context(), demoTeamPageID, &notionapi.PageUpdateRequest{
+		Properties: notionapi.Properties{teamDivisionProperty: notionapi.SelectProperty{Select: notionapi.Option{Name: "U12"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addDemoTeam(ta.store, "Eulen", notionapi.Properties{teamDivisionProperty: &notionapi.RichTextProperty{RichText: demoText("U12")}})
+	// Demo Team 10 Punkte, Die Füchse 25 Punkte, Eulen ohne Abschluss
+	if err := ta.recordCompletion(t.Context(), demoTeamPageID, "1", false, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := ta.recordCompletion(t.Context(), foxesTeamPageID, "4", false, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := ta.do(http.MethodGet, "/leaderboard"+tt.query, nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+			var got []string
+			for _, match := range leaderboardRowPattern.FindAllStringSubmatch(w.Body.String(), -1) {
+				if match[1] != "" {
+					got = append(got, "## "+match[1])
+				} else {
+					got = append(got, match[2]+". "+match[3])
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Leaderboard = %v, erwartet %v", got, tt.want)
+			}
+		})
+	}
+}
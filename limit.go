@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jomei/notionapi"
+)
+
+// defaultQueueTimeout bestimmt, wie lange ein Request auf einen freien Platz wartet
+const defaultQueueTimeout = 5 * time.Second
+
+// parseConcurrencyLimit liest MAX_CONCURRENT_REQUESTS (leer oder 0: unbegrenzt) und REQUEST_QUEUE_TIMEOUT
+func parseConcurrencyLimit(limit, timeout string) (int, time.Duration, error) {
+	n := 0
+	if limit != "" {
+		var err error
+		n, err = strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("MAX_CONCURRENT_REQUESTS muss eine nicht-negative ganze Zahl sein, ist aber %q", limit)
+		}
+	}
+
+	d := defaultQueueTimeout
+	if timeout != "" {
+		var err error
+		d, err = time.ParseDuration(timeout)
+		if err != nil || d < 0 {
+			return 0, 0, fmt.Errorf("REQUEST_QUEUE_TIMEOUT muss eine nicht-negative Dauer sein (z.B. 5s), ist aber %q", timeout)
+		}
+	}
+	return n, d, nil
+}
+
+// concurrencyLimit begrenzt die gleichzeitig bearbeiteten Requests. Weitere Requests
+// warten bis zu timeout auf einen freien Platz und bekommen danach 503.
+// So kommen Ansturm-Spitzen gleichmäßiger bei der Notion-API an.
+func concurrencyLimit(limit int, timeout time.Duration) gin.HandlerFunc {
+	slots := make(chan struct{}, limit)
+	retryAfter := strconv.Itoa(max(1, int(timeout.Seconds())))
+
+	return func(c *gin.Context) {
+		select {
+		case slots <- struct{}{}:
+		default:
+			timer := time.NewTimer(timeout)
+			defer timer.Stop()
+
+			select {
+			case slots <- struct{}{}:
+			case <-timer.C:
+				warnf("%s %s: Request-Limit (%d) erreicht, 503", c.Request.Method, c.Request.URL.Path, limit)
+				c.Header("Retry-After", retryAfter)
+				c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Server ausgelastet, bitte gleich nochmal versuchen"})
+				return
+			case <-c.Request.Context().Done():
+				c.Abort()
+				return
+			}
+		}
+		defer func() { <-slots }()
+
+		c.Next()
+	}
+}
+
+// parseNotionConcurrency liest NOTION_MAX_CONCURRENT (leer oder 0: unbegrenzt)
+func parseNotionConcurrency(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("NOTION_MAX_CONCURRENT muss eine nicht-negative ganze Zahl sein, ist aber %q", s)
+	}
+	return n, nil
+}
+
+// limitedNotion begrenzt die gleichzeitigen Notion-Aufrufe aller Goroutinen, unabhängig
+// vom Request-Limit. Wartende Aufrufe brechen ab, sobald ihr Context endet.
+type limitedNotion struct {
+	notionService
+	slots chan struct{}
+}
+
+func newLimitedNotion(service notionService, limit int) *limitedNotion {
+	return &limitedNotion{notionService: service, slots: make(chan struct{}, limit)}
+}
+
+// acquire belegt einen Platz und liefert die Freigabe
+func (l *limitedNotion) acquire(ctx context.Context) (func(), error) {
+	select {
+	case l.slots <- struct{}{}:
+		return func() { <-l.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (l *limitedNotion) QueryDatabase(ctx context.Context, dbID string, req *notionapi.DatabaseQueryRequest) (*notionapi.DatabaseQueryResponse, error) {
+	release, err := l.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return l.notionService.QueryDatabase(ctx, dbID, req)
+}
+
+func (l *limitedNotion) GetPage(ctx context.Context, pageID string) (*notionapi.Page, error) {
+	release, err := l.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return l.notionService.GetPage(ctx, pageID)
+}
+
+func (l *limitedNotion) UpdatePage(ctx context.Context, pageID string, req *notionapi.PageUpdateRequest) (*notionapi.Page, error) {
+	release, err := l.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return l.notionService.UpdatePage(ctx, pageID, req)
+}
+
+func (l *limitedNotion) CreatePage(ctx context.Context, req *notionapi.PageCreateRequest) (*notionapi.Page, error) {
+	release, err := l.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return l.notionService.CreatePage(ctx, req)
+}
+
+func (l *limitedNotion) GetBlockChildren(ctx context.Context, blockID string, pagination *notionapi.Pagination) (*notionapi.GetChildrenResponse, error) {
+	release, err := l.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return l.notionService.GetBlockChildren(ctx, blockID, pagination)
+}
+
+func (l *limitedNotion) GetDatabase(ctx context.Context, dbID string) (*notionapi.Database, error) {
+	release, err := l.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return l.notionService.GetDatabase(ctx, dbID)
+}
+
+func (l *limitedNotion) GetPropertyItems(ctx context.Context, pageID, propertyID, cursor string) (*propertyItemList, error) {
+	release, err := l.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return l.notionService.GetPropertyItems(ctx, pageID, propertyID, cursor)
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jomei/notionapi"
+)
+
+func TestParseConcurrencyLimit(t *testing.T) {
+	tests := []struct {
+		limit, timeout string
+		want           int
+		wantTimeout    time.Duration
+		wantErr        bool
+	}{
+		{want: 0, wantTimeout: defaultQueueTimeout},
+		{limit: "20", timeout: "2s", want: 20, wantTimeout: 2 * time.Second},
+		{limit: "0", timeout: "0s", want: 0, wantTimeout: 0},
+		{limit: "-1", wantErr: true},
+		{limit: "viele", wantErr: true},
+		{limit: "5", timeout: "5", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, timeout, err := parseConcurrencyLimit(tt.limit, tt.timeout)
+		if (err != nil) != tt.wantErr || (err == nil && (got != tt.want || timeout != tt.wantTimeout)) {
+			t.Errorf("parseConcurrencyLimit(%q, %q) = %d, %v, %v", tt.limit, tt.timeout, got, timeout, err)
+		}
+	}
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	r := gin.New()
+	r.Use(concurrencyLimit(2, 100*time.Millisecond))
+	r.GET("/slow", func(c *gin.Context) {
+		started <- struct{}{}
+		<-release
+		c.Status(http.StatusOK)
+	})
+	r.GET("/fast", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	// Beide Plätze belegen
+	slow := make(chan int, 2)
+	for i := 0; i < 2; i++ {
+		go func() { slow <- serve("/slow").Code }()
+		<-started
+	}
+
+	// Über der Kapazität: Warten bis zum Timeout, dann 503
+	w := serve("/fast")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Status über der Kapazität = %d, erwartet 503", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "1" {
+		t.Errorf("Retry-After = %q", w.Header().Get("Retry-After"))
+	}
+
+	// Ein wartender Request bekommt den Platz, sobald einer frei wird
+	queued := make(chan int)
+	go func() { queued <- serve("/fast").Code }()
+	time.Sleep(10 * time.Millisecond)
+	release <- struct{}{}
+	if code := <-queued; code != http.StatusOK {
+		t.Errorf("Status nach Freigabe = %d, erwartet 200", code)
+	}
+
+	release <- struct{}{}
+	for i := 0; i < 2; i++ {
+		if code := <-slow; code != http.StatusOK {
+			t.Errorf("langsamer Request: Status %d", code)
+		}
+	}
+}
+
+func TestParseNotionConcurrency(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{in: "", want: 0},
+		{in: "0", want: 0},
+		{in: "3", want: 3},
+		{in: "-1", wantErr: true},
+		{in: "drei", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseNotionConcurrency(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseNotionConcurrency(%q) = %d, %v", tt.in, got, err)
+		}
+	}
+}
+
+// blockingNotion hält jeden Aufruf fest, bis release geschlossen wird, und merkt sich,
+// wie viele Aufrufe höchstens gleichzeitig liefen
+type blockingNotion struct {
+	notionService
+	release  chan struct{}
+	mu       sync.Mutex
+	inFlight int
+	maxSeen  int
+}
+
+func (b *blockingNotion) call() {
+	b.mu.Lock()
+	b.inFlight++
+	b.maxSeen = max(b.maxSeen, b.inFlight)
+	b.mu.Unlock()
+
+	<-b.release
+
+	b.mu.Lock()
+	b.inFlight--
+	b.mu.Unlock()
+}
+
+func (b *blockingNotion) current() (int, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.inFlight, b.maxSeen
+}
+
+func (b *blockingNotion) QueryDatabase(context.Context, string, *notionapi.DatabaseQueryRequest) (*notionapi.DatabaseQueryResponse, error) {
+	b.call()
+	return &notionapi.DatabaseQueryResponse{}, nil
+}
+
+func (b *blockingNotion) GetPage(context.Context, string) (*notionapi.Page, error) {
+	b.call()
+	return &notionapi.Page{}, nil
+}
+
+func (b *blockingNotion) UpdatePage(context.Context, string, *notionapi.PageUpdateRequest) (*notionapi.Page, error) {
+	b.call()
+	return &notionapi.Page{}, nil
+}
+
+func TestLimitedNotion(t *testing.T) {
+	const limit = 3
+	backend := &blockingNotion{release: make(chan struct{})}
+	limited := newLimitedNotion(backend, limit)
+
+	// Verschiedene Aufrufarten teilen sich dieselben Plätze
+	var wg sync.WaitGroup
+	for i := range 12 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			switch i % 3 {
+			case 0:
+				limited.QueryDatabase(t.Context(), "db", nil)
+			case 1:
+				limited.GetPage(t.Context(), "page")
+			default:
+				limited.UpdatePage(t.Context(), "page", nil)
+			}
+		}()
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if inFlight, _ := backend.current(); inFlight == limit {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Limit wird nie erreicht")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	// Weitere Aufrufe warten, solange alle Plätze belegt sind
+	time.Sleep(20 * time.Millisecond)
+	if inFlight, _ := backend.current(); inFlight != limit {
+		t.Errorf("%d gleichzeitige Aufrufe, erwartet %d", inFlight, limit)
+	}
+
+	close(backend.release)
+	wg.Wait()
+	if _, maxSeen := backend.current(); maxSeen != limit {
+		t.Errorf("höchstens %d gleichzeitige Aufrufe, erwartet %d", maxSeen, limit)
+	}
+}
+
+func TestLimitedNotionContextCanceled(t *testing.T) {
+	backend := &blockingNotion{release: make(chan struct{})}
+	limited := newLimitedNotion(backend, 1)
+	done := make(chan struct{})
+	go func() {
+		limited.GetPage(context.Background(), "page")
+		close(done)
+	}()
+	for inFlight, _ := backend.current(); inFlight == 0; inFlight, _ = backend.current() {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := limited.QueryDatabase(ctx, "db", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Fehler = %v, erwartet %v", err, context.DeadlineExceeded)
+	}
+
+	close(backend.release)
+	<-done
+}
+
+func TestNotionMaxConcurrentConfig(t *testing.T) {
+	ta := newTestApp(t, map[string]string{"NOTION_MAX_CONCURRENT": "2"})
+	limited, ok := ta.notion.(*limitedNotion)
+	if !ok {
+		t.Fatalf("Notion-Client = %T, erwartet *limitedNotion", ta.notion)
+	}
+	if cap(limited.slots) != 2 {
+		t.Errorf("%d Plätze, erwartet 2", cap(limited.slots))
+	}
+	// Der Demo-Ablauf funktioniert auch mit Limit
+	if w := ta.advance("1", "Demo Team"); w.Code != http.StatusOK {
+		t.Errorf("Status = %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// linkAttrPattern findet Link-Ziele in gerendertem HTML
+var linkAttrPattern = regexp.MustCompile(`(?:href|action)="([^"]*)"`)
+
+// brokenLink ist ein Link aus einem Template, der nicht aufgelöst werden kann
+type brokenLink struct {
+	Template string `json:"template"`
+	Link     string `json:"link"`
+	Reason   string `json:"reason"`
+}
+
+// handleAdminLinkCheck rendert alle Templates mit Beispieldaten und prüft die enthaltenen
+// internen Links: Die Route muss existieren, /next/ Links müssen auf eine echte Challenge zeigen.
+// Externe Links werden nicht abgerufen.
+func (app *App) handleAdminLinkCheck(router *gin.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Beispieldaten mit einer echten Challenge-ID, damit nur echte Fehler gemeldet werden
+		sampleID := ""
+		if challenges, err := app.getAllChallenges(ctx); err == nil && len(challenges) > 0 {
+			sampleID = strconv.Itoa(challenges[0].ID)
+		}
+
+		samples := app.linkCheckSamples()
+		names := make([]string, 0, len(samples))
+		for name := range samples {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		checked := 0
+		broken := []brokenLink{}
+		for _, name := range names {
+			data := samples[name]
+			if _, ok := data["challengeID"]; ok && sampleID != "" {
+				data["challengeID"] = sampleID
+			}
+
+			var buf bytes.Buffer
+			if err := app.templates.ExecuteTemplate(&buf, name, data); err != nil {
+				broken = append(broken, brokenLink{Template: name, Reason: fmt.Sprintf("Template-Fehler: %v", err)})
+				continue
+			}
+
+			for _, match := range linkAttrPattern.FindAllStringSubmatch(buf.String(), -1) {
+				link := html.UnescapeString(match[1])
+				if !internalLink(link) {
+					continue
+				}
+				checked++
+				if reason := app.checkInternalLink(ctx, router.Routes(), link); reason != "" {
+					broken = append(broken, brokenLink{Template: name, Link: link, Reason: reason})
+				}
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"checked": checked,
+			"broken":  broken,
+			"ok":      len(broken) == 0,
+		})
+	}
+}
+
+// linkCheckSamples passt die Beispieldaten an die aktiven Features an. Seiten und Links
+// abgeschalteter Features gibt es im Betrieb nicht und würden sonst als kaputt gemeldet.
+func (app *App) linkCheckSamples() map[string]gin.H {
+	samples := templateSamples()
+	samples["home.html"]["featureMVP"] = app.featureMVP
+	samples["home.html"]["featureLeaderboard"] = app.featureLeaderboard
+	samples["finished.html"]["redirectURL"] = app.finishRedirectURL
+	if !app.featureRegistration {
+		delete(samples, "register.html")
+		delete(samples["error.html"], "registerURL")
+	}
+	if app.eventPIN == "" {
+		delete(samples, "pin.html")
+	}
+	if !app.featureMVP {
+		delete(samples, "mvpgenerator.html")
+	}
+	if !app.featureLeaderboard {
+		delete(samples, "leaderboard.html")
+	}
+	return samples
+}
+
+// internalLink meldet, ob ein Link auf diese Anwendung zeigt (relativer Pfad)
+func internalLink(link string) bool {
+	return strings.HasPrefix(link, "/") && !strings.HasPrefix(link, "//")
+}
+
+// checkInternalLink prüft einen internen Link und liefert den Grund, falls er kaputt ist
+func (app *App) checkInternalLink(ctx context.Context, routes gin.RoutesInfo, link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return "ungültige URL"
+	}
+
+	found := false
+	for _, route := range routes {
+		if (route.Method == http.MethodGet || route.Method == http.MethodPost) && routeMatches(route.Path, u.Path) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return "keine Route für " + u.Path
+	}
+
+	if param, ok := strings.CutPrefix(u.Path, "/next/"); ok {
+		id, err := app.resolveChallengeParam(ctx, param)
+		if err == nil {
+			_, err = app.getChallenge(ctx, id)
+		}
+		if errors.Is(err, errChallengeNotFound) {
+			return "Challenge " + param + " existiert nicht"
+		}
+		if err != nil {
+			return fmt.Sprintf("Challenge %s konnte nicht geladen werden: %v", param, err)
+		}
+	}
+	return ""
+}
+
+// routeMatches vergleicht einen Pfad mit einem Gin-Routenmuster (:param, *wildcard)
+func routeMatches(pattern, path string) bool {
+	patternParts := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range patternParts {
+		if strings.HasPrefix(part, "*") {
+			return true
+		}
+		if i >= len(pathParts) {
+			return false
+		}
+		if !strings.HasPrefix(part, ":") && part != pathParts[i] {
+			return false
+		}
+		if strings.HasPrefix(part, ":") && pathParts[i] == "" {
+			return false
+		}
+	}
+	return len(patternParts) == len(pathParts)
+}
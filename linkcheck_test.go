@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestRouteMatches(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{pattern: "/", path: "/", want: true},
+		{pattern: "/leaderboard", path: "/leaderboard", want: true},
+		{pattern: "/leaderboard", path: "/leaderboard/", want: true},
+		{pattern: "/next/:id", path: "/next/3", want: true},
+		{pattern: "/next/:id", path: "/next/", want: false},
+		{pattern: "/next/:id", path: "/next/3/extra", want: false},
+		{pattern: "/static/*filepath", path: "/static/css/app.css", want: true},
+		{pattern: "/api/teams/:team/mvp", path: "/api/teams/Demo/mvp", want: true},
+		{pattern: "/api/teams/:team/mvp", path: "/api/teams/Demo", want: false},
+	}
+	for _, tt := range tests {
+		if got := routeMatches(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("routeMatches(%q, %q) = %v, erwartet %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestInternalLink(t *testing.T) {
+	tests := map[string]bool{
+		"/leaderboard":         true,
+		"/next/1?team=x":       true,
+		"//evil.example.org/":  false,
+		"https://example.org/": false,
+		"#oben":                false,
+		"":                     false,
+	}
+	for link, want := range tests {
+		if got := internalLink(link); got != want {
+			t.Errorf("internalLink(%q) = %v, erwartet %v", link, got, want)
+		}
+	}
+}
+
+func TestAdminLinkCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        map[string]string
+		home       string // eigene home.html ("": mitgeliefert)
+		wantBroken []brokenLink
+	}{
+		{name: "mitgelieferte Templates"},
+		{
+			name: "alle Features aktiv",
+			env: map[string]string{
+				"FEATURE_REGISTRATION": "true",
+				"EVENT_PIN":            "1234",
+				"FINISH_REDIRECT_URL":  "/leaderboard",
+			},
+		},
+		{
+			name: "Weiterleitung auf abgeschaltete Bestenliste",
+			env:  map[string]string{"FEATURE_LEADERBOARD": "false", "FINISH_REDIRECT_URL": "/leaderboard"},
+			wantBroken: []brokenLink{
+				{Template: "finished.html", Link: "/leaderboard", Reason: "keine Route für /leaderboard"},
+			},
+		},
+		{
+			name: "Links auf Challenges",
+			home: `<a href="/next/2">Kirchturm</a> <a href="/next/demo-rathaus">Rathaus</a> <a href="/next/99">Schild 99</a>`,
+			wantBroken: []brokenLink{
+				{Template: "home.html", Link: "/next/99", Reason: "Challenge 99 existiert nicht"},
+			},
+		},
+		{
+			name: "unbekannte Route",
+			home: `<a href="/bestenliste">Bestenliste</a> <form action="/leaderboard"></form> <a href="https://example.org/gibtsnicht">extern</a>`,
+			wantBroken: []brokenLink{
+				{Template: "home.html", Link: "/bestenliste", Reason: "keine Route für /bestenliste"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, tt.env)
+			if tt.home != "" {
+				if _, err := ta.templates.New("home.html").Parse(tt.home); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			w := ta.admin(http.MethodGet, "/admin/linkcheck", "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+			var result struct {
+				Checked int          `json:"checked"`
+				Broken  []brokenLink `json:"broken"`
+				OK      bool         `json:"ok"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+				t.Fatal(err)
+			}
+
+			if result.Checked == 0 {
+				t.Error("keine Links geprüft")
+			}
+			if !slices.Equal(result.Broken, tt.wantBroken) {
+				t.Errorf("broken = %+v, erwartet %+v", result.Broken, tt.wantBroken)
+			}
+			if result.OK != (len(tt.wantBroken) == 0) {
+				t.Errorf("ok = %v", result.OK)
+			}
+		})
+	}
+}
+
+func TestAdminLinkCheckRequiresToken(t *testing.T) {
+	ta := newTestApp(t, nil)
+	if w := ta.do(http.MethodGet, "/admin/linkcheck", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("Status = %d, erwartet %d", w.Code, http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setupLogging konfiguriert den globalen Logger über LOG_LEVEL (debug/info/warn/error)
+// und LOG_FORMAT (text/json). Auch Ausgaben über das log-Paket laufen danach über slog.
+func setupLogging(level, format string) error {
+	var lvl slog.Level
+	switch strings.ToLower(level) {
+	case "debug":
+		lvl = slog.LevelDebug
+	case "", "info":
+		lvl = slog.LevelInfo
+	case "warn", "warning":
+		lvl = slog.LevelWarn
+	case "error":
+		lvl = slog.LevelError
+	default:
+		return fmt.Errorf("LOG_LEVEL %q ist ungültig (debug, info, warn, error)", level)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "", "text":
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	case "json":
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	default:
+		return fmt.Errorf("LOG_FORMAT %q ist ungültig (text, json)", format)
+	}
+
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+func logf(level slog.Level, format string, args ...any) {
+	logger := slog.Default()
+	if !logger.Enabled(context.Background(), level) {
+		return
+	}
+	logger.Log(context.Background(), level, fmt.Sprintf(format, args...))
+}
+
+// debugf loggt ausführliche Details, z.B. einzelne Schritte pro Request
+func debugf(format string, args ...any) { logf(slog.LevelDebug, format, args...) }
+
+// infof loggt normale Betriebsereignisse
+func infof(format string, args ...any) { logf(slog.LevelInfo, format, args...) }
+
+// warnf loggt Auffälligkeiten, die den Betrieb nicht verhindern
+func warnf(format string, args ...any) { logf(slog.LevelWarn, format, args...) }
+
+// errorf loggt Fehler
+func errorf(format string, args ...any) { logf(slog.LevelError, format, args...) }
+
+// parseAccessLog liest ACCESS_LOG (on/off, Standard: on)
+func parseAccessLog(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "", "on", "true":
+		return true, nil
+	case "off", "false":
+		return false, nil
+	default:
+		return false, fmt.Errorf("ACCESS_LOG %q ist ungültig (on, off)", s)
+	}
+}
+
+// accessLog ersetzt Gins Standard-Logger: eine strukturierte Zeile pro Request über slog,
+// damit LOG_FORMAT=json auch für Zugriffe gilt. 4xx wird als Warnung, 5xx als Fehler geloggt.
+func accessLog(c *gin.Context) {
+	start := time.Now()
+	path := c.Request.URL.Path
+
+	c.Next()
+
+	status := c.Writer.Status()
+	level := slog.LevelInfo
+	switch {
+	case status >= 500:
+		level = slog.LevelError
+	case status >= 400:
+		level = slog.LevelWarn
+	}
+
+	slog.LogAttrs(c.Request.Context(), level, "request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", path),
+		slog.Int("status", status),
+		slog.Duration("latency", time.Since(start)),
+		slog.String("clientIP", c.ClientIP()),
+	)
+}
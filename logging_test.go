@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// captureLog richtet das Logging mit level und format ein, führt fn aus und liefert die
+// Ausgabe auf stderr. Danach gilt wieder die Testkonfiguration aus TestMain.
+func captureLog(t *testing.T, level, format string, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() {
+		os.Stderr = stderr
+		if err := setupLogging("error", ""); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	if err := setupLogging(level, format); err != nil {
+		t.Fatal(err)
+	}
+	fn()
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestSetupLogging(t *testing.T) {
+	tests := []struct {
+		level     string
+		wantDebug bool
+		wantInfo  bool
+		wantWarn  bool
+	}{
+		{level: "debug", wantDebug: true, wantInfo: true, wantWarn: true},
+		{level: "", wantInfo: true, wantWarn: true},
+		{level: "info", wantInfo: true, wantWarn: true},
+		{level: "WARN", wantWarn: true},
+		{level: "error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			out := captureLog(t, tt.level, "text", func() {
+				debugf("debug-zeile %d", 1)
+				infof("info-zeile %d", 2)
+				warnf("warn-zeile %d", 3)
+				errorf("error-zeile %d", 4)
+			})
+			for line, want := range map[string]bool{
+				"debug-zeile 1": tt.wantDebug,
+				"info-zeile 2":  tt.wantInfo,
+				"warn-zeile 3":  tt.wantWarn,
+				"error-zeile 4": true,
+			} {
+				if strings.Contains(out, line) != want {
+					t.Errorf("%q geloggt = %v, erwartet %v:\n%s", line, !want, want, out)
+				}
+			}
+		})
+	}
+}
+
+func TestSetupLoggingJSON(t *testing.T) {
+	out := captureLog(t, "info", "json", func() { infof("Team %s gestartet", "Demo") })
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(out), &entry); err != nil {
+		t.Fatalf("keine JSON-Zeile: %q", out)
+	}
+	if entry["level"] != "INFO" || entry["msg"] != "Team Demo gestartet" {
+		t.Errorf("Eintrag = %v", entry)
+	}
+}
+
+func TestSetupLoggingInvalid(t *testing.T) {
+	for _, tt := range []struct{ level, format string }{{"trace", "text"}, {"info", "xml"}} {
+		if err := setupLogging(tt.level, tt.format); err == nil {
+			t.Errorf("setupLogging(%q, %q) ohne Fehler", tt.level, tt.format)
+		}
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	tests := []struct {
+		name       string
+		accessLog  string
+		path       string
+		wantLine   bool
+		wantLevel  string
+		wantStatus float64
+	}{
+		{name: "Erfolg", accessLog: "on", path: "/version", wantLine: true, wantLevel: "INFO", wantStatus: 200},
+		{name: "unbekannte Route", accessLog: "", path: "/gibt-es-nicht", wantLine: true, wantLevel: "WARN", wantStatus: 404},
+		{name: "abgeschaltet", accessLog: "off", path: "/version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"ACCESS_LOG": tt.accessLog})
+			out := captureLog(t, "info", "json", func() {
+				req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+				req.RemoteAddr = "192.0.2.7:4711"
+				ta.handler.ServeHTTP(httptest.NewRecorder(), req)
+			})
+
+			var entry map[string]any
+			for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+				var candidate map[string]any
+				if json.Unmarshal([]byte(line), &candidate) == nil && candidate["msg"] == "request" {
+					entry = candidate
+				}
+			}
+			if (entry != nil) != tt.wantLine {
+				t.Fatalf("Zugriffszeile = %v, erwartet %v:\n%s", entry, tt.wantLine, out)
+			}
+			if entry == nil {
+				return
+			}
+
+			want := map[string]any{
+				"level":    tt.wantLevel,
+				"method":   "GET",
+				"path":     tt.path,
+				"status":   tt.wantStatus,
+				"clientIP": "192.0.2.7",
+			}
+			for key, value := range want {
+				if entry[key] != value {
+					t.Errorf("%s = %v, erwartet %v", key, entry[key], value)
+				}
+			}
+			if _, ok := entry["latency"].(float64); !ok {
+				t.Errorf("latency fehlt: %v", entry)
+			}
+		})
+	}
+}
@@ -3,105 +3,429 @@ package main
 import (
 	"context"
 	"embed"
+	"errors"
 	"fmt"
 	"html/template"
 	"log"
 	"net/http"
-	"os"
+	"net/url"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"math/rand"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"github.com/jomei/notionapi"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 //go:embed templates/*
 var templates embed.FS
 
+// requiredTemplates listet alle Templates, die von den Handlern gerendert werden
+var requiredTemplates = []string{
+	"home.html",
+	"teamform.html",
+	"error.html",
+	"finished.html",
+	"redirect.html",
+	"mvpgenerator.html",
+	"leaderboard.html",
+	"confirm.html",
+	"register.html",
+	"pin.html",
+	"notyet.html",
+	"noroute.html",
+}
+
 // App enthält alle App-Komponenten
 type App struct {
-	notion        *notionapi.Client
-	teamsDBID     string
-	challengeDBID string
-	templates     *template.Template
+	notion               notionService
+	notionSwap           *swappableNotion // nil im DEMO_MODE
+	teamsDBID            string
+	challengeDBIDs       []string
+	adminToken           string
+	debug                bool
+	production           bool // PRODUCTION: keine Fehlerdetails für Besucher
+	redirectDelay        int
+	completionFormat     string
+	showDescription      bool
+	challengeURLTemplate string
+	warmup               bool
+	confirmAdvance       bool
+	honeypot             bool
+	finishMessage        string
+	confetti             bool
+	finishRedirectURL    string
+	finishRedirectDelay  int
+	featureMVP           bool
+	mvpIdeas             []mvpIdea
+	mvpUsed              *mvpUsedSet
+	featureLeaderboard   bool
+	featureRegistration  bool
+	teamNotFoundAction   string
+	emptyRouteAction     string
+	fuzzyMaxDistance     int
+	fuzzyAutoAccept      bool
+	teamLookupRetry      time.Duration
+	matchMode            string
+	matchPipeline        []string
+	teamInputMode        string
+	positionMode         string
+	eventWindow          eventWindow
+	rememberTeam         bool
+	sessionSecret        string
+	eventPIN             string
+	pendingWrites        *pendingWrites
+	teamLocks            *teamLocks
+	answerConfig         answerConfig
+	answerAttempts       *answerAttempts
+	pinAttempts          *answerAttempts
+	arrivals             *arrivals
+	defaultLanguage      string
+	metrics              bool
+	finishMode           string
+	finishCount          int
+	startChallengeID     int
+	startURL             string
+	baseURL              string
+	allowedOrigins       []string
+	demoMode             bool
+	startedAt            time.Time
+	templates            *template.Template
+	cache                *appCache
+	events               EventLogger
+	updates              *eventBuffer // nil bei UPDATES_BUFFER=0
+	frozenLeaderboard    *leaderboardFreeze
+	configValues         map[string]string
 }
 
 func main() {
 	// .env Datei laden
-	if err := godotenv.Load(); err != nil {
-		log.Println("Keine .env Datei gefunden, nutze Umgebungsvariablen")
+	envErr := godotenv.Load()
+
+	// Konfiguration aus CONFIG_FILE und Umgebung (Umgebungsvariablen haben Vorrang)
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Logging so früh wie möglich konfigurieren
+	if err := setupLogging(cfg.LogLevel, cfg.LogFormat); err != nil {
+		log.Fatal(err)
+	}
+	if envErr != nil {
+		infof("Keine .env Datei gefunden, nutze Umgebungsvariablen")
+	}
+
+	app, err := newApp(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Optional: Tracing per OpenTelemetry (nur mit OTEL_EXPORTER_OTLP_ENDPOINT)
+	shutdownTracing, err := setupTracing(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer shutdownTracing(context.Background())
+
+	r := app.router(cfg)
+
+	// Start-Challenge für den "Begin"-Link; eine konfigurierte ID muss existieren
+	start, err := app.resolveStartChallenge(context.Background(), app.startChallengeID)
+	switch {
+	case err != nil && app.startChallengeID > 0:
+		log.Fatal(err)
+	case err != nil:
+		warnf("Keine Start-Challenge gefunden, Startseite ohne Start-Link: %v", err)
+	default:
+		app.startURL = start.URL
+		infof("Start-Challenge: %d (%s)", start.ID, start.Title)
+	}
+
+	// Doppelte Teamnamen führen zu willkürlichen Treffern in findTeamPage
+	app.warnDuplicateTeams(context.Background())
+	app.warnDuplicateChallengeIDs(context.Background())
+	app.warnPrerequisiteCycles(context.Background())
+
+	// Optional: Caches vor dem ersten Request füllen
+	if app.warmup {
+		app.warmUp(context.Background())
+	}
+
+	// Server starten
+	port := cfg.Port
+	srv := newHTTPServer(":"+port, r, cfg.Server)
+	if err := checkHTTP2(srv); err != nil {
+		log.Fatal(err)
+	}
+	if cfg.Server.tlsEnabled() {
+		infof("Server startet auf https://localhost:%s (HTTP/2)", port)
+	} else {
+		infof("Server startet auf http://localhost:%s", port)
+	}
+	if err := serve(srv, cfg.Server); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
+
+// newApp baut die App aus der geprüften Konfiguration
+func newApp(cfg *Config) (*App, error) {
+	// Notion Client initialisieren, austauschbar für POST /admin/rotate-token
+	swappable := newSwappableNotion(newNotionClient(cfg.NotionToken))
+	var notion notionService = swappable
+	if cfg.DemoMode {
+		swappable = nil
+		notion = newDemoStore()
+		warnf("DEMO_MODE aktiv: Teams und Challenges liegen nur im Speicher, Änderungen gehen beim Neustart verloren")
+	}
+
+	// Optional: gleichzeitige Notion-Aufrufe über alle Requests hinweg begrenzen
+	if cfg.NotionMaxConcurrent > 0 {
+		notion = newLimitedNotion(notion, cfg.NotionMaxConcurrent)
+		infof("Maximal %d gleichzeitige Notion-Aufrufe", cfg.NotionMaxConcurrent)
 	}
 
-	// Konfiguration aus Umgebung
-	notionToken := os.Getenv("NOTION_TOKEN")
-	teamsDBID := os.Getenv("TEAMS_DB_ID")
-	challengeDBID := os.Getenv("CHALLENGES_DB_ID")
+	// Templates laden (verfügbare Funktionen: siehe templateFuncs)
+	tmpl, err := template.New("").Funcs(templateFuncs).ParseFS(templates, "templates/*.html")
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Laden der Templates: %w", err)
+	}
+	if err := checkTemplates(tmpl); err != nil {
+		return nil, err
+	}
 
-	if notionToken == "" || teamsDBID == "" || challengeDBID == "" {
-		log.Fatal("NOTION_TOKEN, TEAMS_DB_ID und CHALLENGES_DB_ID müssen in .env gesetzt sein")
+	events, err := newEventLogger(cfg.EventLog)
+	if err != nil {
+		return nil, err
+	}
+	// Fortschritte zusätzlich im Speicher halten, für GET /api/updates
+	var updates *eventBuffer
+	if cfg.UpdatesBuffer > 0 {
+		updates = newEventBuffer(cfg.UpdatesBuffer)
+		events = teeEventLogger{events, updates}
 	}
 
-	// Notion Client initialisieren
-	client := notionapi.NewClient(notionapi.Token(notionToken))
+	sessionSecret, err := newSessionSecret(cfg.SessionSecret)
+	if err != nil {
+		return nil, err
+	}
 
-	// Templates laden
-	tmpl, err := template.ParseFS(templates, "templates/*.html")
+	// Vergebene MVPs, optional über Neustarts hinweg in MVP_STATE_FILE
+	mvpUsed, err := newMVPUsedSet(cfg.MVPStateFile)
 	if err != nil {
-		log.Fatal("Fehler beim Laden der Templates:", err)
+		return nil, err
 	}
 
-	app := &App{
-		notion:        client,
-		teamsDBID:     teamsDBID,
-		challengeDBID: challengeDBID,
-		templates:     tmpl,
+	// Optional: eigene Schreibstände kurzzeitig über veraltete Lesezugriffe legen
+	var writes *pendingWrites
+	if cfg.OptimisticWrites {
+		writes = newPendingWrites()
 	}
 
+	// Standardmäßig aktiv: Weiterleitungen pro Team serialisieren (TEAM_LOCK=false schaltet ab)
+	var locks *teamLocks
+	if cfg.TeamLock {
+		locks = newTeamLocks()
+	}
+
+	return &App{
+		notion:               notion,
+		notionSwap:           swappable,
+		teamsDBID:            cfg.TeamsDBID,
+		challengeDBIDs:       cfg.ChallengeDBIDs,
+		adminToken:           cfg.AdminToken,
+		debug:                cfg.Debug,
+		production:           cfg.Production,
+		redirectDelay:        cfg.RedirectDelay,
+		completionFormat:     cfg.CompletionFormat,
+		showDescription:      cfg.ShowDescription,
+		challengeURLTemplate: cfg.ChallengeURLTemplate,
+		warmup:               cfg.Warmup,
+		confirmAdvance:       cfg.ConfirmAdvance,
+		honeypot:             cfg.Honeypot,
+		finishMessage:        cfg.FinishMessage,
+		confetti:             cfg.Confetti,
+		finishRedirectURL:    cfg.FinishRedirectURL,
+		finishRedirectDelay:  cfg.FinishRedirectDelay,
+		featureMVP:           cfg.FeatureMVP,
+		mvpIdeas:             cfg.MVPIdeas,
+		mvpUsed:              mvpUsed,
+		featureLeaderboard:   cfg.FeatureLeaderboard,
+		featureRegistration:  cfg.FeatureRegistration,
+		teamNotFoundAction:   cfg.TeamNotFoundAction,
+		emptyRouteAction:     cfg.EmptyRouteAction,
+		fuzzyMaxDistance:     cfg.FuzzyMaxDistance,
+		fuzzyAutoAccept:      cfg.FuzzyAutoAccept,
+		teamLookupRetry:      cfg.TeamLookupRetry,
+		matchMode:            cfg.MatchMode,
+		matchPipeline:        cfg.MatchPipeline,
+		teamInputMode:        cfg.TeamInputMode,
+		positionMode:         cfg.PositionMode,
+		eventWindow:          cfg.EventWindow,
+		rememberTeam:         cfg.RememberTeam,
+		sessionSecret:        sessionSecret,
+		eventPIN:             cfg.EventPIN,
+		pendingWrites:        writes,
+		teamLocks:            locks,
+		answerConfig:         cfg.Answers,
+		answerAttempts:       newAnswerAttempts(),
+		pinAttempts:          newPINAttempts(),
+		arrivals:             newArrivals(),
+		defaultLanguage:      cfg.DefaultLanguage,
+		metrics:              cfg.Metrics,
+		finishMode:           cfg.FinishMode,
+		finishCount:          cfg.FinishCount,
+		startChallengeID:     cfg.StartChallengeID,
+		baseURL:              cfg.BaseURL,
+		allowedOrigins:       cfg.AllowedOrigins,
+		demoMode:             cfg.DemoMode,
+		startedAt:            time.Now(),
+		cache:                newAppCache(),
+		events:               events,
+		updates:              updates,
+		frozenLeaderboard:    &leaderboardFreeze{},
+		configValues:         cfg.Values,
+		templates:            tmpl,
+	}, nil
+}
+
+// router richtet Middleware und alle Routes ein
+func (app *App) router(cfg *Config) *gin.Engine {
 	// Gin Router einrichten
-	r := gin.Default()
+	r := gin.New()
+	// Client-IPs (PIN-Sperre, Logs) nur über X-Forwarded-For bekannter Proxies
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		warnf("TRUSTED_PROXIES nicht übernommen: %v", err)
+	}
+	r.Use(gin.Recovery())
+	if cfg.AccessLog {
+		r.Use(accessLog)
+	}
+	r.Use(tracingMiddleware, app.notionCallsMiddleware)
+	if cfg.MaxConcurrent > 0 {
+		r.Use(concurrencyLimit(cfg.MaxConcurrent, cfg.QueueTimeout))
+		infof("Maximal %d gleichzeitige Requests (Warteschlange %v)", cfg.MaxConcurrent, cfg.QueueTimeout)
+	}
 
 	// Routes
-	r.GET("/", app.handleHome)
-	r.GET("/next/:id", app.handleChallengeForm)
-	r.POST("/next/:id", app.handleNextChallenge)
-	r.GET("/mvpgenerator", app.handleMVPGenerator)
+	r.GET("/", cacheControl(cacheShort), app.handleHome)
+	r.GET("/next/:id", cacheControl(cacheNoStore), app.requirePIN, app.handleChallengeForm)
+	r.POST("/next/:id", cacheControl(cacheNoStore), app.requirePIN, app.handleNextChallenge)
+	if app.eventPIN != "" {
+		r.POST("/pin", cacheControl(cacheNoStore), app.handlePIN)
+	}
+	r.GET("/version", cacheControl(cacheNoStore), app.handleVersion)
+	if app.metrics {
+		r.GET("/metrics", cacheControl(cacheNoStore), app.handleMetrics)
+	}
 
-	// Server starten
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// API für externe Frontends; ohne ALLOWED_ORIGINS gilt nur Same-Origin
+	api := r.Group("/api")
+	if len(app.allowedOrigins) > 0 {
+		api.Use(corsMiddleware(app.allowedOrigins))
+		api.OPTIONS("/*path", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	}
+	api.GET("/order", cacheControl(cacheShort), app.handleAPIOrder)
+	api.GET("/challenges/search", cacheControl(cacheShort), app.handleAPIChallengeSearch)
+	api.GET("/challenges/:id", cacheControl(cacheShort), app.handleAPIChallenge)
+	api.GET("/teams/search", cacheControl(cacheShort), app.handleAPITeamSearch)
+	api.GET("/teams/:team/timeline", cacheControl(cacheNoStore), app.handleAPITeamTimeline)
+	api.GET("/teams/:team/eta", cacheControl(cacheNoStore), app.handleAPITeamETA)
+	api.GET("/teams/:team/badges", cacheControl(cacheNoStore), app.handleAPITeamBadges)
+	api.GET("/progress", cacheControl(cacheNoCache), app.handleAPIProgress)
+	if app.updates != nil {
+		api.GET("/updates", cacheControl(cacheNoStore), app.handleAPIUpdates)
+	}
+
+	// Optionale Features nur registrieren, wenn sie aktiviert sind
+	if app.featureMVP {
+		r.GET("/mvpgenerator", cacheControl(cacheNoStore), app.handleMVPGenerator)
+		api.GET("/teams/:team/mvp", cacheControl(cacheNoStore), app.handleAPITeamMVP)
+	}
+	if app.featureLeaderboard {
+		r.GET("/leaderboard", cacheControl(cacheNoCache), app.handleLeaderboard)
+	}
+	// Selbst-Registrierung legt Teams an und ist daher nur auf ausdrücklichen Wunsch aktiv
+	if app.featureRegistration {
+		r.GET("/register", cacheControl(cacheNoStore), app.handleRegisterForm)
+		r.POST("/register", cacheControl(cacheNoStore), app.handleRegister)
+	}
+
+	// Template-Vorschau nur im Debug-Modus, nie in Produktion
+	if app.debug {
+		r.GET("/debug/template/:name", cacheControl(cacheNoStore), app.handleDebugTemplate)
+	}
+
+	// Admin-Routen nur, wenn ein ADMIN_TOKEN gesetzt ist
+	if app.adminToken != "" {
+		admin := r.Group("/admin", cacheControl(cacheNoStore), app.requireAdmin)
+		admin.POST("/skip/:team/:id", app.handleAdminSkip)
+		admin.POST("/goto/:team/:id", app.handleAdminGoto)
+		admin.POST("/import-teams", app.handleAdminImportTeams)
+		admin.POST("/route/:team", app.handleAdminRoute)
+		admin.POST("/simulate/:team", app.handleAdminSimulate)
+		admin.GET("/duplicate-teams", app.handleAdminDuplicateTeams)
+		admin.GET("/duplicate-challenges", app.handleAdminDuplicateChallenges)
+		admin.GET("/diagnose", app.handleAdminDiagnose)
+		admin.GET("/linkcheck", app.handleAdminLinkCheck(r))
+		admin.GET("/url-preview", app.handleAdminURLPreview)
+		admin.GET("/config-template", app.handleAdminConfigTemplate)
+		admin.POST("/undo/:team", app.handleAdminUndo)
+		admin.GET("/positions", app.handleAdminPositions)
+		admin.GET("/peek/:team", app.handleAdminPeek)
+		admin.GET("/qr.zip", app.handleAdminQRZip)
+		admin.POST("/freeze", app.handleAdminFreeze)
+		admin.POST("/unfreeze", app.handleAdminUnfreeze)
+		admin.POST("/bypass/:team/:id", app.handleAdminBypassToken)
+		admin.POST("/cache/clear", app.handleAdminClearCache)
+		admin.POST("/rotate-token", app.handleAdminRotateToken)
+
+		// MVP-Verwaltung nur mit aktivem MVP-Feature, wie /mvpgenerator
+		if app.featureMVP {
+			admin.GET("/mvp-distribution", app.handleAdminMVPDistribution)
+			admin.POST("/mvp/:team", app.handleAdminAssignMVP)
+		}
 	}
-	log.Printf("Server startet auf http://localhost:%s", port)
-	r.Run(":" + port)
+
+	// Unbekannte Pfade: JSON für API-Clients, sonst die Fehlerseite
+	r.NoRoute(app.handleNotFound)
+	return r
 }
 
 // handleHome zeigt Startseite
 func (app *App) handleHome(c *gin.Context) {
 	c.Header("Content-Type", "text/html; charset=utf-8")
-	if err := app.templates.ExecuteTemplate(c.Writer, "home.html", nil); err != nil {
-		c.String(http.StatusInternalServerError, "Template-Fehler: %v", err)
+	if err := app.templates.ExecuteTemplate(c.Writer, "home.html", gin.H{
+		"featureMVP":         app.featureMVP,
+		"featureLeaderboard": app.featureLeaderboard,
+		"startURL":           app.startURL,
+	}); err != nil {
+		app.templateFailed(c, err)
 	}
 }
 
 // getAllTeamNames holt alle Teamnamen aus der Notion DB
-func (app *App) getAllTeamNames() ([]string, error) {
-	ctx := context.Background()
-	var teamNames []string
-
-	// Query, um alle Seiten aus der Team-DB zu holen
-	query := &notionapi.DatabaseQueryRequest{
-		PageSize: 100, // Annahme: Es gibt nicht mehr als 100 Teams
+func (app *App) getAllTeamNames(ctx context.Context) ([]string, error) {
+	if cached, ok := app.cache.cachedTeamNames(); ok {
+		return cached, nil
 	}
 
-	result, err := app.notion.Database.Query(ctx, notionapi.DatabaseID(app.teamsDBID), query)
+	var teamNames []string
+
+	// Alle Seiten aus der Team-DB holen, auch über mehrere Abfragen à 100 Teams
+	pages, err := app.listTeamPages(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("fehler beim Abfragen der Team-Datenbank: %w", err)
+		return nil, err
 	}
 
 	// Iteriere durch die Ergebnisse und extrahiere den Titel jeder Seite
-	for _, page := range result.Results {
+	for _, page := range pages {
 		// Die Titel-Eigenschaft hat keinen festen Namen, sie wird durch ihren Typ identifiziert.
 		for _, prop := range page.Properties {
 			if titleProp, ok := prop.(*notionapi.TitleProperty); ok {
@@ -114,12 +438,13 @@ func (app *App) getAllTeamNames() ([]string, error) {
 	}
 
 	if len(teamNames) == 0 {
-		return nil, fmt.Errorf("keine Teams in der Datenbank gefunden")
+		return nil, errNoTeams
 	}
 
 	// Sortiere die Teamnamen alphabetisch
-	sort.Strings(teamNames)
+	sortTeamNames(teamNames)
 
+	app.cache.storeTeamNames(teamNames)
 	return teamNames, nil
 }
 func (app *App) handleMVPGenerator(c *gin.Context) {
@@ -129,295 +454,519 @@ func (app *App) handleMVPGenerator(c *gin.Context) {
 
 }
 func (app *App) generateMVP(c *gin.Context) {
-	// Beispiel-MVP-Generierung (hier könnte eine komplexere Logik stehen)
-	mvpArray := []string{
-		"Portable Water Filter",
-		"Eco-Friendly Phone Stand",
-		"Leaf-Based Notebook",
-		"Natural Air Freshener",
-		"Solar-Powered Lantern",
-		"Pocket-Sized Board Game",
-		"Mini Bird Feeder",
-		"Portable Hammock",
-		"Eco Speaker Amplifier",
-		"Handmade Jewelry",
-		"Biodegradable Straw",
-		"Reusable Cutlery Set",
-		"Eco Toy Car",
-		"Compostable Food Container",
-		"Emergency Shelter Kit",
-		"Natural Bandage",
-		"Seed-Paper Business Card",
-		"Upcycled Coin Wallet",
-		"Outdoor Chess Set",
-		"DIY Kite",
-		"Portable Plant Pot",
-		"Zero-Waste Picnic Kit",
-		"Wind Chime",
-		"Stress Relief Toy",
-		"Eco Bracelet",
-		"Paper Recycling Kit",
-		"Biodegradable Soap Holder",
-		"Natural Toothbrush",
-		"Eco-Friendly Bag",
-		"Outdoor Survival Kit",
-		"Pocket Garden",
-		"Park-Themed Board Game",
-		"Compost Bin Prototype",
-		"Mini Solar Oven",
-		"Eco-Friendly Candle",
-		"Toy Drone Shell",
-		"Bird Call Whistle",
-		"Outdoor Gym Equipment",
-		"Eco Keychain",
-		"Rainwater Collector",
-		"DIY Frisbee",
-		"Eco Sunglasses",
-		"Reusable Coffee Sleeve",
-		"Portable Charger Holder",
-		"Nature Bookmark",
-		"Toy Boat",
-		"DIY Musical Instrument",
-		"Eco-Friendly Packaging",
-		"Foldable Stool",
-		"Eco Travel Mug",
-		"Mini Wind Turbine",
-		"Outdoor Meditation Mat",
-		"Portable Whiteboard",
-		"Emergency Cooking Stove",
-		"Toy Puzzle",
-		"Nature Art Frame",
-		"Eco Phone Case",
-		"Reusable Water Filter Straw",
-		"Picnic Blanket Prototype",
-		"Eco Bag Tag",
-		"DIY Pen Holder",
-		"Eco-Friendly Wallet",
-		"Upcycled Backpack",
-		"Biodegradable Plant Pot",
-		"Eco Toothpaste Holder",
-		"DIY Notebook",
-		"Park Cleaning Kit",
-		"Eco Coaster",
-		"Portable Light Reflector",
-		"Outdoor Cooking Kit",
-		"Eco-Friendly Umbrella",
-		"Eco-Friendly Badge",
-		"Mini Greenhouse",
-		"Eco-Friendly Soap Dish",
-		"Outdoor Relaxation Chair",
-		"Toy Rocket",
-		"Eco-Friendly Speaker",
-		"DIY Lamp",
-		"Eco-Friendly Calendar",
-		"Nature Camera Case",
-		"Eco-Friendly Shoes",
-		"Eco-Friendly Gloves",
-		"Outdoor Game Dice",
-		"DIY Jewelry Box",
-		"Eco Candle Holder",
-		"Portable Fire Starter",
-		"Eco Ashtray",
-		"Reusable Straw Holder",
-		"DIY Sunglass Holder",
-		"Eco Plant Sprayer",
-		"Toy Airplane",
-		"Mini Compost Bag",
-		"Eco-Friendly Watch",
-		"Portable Raincoat",
-		"Eco Pencil Case",
-		"Outdoor Card Game",
-		"Toy Binoculars",
-		"Eco Lantern",
-		"Pocket First Aid Kit",
-		"Eco Water Bottle",
-		"Eco Blanket",
-	}
-	// Zufälliges MVP auswählen
-	mvp := mvpArray[randRange(0, len(mvpArray))]
+	ideas := app.availableMVPIdeas()
+
+	// Anzahl der Vorschläge (?count=, Standard 1, begrenzt auf maxMVPCount)
+	count, err := strconv.Atoi(c.DefaultQuery("count", "1"))
+	if err != nil || count < 1 {
+		count = 1
+	}
+	if count > maxMVPCount {
+		count = maxMVPCount
+	}
+
+	// Zufällige, unterschiedliche und noch nicht vergebene MVPs auswählen
+	mvps := app.mvpUsed.assign(ideas, min(count, len(ideas)))
+
+	// JSON bleibt eine Liste von Namen, Bilder gibt es nur im Template
+	if c.Query("format") == "json" {
+		names := make([]string, len(mvps))
+		for i, mvp := range mvps {
+			names[i] = mvp.Name
+		}
+		c.JSON(http.StatusOK, names)
+		return
+	}
 
 	// Ergebnis an das Template übergeben
 	c.Header("Content-Type", "text/html; charset=utf-8")
 	if err := app.templates.ExecuteTemplate(c.Writer, "mvpgenerator.html", gin.H{
-		"mvp": mvp,
+		"mvp":   mvps[0],
+		"mvps":  mvps,
+		"count": count,
 	}); err != nil {
-		c.String(http.StatusInternalServerError, "Template-Fehler: %v", err)
+		app.templateFailed(c, err)
 	}
 }
 func randRange(min, max int) int {
 	return rand.Intn(max-min) + min
 }
 
+// maxMVPCount begrenzt die Anzahl der MVP-Vorschläge pro Anfrage
+const maxMVPCount = 10
+
+// pickRandom wählt n unterschiedliche Einträge zufällig aus (partieller Fisher-Yates)
+func pickRandom[T any](items []T, n int) []T {
+	if n > len(items) {
+		n = len(items)
+	}
+	pool := append([]T(nil), items...)
+	for i := 0; i < n; i++ {
+		j := randRange(i, len(pool))
+		pool[i], pool[j] = pool[j], pool[i]
+	}
+	return pool[:n]
+}
+
 // handleChallengeForm zeigt Formular für Teamname-Eingabe mit Dropdown
 func (app *App) handleChallengeForm(c *gin.Context) {
-	challengeID := c.Param("id")
+	challengeID, err := app.resolveChallengeParam(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		app.renderChallengeParamError(c, err)
+		return
+	}
+	app.renderChallengeForm(c, challengeID, teamForm{})
+}
+
+// formErrors ordnet Formularfeldern (Name wie im HTML) eine Fehlermeldung zu,
+// die das Template direkt am Feld anzeigt
+type formErrors map[string]string
+
+// teamForm ist der Zustand des Teamformulars, wenn es nach einer Einreichung erneut erscheint
+type teamForm struct {
+	Team   string      // vorausgewähltes Team (leer: gemerktes Team)
+	Answer answerState // Ergebnis einer falschen Antwort
+	Errors formErrors  // Validierungsfehler je Feld
+}
 
+// renderChallengeForm rendert das Teamformular, bei einer erneuten Anzeige mit dem Zustand aus form.
+// Einen Fehlerstatus setzt der Aufrufer vorher per c.Status.
+func (app *App) renderChallengeForm(c *gin.Context, challengeID string, form teamForm) {
 	// Alle Teamnamen aus Notion für das Dropdown holen
-	teamNames, err := app.getAllTeamNames()
+	teamNames, err := app.getAllTeamNames(c.Request.Context())
 	if err != nil {
-		log.Printf("Fehler beim Abrufen der Teamnamen: %v", err)
-		c.String(http.StatusInternalServerError, "Fehler beim Laden der Teamliste: %v", err)
+		errorf("Fehler beim Abrufen der Teamnamen: %v", err)
+		c.String(http.StatusInternalServerError, "%s", app.userError("Fehler beim Laden der Teamliste", err))
 		return
 	}
 
+	// Gemerktes Team vorauswählen, sofern es noch in der Liste steht
+	selectedTeam := form.Team
+	if selectedTeam == "" {
+		selectedTeam = app.rememberedTeam(c)
+	}
+	if !slices.Contains(teamNames, selectedTeam) {
+		selectedTeam = ""
+	}
+
+	// Teaser und optional die Beschreibung aus dem Inhalt der Challenge-Page anzeigen (Fehler sind nicht fatal)
+	var description template.HTML
+	var teaser string
+	answerRequired := false
+	if challenge, err := app.getChallenge(c.Request.Context(), challengeID); err == nil {
+		teaser = challenge.Teaser
+		answerRequired = challenge.Answer != "" || len(challenge.NextRules) > 0
+		if app.showDescription {
+			if description, err = app.challengeDescription(c.Request.Context(), challenge); err != nil {
+				errorf("Fehler beim Laden der Challenge-Beschreibung: %v", err)
+			}
+		}
+	}
+
+	// Im Suchmodus steht die Teamliste nicht im HTML, Vorschläge kommen über /api/teams/search
+	// Sonst kommen die Optionen als gecachtes Fragment, neu gerendert nur bei geänderter Teamliste
+	searchMode := app.teamInputMode == teamInputSearch
+	var teamOptions template.HTML
+	if !searchMode {
+		teamOptions = app.cache.teamOptionsHTML(selectedTeam)
+	}
+
 	c.Header("Content-Type", "text/html; charset=utf-8")
 	if err := app.templates.ExecuteTemplate(c.Writer, "teamform.html", gin.H{
-		"challengeID": challengeID,
-		"Teams":       teamNames, // Teamliste an das Template übergeben
+		"challengeID":    challengeID,
+		"teamOptions":    teamOptions,
+		"searchMode":     searchMode,
+		"minQuery":       minPartialMatchLength,
+		"selectedTeam":   selectedTeam,
+		"description":    description,
+		"teaser":         teaser,
+		"answerRequired": answerRequired,
+		"answer":         form.Answer,
+		"errors":         form.Errors,
+		"honeypot":       app.honeypot,
+		"honeypotKey":    honeypotField,
 	}); err != nil {
-		c.String(http.StatusInternalServerError, "Template-Fehler: %v", err)
+		app.templateFailed(c, err)
 	}
 }
 
+// honeypotField ist der Name des versteckten Formularfelds, das echte Nutzer leer lassen
+const honeypotField = "website"
+
+// renderChallengeParamError zeigt die Fehlerseite für eine unbekannte Challenge-ID bzw. -Slug
+func (app *App) renderChallengeParamError(c *gin.Context, err error) {
+	status := http.StatusNotFound
+	message := "Challenge nicht gefunden"
+	if !errors.Is(err, errChallengeNotFound) {
+		errorf("Fehler beim Auflösen der Challenge %s: %v", c.Param("id"), err)
+		status = http.StatusInternalServerError
+		message = "Fehler beim Laden der Challenge"
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(status)
+	app.templates.ExecuteTemplate(c.Writer, "error.html", gin.H{
+		"error": message,
+	})
+}
+
 // handleNextChallenge verarbeitet Team und leitet zur nächsten Challenge weiter
 func (app *App) handleNextChallenge(c *gin.Context) {
-	currentChallengeID := c.Param("id")
-	teamName := c.PostForm("team")
+	// Bots füllen das versteckte Feld aus; sie bekommen dieselbe Seite wie ein unbekanntes Team
+	if app.honeypot && c.PostForm(honeypotField) != "" {
+		warnf("Honeypot ausgelöst von %s, Anfrage verworfen", c.ClientIP())
+		app.logEvent(c.PostForm("team"), c.Param("id"), "", outcomeRejected, false)
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		app.templates.ExecuteTemplate(c.Writer, "error.html", gin.H{
+			"error": "Team nicht gefunden",
+		})
+		return
+	}
+
+	currentChallengeID, err := app.resolveChallengeParam(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		app.renderChallengeParamError(c, err)
+		return
+	}
+
+	// Join-Code hat Vorrang vor der Auswahl im Dropdown
+	teamInput := strings.TrimSpace(c.PostForm("code"))
+	if teamInput == "" {
+		teamInput = trimTeamName(c.PostForm("team"))
+	}
 
-	if teamName == "" {
-		c.String(http.StatusBadRequest, "Teamname erforderlich")
+	if teamInput == "" {
+		c.Status(http.StatusBadRequest)
+		app.renderChallengeForm(c, currentChallengeID, teamForm{
+			Errors: formErrors{"team": "Bitte wählt euer Team aus oder gebt euren Team-Code ein."},
+		})
 		return
 	}
 
-	log.Printf("Suche Team: %s mit Challenge ID: %s", teamName, currentChallengeID)
+	debugf("Suche Team: %s mit Challenge ID: %s", teamInput, currentChallengeID)
 
-	// Finde Team-Page in Teams-DB
-	teamPageID, err := app.findTeamPage(teamName)
+	// Finde Team-Page in Teams-DB (per Join-Code oder Name)
+	teamPageID, teamName, err := app.resolveTeamWithRetry(c.Request.Context(), teamInput)
 	if err != nil || teamPageID == "" {
-		log.Printf("Team nicht gefunden: %s", teamName)
+		debugf("Team nicht gefunden: %s", teamName)
+		app.logEvent(teamInput, currentChallengeID, "", outcomeTeamNotFound, false)
+		data := gin.H{"error": "Team nicht gefunden"}
+		var suggestions *teamSuggestionsError
+		if errors.As(err, &suggestions) {
+			data["suggestions"] = suggestions.Suggestions
+			data["challengeID"] = currentChallengeID
+		}
+
+		// Optional: direkt zur Registrierung mit dem eingegebenen Namen
+		if app.featureRegistration && app.teamNotFoundAction != teamNotFoundError {
+			target := registerURL(teamInput, currentChallengeID)
+			if app.teamNotFoundAction == teamNotFoundRedirect && suggestions == nil {
+				c.Redirect(http.StatusSeeOther, target)
+				return
+			}
+			data["registerURL"] = target
+		}
 		c.Header("Content-Type", "text/html; charset=utf-8")
+		app.templates.ExecuteTemplate(c.Writer, "error.html", data)
+		return
+	}
+
+	debugf("Team-Page gefunden: %s", teamPageID)
+	traceAttributes(c.Request.Context(),
+		attribute.String("team", teamName),
+		attribute.String("challenge.id", currentChallengeID),
+	)
+
+	// Admin-Bypass: vom Organisator ausgestellte Links überspringen die Bestätigung
+	adminAssisted := false
+	if token := c.PostForm(bypassFormField); token != "" {
+		if err := verifyBypassToken(app.adminToken, token, teamName, currentChallengeID, time.Now()); err != nil {
+			warnf("Bypass-Token für Team %s abgelehnt: %v", teamName, err)
+			c.Header("Content-Type", "text/html; charset=utf-8")
+			c.Status(http.StatusForbidden)
+			app.templates.ExecuteTemplate(c.Writer, "error.html", gin.H{
+				"error": app.userError("Ungültiger Admin-Link", err),
+			})
+			return
+		}
+		adminAssisted = true
+		infof("Admin-unterstützte Weiterleitung für Team %s ab Challenge %s", teamName, currentChallengeID)
+	}
+
+	// Außerhalb von EVENT_START/EVENT_END geht es nur mit Bypass-Token weiter
+	if reason := app.eventWindow.inactiveReason(time.Now()); reason != "" && !adminAssisted {
+		app.logEvent(teamName, currentChallengeID, "", outcomeInactive, false)
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		c.Status(http.StatusForbidden)
 		app.templates.ExecuteTemplate(c.Writer, "error.html", gin.H{
-			"error": "Team nicht gefunden",
+			"error": reason,
 		})
 		return
 	}
 
-	log.Printf("Team-Page gefunden: %s", teamPageID)
+	app.rememberTeamCookie(c, teamName)
+
+	// Sprache der folgenden Seiten: Team-Page vor Browser vor DEFAULT_LANGUAGE
+	lang := app.languageFor(c.Request.Context(), c, teamPageID)
+
+	// Stationen mit MinDwellSeconds erst nach Ablauf der Mindestzeit verlassen (Bypass-Links ausgenommen)
+	if !adminAssisted && !app.checkDwell(c, teamPageID, teamName, currentChallengeID, lang) {
+		return
+	}
+
+	// Challenges mit "Answer" verlangen die richtige Antwort (Bypass-Links ausgenommen)
+	if !adminAssisted && !app.checkAnswer(c, teamPageID, teamName, currentChallengeID) {
+		return
+	}
+
+	// Optional: Abschluss erst nach Bestätigung festhalten
+	if app.confirmAdvance && !adminAssisted && c.PostForm("confirm") != "yes" {
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		if err := app.templates.ExecuteTemplate(c.Writer, "confirm.html", gin.H{
+			"challengeID": currentChallengeID,
+			"team":        teamName,
+			"answer":      c.PostForm("answer"),
+			"lang":        lang,
+		}); err != nil {
+			app.templateFailed(c, err)
+		}
+		return
+	}
+
+	// Gleichzeitige Weiterleitungen desselben Teams nacheinander abarbeiten
+	unlock := app.teamLocks.lock(teamPageID)
+	defer unlock()
 
 	// Hole Team-Daten
-	teamData, err := app.getTeamChallenges(teamPageID)
+	teamData, err := app.getTeamChallenges(c.Request.Context(), teamPageID)
 	if err != nil {
-		log.Printf("Fehler beim Abrufen der Challenges: %v", err)
+		errorf("Fehler beim Abrufen der Challenges: %v", err)
 		c.Header("Content-Type", "text/html; charset=utf-8")
 		app.templates.ExecuteTemplate(c.Writer, "error.html", gin.H{
-			"error": fmt.Sprintf("Fehler beim Abrufen der Team-Daten: %v", err),
+			"error": app.userError("Fehler beim Abrufen der Team-Daten", err),
 		})
 		return
 	}
 
-	log.Printf("Gefundene Challenges: %v", teamData)
+	debugf("Gefundene Challenges: %v", teamData)
+
+	// Ohne zugewiesene Challenges wäre "Ziel erreicht" irreführend
+	if len(teamData) == 0 && app.emptyRouteAction == emptyRouteNotice {
+		warnf("Team %s hat keine Challenges zugewiesen", teamName)
+		app.logEvent(teamName, currentChallengeID, "", outcomeNoRoute, adminAssisted)
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		if err := app.templates.ExecuteTemplate(c.Writer, "noroute.html", gin.H{"team": teamName, "lang": lang}); err != nil {
+			app.templateFailed(c, err)
+		}
+		return
+	}
+
+	// Abschluss der aktuellen Challenge festhalten (Fehler sind nicht fatal)
+	if routeContains(teamData, currentChallengeID) {
+		if err := app.recordCompletion(c.Request.Context(), teamPageID, currentChallengeID, false, ""); err != nil {
+			errorf("Fehler beim Speichern des Abschlusses: %v", err)
+		}
+	}
 
-	// Finde aktuelle Challenge-Position und hole nächste
-	nextChallengeURL := app.findNextChallengeURL(teamData, currentChallengeID)
+	// Zielbedingung (z.B. "5 von 8") kann vor dem Ende der Route erreicht sein
+	if app.finishReached(c.Request.Context(), teamPageID) {
+		debugf("Zielbedingung erreicht für Team: %s", teamName)
+		app.logEvent(teamName, currentChallengeID, "", outcomeFinished, adminAssisted)
+		app.renderFinished(c, teamName, teamPageID, lang)
+		return
+	}
+
+	// Antwortabhängige Verzweigung (NextRules) hat Vorrang, sonst aktuelle Position finden und nächste holen
+	next, err := app.branchTarget(c.Request.Context(), currentChallengeID, c.PostForm("answer"))
+	if err == nil && next == nil {
+		from := app.positionChallengeID(c.Request.Context(), teamPageID, teamData, currentChallengeID)
+		next, err = app.findNextChallenge(c.Request.Context(), teamData, from)
+	}
+	if err != nil {
+		errorf("Nächste Challenge für Team %s nicht ladbar: %v", teamName, err)
+		app.renderRetry(c, currentChallengeID, teamName)
+		return
+	}
+
+	// Offene Voraussetzungen der nächsten Challenge haben Vorrang
+	if next != nil && len(next.Prerequisites) > 0 {
+		prerequisite, ok := app.checkPrerequisites(c, teamPageID, teamData, next)
+		if !ok {
+			app.logEvent(teamName, currentChallengeID, strconv.Itoa(next.ID), outcomeBlocked, adminAssisted)
+			return
+		}
+		next = prerequisite
+	}
+
+	nextChallengeURL := ""
+	if next != nil {
+		nextChallengeURL = next.URL
+	}
 
 	if nextChallengeURL == "" {
-		log.Printf("Keine weitere Challenge gefunden nach ID: %s", currentChallengeID)
-		// Keine weitere Challenge oder Challenge nicht gefunden
-		c.Header("Content-Type", "text/html; charset=utf-8")
-		app.templates.ExecuteTemplate(c.Writer, "finished.html", gin.H{
-			"team": teamName,
-		})
+		debugf("Keine weitere Challenge gefunden nach ID: %s", currentChallengeID)
+		app.logEvent(teamName, currentChallengeID, "", outcomeFinished, adminAssisted)
+		// Ende der Route erreicht
+		app.renderFinished(c, teamName, teamPageID, lang)
 		return
 	}
 
-	log.Printf("Nächste Challenge URL: %s", nextChallengeURL)
+	debugf("Nächste Challenge URL: %s", nextChallengeURL)
+	app.logEvent(teamName, currentChallengeID, strconv.Itoa(next.ID), outcomeAdvanced, adminAssisted)
+	app.arrivals.record(teamPageID, strconv.Itoa(next.ID), time.Now())
 
-	// Weiterleitung zur nächsten Challenge
+	// Weiterleitung zur nächsten Challenge (optional mit eigenem Template der Challenge)
 	c.Header("Content-Type", "text/html; charset=utf-8")
-	app.templates.ExecuteTemplate(c.Writer, "redirect.html", gin.H{
-		"url":  nextChallengeURL,
-		"team": teamName,
+	app.templates.ExecuteTemplate(c.Writer, app.redirectTemplate(next), gin.H{
+		"url":   nextChallengeURL,
+		"team":  teamName,
+		"delay": app.redirectDelay,
+		"lang":  lang,
 	})
 }
 
-// findTeamPage findet die Team-Page ID anhand des Teamnamens
-func (app *App) findTeamPage(teamName string) (string, error) {
-	ctx := context.Background()
-
-	// Versuche verschiedene Property-Namen für den Titel
-	propertyNames := []string{"Name", "Team", "Title", "title"}
+// renderRetry zeigt eine Fehlerseite, deren Button die Weiterleitung erneut absendet.
+// Bestätigung, Antwort und Bypass-Token werden mitgeschickt, da sie bereits geprüft wurden.
+func (app *App) renderRetry(c *gin.Context, challengeID, teamName string) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusServiceUnavailable)
+	if err := app.templates.ExecuteTemplate(c.Writer, "error.html", gin.H{
+		"error": "Die nächste Challenge konnte gerade nicht geladen werden. Bitte versucht es gleich noch einmal.",
+		"retry": gin.H{
+			"challengeID": challengeID,
+			"team":        teamName,
+			"answer":      c.PostForm("answer"),
+			"bypass":      c.PostForm(bypassFormField),
+		},
+	}); err != nil {
+		app.templateFailed(c, err)
+	}
+}
 
-	for _, prop := range propertyNames {
-		filter := &notionapi.DatabaseQueryRequest{
-			Filter: &notionapi.PropertyFilter{
-				Property: prop,
-				RichText: &notionapi.TextFilterCondition{
-					Equals: teamName,
-				},
-			},
-		}
+// renderFinished zeigt die Zielseite, optional mit FINISH_MESSAGE und den Zahlen des Teams.
+// Konfetti (CONFETTI) und Weiterleitung (FINISH_REDIRECT_URL) steuert allein die Konfiguration.
+// Fehlen die Team-Daten, wird die Seite ohne Statistik gezeigt.
+func (app *App) renderFinished(c *gin.Context, teamName, teamPageID, lang string) {
+	data := gin.H{
+		"team":          teamName,
+		"message":       app.finishMessage,
+		"lang":          lang,
+		"confetti":      app.confetti,
+		"redirectURL":   app.finishRedirectURL,
+		"redirectDelay": app.finishRedirectDelay,
+	}
 
-		result, err := app.notion.Database.Query(ctx, notionapi.DatabaseID(app.teamsDBID), filter)
-		if err == nil && len(result.Results) > 0 {
-			return string(result.Results[0].ID), nil
+	if page, err := app.getPage(c.Request.Context(), teamPageID); err != nil {
+		errorf("Fehler beim Laden der Team-Statistik: %v", err)
+	} else {
+		data["completed"] = len(app.completedChallenges(page))
+		if p, ok := page.Properties[teamScoreProperty].(*notionapi.NumberProperty); ok {
+			data["score"] = p.Number
 		}
 	}
 
-	// Als letzten Versuch: Ohne Filter alle Teams holen und manuell suchen
-	allTeams := &notionapi.DatabaseQueryRequest{
-		PageSize: 100,
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := app.templates.ExecuteTemplate(c.Writer, "finished.html", data); err != nil {
+		app.templateFailed(c, err)
 	}
+}
 
-	result, err := app.notion.Database.Query(ctx, notionapi.DatabaseID(app.teamsDBID), allTeams)
-	if err != nil {
+// findTeamPage findet die Team-Page ID anhand des Teamnamens. Die Strategien aus
+// TEAM_MATCH_PIPELINE werden der Reihe nach versucht, der erste Treffer gewinnt.
+func (app *App) findTeamPage(ctx context.Context, teamName string) (string, error) {
+	page, err := app.findTeam(ctx, teamName)
+	if page == nil {
 		return "", err
 	}
+	return string(page.ID), err
+}
 
-	// Manuell nach dem Team suchen
-	for _, page := range result.Results {
-		for _, prop := range page.Properties {
-			switch p := prop.(type) {
-			case *notionapi.TitleProperty:
-				if len(p.Title) > 0 && p.Title[0].PlainText == teamName {
-					return string(page.ID), nil
-				}
-			case *notionapi.RichTextProperty:
-				if len(p.RichText) > 0 && p.RichText[0].PlainText == teamName {
-					return string(page.ID), nil
-				}
+// findTeam sucht die Team-Page zu einer Eingabe über die Strategien aus TEAM_MATCH_PIPELINE
+// (nil, wenn keine passt). Den kanonischen Teamnamen liefert teamTitle der Page.
+func (app *App) findTeam(ctx context.Context, teamName string) (*notionapi.Page, error) {
+	var pages []notionapi.Page
+	loaded := false
+	for _, strategy := range app.matchPipeline {
+		// "exact" fragt Notion direkt und braucht die Teamliste nicht
+		if strategy == matchExact {
+			if page := app.findTeamPageExact(ctx, teamName); page != nil {
+				return page, nil
+			}
+			continue
+		}
+
+		if !loaded {
+			var err error
+			if pages, err = app.listTeamPages(ctx); err != nil {
+				return nil, err
 			}
+			loaded = true
+		}
+		if page, err := app.matchTeamPages(strategy, pages, teamName); err != nil || page != nil {
+			return page, err
 		}
 	}
 
-	return "", nil
+	return nil, nil
+}
+
+// teamTitle liefert den Namen eines Teams, wie er in Notion steht. Ohne Titel bleibt es
+// bei der Eingabe, damit Cookie, Event-Log und Seiten nie einen leeren Namen zeigen.
+func teamTitle(page *notionapi.Page, input string) string {
+	if title := pageTitle(*page); title != "" {
+		return title
+	}
+	return input
+}
+
+// teamAliases liest die optionale "Aliases" Property einer Team-Page.
+// Unterstützt Multi-Select oder kommagetrennten Rich-Text.
+func teamAliases(page notionapi.Page) []string {
+	var aliases []string
+
+	switch p := page.Properties["Aliases"].(type) {
+	case *notionapi.MultiSelectProperty:
+		for _, option := range p.MultiSelect {
+			aliases = append(aliases, option.Name)
+		}
+	case *notionapi.RichTextProperty:
+		for _, alias := range strings.Split(richTextPlain(p.RichText), ",") {
+			if alias = trimTeamName(alias); alias != "" {
+				aliases = append(aliases, alias)
+			}
+		}
+	}
+
+	return aliases
 }
 
 // getTeamChallenges holt alle Challenge-Relations einer Team-Page
-func (app *App) getTeamChallenges(teamPageID string) (map[int]string, error) {
-	ctx := context.Background()
+func (app *App) getTeamChallenges(ctx context.Context, teamPageID string) (map[int]string, error) {
 
-	page, err := app.notion.Page.Get(ctx, notionapi.PageID(teamPageID))
+	page, err := app.getPage(ctx, teamPageID)
 	if err != nil {
 		return nil, err
 	}
 
 	challenges := make(map[int]string)
 
+	// Eine Position mit mehreren Challenges ist ein Pflegefehler in Notion, es zählt die erste
+	if slots := ambiguousRouteSlots(page); len(slots) > 0 {
+		warnf("Team-Page %s: Challenge-Relations %v verweisen auf mehr als eine Challenge, verwendet wird jeweils die erste", teamPageID, slots)
+	}
+
 	// Durchsuche alle Properties nach Challenge-Relations
 	for propName, prop := range page.Properties {
 		// Prüfe ob Property eine Challenge-Relation ist (Challenge1, Challenge2, etc.)
 		var challengeNum int
 		if _, err := fmt.Sscanf(propName, "Challenge%d", &challengeNum); err == nil {
-			if relation, ok := prop.(*notionapi.RelationProperty); ok && len(relation.Relation) > 0 {
+			if challengePageID, ok := routeRelation(prop); ok {
 				// Hole die verlinkte Challenge-Page
-				challengePageID := string(relation.Relation[0].ID)
-				challengePage, err := app.notion.Page.Get(ctx, notionapi.PageID(challengePageID))
+				challengePage, err := app.getPage(ctx, challengePageID)
 				if err == nil {
-					// Extrahiere Challenge-ID aus der "id" Property (Number)
-					if idProp, ok := challengePage.Properties["id"]; ok {
-						switch v := idProp.(type) {
-						case *notionapi.NumberProperty:
-							challenges[challengeNum] = fmt.Sprintf("%.0f", v.Number)
-						}
-					}
-					// Fallback: Versuche "ID" (groß)
-					if _, exists := challenges[challengeNum]; !exists {
-						if idProp, ok := challengePage.Properties["ID"]; ok {
-							switch v := idProp.(type) {
-							case *notionapi.NumberProperty:
-								challenges[challengeNum] = fmt.Sprintf("%.0f", v.Number)
-							}
-						}
+					// Extrahiere Challenge-ID aus der "id" bzw. "ID" Property (Number)
+					if num, ok := challengeNumber(*challengePage); ok {
+						challenges[challengeNum] = fmt.Sprintf("%d", num)
 					}
 				}
 			}
@@ -427,71 +976,135 @@ func (app *App) getTeamChallenges(teamPageID string) (map[int]string, error) {
 	return challenges, nil
 }
 
-// findNextChallengeURL findet die URL der nächsten Challenge
-func (app *App) findNextChallengeURL(challenges map[int]string, currentID string) string {
-	// Finde Position der aktuellen Challenge
-	currentPos := 0
-	for pos, id := range challenges {
-		if id == currentID {
-			currentPos = pos
-			break
-		}
+// findNextChallengeURL findet die URL der nächsten Challenge ("" am Ende der Route)
+func (app *App) findNextChallengeURL(ctx context.Context, challenges map[int]string, currentID string) (string, error) {
+	next, err := app.findNextChallenge(ctx, challenges, currentID)
+	if err != nil || next == nil {
+		return "", err
 	}
+	return next.URL, nil
+}
+
+// findNextChallenge findet die nächste Challenge auf der Route. Am Ende der Route
+// liefert sie nil ohne Fehler; kann die nächste Challenge nicht geladen werden,
+// kommt ein Fehler, damit das Team nicht fälschlich im Ziel landet.
+func (app *App) findNextChallenge(ctx context.Context, challenges map[int]string, currentID string) (*Challenge, error) {
+	currentPos := currentPosition(challenges, currentID)
 
 	// Suche nächste Challenge (currentPos + 1)
 	nextPos := currentPos + 1
-	if nextID, exists := challenges[nextPos]; exists {
-		// Hole die Challenge-Page aus der Challenge-DB mit der ID
-		ctx := context.Background()
-
-		// Suche Challenge mit dieser ID (Number Property)
-		filter := &notionapi.DatabaseQueryRequest{
-			Filter: &notionapi.PropertyFilter{
-				Property: "id",
-				Number: &notionapi.NumberFilterCondition{
-					Equals: func() *float64 {
-						f, _ := parseFloat(nextID)
-						return &f
-					}(),
-				},
-			},
+	nextID, exists := challenges[nextPos]
+	if !exists {
+		return nil, nil
+	}
+
+	// Hole die Challenge-Page aus der Challenge-DB mit der ID
+	challenge, err := app.getChallenge(ctx, nextID)
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Abrufen der Challenge %s: %w", nextID, err)
+	}
+	return challenge, nil
+}
+
+// currentPosition findet die Position einer Challenge auf der Route (0, wenn sie fehlt).
+// Die Positionen werden aufsteigend durchsucht: Kommt eine Challenge mehrfach vor,
+// zählt immer die niedrigste Position, unabhängig von der Map-Reihenfolge.
+func currentPosition(challenges map[int]string, currentID string) int {
+	positions := make([]int, 0, len(challenges))
+	for pos := range challenges {
+		positions = append(positions, pos)
+	}
+	sort.Ints(positions)
+
+	for _, pos := range positions {
+		if challenges[pos] == currentID {
+			return pos
 		}
+	}
+	return 0
+}
 
-		result, err := app.notion.Database.Query(ctx, notionapi.DatabaseID(app.challengeDBID), filter)
-
-		// Fallback: Versuche "ID" (groß)
-		if err != nil || len(result.Results) == 0 {
-			filter.Filter = &notionapi.PropertyFilter{
-				Property: "ID",
-				Number: &notionapi.NumberFilterCondition{
-					Equals: func() *float64 {
-						f, _ := parseFloat(nextID)
-						return &f
-					}(),
-				},
-			}
-			result, _ = app.notion.Database.Query(ctx, notionapi.DatabaseID(app.challengeDBID), filter)
+// routeContains prüft, ob eine Challenge-ID in der Route eines Teams vorkommt
+func routeContains(challenges map[int]string, challengeID string) bool {
+	for _, id := range challenges {
+		if id == challengeID {
+			return true
 		}
+	}
+	return false
+}
 
-		if result != nil && len(result.Results) > 0 {
-			// Baue Notion-URL
-			pageID := string(result.Results[0].ID)
-			// Entferne Bindestriche aus der ID für die URL
-			cleanID := ""
-			for _, c := range pageID {
-				if c != '-' {
-					cleanID += string(c)
-				}
-			}
-			return fmt.Sprintf("https://marcbaumholz.notion.site/%s", cleanID)
+// featureEnabled wertet einen FEATURE_* Schalter aus (Standard: aktiviert)
+func featureEnabled(value string) bool {
+	return value != "false"
+}
+
+// checkTemplates prüft beim Start, ob alle benötigten Templates geladen wurden
+func checkTemplates(tmpl *template.Template) error {
+	var missing []string
+	for _, name := range requiredTemplates {
+		if tmpl.Lookup(name) == nil {
+			missing = append(missing, name)
 		}
 	}
+	if len(missing) > 0 {
+		return fmt.Errorf("fehlende Templates: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Cache-Control Werte für die Routen
+const (
+	// Startseite und Challenge-Details ändern sich selten
+	cacheShort = "public, max-age=300"
+	// Leaderboard darf zwischengespeichert, muss aber immer revalidiert werden
+	cacheNoCache = "no-cache"
+	// Formulare (Teamliste), Weiterleitungen und zufällige MVPs nie cachen
+	cacheNoStore = "no-store"
+)
+
+// cacheControl setzt den Cache-Control Header für eine Route
+func cacheControl(value string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", value)
+		c.Next()
+	}
+}
 
-	return ""
+// parseRedirectDelay liest REDIRECT_DELAY_SECONDS (Standard 0 = sofort weiterleiten)
+func parseRedirectDelay(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	delay, err := strconv.Atoi(s)
+	if err != nil || delay < 0 {
+		return 0, fmt.Errorf("REDIRECT_DELAY_SECONDS muss eine nicht-negative ganze Zahl sein, ist aber %q", s)
+	}
+	return delay, nil
 }
 
-func parseFloat(s string) (float64, error) {
-	var f float64
-	_, err := fmt.Sscanf(s, "%f", &f)
-	return f, err
+// defaultFinishRedirectDelay gilt, wenn FINISH_REDIRECT_URL ohne FINISH_REDIRECT_DELAY gesetzt ist
+const defaultFinishRedirectDelay = 10
+
+// parseFinishRedirect liest FINISH_REDIRECT_URL (http(s)-URL oder Pfad wie /leaderboard)
+// und FINISH_REDIRECT_DELAY in Sekunden. Ohne URL bleibt die Zielseite stehen.
+func parseFinishRedirect(target, delay string) (string, int, error) {
+	if target == "" {
+		return "", 0, nil
+	}
+	u, err := url.Parse(target)
+	isPath := strings.HasPrefix(target, "/")
+	// "//host" und "/\host" sehen wie Pfade aus, Browser folgen ihnen aber auf fremde Hosts
+	if err != nil || (isPath && localRedirectTarget(target) != target) ||
+		(!isPath && ((u.Scheme != "http" && u.Scheme != "https") || u.Host == "")) {
+		return "", 0, fmt.Errorf("FINISH_REDIRECT_URL %q muss eine http(s)-URL oder ein Pfad sein", target)
+	}
+	if delay == "" {
+		return target, defaultFinishRedirectDelay, nil
+	}
+	seconds, err := strconv.Atoi(delay)
+	if err != nil || seconds < 0 {
+		return "", 0, fmt.Errorf("FINISH_REDIRECT_DELAY muss eine nicht-negative ganze Zahl sein, ist aber %q", delay)
+	}
+	return target, seconds, nil
 }
@@ -0,0 +1,724 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jomei/notionapi"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	// Nur Fehler loggen, damit die Testausgabe lesbar bleibt
+	if err := setupLogging("error", ""); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// testAdminToken ist das ADMIN_TOKEN aller Test-Apps
+const testAdminToken = "test-admin"
+
+// Page-IDs der Demo-Teams aus newDemoStore
+const (
+	demoTeamPageID   = "demo-page-1" // "Demo Team": Brunnen, Kirchturm, Stadtpark, Rathaus
+	foxesTeamPageID  = "demo-page-2" // "Die Füchse": Brunnen, Stadtpark, Kirchturm, Rathaus
+	demoChallengeURL = "http://localhost:8080/next/"
+)
+
+// testApp ist eine App im DEMO_MODE samt Router und Demo-Speicher
+type testApp struct {
+	*App
+	store   *demoStore
+	handler http.Handler
+}
+
+// newTestApp baut die App wie main im DEMO_MODE; env ergänzt die Konfiguration
+func newTestApp(t *testing.T, env map[string]string) *testApp {
+	t.Helper()
+	t.Setenv("DEMO_MODE", "true")
+	t.Setenv("ADMIN_TOKEN", testAdminToken)
+	for name, value := range env {
+		t.Setenv(name, value)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	app, err := newApp(cfg)
+	if err != nil {
+		t.Fatalf("newApp: %v", err)
+	}
+
+	var store *demoStore
+	switch notion := app.notion.(type) {
+	case *demoStore:
+		store = notion
+	case *limitedNotion:
+		store = notion.notionService.(*demoStore)
+	}
+	return &testApp{App: app, store: store, handler: app.router(cfg)}
+}
+
+// do schickt einen Request an den Router; ein Formular wird als POST-Body kodiert
+func (ta *testApp) do(method, path string, form url.Values) *httptest.ResponseRecorder {
+	var req *http.Request
+	if form != nil {
+		req = httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		req = httptest.NewRequest(method, path, nil)
+	}
+	w := httptest.NewRecorder()
+	ta.handler.ServeHTTP(w, req)
+	return w
+}
+
+// admin schickt einen Request mit ADMIN_TOKEN und optionalem JSON-Body
+func (ta *testApp) admin(method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	ta.handler.ServeHTTP(w, req)
+	return w
+}
+
+// teamPage liest eine Page direkt aus dem Demo-Speicher
+func (ta *testApp) teamPage(t *testing.T, pageID string) *notionapi.Page {
+	t.Helper()
+	page, err := ta.store.GetPage(t.Context(), pageID)
+	if err != nil {
+		t.Fatalf("GetPage(%s): %v", pageID, err)
+	}
+	return page
+}
+
+// decodeJSON liest die JSON-Antwort eines Handlers
+func decodeJSON(t *testing.T, w *httptest.ResponseRecorder) map[string]any {
+	t.Helper()
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Antwort ist kein JSON (%d): %s", w.Code, w.Body.String())
+	}
+	return body
+}
+
+// stubNotion reicht alle Aufrufe an notionService weiter; query ersetzt QueryDatabase,
+// database ersetzt GetDatabase
+type stubNotion struct {
+	notionService
+	query    func(ctx context.Context, dbID string, req *notionapi.DatabaseQueryRequest) (*notionapi.DatabaseQueryResponse, error)
+	database func(ctx context.Context, dbID string) (*notionapi.Database, error)
+}
+
+func (s *stubNotion) QueryDatabase(ctx context.Context, dbID string, req *notionapi.DatabaseQueryRequest) (*notionapi.DatabaseQueryResponse, error) {
+	if s.query != nil {
+		return s.query(ctx, dbID, req)
+	}
+	return s.notionService.QueryDatabase(ctx, dbID, req)
+}
+
+func (s *stubNotion) GetDatabase(ctx context.Context, dbID string) (*notionapi.Database, error) {
+	if s.database != nil {
+		return s.database(ctx, dbID)
+	}
+	return s.notionService.GetDatabase(ctx, dbID)
+}
+
+// notionError baut eine Fehlerantwort der Notion-API
+func notionError(status int, code notionapi.ErrorCode, message string) error {
+	return &notionapi.Error{Object: "error", Status: status, Code: code, Message: message}
+}
+
+// advance schickt das Teamformular einer Challenge ab, wie es die Teilnehmenden tun
+func (ta *testApp) advance(challengeID, team string) *httptest.ResponseRecorder {
+	return ta.do(http.MethodPost, "/next/"+challengeID, url.Values{"team": {team}})
+}
+
+func TestParseRedirectDelay(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{in: "", want: 0},
+		{in: "0", want: 0},
+		{in: "5", want: 5},
+		{in: "-1", wantErr: true},
+		{in: "2s", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseRedirectDelay(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseRedirectDelay(%q) = %d, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestRedirectDelay(t *testing.T) {
+	for _, delay := range []string{"0", "7"} {
+		t.Run(delay, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"REDIRECT_DELAY_SECONDS": delay})
+			w := ta.advance("1", "Demo Team")
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+			body := w.Body.String()
+			if !strings.Contains(body, " "+delay+"  * 1000);") {
+				t.Errorf("Verzögerung %s fehlt in redirect.html:\n%s", delay, body)
+			}
+			if !strings.Contains(body, demoChallengeURL+"demo-kirchturm") {
+				t.Errorf("Weiterleitung zur nächsten Challenge fehlt:\n%s", body)
+			}
+		})
+	}
+}
+
+func TestCheckTemplates(t *testing.T) {
+	full, err := template.New("").Funcs(templateFuncs).ParseFS(templates, "templates/*.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	partial := template.Must(template.New("home.html").Parse("home"))
+	template.Must(partial.New("error.html").Parse("error"))
+
+	tests := []struct {
+		name        string
+		tmpl        *template.Template
+		wantMissing []string
+	}{
+		{name: "alle Templates", tmpl: full},
+		{name: "unvollständig", tmpl: partial, wantMissing: []string{"teamform.html", "pin.html"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkTemplates(tt.tmpl)
+			if (err != nil) != (len(tt.wantMissing) > 0) {
+				t.Fatalf("checkTemplates = %v", err)
+			}
+			for _, name := range tt.wantMissing {
+				if !strings.Contains(err.Error(), name) {
+					t.Errorf("%s fehlt in %q", name, err)
+				}
+			}
+			if err != nil && strings.Contains(err.Error(), "home.html") {
+				t.Errorf("vorhandenes Template als fehlend gemeldet: %v", err)
+			}
+		})
+	}
+}
+
+func TestTemplateFailed(t *testing.T) {
+	tests := []struct {
+		name       string
+		production bool
+		wantDetail bool
+	}{
+		{name: "Entwicklung mit Details", production: false, wantDetail: true},
+		{name: "Produktion ohne Details", production: true, wantDetail: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"PRODUCTION": strconv.FormatBool(tt.production)})
+			// Ein Template, das beim Ausführen scheitert, statt der Startseite
+			ta.templates = template.Must(template.New("home.html").Parse(`{{template "fehlt"}}`))
+
+			w := ta.do(http.MethodGet, "/", nil)
+			if w.Code != http.StatusInternalServerError {
+				t.Fatalf("Status = %d, erwartet 500", w.Code)
+			}
+			body := w.Body.String()
+			if !strings.HasPrefix(body, "Template-Fehler") {
+				t.Errorf("Antwort = %q", body)
+			}
+			if strings.Contains(body, "fehlt") != tt.wantDetail {
+				t.Errorf("Details in %q, erwartet %v", body, tt.wantDetail)
+			}
+		})
+	}
+}
+
+// addDemoChallenge legt eine weitere Challenge im Demo-Speicher an
+func addDemoChallenge(store *demoStore, pageID string, id int, props ...notionapi.Properties) *notionapi.Page {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	all := notionapi.Properties{
+		"Name": &notionapi.TitleProperty{Title: demoText(fmt.Sprintf("Challenge %d", id))},
+		"id":   &notionapi.NumberProperty{Number: float64(id)},
+	}
+	for _, extra := range props {
+		for name, prop := range extra {
+			all[name] = prop
+		}
+	}
+	return store.add(demoChallengesDBID, pageID, all)
+}
+
+func TestCacheControl(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{http.MethodGet, "/", cacheShort},
+		{http.MethodGet, "/api/challenges/1", cacheShort},
+		{http.MethodGet, "/api/order", cacheShort},
+		{http.MethodGet, "/leaderboard", cacheNoCache},
+		{http.MethodGet, "/next/1", cacheNoStore},
+		{http.MethodPost, "/next/1", cacheNoStore},
+		{http.MethodGet, "/version", cacheNoStore},
+		{http.MethodGet, "/admin/positions", cacheNoStore},
+	}
+
+	ta := newTestApp(t, map[string]string{"FEATURE_LEADERBOARD": "true"})
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			var w *httptest.ResponseRecorder
+			switch {
+			case strings.HasPrefix(tt.path, "/admin"):
+				w = ta.admin(tt.method, tt.path, "")
+			case tt.method == http.MethodPost:
+				w = ta.do(tt.method, tt.path, url.Values{"team": {"Demo Team"}})
+			default:
+				w = ta.do(tt.method, tt.path, nil)
+			}
+			if got := w.Header().Get("Cache-Control"); got != tt.want {
+				t.Errorf("Cache-Control = %q, erwartet %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfirmAdvance(t *testing.T) {
+	tests := []struct {
+		name          string
+		confirmConfig string
+		form          url.Values
+		wantConfirm   bool
+	}{
+		{name: "ohne Bestätigungsschritt", confirmConfig: "false", form: url.Values{"team": {"Demo Team"}}},
+		{name: "Bestätigung angefordert", confirmConfig: "true", form: url.Values{"team": {"Demo Team"}}, wantConfirm: true},
+		{name: "bestätigt", confirmConfig: "true", form: url.Values{"team": {"Demo Team"}, "confirm": {"yes"}}},
+		{name: "falscher Bestätigungswert", confirmConfig: "true", form: url.Values{"team": {"Demo Team"}, "confirm": {"1"}}, wantConfirm: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"CONFIRM_ADVANCE": tt.confirmConfig})
+			w := ta.do(http.MethodPost, "/next/1", tt.form)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+
+			body := w.Body.String()
+			if got := strings.Contains(body, `name="confirm" value="yes"`); got != tt.wantConfirm {
+				t.Errorf("Bestätigungsseite = %v, erwartet %v", got, tt.wantConfirm)
+			}
+			_, done := ta.completedChallenges(ta.teamPage(t, demoTeamPageID))[1]
+			if done == tt.wantConfirm {
+				t.Errorf("Abschluss gespeichert = %v, erwartet %v", done, !tt.wantConfirm)
+			}
+		})
+	}
+}
+
+// addDemoTeam legt ein weiteres Team ohne Route im Demo-Speicher an und liefert die Page-ID
+func addDemoTeam(store *demoStore, name string, props ...notionapi.Properties) string {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	all := notionapi.Properties{teamTitleProperty: &notionapi.TitleProperty{Title: demoText(name)}}
+	for _, extra := range props {
+		for propName, prop := range extra {
+			all[propName] = prop
+		}
+	}
+	return string(store.add(demoTeamsDBID, "", all).ID)
+}
+
+func TestOptionalFeatureRoutes(t *testing.T) {
+	tests := []struct {
+		env      string
+		value    string
+		method   string // "": GET
+		path     string
+		link     string // Link auf der Startseite ("": keiner)
+		wantCode int
+	}{
+		{env: "FEATURE_MVP", value: "false", path: "/mvpgenerator", link: `href="/mvpgenerator"`, wantCode: http.StatusNotFound},
+		{env: "FEATURE_MVP", value: "", path: "/mvpgenerator", link: `href="/mvpgenerator"`, wantCode: http.StatusOK},
+		{env: "FEATURE_MVP", value: "false", path: "/api/teams/Demo%20Team/mvp", wantCode: http.StatusNotFound},
+		{env: "FEATURE_MVP", value: "false", path: "/admin/mvp-distribution", wantCode: http.StatusNotFound},
+		{env: "FEATURE_MVP", value: "", path: "/admin/mvp-distribution", wantCode: http.StatusOK},
+		{env: "FEATURE_MVP", value: "false", method: http.MethodPost, path: "/admin/mvp/Demo%20Team", wantCode: http.StatusNotFound},
+		{env: "FEATURE_MVP", value: "", method: http.MethodPost, path: "/admin/mvp/Demo%20Team", wantCode: http.StatusOK},
+		{env: "FEATURE_LEADERBOARD", value: "false", path: "/leaderboard", link: `href="/leaderboard"`, wantCode: http.StatusNotFound},
+		{env: "FEATURE_LEADERBOARD", value: "true", path: "/leaderboard", link: `href="/leaderboard"`, wantCode: http.StatusOK},
+		{env: "FEATURE_REGISTRATION", value: "", path: "/register", wantCode: http.StatusNotFound},
+		{env: "FEATURE_REGISTRATION", value: "true", path: "/register", wantCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		method := tt.method
+		if method == "" {
+			method = http.MethodGet
+		}
+		t.Run(tt.env+"="+tt.value+" "+method+" "+tt.path, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{tt.env: tt.value})
+			// Admin-Routen mit Token, damit nur das Feature über den Status entscheidet
+			do := func() *httptest.ResponseRecorder { return ta.do(method, tt.path, nil) }
+			if strings.HasPrefix(tt.path, "/admin/") {
+				do = func() *httptest.ResponseRecorder { return ta.admin(method, tt.path, "") }
+			}
+			if w := do(); w.Code != tt.wantCode {
+				t.Errorf("Status = %d, erwartet %d: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+			if tt.link == "" {
+				return
+			}
+			home := ta.do(http.MethodGet, "/", nil).Body.String()
+			if got := strings.Contains(home, tt.link); got != (tt.wantCode == http.StatusOK) {
+				t.Errorf("Link %s auf der Startseite = %v", tt.link, got)
+			}
+		})
+	}
+}
+
+func TestHoneypot(t *testing.T) {
+	tests := []struct {
+		name     string
+		honeypot string
+		website  string
+		wantDone bool
+	}{
+		{name: "Feld leer", honeypot: "true", wantDone: true},
+		{name: "Feld ausgefüllt", honeypot: "true", website: "https://spam.example", wantDone: false},
+		{name: "Honeypot aus", honeypot: "false", website: "https://spam.example", wantDone: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"HONEYPOT": tt.honeypot})
+
+			form := url.Values{"team": {"Demo Team"}}
+			if tt.website != "" {
+				form.Set(honeypotField, tt.website)
+			}
+			w := ta.do(http.MethodPost, "/next/1", form)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+
+			_, done := ta.completedChallenges(ta.teamPage(t, demoTeamPageID))[1]
+			if done != tt.wantDone {
+				t.Errorf("Abschluss gespeichert = %v, erwartet %v", done, tt.wantDone)
+			}
+			if !tt.wantDone && !strings.Contains(w.Body.String(), "Team nicht gefunden") {
+				t.Errorf("Bot bekommt nicht die Fehlerseite:\n%s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHoneypotField(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		t.Run(strconv.FormatBool(enabled), func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"HONEYPOT": strconv.FormatBool(enabled)})
+			body := ta.do(http.MethodGet, "/next/1", nil).Body.String()
+			if got := strings.Contains(body, `name="`+honeypotField+`"`); got != enabled {
+				t.Errorf("Honeypot-Feld im Formular = %v, erwartet %v", got, enabled)
+			}
+		})
+	}
+}
+
+func TestCurrentPosition(t *testing.T) {
+	tests := []struct {
+		name      string
+		route     map[int]string
+		currentID string
+		want      int
+	}{
+		{name: "eindeutig", route: map[int]string{1: "1", 2: "2", 3: "3"}, currentID: "2", want: 2},
+		{name: "doppelt: niedrigste Position", route: map[int]string{1: "5", 2: "7", 3: "5", 4: "9"}, currentID: "5", want: 1},
+		{name: "doppelt mit Lücke", route: map[int]string{2: "4", 5: "4", 9: "4"}, currentID: "4", want: 2},
+		{name: "nicht auf der Route", route: map[int]string{1: "1"}, currentID: "8", want: 0},
+		{name: "leere Route", route: map[int]string{}, currentID: "1", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Map-Reihenfolge ist zufällig, daher mehrfach prüfen
+			for i := 0; i < 50; i++ {
+				if got := currentPosition(tt.route, tt.currentID); got != tt.want {
+					t.Fatalf("Durchlauf %d: currentPosition = %d, erwartet %d", i, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFindNextChallengeDuplicateID(t *testing.T) {
+	ta := newTestApp(t, nil)
+	route := map[int]string{1: "1", 2: "2", 3: "1", 4: "3"}
+	for i := 0; i < 20; i++ {
+		next, err := ta.findNextChallenge(t.Context(), route, "1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next == nil || next.ID != 2 {
+			t.Fatalf("Durchlauf %d: nächste Challenge = %+v, erwartet 2", i, next)
+		}
+	}
+}
+
+// failingChallengeNotion lässt das Laden einzelner Challenges scheitern, solange failing gesetzt ist
+func failingChallengeNotion(ta *testApp, failing *atomic.Bool, id float64) *stubNotion {
+	return &stubNotion{notionService: ta.store, query: func(ctx context.Context, dbID string, req *notionapi.DatabaseQueryRequest) (*notionapi.DatabaseQueryResponse, error) {
+		if f, ok := req.Filter.(*notionapi.PropertyFilter); ok && failing.Load() && dbID == demoChallengesDBID &&
+			f.Number != nil && f.Number.Equals != nil && *f.Number.Equals == id {
+			return nil, notionError(http.StatusBadGateway, "service_unavailable", "Notion ist gerade nicht erreichbar")
+		}
+		return ta.store.QueryDatabase(ctx, dbID, req)
+	}}
+}
+
+func TestFindNextChallenge(t *testing.T) {
+	route := map[int]string{1: "1", 2: "2", 3: "3"}
+	tests := []struct {
+		name    string
+		current string
+		failing bool
+		wantID  int // 0: Ende der Route
+		wantErr bool
+	}{
+		{name: "Mitte der Route", current: "1", wantID: 2},
+		{name: "Ende der Route", current: "3"},
+		{name: "unbekannte Challenge beginnt vorn", current: "9", wantID: 1},
+		{name: "Laden scheitert", current: "1", failing: true, wantErr: true},
+		{name: "Ende trotz Störung", current: "3", failing: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			var failing atomic.Bool
+			failing.Store(tt.failing)
+			ta.notion = failingChallengeNotion(ta, &failing, 2)
+
+			next, err := ta.findNextChallenge(t.Context(), route, tt.current)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("findNextChallenge: Fehler = %v, erwartet Fehler = %v", err, tt.wantErr)
+			}
+			gotID := 0
+			if next != nil {
+				gotID = next.ID
+			}
+			if gotID != tt.wantID {
+				t.Errorf("nächste Challenge = %d, erwartet %d", gotID, tt.wantID)
+			}
+
+			nextURL, err := ta.findNextChallengeURL(t.Context(), route, tt.current)
+			if (err != nil) != tt.wantErr || (nextURL == "") != (tt.wantID == 0) {
+				t.Errorf("findNextChallengeURL = %q, %v", nextURL, err)
+			}
+		})
+	}
+}
+
+func TestAdvanceRetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		challenge  string
+		wantStatus int
+		wantBody   []string
+		notBody    []string
+	}{
+		{
+			name:       "echtes Ziel",
+			challenge:  "4",
+			wantStatus: http.StatusOK,
+			wantBody:   []string{translate(defaultLanguage, "finished.heading")},
+			notBody:    []string{"Try again"},
+		},
+		{
+			name:       "nächste Challenge nicht ladbar",
+			challenge:  "1",
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody: []string{
+				"Die nächste Challenge konnte gerade nicht geladen werden",
+				`<form method="POST" action="/next/1">`,
+				`<input type="hidden" name="team" value="Demo Team">`,
+				`<input type="hidden" name="answer" value="Wasser">`,
+			},
+			notBody: []string{translate(defaultLanguage, "finished.heading")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			var failing atomic.Bool
+			failing.Store(true)
+			ta.notion = failingChallengeNotion(ta, &failing, 2)
+
+			form := url.Values{"team": {"Demo Team"}, "answer": {"Wasser"}}
+			w := ta.do(http.MethodPost, "/next/"+tt.challenge, form)
+			body := w.Body.String()
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, erwartet %d:\n%s", w.Code, tt.wantStatus, body)
+			}
+			for _, want := range tt.wantBody {
+				if !strings.Contains(body, want) {
+					t.Errorf("%q fehlt:\n%s", want, body)
+				}
+			}
+			for _, unwanted := range tt.notBody {
+				if strings.Contains(body, unwanted) {
+					t.Errorf("%q unerwartet:\n%s", unwanted, body)
+				}
+			}
+			if tt.wantStatus != http.StatusServiceUnavailable {
+				return
+			}
+
+			// Der erneute Versuch nach der Störung führt weiter
+			failing.Store(false)
+			form.Set("confirm", "yes")
+			w = ta.do(http.MethodPost, "/next/"+tt.challenge, form)
+			if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), demoChallengeURL+"demo-kirchturm") {
+				t.Errorf("erneuter Versuch: Status = %d:\n%s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestParseFinishRedirect(t *testing.T) {
+	tests := []struct {
+		target, delay string
+		wantURL       string
+		wantDelay     int
+		wantErr       bool
+	}{
+		{},
+		{target: "", delay: "5"},
+		{target: "/leaderboard", wantURL: "/leaderboard", wantDelay: defaultFinishRedirectDelay},
+		{target: "https://example.org/danke", delay: "0", wantURL: "https://example.org/danke"},
+		{target: "http://example.org", delay: "30", wantURL: "http://example.org", wantDelay: 30},
+		{target: "//evil.example", wantErr: true},
+		{target: `/\evil.example`, wantErr: true},
+		{target: "javascript:alert(1)", wantErr: true},
+		{target: "ftp://example.org", wantErr: true},
+		{target: "https://", wantErr: true},
+		{target: "leaderboard", wantErr: true},
+		{target: "/leaderboard", delay: "-1", wantErr: true},
+		{target: "/leaderboard", delay: "bald", wantErr: true},
+	}
+	for _, tt := range tests {
+		gotURL, gotDelay, err := parseFinishRedirect(tt.target, tt.delay)
+		if (err != nil) != tt.wantErr || gotURL != tt.wantURL || gotDelay != tt.wantDelay {
+			t.Errorf("parseFinishRedirect(%q, %q) = %q, %d, %v; erwartet %q, %d, Fehler = %v",
+				tt.target, tt.delay, gotURL, gotDelay, err, tt.wantURL, tt.wantDelay, tt.wantErr)
+		}
+	}
+}
+
+func TestFinishedPage(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		wantBody []string
+		notBody  []string
+	}{
+		{
+			name:     "Standard",
+			wantBody: []string{`<div class="confetti">`},
+			notBody:  []string{`id="countdown"`},
+		},
+		{
+			name:    "ohne Konfetti",
+			env:     map[string]string{"CONFETTI": "false"},
+			notBody: []string{`<div class="confetti">`, "confetti-piece'"},
+		},
+		{
+			name:     "mit Weiterleitung",
+			env:      map[string]string{"FINISH_REDIRECT_URL": "/leaderboard", "FINISH_REDIRECT_DELAY": "7"},
+			wantBody: []string{`<span id="countdown">7</span>`, `<a href="/leaderboard">`, `var target = '\/leaderboard'`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, tt.env)
+			w := ta.advance("4", "Demo Team")
+			body := w.Body.String()
+			if w.Code != http.StatusOK || !strings.Contains(body, translate(defaultLanguage, "finished.heading")) {
+				t.Fatalf("keine Zielseite (Status %d):\n%s", w.Code, body)
+			}
+			for _, want := range tt.wantBody {
+				if !strings.Contains(body, want) {
+					t.Errorf("%q fehlt:\n%s", want, body)
+				}
+			}
+			for _, unwanted := range tt.notBody {
+				if strings.Contains(body, unwanted) {
+					t.Errorf("%q unerwartet", unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestEmptyTeamSubmission(t *testing.T) {
+	const message = "Bitte wählt euer Team aus oder gebt euren Team-Code ein."
+	tests := []struct {
+		name      string
+		challenge string
+		form      url.Values
+		wantError bool
+	}{
+		{name: "leeres Formular", challenge: "2", form: url.Values{}, wantError: true},
+		{name: "nur Leerzeichen", challenge: "2", form: url.Values{"team": {"   "}}, wantError: true},
+		{name: "unsichtbare Zeichen", challenge: "3", form: url.Values{"team": {"\u00A0\u200B"}, "code": {" "}}, wantError: true},
+		{name: "mit Team", challenge: "1", form: url.Values{"team": {"Demo Team"}}},
+		{name: "mit Code statt Team", challenge: "1", form: url.Values{"code": {"GIBTSNICHT"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			w := ta.do(http.MethodPost, "/next/"+tt.challenge, tt.form)
+			body := w.Body.String()
+			if got := strings.Contains(body, message); got != tt.wantError {
+				t.Fatalf("Fehlermeldung = %v, erwartet %v (Status %d):\n%s", got, tt.wantError, w.Code, body)
+			}
+			if !tt.wantError {
+				return
+			}
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Status = %d, erwartet %d", w.Code, http.StatusBadRequest)
+			}
+			for _, want := range []string{
+				`action="/next/` + tt.challenge + `"`,
+				`<option value="Demo Team">Demo Team</option>`,
+				`<option value="Die Füchse">Die Füchse</option>`,
+				`<div class="field-error" role="alert">`,
+			} {
+				if !strings.Contains(body, want) {
+					t.Errorf("%q fehlt:\n%s", want, body)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jomei/notionapi"
+)
+
+// Strategien für TEAM_MATCH_PIPELINE, von streng nach großzügig
+const (
+	matchExact    = "exact"    // Titel exakt wie eingegeben (Notion-Filter)
+	matchTrimmed  = "trimmed"  // Leerraum am Rand und doppelter Leerraum egal
+	matchCasefold = "casefold" // zusätzlich Groß-/Kleinschreibung egal
+	matchAlias    = "alias"    // Aliases der Teams, wie casefold
+	matchFuzzy    = "fuzzy"    // Tippfehler und Teileingaben (nur mit FUZZY_MATCH_DISTANCE > 0)
+)
+
+// defaultMatchPipeline entspricht der bisherigen festen Reihenfolge
+var defaultMatchPipeline = []string{matchExact, matchTrimmed, matchCasefold, matchAlias, matchFuzzy}
+
+// parseMatchPipeline liest TEAM_MATCH_PIPELINE, eine kommagetrennte Liste von Strategien
+// in der Reihenfolge, in der findTeamPage sie versucht (Standard: alle)
+func parseMatchPipeline(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return defaultMatchPipeline, nil
+	}
+
+	var pipeline []string
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ",") {
+		strategy := strings.ToLower(strings.TrimSpace(part))
+		switch strategy {
+		case matchExact, matchTrimmed, matchCasefold, matchAlias, matchFuzzy:
+		default:
+			return nil, fmt.Errorf("TEAM_MATCH_PIPELINE enthält unbekannte Strategie %q (exact, trimmed, casefold, alias, fuzzy)", part)
+		}
+		if seen[strategy] {
+			return nil, fmt.Errorf("TEAM_MATCH_PIPELINE enthält %q mehrfach", strategy)
+		}
+		seen[strategy] = true
+		pipeline = append(pipeline, strategy)
+	}
+	return pipeline, nil
+}
+
+// findTeamPageExact sucht über den Notion-Filter nach exakt gleichem Titel.
+// Die Titel-Property kann je nach DB anders heißen.
+func (app *App) findTeamPageExact(ctx context.Context, teamName string) *notionapi.Page {
+	for _, prop := range []string{"Name", "Team", "Title", "title"} {
+		filter := &notionapi.DatabaseQueryRequest{
+			Filter: &notionapi.PropertyFilter{
+				Property: prop,
+				RichText: &notionapi.TextFilterCondition{
+					Equals: teamName,
+				},
+			},
+		}
+
+		result, err := app.queryDatabase(ctx, app.teamsDBID, filter)
+		if err == nil && len(result.Results) > 0 {
+			return &result.Results[0]
+		}
+	}
+	return nil
+}
+
+// matchTeamPages wendet eine Strategie (außer exact) auf die geladene Teamliste an
+func (app *App) matchTeamPages(strategy string, pages []notionapi.Page, teamName string) (*notionapi.Page, error) {
+	switch strategy {
+	case matchTrimmed:
+		return matchTeamNames(pages, collapseTeamName(teamName), collapseTeamName), nil
+	case matchCasefold:
+		return matchTeamNames(pages, normalizeTeamName(teamName), normalizeTeamName), nil
+	case matchAlias:
+		wanted := normalizeTeamName(teamName)
+		for i := range pages {
+			for _, alias := range teamAliases(pages[i]) {
+				if normalizeTeamName(alias) == wanted {
+					return &pages[i], nil
+				}
+			}
+		}
+	case matchFuzzy:
+		if app.fuzzyMaxDistance > 0 {
+			return app.fuzzyMatchTeam(pages, teamName)
+		}
+	}
+	return nil, nil
+}
+
+// matchTeamNames vergleicht den Namen jeder Team-Page nach key mit wanted
+func matchTeamNames(pages []notionapi.Page, wanted string, key func(string) string) *notionapi.Page {
+	for i := range pages {
+		for _, prop := range pages[i].Properties {
+			switch p := prop.(type) {
+			case *notionapi.TitleProperty:
+				if len(p.Title) > 0 && key(p.Title[0].PlainText) == wanted {
+					return &pages[i]
+				}
+			case *notionapi.RichTextProperty:
+				if len(p.RichText) > 0 && key(p.RichText[0].PlainText) == wanted {
+					return &pages[i]
+				}
+			}
+		}
+	}
+	return nil
+}
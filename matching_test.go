@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestParseMatchPipeline(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{in: "", want: defaultMatchPipeline},
+		{in: "  ", want: defaultMatchPipeline},
+		{in: "exact", want: []string{matchExact}},
+		{in: " Casefold , alias,EXACT ", want: []string{matchCasefold, matchAlias, matchExact}},
+		{in: "fuzzy,trimmed", want: []string{matchFuzzy, matchTrimmed}},
+		{in: "exact,soundex", wantErr: true},
+		{in: "exact,,alias", wantErr: true},
+		{in: "alias,Alias", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseMatchPipeline(tt.in)
+		if (err != nil) != tt.wantErr || !slices.Equal(got, tt.want) {
+			t.Errorf("parseMatchPipeline(%q) = %v, %v; erwartet %v, Fehler = %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestMatchPipeline(t *testing.T) {
+	tests := []struct {
+		pipeline string
+		input    string
+		want     string   // gefundenes Team ("": keins)
+		suggest  []string // Vorschläge, wenn fuzzy mehrere Teams findet
+	}{
+		// Dieselbe mehrdeutige Eingabe passt je nach Stufe auf verschiedene Teams
+		{pipeline: "exact", input: "die füchse"},
+		{pipeline: "exact,trimmed", input: "die füchse"},
+		{pipeline: "casefold", input: "die füchse", want: "Die Füchse"},
+		{pipeline: "alias", input: "die füchse", want: "Fuchsbau"},
+		{pipeline: "alias,casefold", input: "die füchse", want: "Fuchsbau"},
+		{pipeline: "casefold,alias", input: "die füchse", want: "Die Füchse"},
+		{pipeline: "fuzzy", input: "die füchse", suggest: []string{"Die Füchse", "Fuchsbau"}},
+
+		// Leerraum zählt erst ab trimmed nicht mehr
+		{pipeline: "exact", input: " Die  Füchse "},
+		{pipeline: "trimmed", input: " Die  Füchse ", want: "Die Füchse"},
+		{pipeline: "trimmed", input: " die  füchse "},
+
+		// Tippfehler findet nur fuzzy
+		{pipeline: "exact,trimmed,casefold,alias", input: "Die Füchss"},
+		{pipeline: "exact,fuzzy", input: "Fuchsbaum", want: "Fuchsbau"},
+		{pipeline: "exact,fuzzy", input: "Die Füchss", suggest: []string{"Die Füchse", "Fuchsbau"}},
+		{pipeline: "", input: "Die Füchss", suggest: []string{"Die Füchse", "Fuchsbau"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pipeline+" "+tt.input, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{
+				"TEAM_MATCH_PIPELINE":  tt.pipeline,
+				"FUZZY_MATCH_DISTANCE": "2",
+				"FUZZY_AUTO_ACCEPT":    "true",
+			})
+			addDemoTeam(ta.store, "Fuchsbau", notionapi.Properties{
+				teamAliasesProperty: &notionapi.RichTextProperty{RichText: demoText("DIE FÜCHSE")},
+			})
+
+			page, err := ta.findTeam(t.Context(), tt.input)
+			var suggestions *teamSuggestionsError
+			if errors.As(err, &suggestions) {
+				if !slices.Equal(suggestions.Suggestions, tt.suggest) {
+					t.Errorf("Vorschläge = %q, erwartet %q", suggestions.Suggestions, tt.suggest)
+				}
+				return
+			}
+			if err != nil || tt.suggest != nil {
+				t.Fatalf("findTeam(%q): %v, erwartet Vorschläge %q", tt.input, err, tt.suggest)
+			}
+			got := ""
+			if page != nil {
+				got = pageTitle(*page)
+			}
+			if got != tt.want {
+				t.Errorf("findTeam(%q) = %q, erwartet %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// notionLatencyBuckets sind die oberen Grenzen (Sekunden) des Latenz-Histogramms
+var notionLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// notionLatency misst die Dauer der Notion-Aufrufe je Operation (query, page.get, ...)
+var notionLatency = newLatencyHistogram(notionLatencyBuckets)
+
+// latencyHistogram ist ein Histogramm pro Label im Prometheus-Stil (kumulative Buckets)
+type latencyHistogram struct {
+	mu      sync.Mutex
+	buckets []float64
+	series  map[string]*latencySeries
+}
+
+type latencySeries struct {
+	counts []uint64 // je Bucket, nicht kumulativ
+	count  uint64
+	sum    float64
+}
+
+func newLatencyHistogram(buckets []float64) *latencyHistogram {
+	return &latencyHistogram{buckets: buckets, series: make(map[string]*latencySeries)}
+}
+
+// observe erfasst die Dauer seit start für eine Operation
+func (h *latencyHistogram) observe(operation string, start time.Time) {
+	seconds := time.Since(start).Seconds()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := h.series[operation]
+	if s == nil {
+		s = &latencySeries{counts: make([]uint64, len(h.buckets))}
+		h.series[operation] = s
+	}
+	for i, bound := range h.buckets {
+		if seconds <= bound {
+			s.counts[i]++
+			break
+		}
+	}
+	s.count++
+	s.sum += seconds
+}
+
+// writePrometheus schreibt das Histogramm im Prometheus-Textformat
+func (h *latencyHistogram) writePrometheus(w io.Writer, name, help string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+
+	operations := make([]string, 0, len(h.series))
+	for operation := range h.series {
+		operations = append(operations, operation)
+	}
+	sort.Strings(operations)
+
+	for _, operation := range operations {
+		s := h.series[operation]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket{operation=%q,le=%q} %d\n", name, operation, strconv.FormatFloat(bound, 'f', -1, 64), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket{operation=%q,le=\"+Inf\"} %d\n", name, operation, s.count)
+		fmt.Fprintf(w, "%s_sum{operation=%q} %g\n", name, operation, s.sum)
+		fmt.Fprintf(w, "%s_count{operation=%q} %d\n", name, operation, s.count)
+	}
+}
+
+// handleMetrics liefert die Metriken im Prometheus-Textformat (nur bei METRICS=true registriert)
+func (app *App) handleMetrics(c *gin.Context) {
+	c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	notionLatency.writePrometheus(c.Writer, "notion_request_duration_seconds", "Dauer der Notion-API-Aufrufe je Operation.")
+}
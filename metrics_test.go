@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestLatencyHistogram(t *testing.T) {
+	h := newLatencyHistogram([]float64{0.1, 1})
+	now := time.Now()
+	h.observe("query", now.Add(-30*time.Millisecond))
+	h.observe("query", now.Add(-500*time.Millisecond))
+	h.observe("query", now.Add(-5*time.Second))
+	h.observe("page.get", now.Add(-30*time.Millisecond))
+
+	var buf bytes.Buffer
+	h.writePrometheus(&buf, "test_seconds", "Testhistogramm.")
+	got := buf.String()
+
+	want := []string{
+		"# HELP test_seconds Testhistogramm.\n# TYPE test_seconds histogram\n",
+		`test_seconds_bucket{operation="query",le="0.1"} 1`,
+		`test_seconds_bucket{operation="query",le="1"} 2`,
+		`test_seconds_bucket{operation="query",le="+Inf"} 3`,
+		`test_seconds_count{operation="query"} 3`,
+		`test_seconds_bucket{operation="page.get",le="1"} 1`,
+		`test_seconds_count{operation="page.get"} 1`,
+	}
+	for _, line := range want {
+		if !strings.Contains(got, line) {
+			t.Errorf("%q fehlt:\n%s", line, got)
+		}
+	}
+	// Operationen erscheinen sortiert
+	if strings.Index(got, `operation="page.get"`) > strings.Index(got, `operation="query"`) {
+		t.Errorf("Operationen nicht sortiert:\n%s", got)
+	}
+}
+
+func TestNotionLatencyPerOperation(t *testing.T) {
+	previous := notionLatency
+	notionLatency = newLatencyHistogram(notionLatencyBuckets)
+	t.Cleanup(func() { notionLatency = previous })
+
+	ta := newTestApp(t, map[string]string{"METRICS": "true"})
+	ctx := t.Context()
+
+	// Auch fehlgeschlagene Aufrufe zählen, der Demo-Speicher kann nicht alles
+	ta.queryDatabase(ctx, demoTeamsDBID, &notionapi.DatabaseQueryRequest{})
+	ta.getPage(ctx, demoTeamPageID)
+	ta.getPage(ctx, demoTeamPageID)
+	ta.updatePage(ctx, demoTeamPageID, &notionapi.PageUpdateRequest{Properties: notionapi.Properties{}})
+	ta.createPage(ctx, &notionapi.PageCreateRequest{Parent: notionapi.Parent{DatabaseID: demoTeamsDBID}, Properties: notionapi.Properties{}})
+	ta.getBlockChildren(ctx, "demo-brunnen", nil)
+	ta.getDatabase(ctx, demoTeamsDBID)
+	ta.getPropertyItems(ctx, demoTeamPageID, "Challenge1", "")
+
+	w := ta.do(http.MethodGet, "/metrics", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+	}
+	body := w.Body.String()
+
+	want := map[string]int{
+		"query":          1,
+		"page.get":       2,
+		"page.update":    1,
+		"page.create":    1,
+		"block.children": 1,
+		"database.get":   1,
+		"page.property":  1,
+	}
+	for operation, count := range want {
+		line := fmt.Sprintf("notion_request_duration_seconds_count{operation=%q} %d", operation, count)
+		if !strings.Contains(body, line) {
+			t.Errorf("%q fehlt:\n%s", line, body)
+		}
+	}
+}
+
+func TestMetricsDisabled(t *testing.T) {
+	ta := newTestApp(t, nil)
+	if w := ta.do(http.MethodGet, "/metrics", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Status ohne METRICS = %d, erwartet %d", w.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jomei/notionapi"
+)
+
+// teamMVPProperty enthält das zugewiesene MVP auf der Team-Page
+const teamMVPProperty = "MVP"
+
+// mvpIdea ist ein Vorschlag des MVP-Generators, optional mit Bild
+type mvpIdea struct {
+	Name  string `json:"name"`
+	Image string `json:"image,omitempty"`
+}
+
+// builtinMVPs wird genutzt, wenn keine MVP_FILE konfiguriert ist
+var builtinMVPs = []string{
+	"Portable Water Filter",
+	"Eco-Friendly Phone Stand",
+	"Leaf-Based Notebook",
+	"Natural Air Freshener",
+	"Solar-Powered Lantern",
+	"Pocket-Sized Board Game",
+	"Mini Bird Feeder",
+	"Portable Hammock",
+	"Eco Speaker Amplifier",
+	"Handmade Jewelry",
+	"Biodegradable Straw",
+	"Reusable Cutlery Set",
+	"Eco Toy Car",
+	"Compostable Food Container",
+	"Emergency Shelter Kit",
+	"Natural Bandage",
+	"Seed-Paper Business Card",
+	"Upcycled Coin Wallet",
+	"Outdoor Chess Set",
+	"DIY Kite",
+	"Portable Plant Pot",
+	"Zero-Waste Picnic Kit",
+	"Wind Chime",
+	"Stress Relief Toy",
+	"Eco Bracelet",
+	"Paper Recycling Kit",
+	"Biodegradable Soap Holder",
+	"Natural Toothbrush",
+	"Eco-Friendly Bag",
+	"Outdoor Survival Kit",
+	"Pocket Garden",
+	"Park-Themed Board Game",
+	"Compost Bin Prototype",
+	"Mini Solar Oven",
+	"Eco-Friendly Candle",
+	"Toy Drone Shell",
+	"Bird Call Whistle",
+	"Outdoor Gym Equipment",
+	"Eco Keychain",
+	"Rainwater Collector",
+	"DIY Frisbee",
+	"Eco Sunglasses",
+	"Reusable Coffee Sleeve",
+	"Portable Charger Holder",
+	"Nature Bookmark",
+	"Toy Boat",
+	"DIY Musical Instrument",
+	"Eco-Friendly Packaging",
+	"Foldable Stool",
+	"Eco Travel Mug",
+	"Mini Wind Turbine",
+	"Outdoor Meditation Mat",
+	"Portable Whiteboard",
+	"Emergency Cooking Stove",
+	"Toy Puzzle",
+	"Nature Art Frame",
+	"Eco Phone Case",
+	"Reusable Water Filter Straw",
+	"Picnic Blanket Prototype",
+	"Eco Bag Tag",
+	"DIY Pen Holder",
+	"Eco-Friendly Wallet",
+	"Upcycled Backpack",
+	"Biodegradable Plant Pot",
+	"Eco Toothpaste Holder",
+	"DIY Notebook",
+	"Park Cleaning Kit",
+	"Eco Coaster",
+	"Portable Light Reflector",
+	"Outdoor Cooking Kit",
+	"Eco-Friendly Umbrella",
+	"Eco-Friendly Badge",
+	"Mini Greenhouse",
+	"Eco-Friendly Soap Dish",
+	"Outdoor Relaxation Chair",
+	"Toy Rocket",
+	"Eco-Friendly Speaker",
+	"DIY Lamp",
+	"Eco-Friendly Calendar",
+	"Nature Camera Case",
+	"Eco-Friendly Shoes",
+	"Eco-Friendly Gloves",
+	"Outdoor Game Dice",
+	"DIY Jewelry Box",
+	"Eco Candle Holder",
+	"Portable Fire Starter",
+	"Eco Ashtray",
+	"Reusable Straw Holder",
+	"DIY Sunglass Holder",
+	"Eco Plant Sprayer",
+	"Toy Airplane",
+	"Mini Compost Bag",
+	"Eco-Friendly Watch",
+	"Portable Raincoat",
+	"Eco Pencil Case",
+	"Outdoor Card Game",
+	"Toy Binoculars",
+	"Eco Lantern",
+	"Pocket First Aid Kit",
+	"Eco Water Bottle",
+	"Eco Blanket",
+}
+
+// builtinMVPIdeas liefert die eingebaute Liste als Ideen ohne Bild
+func builtinMVPIdeas() []mvpIdea {
+	ideas := make([]mvpIdea, len(builtinMVPs))
+	for i, name := range builtinMVPs {
+		ideas[i] = mvpIdea{Name: name}
+	}
+	return ideas
+}
+
+// availableMVPIdeas liefert die Ideen aus MVP_FILE, sonst die eingebaute Liste
+func (app *App) availableMVPIdeas() []mvpIdea {
+	if len(app.mvpIdeas) == 0 {
+		return builtinMVPIdeas()
+	}
+	return app.mvpIdeas
+}
+
+// loadMVPIdeas liest MVP_FILE: ein JSON-Array aus Namen oder Objekten
+// {"name": "...", "image": "https://..."}. Leerer Pfad bedeutet eingebaute Liste.
+func loadMVPIdeas(path string) ([]mvpIdea, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Lesen von MVP_FILE: %w", err)
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("MVP_FILE %q muss ein JSON-Array sein: %w", path, err)
+	}
+
+	ideas := make([]mvpIdea, 0, len(raw))
+	for i, item := range raw {
+		var idea mvpIdea
+		if err := json.Unmarshal(item, &idea.Name); err != nil {
+			if err := json.Unmarshal(item, &idea); err != nil {
+				return nil, fmt.Errorf("MVP_FILE: Eintrag %d ist weder Name noch Objekt: %w", i+1, err)
+			}
+		}
+		idea.Name = strings.TrimSpace(idea.Name)
+		idea.Image = strings.TrimSpace(idea.Image)
+		if idea.Name == "" {
+			return nil, fmt.Errorf("MVP_FILE: Eintrag %d hat keinen Namen", i+1)
+		}
+		if idea.Image != "" {
+			if u, err := url.Parse(idea.Image); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				return nil, fmt.Errorf("MVP_FILE: Eintrag %d (%s) hat eine ungültige Bild-URL %q", i+1, idea.Name, idea.Image)
+			}
+		}
+		ideas = append(ideas, idea)
+	}
+	if len(ideas) == 0 {
+		return nil, fmt.Errorf("MVP_FILE %q enthält keine Einträge", path)
+	}
+	return ideas, nil
+}
+
+// handleAPITeamMVP liefert das zugewiesene MVP eines Teams
+func (app *App) handleAPITeamMVP(c *gin.Context) {
+	ctx := c.Request.Context()
+	teamName := c.Param("team")
+
+	teamPageID, err := app.findTeamPage(ctx, teamName)
+	if err != nil || teamPageID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team nicht gefunden"})
+		return
+	}
+
+	mvp, err := app.getTeamMVP(ctx, teamPageID)
+	if err != nil {
+		errorf("Fehler beim Abrufen des MVP: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fehler beim Abrufen der Team-Daten"})
+		return
+	}
+	if mvp == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kein MVP zugewiesen"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"team": teamName,
+		"mvp":  mvp,
+	})
+}
+
+// handleAdminAssignMVP weist einem Team ein MVP zu. Der Body {"mvp": "..."} ist optional,
+// ohne Namen wird wie beim Generator eine noch nicht vergebene Idee gezogen.
+// Die Zuweisung liegt im Speicher bzw. in MVP_STATE_FILE und geht der Notion-Property vor.
+func (app *App) handleAdminAssignMVP(c *gin.Context) {
+	ctx := c.Request.Context()
+	teamName := c.Param("team")
+
+	var body struct {
+		MVP string `json:"mvp" form:"mvp"`
+	}
+	if err := c.ShouldBind(&body); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ungültiger Request-Body"})
+		return
+	}
+
+	teamPageID, err := app.findTeamPage(ctx, teamName)
+	if err != nil || teamPageID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team nicht gefunden"})
+		return
+	}
+
+	mvp := app.mvpUsed.assignTeam(teamPageID, strings.TrimSpace(body.MVP), app.availableMVPIdeas())
+	infof("MVP %q an Team %s vergeben", mvp, teamName)
+	c.JSON(http.StatusOK, gin.H{
+		"team": teamName,
+		"mvp":  mvp,
+	})
+}
+
+// getTeamMVP liefert das zugewiesene MVP eines Teams: zuerst die Zuweisung über
+// POST /admin/mvp/:team, sonst die "MVP" Property der Team-Page (Rich-Text oder Select)
+func (app *App) getTeamMVP(ctx context.Context, teamPageID string) (string, error) {
+	if mvp := app.mvpUsed.teamMVP(teamPageID); mvp != "" {
+		return mvp, nil
+	}
+
+	page, err := app.getPage(ctx, teamPageID)
+	if err != nil {
+		return "", err
+	}
+
+	switch p := page.Properties[teamMVPProperty].(type) {
+	case *notionapi.RichTextProperty:
+		return strings.TrimSpace(richTextPlain(p.RichText)), nil
+	case *notionapi.SelectProperty:
+		return p.Select.Name, nil
+	}
+	return "", nil
+}
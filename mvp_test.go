@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jomei/notionapi"
+)
+
+// writeMVPFile legt eine MVP_FILE mit content im Testverzeichnis an
+func writeMVPFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "mvps.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMVPGeneratorCount(t *testing.T) {
+	fourIdeas := writeMVPFile(t, `["Solarlampe", "Wasserfilter", "Regenschirm-Drohne", "Faltboot"]`)
+
+	tests := []struct {
+		name    string
+		mvpFile string
+		query   string
+		want    int
+	}{
+		{name: "Standard", mvpFile: fourIdeas, query: "", want: 1},
+		{name: "drei", mvpFile: fourIdeas, query: "count=3", want: 3},
+		{name: "null", mvpFile: fourIdeas, query: "count=0", want: 1},
+		{name: "keine Zahl", mvpFile: fourIdeas, query: "count=viele", want: 1},
+		{name: "mehr als Ideen", mvpFile: fourIdeas, query: "count=9", want: 4},
+		{name: "über dem Maximum", query: "count=99", want: maxMVPCount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"FEATURE_MVP": "true", "MVP_FILE": tt.mvpFile})
+			w := ta.do(http.MethodGet, "/mvpgenerator?format=json&"+tt.query, nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+
+			var names []string
+			if err := json.Unmarshal(w.Body.Bytes(), &names); err != nil {
+				t.Fatal(err)
+			}
+			if len(names) != tt.want {
+				t.Errorf("%d Vorschläge, erwartet %d: %v", len(names), tt.want, names)
+			}
+			distinct := make(map[string]bool)
+			for _, name := range names {
+				distinct[name] = true
+			}
+			if len(distinct) != len(names) {
+				t.Errorf("doppelte Vorschläge: %v", names)
+			}
+		})
+	}
+}
+
+func TestAPITeamMVP(t *testing.T) {
+	tests := []struct {
+		name       string
+		props      notionapi.Properties // MVP-Property des Teams "MVP Crew"
+		assign     string               // Body für POST /admin/mvp/:team ("": keine Zuweisung)
+		team       string
+		wantStatus int
+		wantMVP    string
+	}{
+		{
+			name:       "Rich-Text-Property",
+			props:      notionapi.Properties{teamMVPProperty: &notionapi.RichTextProperty{RichText: demoText(" Solarlampe ")}},
+			team:       "MVP Crew",
+			wantStatus: http.StatusOK,
+			wantMVP:    "Solarlampe",
+		},
+		{
+			name:       "Select-Property",
+			props:      notionapi.Properties{teamMVPProperty: &notionapi.SelectProperty{Select: notionapi.Option{Name: "Faltboot"}}},
+			team:       "MVP Crew",
+			wantStatus: http.StatusOK,
+			wantMVP:    "Faltboot",
+		},
+		{
+			name:       "Zuweisung geht der Property vor",
+			props:      notionapi.Properties{teamMVPProperty: &notionapi.RichTextProperty{RichText: demoText("Solarlampe")}},
+			assign:     `{"mvp":"Wasserfilter"}`,
+			team:       "MVP Crew",
+			wantStatus: http.StatusOK,
+			wantMVP:    "Wasserfilter",
+		},
+		{name: "nicht zugewiesen", team: "MVP Crew", wantStatus: http.StatusNotFound},
+		{name: "unbekanntes Team", team: "Niemand", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"FEATURE_MVP": "true"})
+			addDemoTeam(ta.store, "MVP Crew", tt.props)
+
+			if tt.assign != "" {
+				if w := ta.admin(http.MethodPost, "/admin/mvp/MVP%20Crew", tt.assign); w.Code != http.StatusOK {
+					t.Fatalf("Zuweisung: %d %s", w.Code, w.Body.String())
+				}
+			}
+
+			w := ta.do(http.MethodGet, "/api/teams/"+url.PathEscape(tt.team)+"/mvp", nil)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, erwartet %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantMVP != "" {
+				if body := decodeJSON(t, w); body["mvp"] != tt.wantMVP {
+					t.Errorf("mvp = %v, erwartet %q", body["mvp"], tt.wantMVP)
+				}
+			}
+		})
+	}
+}
+
+func TestAdminAssignMVPDraws(t *testing.T) {
+	ta := newTestApp(t, map[string]string{"FEATURE_MVP": "true", "MVP_FILE": writeMVPFile(t, `["Solarlampe"]`)})
+
+	w := ta.admin(http.MethodPost, "/admin/mvp/Demo%20Team", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+	}
+	if body := decodeJSON(t, w); body["mvp"] != "Solarlampe" {
+		t.Errorf("mvp = %v, erwartet die einzige Idee", body["mvp"])
+	}
+	if got := ta.mvpUsed.teamMVP(demoTeamPageID); got != "Solarlampe" {
+		t.Errorf("teamMVP = %q", got)
+	}
+}
+
+func TestLoadMVPIdeas(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []mvpIdea
+		wantErr bool
+	}{
+		{name: "nur Namen", content: `["Solarlampe", " Faltboot "]`, want: []mvpIdea{{Name: "Solarlampe"}, {Name: "Faltboot"}}},
+		{
+			name:    "mit Bildern",
+			content: `[{"name": "Solarlampe", "image": "https://example.org/lampe.png"}, "Faltboot"]`,
+			want:    []mvpIdea{{Name: "Solarlampe", Image: "https://example.org/lampe.png"}, {Name: "Faltboot"}},
+		},
+		{name: "Objekt ohne Bild", content: `[{"name": "Wasserfilter"}]`, want: []mvpIdea{{Name: "Wasserfilter"}}},
+		{name: "ungültige Bild-URL", content: `[{"name": "Solarlampe", "image": "javascript:alert(1)"}]`, wantErr: true},
+		{name: "ohne Namen", content: `[{"image": "https://example.org/x.png"}]`, wantErr: true},
+		{name: "leere Liste", content: `[]`, wantErr: true},
+		{name: "kein Array", content: `{"name": "Solarlampe"}`, wantErr: true},
+		{name: "falscher Typ", content: `[42]`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadMVPIdeas(writeMVPFile(t, tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadMVPIdeas: Fehler = %v, erwartet Fehler = %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("loadMVPIdeas = %+v, erwartet %+v", got, tt.want)
+			}
+		})
+	}
+
+	if ideas, err := loadMVPIdeas(""); ideas != nil || err != nil {
+		t.Errorf("ohne MVP_FILE: %v, %v", ideas, err)
+	}
+}
+
+func TestMVPGeneratorImages(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string // MVP_FILE, "" für die eingebaute Liste
+		wantImage string
+	}{
+		{name: "Idee mit Bild", content: `[{"name": "Solarlampe", "image": "https://example.org/lampe.png"}]`, wantImage: `<img class="mvp-image" src="https://example.org/lampe.png" alt="Solarlampe">`},
+		{name: "Idee ohne Bild", content: `["Solarlampe"]`},
+		{name: "eingebaute Liste"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mvpFile string
+			if tt.content != "" {
+				mvpFile = writeMVPFile(t, tt.content)
+			}
+			ta := newTestApp(t, map[string]string{"FEATURE_MVP": "true", "MVP_FILE": mvpFile})
+
+			w := ta.do(http.MethodGet, "/mvpgenerator", nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+			body := w.Body.String()
+			if tt.wantImage == "" {
+				if strings.Contains(body, "<img") {
+					t.Errorf("Bild ohne Bild-URL gerendert:\n%s", body)
+				}
+				return
+			}
+			if !strings.Contains(body, tt.wantImage) {
+				t.Errorf("%s fehlt:\n%s", tt.wantImage, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mvpUsedSet merkt sich bereits vergebene MVP-Ideen, damit der Generator keine doppelt
+// ausgibt. Mit MVP_STATE_FILE wird der Stand nach jeder Vergabe in eine JSON-Datei
+// geschrieben und beim Start geladen, ohne Datei bleibt er nur im Speicher.
+// counts zählt alle Vergaben je Idee und bleibt auch beim Neubeginn der Vergabe erhalten.
+// teams hält die einem Team fest zugewiesenen MVPs (Team-Page-ID → Name).
+type mvpUsedSet struct {
+	mu     sync.Mutex
+	used   map[string]bool
+	counts map[string]int
+	teams  map[string]string
+	path   string
+}
+
+// mvpState ist das Format von MVP_STATE_FILE
+type mvpState struct {
+	Used   []string          `json:"used"`
+	Counts map[string]int    `json:"counts"`
+	Teams  map[string]string `json:"teams,omitempty"`
+}
+
+// mvpCount ist die Anzahl der Vergaben einer Idee
+type mvpCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// newMVPUsedSet lädt den Stand aus path (leer: nur im Speicher). Eine fehlende Datei ist kein Fehler.
+func newMVPUsedSet(path string) (*mvpUsedSet, error) {
+	set := &mvpUsedSet{used: make(map[string]bool), counts: make(map[string]int), teams: make(map[string]string), path: path}
+	if path == "" {
+		return set, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return set, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Lesen von MVP_STATE_FILE: %w", err)
+	}
+
+	var state mvpState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("MVP_STATE_FILE %q ist ungültig: %w", path, err)
+	}
+	for _, name := range state.Used {
+		set.used[name] = true
+	}
+	for name, n := range state.Counts {
+		set.counts[name] = n
+	}
+	for teamPageID, name := range state.Teams {
+		set.teams[normalizePageID(teamPageID)] = name
+	}
+	return set, nil
+}
+
+// assign wählt count noch nicht vergebene Ideen zufällig aus und markiert sie als vergeben.
+// Sind alle vergeben, beginnt die Vergabe von vorn.
+func (s *mvpUsedSet) assign(ideas []mvpIdea, count int) []mvpIdea {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var unused []mvpIdea
+	for _, idea := range ideas {
+		if !s.used[idea.Name] {
+			unused = append(unused, idea)
+		}
+	}
+	if len(unused) < count {
+		infof("Alle MVP-Ideen vergeben, beginne von vorn")
+		s.used = make(map[string]bool)
+		unused = ideas
+	}
+
+	picked := pickRandom(unused, count)
+	for _, idea := range picked {
+		s.used[idea.Name] = true
+		s.counts[idea.Name]++
+	}
+	if err := s.save(); err != nil {
+		errorf("Fehler beim Speichern von MVP_STATE_FILE: %v", err)
+	}
+	return picked
+}
+
+// assignTeam weist einem Team ein MVP fest zu. Ohne name wird eine noch nicht
+// vergebene Idee gezogen (wie beim Generator). Liefert den zugewiesenen Namen.
+func (s *mvpUsedSet) assignTeam(teamPageID, name string, ideas []mvpIdea) string {
+	if name == "" {
+		name = s.assign(ideas, 1)[0].Name
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.teams[normalizePageID(teamPageID)] = name
+	if err := s.save(); err != nil {
+		errorf("Fehler beim Speichern von MVP_STATE_FILE: %v", err)
+	}
+	return name
+}
+
+// teamMVP liefert das einem Team zugewiesene MVP ("" wenn keins)
+func (s *mvpUsedSet) teamMVP(teamPageID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.teams[normalizePageID(teamPageID)]
+}
+
+// distribution liefert die Vergaben je Idee, häufigste zuerst. Ideen aus ideas
+// ohne Vergabe erscheinen mit 0, damit ein Ungleichgewicht sichtbar wird.
+func (s *mvpUsedSet) distribution(ideas []mvpIdea) []mvpCount {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make(map[string]int, len(ideas))
+	for _, idea := range ideas {
+		counts[idea.Name] = 0
+	}
+	for name, n := range s.counts {
+		counts[name] = n
+	}
+
+	result := make([]mvpCount, 0, len(counts))
+	for name, n := range counts {
+		result = append(result, mvpCount{Name: name, Count: n})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// save schreibt den Stand über eine temporäre Datei, damit ein Absturz keine halbe Datei hinterlässt.
+// Muss unter Lock aufgerufen werden.
+func (s *mvpUsedSet) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	state := mvpState{Used: make([]string, 0, len(s.used)), Counts: s.counts, Teams: s.teams}
+	for name := range s.used {
+		state.Used = append(state.Used, name)
+	}
+	sort.Strings(state.Used)
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".mvp-state-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// handleAdminMVPDistribution zeigt, wie oft jede MVP-Idee bisher vergeben wurde
+func (app *App) handleAdminMVPDistribution(c *gin.Context) {
+	distribution := app.mvpUsed.distribution(app.availableMVPIdeas())
+	total := 0
+	for _, entry := range distribution {
+		total += entry.Count
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"total": total,
+		"ideas": distribution,
+	})
+}
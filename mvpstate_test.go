@@ -0,0 +1,248 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestNewMVPUsedSet(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string // Inhalt von MVP_STATE_FILE ("": Datei fehlt)
+		wantUsed  []string
+		wantCount map[string]int
+		wantTeam  string // MVP von demoTeamPageID
+		wantErr   bool
+	}{
+		{name: "Datei fehlt"},
+		{
+			name:      "aktuelles Format",
+			content:   `{"used": ["Solarlampe"], "counts": {"Solarlampe": 3, "Faltboot": 1}, "teams": {"DEMO-PAGE-1": "Faltboot"}}`,
+			wantUsed:  []string{"Solarlampe"},
+			wantCount: map[string]int{"Solarlampe": 3, "Faltboot": 1},
+			wantTeam:  "Faltboot",
+		},
+		{name: "kaputt", content: `{"used": `, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "mvp-state.json")
+			if tt.content != "" {
+				if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			set, err := newMVPUsedSet(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newMVPUsedSet: %v", err)
+			}
+			if err != nil {
+				return
+			}
+			var used []string
+			for name := range set.used {
+				used = append(used, name)
+			}
+			slices.Sort(used)
+			if !slices.Equal(used, tt.wantUsed) {
+				t.Errorf("used = %v, erwartet %v", used, tt.wantUsed)
+			}
+			for name, want := range tt.wantCount {
+				if set.counts[name] != want {
+					t.Errorf("counts[%s] = %d, erwartet %d", name, set.counts[name], want)
+				}
+			}
+			if got := set.teamMVP(demoTeamPageID); got != tt.wantTeam {
+				t.Errorf("teamMVP = %q, erwartet %q", got, tt.wantTeam)
+			}
+		})
+	}
+}
+
+func TestMVPUsedSetRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mvp-state.json")
+	ideas := []mvpIdea{{Name: "Solarlampe"}, {Name: "Faltboot"}, {Name: "Wasserfilter"}}
+
+	first, err := newMVPUsedSet(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	picked := first.assign(ideas, 2)
+	first.assignTeam(demoTeamPageID, "Solarlampe", ideas)
+
+	// Nach einem Neustart fehlt nur noch die dritte Idee
+	second, err := newMVPUsedSet(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := second.teamMVP(demoTeamPageID); got != "Solarlampe" {
+		t.Errorf("teamMVP nach Neustart = %q", got)
+	}
+	var remaining mvpIdea
+	for _, idea := range ideas {
+		if !slices.Contains(picked, idea) {
+			remaining = idea
+		}
+	}
+	if got := second.assign(ideas, 1); !slices.Equal(got, []mvpIdea{remaining}) {
+		t.Errorf("assign nach Neustart = %v, erwartet %v", got, remaining)
+	}
+
+	// Alle vergeben: Die Vergabe beginnt von vorn, die Zählung bleibt
+	second.assign(ideas, 1)
+	total := 0
+	for _, entry := range second.distribution(ideas) {
+		total += entry.Count
+	}
+	if total != 4 {
+		t.Errorf("Vergaben insgesamt = %d, erwartet 4", total)
+	}
+
+	// Keine temporären Dateien bleiben liegen
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Dateien im Verzeichnis: %v", entries)
+	}
+}
+
+func TestMVPUsedSetInMemory(t *testing.T) {
+	set, err := newMVPUsedSet("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	set.assign([]mvpIdea{{Name: "Solarlampe"}}, 1)
+	if !set.used["Solarlampe"] {
+		t.Error("Vergabe ohne MVP_STATE_FILE nicht gemerkt")
+	}
+}
+
+func TestMVPUsedSetConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mvp-state.json")
+	set, err := newMVPUsedSet(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ideas := make([]mvpIdea, 20)
+	for i := range ideas {
+		ideas[i] = mvpIdea{Name: fmt.Sprintf("Idee %d", i)}
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var got []string
+	for range len(ideas) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			picked := set.assign(ideas, 1)
+			mu.Lock()
+			got = append(got, picked[0].Name)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	slices.Sort(got)
+	if len(slices.Compact(got)) != len(ideas) {
+		t.Errorf("Ideen doppelt vergeben: %v", got)
+	}
+
+	reloaded, err := newMVPUsedSet(path)
+	if err != nil {
+		t.Fatalf("Datei nach gleichzeitigen Vergaben: %v", err)
+	}
+	if len(reloaded.used) != len(ideas) {
+		t.Errorf("%d Ideen gespeichert, erwartet %d", len(reloaded.used), len(ideas))
+	}
+}
+
+func TestAdminMVPDistribution(t *testing.T) {
+	tests := []struct {
+		name      string
+		state     string // Inhalt von MVP_STATE_FILE ("": keine Datei)
+		generator []string
+		want      []mvpCount
+		wantTotal int
+	}{
+		{
+			name:      "keine Vergaben",
+			want:      []mvpCount{{Name: "Faltboot"}, {Name: "Solarlampe"}, {Name: "Wasserfilter"}},
+			wantTotal: 0,
+		},
+		{
+			name:      "Generator",
+			generator: []string{"count=3"},
+			want:      []mvpCount{{Name: "Faltboot", Count: 1}, {Name: "Solarlampe", Count: 1}, {Name: "Wasserfilter", Count: 1}},
+			wantTotal: 3,
+		},
+		{
+			name:      "gespeicherter Stand mit entfernter Idee",
+			state:     `{"counts": {"Solarlampe": 3, "Faltboot": 1, "Alte Idee": 2}}`,
+			want:      []mvpCount{{Name: "Solarlampe", Count: 3}, {Name: "Alte Idee", Count: 2}, {Name: "Faltboot", Count: 1}, {Name: "Wasserfilter"}},
+			wantTotal: 6,
+		},
+		{
+			name:      "Stand plus Generator",
+			state:     `{"used": ["Solarlampe", "Faltboot"], "counts": {"Solarlampe": 2, "Faltboot": 2}}`,
+			generator: []string{"count=1"},
+			want:      []mvpCount{{Name: "Faltboot", Count: 2}, {Name: "Solarlampe", Count: 2}, {Name: "Wasserfilter", Count: 1}},
+			wantTotal: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statePath := filepath.Join(t.TempDir(), "mvp-state.json")
+			if tt.state != "" {
+				if err := os.WriteFile(statePath, []byte(tt.state), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			ta := newTestApp(t, map[string]string{
+				"FEATURE_MVP":    "true",
+				"MVP_FILE":       writeMVPFile(t, `["Solarlampe", "Faltboot", "Wasserfilter"]`),
+				"MVP_STATE_FILE": statePath,
+			})
+			for _, query := range tt.generator {
+				if w := ta.do(http.MethodGet, "/mvpgenerator?format=json&"+query, nil); w.Code != http.StatusOK {
+					t.Fatalf("Generator: %d %s", w.Code, w.Body.String())
+				}
+			}
+
+			w := ta.admin(http.MethodGet, "/admin/mvp-distribution", "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+			var body struct {
+				Total int        `json:"total"`
+				Ideas []mvpCount `json:"ideas"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Total != tt.wantTotal {
+				t.Errorf("total = %d, erwartet %d", body.Total, tt.wantTotal)
+			}
+			if !slices.Equal(body.Ideas, tt.want) {
+				t.Errorf("ideas = %+v, erwartet %+v", body.Ideas, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdminMVPDistributionRequiresToken(t *testing.T) {
+	ta := newTestApp(t, map[string]string{"FEATURE_MVP": "true"})
+	if w := ta.do(http.MethodGet, "/admin/mvp-distribution", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("Status = %d, erwartet %d", w.Code, http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jomei/notionapi"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// notionService kapselt die genutzten Notion-Endpunkte. Produktiv steckt der
+// notionapi-Client dahinter, im DEMO_MODE der In-Memory-Speicher demoStore.
+type notionService interface {
+	QueryDatabase(ctx context.Context, dbID string, req *notionapi.DatabaseQueryRequest) (*notionapi.DatabaseQueryResponse, error)
+	GetPage(ctx context.Context, pageID string) (*notionapi.Page, error)
+	UpdatePage(ctx context.Context, pageID string, req *notionapi.PageUpdateRequest) (*notionapi.Page, error)
+	CreatePage(ctx context.Context, req *notionapi.PageCreateRequest) (*notionapi.Page, error)
+	GetBlockChildren(ctx context.Context, blockID string, pagination *notionapi.Pagination) (*notionapi.GetChildrenResponse, error)
+	GetDatabase(ctx context.Context, dbID string) (*notionapi.Database, error)
+	GetPropertyItems(ctx context.Context, pageID, propertyID, cursor string) (*propertyItemList, error)
+}
+
+// notionRelationLimit ist die Anzahl Relation-Einträge, die Notion beim Lesen einer
+// Page höchstens mitliefert. Längere Relations lädt relationPageIDs seitenweise nach.
+const notionRelationLimit = 25
+
+// propertyItemList ist eine Seite von GET /v1/pages/{page_id}/properties/{property_id}
+type propertyItemList struct {
+	Results    []propertyItem `json:"results"`
+	NextCursor string         `json:"next_cursor"`
+	HasMore    bool           `json:"has_more"`
+}
+
+// propertyItem ist ein Eintrag einer paginierten Property (hier nur Relations)
+type propertyItem struct {
+	Relation notionapi.Relation `json:"relation"`
+}
+
+// notionClient implementiert notionService über die Notion-API
+type notionClient struct {
+	client *notionapi.Client
+	http   *http.Client // für Endpunkte, die notionapi nicht kennt
+}
+
+// Basis-URL und API-Version für Aufrufe am notionapi-Client vorbei
+const (
+	notionAPIBaseURL = "https://api.notion.com/v1"
+	notionAPIVersion = "2022-06-28"
+)
+
+func (n notionClient) QueryDatabase(ctx context.Context, dbID string, req *notionapi.DatabaseQueryRequest) (*notionapi.DatabaseQueryResponse, error) {
+	return n.client.Database.Query(ctx, notionapi.DatabaseID(dbID), req)
+}
+
+func (n notionClient) GetPage(ctx context.Context, pageID string) (*notionapi.Page, error) {
+	return n.client.Page.Get(ctx, notionapi.PageID(pageID))
+}
+
+func (n notionClient) UpdatePage(ctx context.Context, pageID string, req *notionapi.PageUpdateRequest) (*notionapi.Page, error) {
+	return n.client.Page.Update(ctx, notionapi.PageID(pageID), req)
+}
+
+func (n notionClient) CreatePage(ctx context.Context, req *notionapi.PageCreateRequest) (*notionapi.Page, error) {
+	return n.client.Page.Create(ctx, req)
+}
+
+func (n notionClient) GetBlockChildren(ctx context.Context, blockID string, pagination *notionapi.Pagination) (*notionapi.GetChildrenResponse, error) {
+	return n.client.Block.GetChildren(ctx, notionapi.BlockID(blockID), pagination)
+}
+
+func (n notionClient) GetDatabase(ctx context.Context, dbID string) (*notionapi.Database, error) {
+	return n.client.Database.Get(ctx, notionapi.DatabaseID(dbID))
+}
+
+// GetPropertyItems liest eine Seite einer Page-Property. notionapi bietet den Endpunkt
+// nicht an, daher geht der Request direkt über den HTTP-Client des Notion-Clients.
+// Property-IDs liefert Notion bereits URL-kodiert, sie werden unverändert eingesetzt.
+func (n notionClient) GetPropertyItems(ctx context.Context, pageID, propertyID, cursor string) (*propertyItemList, error) {
+	u := fmt.Sprintf("%s/pages/%s/properties/%s", notionAPIBaseURL, url.PathEscape(pageID), propertyID)
+	if cursor != "" {
+		u += "?start_cursor=" + url.QueryEscape(cursor)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+n.client.Token.String())
+	req.Header.Set("Notion-Version", notionAPIVersion)
+
+	resp, err := n.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr notionapi.Error
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil || apiErr.Message == "" {
+			return nil, fmt.Errorf("notion antwortet mit Status %d", resp.StatusCode)
+		}
+		return nil, &apiErr
+	}
+
+	var list propertyItemList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("fehler beim Lesen der Property-Items: %w", err)
+	}
+	return &list, nil
+}
+
+// notionCalls zählt die Notion-API-Aufrufe eines Requests
+type notionCalls struct {
+	queries     atomic.Int64
+	pageGets    atomic.Int64
+	pageUpdates atomic.Int64
+	pageCreates atomic.Int64
+	blockGets   atomic.Int64
+}
+
+// Total liefert die Summe aller gezählten Aufrufe
+func (n *notionCalls) Total() int64 {
+	return n.queries.Load() + n.pageGets.Load() + n.pageUpdates.Load() + n.pageCreates.Load() + n.blockGets.Load()
+}
+
+type notionCallsKey struct{}
+
+// withNotionCalls hängt einen neuen Zähler an den Context
+func withNotionCalls(ctx context.Context) (context.Context, *notionCalls) {
+	calls := &notionCalls{}
+	return context.WithValue(ctx, notionCallsKey{}, calls), calls
+}
+
+// notionCallsFrom liefert den Zähler aus dem Context (nil außerhalb eines Requests)
+func notionCallsFrom(ctx context.Context) *notionCalls {
+	calls, _ := ctx.Value(notionCallsKey{}).(*notionCalls)
+	return calls
+}
+
+// notionCallsMiddleware zählt die Notion-Aufrufe pro Request und loggt sie am Ende.
+// Im Debug-Modus wird die Anzahl zusätzlich als X-Notion-Calls Header gesendet.
+func (app *App) notionCallsMiddleware(c *gin.Context) {
+	ctx, calls := withNotionCalls(c.Request.Context())
+	c.Request = c.Request.WithContext(ctx)
+
+	if app.debug {
+		c.Writer = &notionCallsWriter{ResponseWriter: c.Writer, calls: calls}
+	}
+
+	c.Next()
+
+	if calls.Total() > 0 {
+		infof("%s %s: %d Notion-Aufrufe (%d Queries, %d Page-Gets, %d Page-Updates, %d Page-Creates, %d Block-Gets)",
+			c.Request.Method, c.Request.URL.Path, calls.Total(),
+			calls.queries.Load(), calls.pageGets.Load(), calls.pageUpdates.Load(), calls.pageCreates.Load(), calls.blockGets.Load())
+	}
+}
+
+// notionCallsWriter setzt den X-Notion-Calls Header, bevor die Antwort geschrieben wird
+type notionCallsWriter struct {
+	gin.ResponseWriter
+	calls *notionCalls
+}
+
+func (w *notionCallsWriter) setHeader() {
+	if !w.Written() {
+		w.Header().Set("X-Notion-Calls", strconv.FormatInt(w.calls.Total(), 10))
+	}
+}
+
+func (w *notionCallsWriter) WriteHeaderNow() {
+	w.setHeader()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *notionCallsWriter) Write(data []byte) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *notionCallsWriter) WriteString(s string) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.WriteString(s)
+}
+
+// queryDatabase fragt eine Notion-Datenbank ab und zählt den Aufruf
+func (app *App) queryDatabase(ctx context.Context, dbID string, req *notionapi.DatabaseQueryRequest) (*notionapi.DatabaseQueryResponse, error) {
+	if calls := notionCallsFrom(ctx); calls != nil {
+		calls.queries.Add(1)
+	}
+	start := time.Now()
+	ctx, span := startNotionSpan(ctx, "query", attribute.String("notion.database_id", dbID))
+	resp, err := app.notion.QueryDatabase(ctx, dbID, req)
+	endSpan(span, err)
+	notionLatency.observe("query", start)
+	return resp, err
+}
+
+// getPage holt eine Notion-Page und zählt den Aufruf
+func (app *App) getPage(ctx context.Context, pageID string) (*notionapi.Page, error) {
+	if calls := notionCallsFrom(ctx); calls != nil {
+		calls.pageGets.Add(1)
+	}
+	start := time.Now()
+	ctx, span := startNotionSpan(ctx, "page.get", attribute.String("notion.page_id", pageID))
+	page, err := app.notion.GetPage(ctx, pageID)
+	endSpan(span, err)
+	notionLatency.observe("page.get", start)
+	if err == nil {
+		app.pendingWrites.apply(page)
+	}
+	return page, err
+}
+
+// updatePage aktualisiert die Properties einer Notion-Page und zählt den Aufruf
+func (app *App) updatePage(ctx context.Context, pageID string, req *notionapi.PageUpdateRequest) (*notionapi.Page, error) {
+	if calls := notionCallsFrom(ctx); calls != nil {
+		calls.pageUpdates.Add(1)
+	}
+	start := time.Now()
+	ctx, span := startNotionSpan(ctx, "page.update", attribute.String("notion.page_id", pageID))
+	page, err := app.notion.UpdatePage(ctx, pageID, req)
+	endSpan(span, err)
+	notionLatency.observe("page.update", start)
+	if err == nil {
+		app.pendingWrites.record(pageID, req.Properties)
+	}
+	return page, err
+}
+
+// createPage legt eine neue Notion-Page an und zählt den Aufruf
+func (app *App) createPage(ctx context.Context, req *notionapi.PageCreateRequest) (*notionapi.Page, error) {
+	if calls := notionCallsFrom(ctx); calls != nil {
+		calls.pageCreates.Add(1)
+	}
+	start := time.Now()
+	ctx, span := startNotionSpan(ctx, "page.create")
+	page, err := app.notion.CreatePage(ctx, req)
+	endSpan(span, err)
+	notionLatency.observe("page.create", start)
+	return page, err
+}
+
+// getBlockChildren holt die Blocks einer Page und zählt den Aufruf
+func (app *App) getBlockChildren(ctx context.Context, blockID string, pagination *notionapi.Pagination) (*notionapi.GetChildrenResponse, error) {
+	if calls := notionCallsFrom(ctx); calls != nil {
+		calls.blockGets.Add(1)
+	}
+	start := time.Now()
+	ctx, span := startNotionSpan(ctx, "block.children", attribute.String("notion.block_id", blockID))
+	resp, err := app.notion.GetBlockChildren(ctx, blockID, pagination)
+	endSpan(span, err)
+	notionLatency.observe("block.children", start)
+	return resp, err
+}
+
+// getDatabase holt das Schema einer Notion-Datenbank und zählt den Aufruf als Query
+func (app *App) getDatabase(ctx context.Context, dbID string) (*notionapi.Database, error) {
+	if calls := notionCallsFrom(ctx); calls != nil {
+		calls.queries.Add(1)
+	}
+	start := time.Now()
+	ctx, span := startNotionSpan(ctx, "database.get", attribute.String("notion.database_id", dbID))
+	db, err := app.notion.GetDatabase(ctx, dbID)
+	endSpan(span, err)
+	notionLatency.observe("database.get", start)
+	return db, err
+}
+
+// getPropertyItems holt eine Seite einer Page-Property und zählt den Aufruf als Page-Get
+func (app *App) getPropertyItems(ctx context.Context, pageID, propertyID, cursor string) (*propertyItemList, error) {
+	if calls := notionCallsFrom(ctx); calls != nil {
+		calls.pageGets.Add(1)
+	}
+	start := time.Now()
+	ctx, span := startNotionSpan(ctx, "page.property", attribute.String("notion.page_id", pageID))
+	list, err := app.notion.GetPropertyItems(ctx, pageID, propertyID, cursor)
+	endSpan(span, err)
+	notionLatency.observe("page.property", start)
+	return list, err
+}
+
+// relationPageIDs liefert alle Page-IDs einer Relation-Property. Erreicht die Relation
+// notionRelationLimit, hat Notion sie womöglich gekürzt; dann wird sie über den
+// Property-Endpunkt seitenweise geladen, bis Notion kein has_more mehr meldet.
+func (app *App) relationPageIDs(ctx context.Context, pageID string, prop *notionapi.RelationProperty) ([]string, error) {
+	if len(prop.Relation) < notionRelationLimit {
+		ids := make([]string, 0, len(prop.Relation))
+		for _, relation := range prop.Relation {
+			ids = append(ids, string(relation.ID))
+		}
+		return ids, nil
+	}
+
+	var ids []string
+	cursor := ""
+	for {
+		list, err := app.getPropertyItems(ctx, pageID, string(prop.ID), cursor)
+		if err != nil {
+			return nil, fmt.Errorf("fehler beim Laden der Relation %s: %w", prop.ID, err)
+		}
+		for _, item := range list.Results {
+			ids = append(ids, string(item.Relation.ID))
+		}
+		if !list.HasMore || list.NextCursor == "" {
+			return ids, nil
+		}
+		cursor = list.NextCursor
+	}
+}
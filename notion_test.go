@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jomei/notionapi"
+)
+
+// countingNotion zählt die Aufrufe, die tatsächlich beim notionService ankommen
+type countingNotion struct {
+	notionService
+	calls atomic.Int64
+}
+
+func (n *countingNotion) QueryDatabase(ctx context.Context, dbID string, req *notionapi.DatabaseQueryRequest) (*notionapi.DatabaseQueryResponse, error) {
+	n.calls.Add(1)
+	return n.notionService.QueryDatabase(ctx, dbID, req)
+}
+
+func (n *countingNotion) GetPage(ctx context.Context, pageID string) (*notionapi.Page, error) {
+	n.calls.Add(1)
+	return n.notionService.GetPage(ctx, pageID)
+}
+
+func (n *countingNotion) UpdatePage(ctx context.Context, pageID string, req *notionapi.PageUpdateRequest) (*notionapi.Page, error) {
+	n.calls.Add(1)
+	return n.notionService.UpdatePage(ctx, pageID, req)
+}
+
+func (n *countingNotion) GetBlockChildren(ctx context.Context, blockID string, pagination *notionapi.Pagination) (*notionapi.GetChildrenResponse, error) {
+	n.calls.Add(1)
+	return n.notionService.GetBlockChildren(ctx, blockID, pagination)
+}
+
+func TestNotionCallsHeader(t *testing.T) {
+	tests := []struct {
+		name       string
+		debug      bool
+		warm       bool // Challenge vorher einmal laden, damit sie im Cache liegt
+		wantHeader bool
+	}{
+		{name: "Debug zählt Aufrufe", debug: true, wantHeader: true},
+		{name: "Debug mit Cache-Treffer", debug: true, warm: true, wantHeader: true},
+		{name: "ohne Debug kein Header", debug: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"DEBUG": strconv.FormatBool(tt.debug)})
+			counting := &countingNotion{notionService: ta.store}
+			ta.notion = counting
+			if tt.warm {
+				ta.do(http.MethodGet, "/api/challenges/1", nil)
+				counting.calls.Store(0)
+			}
+
+			w := ta.do(http.MethodGet, "/api/challenges/1", nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+
+			header := w.Header().Get("X-Notion-Calls")
+			if !tt.wantHeader {
+				if header != "" {
+					t.Errorf("X-Notion-Calls = %q, erwartet keinen Header", header)
+				}
+				return
+			}
+			if want := strconv.FormatInt(counting.calls.Load(), 10); header != want {
+				t.Errorf("X-Notion-Calls = %q, erwartet %s", header, want)
+			}
+			if !tt.warm && header == "0" {
+				t.Error("ohne Cache muss die Challenge aus Notion geladen werden")
+			}
+			if tt.warm && header != "0" {
+				t.Errorf("Cache-Treffer sollte keine Notion-Aufrufe brauchen, X-Notion-Calls = %q", header)
+			}
+		})
+	}
+}
+
+// propertyItemsNotion zählt die Aufrufe des Property-Endpunkts
+type propertyItemsNotion struct {
+	notionService
+	calls atomic.Int64
+}
+
+func (n *propertyItemsNotion) GetPropertyItems(ctx context.Context, pageID, propertyID, cursor string) (*propertyItemList, error) {
+	n.calls.Add(1)
+	return n.notionService.GetPropertyItems(ctx, pageID, propertyID, cursor)
+}
+
+func TestRelationPageIDs(t *testing.T) {
+	tests := []struct {
+		size      int // Einträge der Relation in Notion
+		wantCalls int64
+	}{
+		{size: 0},
+		{size: 1},
+		{size: notionRelationLimit - 1},
+		{size: notionRelationLimit, wantCalls: 1},
+		{size: notionRelationLimit + 1, wantCalls: 2},
+		{size: 60, wantCalls: 3},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.size), func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			counting := &propertyItemsNotion{notionService: ta.store}
+			ta.notion = counting
+
+			full := make([]notionapi.Relation, tt.size)
+			want := make([]string, tt.size)
+			for i := range full {
+				want[i] = fmt.Sprintf("demo-voraussetzung-%d", i)
+				full[i] = notionapi.Relation{ID: notionapi.PageID(want[i])}
+			}
+			page := addDemoChallenge(ta.store, "demo-lang", 9, notionapi.Properties{
+				challengePrerequisitesProperty: &notionapi.RelationProperty{ID: "prq", Relation: full},
+			})
+
+			// Notion liefert beim Lesen der Page höchstens notionRelationLimit Einträge
+			truncated := &notionapi.RelationProperty{ID: "prq", Relation: full[:min(tt.size, notionRelationLimit)]}
+			got, err := ta.relationPageIDs(t.Context(), string(page.ID), truncated)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("%d Page-IDs, erwartet %d: %v", len(got), len(want), got)
+			}
+			if calls := counting.calls.Load(); calls != tt.wantCalls {
+				t.Errorf("%d Aufrufe des Property-Endpunkts, erwartet %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRelationPageIDsError(t *testing.T) {
+	ta := newTestApp(t, nil)
+	relation := &notionapi.RelationProperty{ID: "prq", Relation: make([]notionapi.Relation, notionRelationLimit)}
+	if _, err := ta.relationPageIDs(t.Context(), "gibt-es-nicht", relation); err == nil {
+		t.Error("kein Fehler für eine unbekannte Page")
+	}
+}
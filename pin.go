@@ -0,0 +1,103 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Mit EVENT_PIN sind Formular und Weiterleitung erst nach Eingabe der Event-PIN erreichbar.
+// Die PIN wird einmal eingegeben und danach in einem signierten Cookie vermerkt.
+const (
+	pinCookieName   = "event_pin"
+	pinCookieMaxAge = 24 * time.Hour
+)
+
+// Gegen Durchprobieren der PIN: nach pinMaxAttempts Fehlversuchen wird eine IP-Adresse
+// bis pinLockout nach dem letzten Fehlversuch abgewiesen
+const (
+	pinMaxAttempts = 5
+	pinLockout     = 15 * time.Minute
+)
+
+// newPINAttempts zählt falsche PINs pro Client-IP. X-Forwarded-For zählt dabei nur
+// hinter Proxies aus TRUSTED_PROXIES, sonst die Adresse der Verbindung.
+func newPINAttempts() *answerAttempts {
+	return &answerAttempts{entries: make(map[string]answerAttempt), ttl: pinLockout}
+}
+
+// pinCookieValue ist an Session-Schlüssel und PIN gebunden; eine neue PIN macht alte Cookies ungültig
+func pinCookieValue(secret, pin string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("event-pin|" + pin))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// requirePIN zeigt ohne gültiges PIN-Cookie die PIN-Abfrage statt der Route
+func (app *App) requirePIN(c *gin.Context) {
+	if app.eventPIN == "" {
+		c.Next()
+		return
+	}
+	if value, err := c.Cookie(pinCookieName); err == nil &&
+		hmac.Equal([]byte(value), []byte(pinCookieValue(app.sessionSecret, app.eventPIN))) {
+		c.Next()
+		return
+	}
+
+	// Nach der PIN geht es zurück zum Formular; ein POST wird dabei nicht wiederholt
+	app.renderPINGate(c, http.StatusUnauthorized, c.Request.URL.Path, "")
+	c.Abort()
+}
+
+// handlePIN prüft die eingegebene PIN und setzt bei Erfolg das Cookie
+func (app *App) handlePIN(c *gin.Context) {
+	next := localRedirectTarget(c.PostForm("next"))
+	pin := strings.TrimSpace(c.PostForm("pin"))
+	ip := c.ClientIP()
+
+	if app.pinAttempts.count(ip, "pin") >= pinMaxAttempts {
+		warnf("Event-PIN von %s gesperrt nach %d Fehlversuchen", ip, pinMaxAttempts)
+		c.Header("Retry-After", strconv.Itoa(int(pinLockout.Seconds())))
+		app.renderPINGate(c, http.StatusTooManyRequests, next, "Zu viele Fehlversuche, bitte später erneut versuchen")
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(pin), []byte(app.eventPIN)) != 1 {
+		app.pinAttempts.recordWrong(ip, "pin")
+		warnf("Falsche Event-PIN von %s", ip)
+		app.renderPINGate(c, http.StatusUnauthorized, next, "Falsche PIN, bitte erneut versuchen")
+		return
+	}
+	app.pinAttempts.reset(ip, "pin")
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(pinCookieName, pinCookieValue(app.sessionSecret, app.eventPIN), int(pinCookieMaxAge.Seconds()), "/", "", c.Request.TLS != nil, true)
+	c.Redirect(http.StatusSeeOther, next)
+}
+
+func (app *App) renderPINGate(c *gin.Context, status int, next, message string) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(status)
+	if err := app.templates.ExecuteTemplate(c.Writer, "pin.html", gin.H{
+		"next":  next,
+		"error": message,
+	}); err != nil {
+		app.templateFailed(c, err)
+	}
+}
+
+// localRedirectTarget lässt nur Pfade auf dieser Seite als Weiterleitungsziel zu
+func localRedirectTarget(target string) string {
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") || strings.HasPrefix(target, "/\\") {
+		return "/"
+	}
+	return target
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestLocalRedirectTarget(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{in: "/next/1", want: "/next/1"},
+		{in: "/next/1?challenge=2", want: "/next/1?challenge=2"},
+		{in: "", want: "/"},
+		{in: "https://evil.example.org/", want: "/"},
+		{in: "//evil.example.org/", want: "/"},
+		{in: `/\evil.example.org`, want: "/"},
+		{in: "next/1", want: "/"},
+	}
+	for _, tt := range tests {
+		if got := localRedirectTarget(tt.in); got != tt.want {
+			t.Errorf("localRedirectTarget(%q) = %q, erwartet %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// withCookies schickt einen Request mit den Cookies einer vorherigen Antwort
+func (ta *testApp) withCookies(req *http.Request, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	w := httptest.NewRecorder()
+	ta.handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestEventPIN(t *testing.T) {
+	tests := []struct {
+		name         string
+		pin          string // EVENT_PIN
+		entered      string // eingegebene PIN, "" ohne Eingabe
+		wantGate     bool
+		wantPINCode  int
+		wantFormCode int
+	}{
+		{name: "ohne EVENT_PIN offen", wantFormCode: http.StatusOK},
+		{name: "ohne Eingabe gesperrt", pin: "4711", wantGate: true, wantFormCode: http.StatusUnauthorized},
+		{name: "richtige PIN", pin: "4711", entered: " 4711 ", wantGate: true, wantPINCode: http.StatusSeeOther, wantFormCode: http.StatusOK},
+		{name: "falsche PIN", pin: "4711", entered: "1234", wantGate: true, wantPINCode: http.StatusUnauthorized, wantFormCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"EVENT_PIN": tt.pin})
+
+			w := ta.do(http.MethodGet, "/next/1", nil)
+			if gated := w.Code == http.StatusUnauthorized; gated != tt.wantGate {
+				t.Fatalf("Formular ohne PIN: Status = %d", w.Code)
+			}
+			if tt.wantGate && !strings.Contains(w.Body.String(), `value="/next/1"`) {
+				t.Errorf("PIN-Abfrage ohne Rücksprung zum Formular:\n%s", w.Body.String())
+			}
+			// Auch das Weiterkommen ist gesperrt, ohne etwas zu speichern
+			if w := ta.advance("1", "Demo Team"); tt.wantGate && w.Code != http.StatusUnauthorized {
+				t.Errorf("Weiterleitung ohne PIN: Status = %d", w.Code)
+			}
+
+			var cookies []*http.Cookie
+			if tt.entered != "" {
+				w := ta.do(http.MethodPost, "/pin", url.Values{"pin": {tt.entered}, "next": {"/next/1"}})
+				if w.Code != tt.wantPINCode {
+					t.Fatalf("PIN: Status = %d, erwartet %d", w.Code, tt.wantPINCode)
+				}
+				if w.Code == http.StatusSeeOther && w.Header().Get("Location") != "/next/1" {
+					t.Errorf("Location = %q", w.Header().Get("Location"))
+				}
+				cookies = w.Result().Cookies()
+			}
+
+			w = ta.withCookies(httptest.NewRequest(http.MethodGet, "/next/1", nil), cookies)
+			if w.Code != tt.wantFormCode {
+				t.Errorf("Formular: Status = %d, erwartet %d", w.Code, tt.wantFormCode)
+			}
+		})
+	}
+}
+
+func TestEventPINCookieFromOldPIN(t *testing.T) {
+	ta := newTestApp(t, map[string]string{"EVENT_PIN": "neu", "SESSION_SECRET": "s"})
+	old := &http.Cookie{Name: pinCookieName, Value: pinCookieValue("s", "alt")}
+	if w := ta.withCookies(httptest.NewRequest(http.MethodGet, "/next/1", nil), []*http.Cookie{old}); w.Code != http.StatusUnauthorized {
+		t.Errorf("Cookie der alten PIN: Status = %d, erwartet 401", w.Code)
+	}
+}
+
+func TestEventPINLockout(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts []string // nacheinander eingegebene PINs
+		want     []int
+	}{
+		{
+			name:     "Sperre nach fünf Fehlversuchen",
+			attempts: []string{"1", "2", "3", "4", "5", "4711"},
+			want:     []int{401, 401, 401, 401, 401, 429},
+		},
+		{
+			name:     "Erfolg setzt den Zähler zurück",
+			attempts: []string{"1", "2", "3", "4", "4711", "1", "2", "3", "4", "4711"},
+			want:     []int{401, 401, 401, 401, 303, 401, 401, 401, 401, 303},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"EVENT_PIN": "4711"})
+			for i, pin := range tt.attempts {
+				w := ta.do(http.MethodPost, "/pin", url.Values{"pin": {pin}})
+				if w.Code != tt.want[i] {
+					t.Fatalf("Versuch %d: Status = %d, erwartet %d", i+1, w.Code, tt.want[i])
+				}
+				if w.Code == http.StatusTooManyRequests && w.Header().Get("Retry-After") == "" {
+					t.Error("Retry-After fehlt")
+				}
+			}
+		})
+	}
+}
+
+func TestEventPINLockoutForwardedFor(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies string
+		wantLocked     bool
+	}{
+		// httptest-Requests kommen von 192.0.2.1
+		{name: "ohne vertrauenswürdige Proxies", wantLocked: true},
+		{name: "fremder Proxy", trustedProxies: "10.0.0.0/8", wantLocked: true},
+		{name: "bekannter Proxy", trustedProxies: "192.0.2.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"EVENT_PIN": "4711", "TRUSTED_PROXIES": tt.trustedProxies})
+
+			// Jeder Versuch gibt eine andere Client-IP vor
+			status := 0
+			for i := 1; i <= pinMaxAttempts+1; i++ {
+				req := httptest.NewRequest(http.MethodPost, "/pin", strings.NewReader(url.Values{"pin": {"0000"}}.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				req.Header.Set("X-Forwarded-For", fmt.Sprintf("198.51.100.%d", i))
+				w := httptest.NewRecorder()
+				ta.handler.ServeHTTP(w, req)
+				status = w.Code
+			}
+			if locked := status == http.StatusTooManyRequests; locked != tt.wantLocked {
+				t.Errorf("Status nach %d Fehlversuchen = %d, gesperrt erwartet: %v", pinMaxAttempts+1, status, tt.wantLocked)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jomei/notionapi"
+)
+
+// teamPosition beschreibt, an welcher Challenge der Route ein Team gerade steht
+type teamPosition struct {
+	Team           string `json:"team"`
+	Position       int    `json:"position"`
+	RouteLength    int    `json:"routeLength"`
+	ChallengeID    int    `json:"challengeId,omitempty"`
+	ChallengeTitle string `json:"challengeTitle,omitempty"`
+	Finished       bool   `json:"finished"`
+}
+
+// handleAdminPositions listet alle Teams mit ihrer aktuellen Challenge
+func (app *App) handleAdminPositions(c *gin.Context) {
+	positions, err := app.getTeamPositions(c.Request.Context())
+	if err != nil {
+		errorf("Fehler beim Ermitteln der Team-Positionen: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": app.userError("Fehler beim Ermitteln der Team-Positionen", err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"teams": positions})
+}
+
+// peekChallenge beschreibt eine Challenge in der Vorschau von /admin/peek
+type peekChallenge struct {
+	Position int    `json:"position"`
+	ID       int    `json:"id"`
+	Title    string `json:"title"`
+	URL      string `json:"url"`
+}
+
+// handleAdminPeek zeigt die aktuelle und die nächste Challenge eines Teams, ohne etwas zu speichern.
+// Aktuell ist die erste nicht abgeschlossene Challenge der Route, die nächste ermittelt findNextChallenge.
+func (app *App) handleAdminPeek(c *gin.Context) {
+	ctx := c.Request.Context()
+	teamName := c.Param("team")
+
+	teamPageID, err := app.findTeamPage(ctx, teamName)
+	if err != nil || teamPageID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team nicht gefunden"})
+		return
+	}
+
+	route, err := app.getTeamChallenges(ctx, teamPageID)
+	if err != nil {
+		errorf("Fehler beim Abrufen der Challenges: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fehler beim Abrufen der Team-Daten"})
+		return
+	}
+	completed, err := app.getCompletions(ctx, teamPageID)
+	if err != nil {
+		errorf("Fehler beim Abrufen der Abschlüsse: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fehler beim Abrufen der Team-Daten"})
+		return
+	}
+
+	result := gin.H{"team": teamName, "routeLength": len(route), "finished": true}
+	if app.finishMode == finishModeCount && len(completed) >= app.finishCount {
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	slots := make([]int, 0, len(route))
+	for pos := range route {
+		slots = append(slots, pos)
+	}
+	sort.Ints(slots)
+
+	for _, pos := range slots {
+		id, _ := strconv.Atoi(route[pos])
+		if _, done := completed[id]; done {
+			continue
+		}
+		current, err := app.getChallenge(ctx, route[pos])
+		if err != nil {
+			errorf("Fehler beim Abrufen der Challenge %s: %v", route[pos], err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Fehler beim Abrufen der Challenge " + route[pos]})
+			return
+		}
+		result["finished"] = false
+		result["current"] = peekChallenge{Position: pos, ID: current.ID, Title: current.Title, URL: current.URL}
+		next, err := app.findNextChallenge(ctx, route, route[pos])
+		if err != nil {
+			errorf("Fehler beim Ermitteln der nächsten Challenge: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": app.userError("Fehler beim Abrufen der nächsten Challenge", err)})
+			return
+		}
+		if next != nil {
+			result["next"] = peekChallenge{Position: currentPosition(route, route[pos]) + 1, ID: next.ID, Title: next.Title, URL: next.URL}
+		}
+		break
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// getTeamPositions ermittelt für alle Teams die erste nicht abgeschlossene Challenge ihrer Route.
+// Teams und Challenges werden je einmal komplett geladen statt pro Team einzeln.
+func (app *App) getTeamPositions(ctx context.Context) ([]teamPosition, error) {
+	pages, err := app.listTeamPages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	challenges, err := app.loadChallenges(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byPageID := make(map[string]*Challenge, len(challenges))
+	for _, challenge := range challenges {
+		byPageID[normalizePageID(challenge.PageID)] = challenge
+	}
+
+	var positions []teamPosition
+	for _, page := range pages {
+		name := pageTitle(page)
+		if name == "" {
+			continue
+		}
+
+		route := routeChallenges(page, byPageID)
+		completed := app.completedChallenges(&page)
+		position := teamPosition{Team: name, RouteLength: len(route), Position: len(route) + 1, Finished: true}
+		for i, challenge := range route {
+			if _, done := completed[challenge.ID]; !done {
+				position.Position = i + 1
+				position.ChallengeID = challenge.ID
+				position.ChallengeTitle = challenge.Title
+				position.Finished = false
+				break
+			}
+		}
+		if app.finishMode == finishModeCount && len(completed) >= app.finishCount {
+			position.Finished = true
+		}
+
+		positions = append(positions, position)
+	}
+
+	// Am weitesten fortgeschrittene Teams zuerst
+	sort.SliceStable(positions, func(i, j int) bool {
+		a, b := positions[i], positions[j]
+		if a.Finished != b.Finished {
+			return a.Finished
+		}
+		if a.Position != b.Position {
+			return a.Position > b.Position
+		}
+		return a.Team < b.Team
+	})
+
+	return positions, nil
+}
+
+// routeChallenges liest die ChallengeN-Relations einer Team-Page in Routen-Reihenfolge.
+// Relations auf unbekannte Challenge-Pages werden übersprungen.
+func routeChallenges(page notionapi.Page, byPageID map[string]*Challenge) []*Challenge {
+	var slots []int
+	relations := make(map[int]string)
+	for propName, prop := range page.Properties {
+		var num int
+		if _, err := fmt.Sscanf(propName, "Challenge%d", &num); err != nil || propName != fmt.Sprintf("Challenge%d", num) {
+			continue
+		}
+		if pageID, ok := routeRelation(prop); ok {
+			slots = append(slots, num)
+			relations[num] = pageID
+		}
+	}
+	sort.Ints(slots)
+
+	var route []*Challenge
+	for _, num := range slots {
+		if challenge, ok := byPageID[normalizePageID(relations[num])]; ok {
+			route = append(route, challenge)
+		}
+	}
+	return route
+}
+
+// normalizePageID vereinheitlicht Page-IDs mit und ohne Bindestriche für Vergleiche
+func normalizePageID(id string) string {
+	return strings.ToLower(strings.ReplaceAll(id, "-", ""))
+}
+
+// Modi für POSITION_MODE: woran die aktuelle Position eines Teams beim Weiterleiten festgemacht wird
+const (
+	positionModeRelation = "relation" // aufgerufene Challenge (Standard)
+	positionModeFallback = "fallback" // Abschluss-Marker, wenn die aufgerufene Challenge nicht auf der Route liegt
+	positionModeMarkers  = "markers"  // immer die höchste abgeschlossene Position laut Abschluss-Markern
+)
+
+// parsePositionMode liest POSITION_MODE
+func parsePositionMode(s string) (string, error) {
+	switch strings.ToLower(s) {
+	case "", positionModeRelation:
+		return positionModeRelation, nil
+	case positionModeFallback, positionModeMarkers:
+		return strings.ToLower(s), nil
+	default:
+		return "", fmt.Errorf("POSITION_MODE %q ist ungültig (relation, fallback, markers)", s)
+	}
+}
+
+// positionChallengeID bestimmt die Challenge, ab der die nächste gesucht wird.
+// Je nach POSITION_MODE ist das die aufgerufene Challenge oder die laut
+// Abschluss-Markern am weitesten fortgeschrittene Challenge der Route.
+func (app *App) positionChallengeID(ctx context.Context, teamPageID string, challenges map[int]string, currentID string) string {
+	if app.positionMode == positionModeRelation {
+		return currentID
+	}
+	if app.positionMode == positionModeFallback && currentPosition(challenges, currentID) > 0 {
+		return currentID
+	}
+
+	completed, err := app.getCompletions(ctx, teamPageID)
+	if err != nil {
+		errorf("Fehler beim Lesen der Abschluss-Marker, nutze Challenge %s: %v", currentID, err)
+		return currentID
+	}
+
+	derived := challenges[markerPosition(challenges, completed)]
+	if derived != currentID {
+		warnf("Position aus Abschluss-Markern: Challenge %q statt %q", derived, currentID)
+	}
+	return derived
+}
+
+// markerPosition liefert die höchste Routen-Position, deren Challenge einen
+// Abschluss-Marker hat (0, wenn noch keine Challenge der Route abgeschlossen ist)
+func markerPosition(challenges map[int]string, completed map[int]time.Time) int {
+	highest := 0
+	for pos, id := range challenges {
+		num, err := strconv.Atoi(id)
+		if err != nil {
+			continue
+		}
+		if _, done := completed[num]; done && pos > highest {
+			highest = pos
+		}
+	}
+	return highest
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestParsePositionMode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "", want: positionModeRelation},
+		{in: "relation", want: positionModeRelation},
+		{in: "Fallback", want: positionModeFallback},
+		{in: "markers", want: positionModeMarkers},
+		{in: "highest", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parsePositionMode(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("parsePositionMode(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestMarkerPosition(t *testing.T) {
+	done := time.Now()
+	tests := []struct {
+		name      string
+		route     map[int]string
+		completed []int
+		want      int
+	}{
+		{name: "nichts abgeschlossen", route: map[int]string{1: "1", 2: "2"}, want: 0},
+		{name: "der Reihe nach", route: map[int]string{1: "1", 2: "2", 3: "3"}, completed: []int{1, 2}, want: 2},
+		{name: "mit Lücke", route: map[int]string{1: "1", 2: "2", 3: "3"}, completed: []int{1, 3}, want: 3},
+		{name: "andere Reihenfolge", route: map[int]string{1: "4", 2: "2", 3: "7"}, completed: []int{7, 4}, want: 3},
+		{name: "Abschluss nicht auf der Route", route: map[int]string{1: "1", 2: "2"}, completed: []int{1, 9}, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			completed := make(map[int]time.Time)
+			for _, id := range tt.completed {
+				completed[id] = done
+			}
+			if got := markerPosition(tt.route, completed); got != tt.want {
+				t.Errorf("markerPosition = %d, erwartet %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPositionChallengeID(t *testing.T) {
+	// Route des Demo Teams wie aus den Relationen gelesen
+	route := map[int]string{1: "1", 2: "2", 3: "3", 4: "4"}
+
+	tests := []struct {
+		name      string
+		mode      string
+		currentID string
+		completed []int
+		want      string
+	}{
+		{name: "relation vertraut der aufgerufenen Challenge", mode: "relation", currentID: "1", completed: []int{1, 2, 3}, want: "1"},
+		{name: "fallback mit Challenge auf der Route", mode: "fallback", currentID: "1", completed: []int{1, 2, 3}, want: "1"},
+		{name: "fallback mit fremder Challenge", mode: "fallback", currentID: "9", completed: []int{1, 2}, want: "2"},
+		{name: "markers mit weiterem Fortschritt", mode: "markers", currentID: "1", completed: []int{1, 2, 3}, want: "3"},
+		{name: "markers ohne Abweichung", mode: "markers", currentID: "2", completed: []int{1, 2}, want: "2"},
+		{name: "markers ohne Abschlüsse", mode: "markers", currentID: "2", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"POSITION_MODE": tt.mode})
+			props := notionapi.Properties{}
+			for _, num := range tt.completed {
+				at := notionapi.Date(time.Now())
+				props[ta.completionProperty(num)] = notionapi.DateProperty{Date: &notionapi.DateObject{Start: &at}}
+			}
+			if _, err := ta.store.UpdatePage(t.Context(), demoTeamPageID, &notionapi.PageUpdateRequest{Properties: props}); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := ta.positionChallengeID(t.Context(), demoTeamPageID, route, tt.currentID); got != tt.want {
+				t.Errorf("positionChallengeID = %q, erwartet %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPositionModeAdvance(t *testing.T) {
+	// Challenges 1 und 2 sind laut Markern erledigt, das Team ruft aber erneut Challenge 1 auf
+	tests := []struct {
+		mode     string
+		wantSlug string
+	}{
+		{mode: "relation", wantSlug: "demo-kirchturm"},
+		{mode: "markers", wantSlug: "demo-stadtpark"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"POSITION_MODE": tt.mode})
+			for _, id := range []string{"1", "2"} {
+				if err := ta.recordCompletion(t.Context(), demoTeamPageID, id, false, ""); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			w := ta.advance("1", "Demo Team")
+			if !strings.Contains(w.Body.String(), demoChallengeURL+tt.wantSlug) {
+				t.Errorf("keine Weiterleitung nach %s (%d):\n%s", tt.wantSlug, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAdminPeek(t *testing.T) {
+	tests := []struct {
+		name         string
+		env          map[string]string
+		team         string
+		teamPageID   string
+		completed    []int
+		wantFinished bool
+		wantCurrent  *peekChallenge
+		wantNext     *peekChallenge
+	}{
+		{
+			name: "am Start", team: "Demo%20Team", teamPageID: demoTeamPageID,
+			wantCurrent: &peekChallenge{Position: 1, ID: 1, Title: "Der alte Brunnen"},
+			wantNext:    &peekChallenge{Position: 2, ID: 2},
+		},
+		{
+			name: "unterwegs", team: "Demo%20Team", teamPageID: demoTeamPageID, completed: []int{1, 2},
+			wantCurrent: &peekChallenge{Position: 3, ID: 3},
+			wantNext:    &peekChallenge{Position: 4, ID: 4},
+		},
+		{
+			name: "eigene Reihenfolge", team: "Die%20F%C3%BCchse", teamPageID: foxesTeamPageID, completed: []int{1},
+			wantCurrent: &peekChallenge{Position: 2, ID: 3},
+			wantNext:    &peekChallenge{Position: 3, ID: 2},
+		},
+		{
+			name: "letzte Station", team: "Demo%20Team", teamPageID: demoTeamPageID, completed: []int{1, 2, 3},
+			wantCurrent: &peekChallenge{Position: 4, ID: 4},
+		},
+		{name: "fertig", team: "Demo%20Team", teamPageID: demoTeamPageID, completed: []int{1, 2, 3, 4}, wantFinished: true},
+		{
+			name: "fertig nach FINISH_COUNT", env: map[string]string{"FINISH_MODE": "count", "FINISH_COUNT": "2"},
+			team: "Demo%20Team", teamPageID: demoTeamPageID, completed: []int{1, 3}, wantFinished: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, tt.env)
+			props := notionapi.Properties{}
+			for _, num := range tt.completed {
+				at := notionapi.Date(time.Now())
+				props[ta.completionProperty(num)] = notionapi.DateProperty{Date: &notionapi.DateObject{Start: &at}}
+			}
+			if _, err := ta.store.UpdatePage(t.Context(), tt.teamPageID, &notionapi.PageUpdateRequest{Properties: props}); err != nil {
+				t.Fatal(err)
+			}
+			before := ta.teamPage(t, tt.teamPageID)
+
+			w := ta.admin(http.MethodGet, "/admin/peek/"+tt.team, "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+			var result struct {
+				Finished bool           `json:"finished"`
+				Current  *peekChallenge `json:"current"`
+				Next     *peekChallenge `json:"next"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+				t.Fatal(err)
+			}
+
+			if result.Finished != tt.wantFinished {
+				t.Errorf("finished = %v, erwartet %v", result.Finished, tt.wantFinished)
+			}
+			checkPeek(t, "current", result.Current, tt.wantCurrent)
+			checkPeek(t, "next", result.Next, tt.wantNext)
+
+			// Vorschau schreibt nichts
+			after := ta.teamPage(t, tt.teamPageID)
+			if !after.LastEditedTime.Equal(before.LastEditedTime) || len(ta.completedChallenges(after)) != len(tt.completed) {
+				t.Errorf("Team-Page verändert: Abschlüsse %v", ta.completedChallenges(after))
+			}
+		})
+	}
+}
+
+// checkPeek vergleicht eine Challenge der Vorschau; leere Felder in want werden nicht geprüft
+func checkPeek(t *testing.T, field string, got, want *peekChallenge) {
+	t.Helper()
+	if (got == nil) != (want == nil) {
+		t.Errorf("%s = %+v, erwartet %+v", field, got, want)
+		return
+	}
+	if got == nil {
+		return
+	}
+	if got.Position != want.Position || got.ID != want.ID || (want.Title != "" && got.Title != want.Title) || got.URL == "" {
+		t.Errorf("%s = %+v, erwartet %+v", field, got, want)
+	}
+}
+
+func TestAdminPeekUnknownTeam(t *testing.T) {
+	ta := newTestApp(t, nil)
+	if w := ta.admin(http.MethodGet, "/admin/peek/Unbekannt", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Status = %d, erwartet %d", w.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jomei/notionapi"
+)
+
+// challengePrerequisitesProperty ist die Relation auf Challenges, die vorher abgeschlossen sein müssen
+const challengePrerequisitesProperty = "Prerequisites"
+
+// prerequisitePageIDs liest die Page-IDs der Voraussetzungen einer Challenge-Page,
+// so wie Notion sie beim Lesen der Page liefert (höchstens notionRelationLimit).
+// Vollständig lädt sie loadPrerequisites.
+func prerequisitePageIDs(page notionapi.Page) []string {
+	p, ok := page.Properties[challengePrerequisitesProperty].(*notionapi.RelationProperty)
+	if !ok {
+		return nil
+	}
+	ids := make([]string, 0, len(p.Relation))
+	for _, relation := range p.Relation {
+		ids = append(ids, string(relation.ID))
+	}
+	return ids
+}
+
+// loadPrerequisites lädt die Voraussetzungen einer Challenge nach, wenn Notion die
+// Relation beim Lesen der Page gekürzt haben kann
+func (app *App) loadPrerequisites(ctx context.Context, challenge *Challenge, page notionapi.Page) error {
+	p, ok := page.Properties[challengePrerequisitesProperty].(*notionapi.RelationProperty)
+	if !ok || len(p.Relation) < notionRelationLimit {
+		return nil
+	}
+	ids, err := app.relationPageIDs(ctx, string(page.ID), p)
+	if err != nil {
+		return fmt.Errorf("fehler beim Laden der Voraussetzungen von Challenge %d: %w", challenge.ID, err)
+	}
+	challenge.Prerequisites = ids
+	return nil
+}
+
+// unmetPrerequisites liefert alle Voraussetzungen einer Challenge, die noch nicht
+// abgeschlossen sind, aufsteigend nach Challenge-ID sortiert
+func (app *App) unmetPrerequisites(ctx context.Context, challenge *Challenge, completed map[int]time.Time) ([]*Challenge, error) {
+	var unmet []*Challenge
+	for _, pageID := range challenge.Prerequisites {
+		page, err := app.getPage(ctx, pageID)
+		if err != nil {
+			return nil, fmt.Errorf("fehler beim Laden der Voraussetzung %s: %w", pageID, err)
+		}
+		prerequisite := app.challengeFromPage(*page)
+		if _, done := completed[prerequisite.ID]; !done {
+			unmet = append(unmet, prerequisite)
+		}
+	}
+
+	sort.Slice(unmet, func(i, j int) bool { return unmet[i].ID < unmet[j].ID })
+	return unmet, nil
+}
+
+// earliestReachablePrerequisite wählt die erste offene Voraussetzung, die auf der
+// Route des Teams liegt. Liegt keine auf der Route, kann das Team sie nicht erreichen.
+func earliestReachablePrerequisite(unmet []*Challenge, route map[int]string) *Challenge {
+	for _, prerequisite := range unmet {
+		if routeContains(route, strconv.Itoa(prerequisite.ID)) {
+			return prerequisite
+		}
+	}
+	return nil
+}
+
+// checkPrerequisites prüft die Voraussetzungen der nächsten Challenge. Ist eine offen,
+// wird stattdessen die früheste erreichbare Voraussetzung geliefert. Liegt keine offene
+// Voraussetzung auf der Route, wird eine Erklärung gerendert und false geliefert.
+func (app *App) checkPrerequisites(c *gin.Context, teamPageID string, route map[int]string, next *Challenge) (*Challenge, bool) {
+	ctx := c.Request.Context()
+
+	completed, err := app.getCompletions(ctx, teamPageID)
+	if err != nil {
+		// Ohne Abschlussdaten lieber normal weiterleiten als das Team festzuhalten
+		errorf("Fehler beim Prüfen der Voraussetzungen: %v", err)
+		return next, true
+	}
+
+	unmet, err := app.unmetPrerequisites(ctx, next, completed)
+	if err != nil {
+		errorf("Fehler beim Prüfen der Voraussetzungen: %v", err)
+		return next, true
+	}
+	if len(unmet) == 0 {
+		return next, true
+	}
+
+	if prerequisite := earliestReachablePrerequisite(unmet, route); prerequisite != nil {
+		debugf("Challenge %d hat offene Voraussetzung %d, leite dorthin um", next.ID, prerequisite.ID)
+		return prerequisite, true
+	}
+
+	titles := make([]string, 0, len(unmet))
+	for _, prerequisite := range unmet {
+		titles = append(titles, prerequisite.Title)
+	}
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	app.templates.ExecuteTemplate(c.Writer, "error.html", gin.H{
+		"error": fmt.Sprintf("Für die nächste Challenge müsst ihr zuerst abschließen: %s", strings.Join(titles, ", ")),
+	})
+	return nil, false
+}
+
+// prerequisiteCycleError beschreibt Challenges, die sich im Kreis gegenseitig vorausgehen
+// müssen. Über Prerequisites kann kein Team sie je erreichen, über NextRules dreht es sich im Kreis.
+type prerequisiteCycleError struct {
+	IDs []int // Challenge-IDs entlang des Zyklus ("kommt nach"), die erste wiederholt sich am Ende
+	// ViaNextRules ist gesetzt, wenn mindestens eine Kante des Zyklus aus NextRules stammt
+	ViaNextRules bool
+}
+
+func (e *prerequisiteCycleError) Error() string {
+	parts := make([]string, len(e.IDs))
+	for i, id := range e.IDs {
+		parts[i] = strconv.Itoa(id)
+	}
+	if e.ViaNextRules {
+		return "zirkuläre Reihenfolge aus Prerequisites und NextRules: " + strings.Join(parts, " → ")
+	}
+	return "zirkuläre Voraussetzungen: " + strings.Join(parts, " → ")
+}
+
+// contains meldet, ob eine Challenge-ID Teil des Zyklus ist
+func (e *prerequisiteCycleError) contains(id int) bool {
+	for _, cycleID := range e.IDs {
+		if cycleID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// findPrerequisiteCycle sucht per Tiefensuche den ersten Zyklus in der Reihenfolge der
+// Challenges. Kanten führen von einer Challenge zu allem, was vor ihr liegen muss: ihren
+// Prerequisites und den Challenges, deren NextRules auf sie verzweigen. Eine Challenge,
+// die auf dem aktuellen Pfad erneut besucht wird, schließt den Zyklus. Verweise außerhalb
+// der übergebenen Challenges werden ignoriert.
+//
+// Die lineare Route (ChallengeN) gehört nicht dazu: Sie ist pro Team festgelegt, ihre
+// Positionen steigen streng an und können daher keinen Zyklus bilden. Routen verschiedener
+// Teams dürfen sich widersprechen (A vor B bei einem, B vor A beim anderen).
+func findPrerequisiteCycle(challenges []*Challenge) *prerequisiteCycleError {
+	byPageID := make(map[string]*Challenge, len(challenges))
+	byID := make(map[string]*Challenge, len(challenges))
+	for _, challenge := range challenges {
+		byPageID[normalizePageID(challenge.PageID)] = challenge
+		byID[strconv.Itoa(challenge.ID)] = challenge
+	}
+
+	// Kanten: Prerequisites und umgekehrte NextRules, jeweils mit Herkunft
+	type edge struct {
+		to       *Challenge
+		nextRule bool
+	}
+	edges := make(map[*Challenge][]edge, len(challenges))
+	for _, challenge := range challenges {
+		for _, pageID := range challenge.Prerequisites {
+			if prerequisite, ok := byPageID[normalizePageID(pageID)]; ok {
+				edges[challenge] = append(edges[challenge], edge{to: prerequisite})
+			}
+		}
+		for _, rule := range challenge.NextRules {
+			if target, ok := byID[rule.ChallengeID]; ok {
+				edges[target] = append(edges[target], edge{to: challenge, nextRule: true})
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		onPath
+		done
+	)
+	state := make(map[string]int, len(challenges))
+	var path []*Challenge
+	var viaRule []bool // viaRule[i]: Kante von path[i-1] zu path[i] stammt aus NextRules
+
+	var visit func(challenge *Challenge, byRule bool) *prerequisiteCycleError
+	visit = func(challenge *Challenge, byRule bool) *prerequisiteCycleError {
+		key := normalizePageID(challenge.PageID)
+		state[key] = onPath
+		path = append(path, challenge)
+		viaRule = append(viaRule, byRule)
+
+		for _, e := range edges[challenge] {
+			switch state[normalizePageID(e.to.PageID)] {
+			case onPath:
+				cycle := &prerequisiteCycleError{ViaNextRules: e.nextRule}
+				for i := len(path) - 1; i >= 0; i-- {
+					if path[i] == e.to {
+						for j, c := range path[i:] {
+							cycle.IDs = append(cycle.IDs, c.ID)
+							if j > 0 && viaRule[i+j] {
+								cycle.ViaNextRules = true
+							}
+						}
+						break
+					}
+				}
+				cycle.IDs = append(cycle.IDs, e.to.ID)
+				return cycle
+			case unvisited:
+				if cycle := visit(e.to, e.nextRule); cycle != nil {
+					return cycle
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		viaRule = viaRule[:len(viaRule)-1]
+		state[key] = done
+		return nil
+	}
+
+	// Aufsteigend nach ID, damit derselbe Datenbestand immer denselben Zyklus meldet
+	sorted := append([]*Challenge(nil), challenges...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+	for _, challenge := range sorted {
+		if state[normalizePageID(challenge.PageID)] == unvisited {
+			if cycle := visit(challenge, false); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// warnPrerequisiteCycles meldet beim Start zirkuläre Voraussetzungen und NextRules
+func (app *App) warnPrerequisiteCycles(ctx context.Context) {
+	challenges, err := app.getAllChallenges(ctx)
+	if err != nil {
+		errorf("Prüfung auf zirkuläre Voraussetzungen fehlgeschlagen: %v", err)
+		return
+	}
+	cycle := findPrerequisiteCycle(challenges)
+	switch {
+	case cycle == nil:
+	case cycle.ViaNextRules:
+		warnf("%v – Teams können sich auf diesen Challenges im Kreis drehen", cycle)
+	default:
+		warnf("%v – diese Challenges sind für kein Team erreichbar", cycle)
+	}
+}
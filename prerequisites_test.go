@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+// setPrerequisites setzt die Prerequisites-Relation einer Demo-Challenge
+func setPrerequisites(t *testing.T, ta *testApp, challengePageID string, prerequisitePageIDs ...string) {
+	t.Helper()
+	relation := notionapi.RelationProperty{Relation: []notionapi.Relation{}}
+	for _, id := range prerequisitePageIDs {
+		relation.Relation = append(relation.Relation, notionapi.Relation{ID: notionapi.PageID(id)})
+	}
+	_, err := ta.store.UpdatePage(t.Context(), challengePageID, &notionapi.PageUpdateRequest{
+		Properties: notionapi.Properties{challengePrerequisitesProperty: relation},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setNextRules setzt die NextRules-Property einer Demo-Challenge
+func setNextRules(t *testing.T, ta *testApp, challengePageID, rules string) {
+	t.Helper()
+	_, err := ta.store.UpdatePage(t.Context(), challengePageID, &notionapi.PageUpdateRequest{
+		Properties: notionapi.Properties{challengeNextRulesProperty: notionapi.RichTextProperty{RichText: demoText(rules)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPrerequisites(t *testing.T) {
+	tests := []struct {
+		name          string
+		prerequisites []string // Voraussetzungen von Challenge 2 (Kirchturm)
+		completed     []string // vor Challenge 1 abgeschlossen
+		wantURL       string
+		wantError     string
+	}{
+		{
+			name:    "ohne Voraussetzungen",
+			wantURL: demoChallengeURL + "demo-kirchturm",
+		},
+		{
+			name:          "Voraussetzung erfüllt",
+			prerequisites: []string{"demo-stadtpark"},
+			completed:     []string{"3"},
+			wantURL:       demoChallengeURL + "demo-kirchturm",
+		},
+		{
+			name:          "offene Voraussetzung auf der Route",
+			prerequisites: []string{"demo-stadtpark"},
+			wantURL:       demoChallengeURL + "demo-stadtpark",
+		},
+		{
+			name:          "früheste offene Voraussetzung",
+			prerequisites: []string{"demo-rathaus", "demo-stadtpark"},
+			wantURL:       demoChallengeURL + "demo-stadtpark",
+		},
+		{
+			name:          "Voraussetzung nicht auf der Route",
+			prerequisites: []string{"demo-extra-5"},
+			wantError:     "zuerst abschließen: Challenge 5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			addDemoChallenge(ta.store, "demo-extra-5", 5)
+			if tt.prerequisites != nil {
+				setPrerequisites(t, ta, "demo-kirchturm", tt.prerequisites...)
+			}
+			for _, id := range tt.completed {
+				if err := ta.recordCompletion(t.Context(), demoTeamPageID, id, false, ""); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			w := ta.advance("1", "Demo Team")
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+			body := w.Body.String()
+			if tt.wantURL != "" && !strings.Contains(body, tt.wantURL) {
+				t.Errorf("Weiterleitung zu %s fehlt:\n%s", tt.wantURL, body)
+			}
+			if tt.wantError != "" && !strings.Contains(body, tt.wantError) {
+				t.Errorf("Erklärung %q fehlt:\n%s", tt.wantError, body)
+			}
+		})
+	}
+}
+
+func TestFindPrerequisiteCycle(t *testing.T) {
+	type spec struct {
+		id            int
+		prerequisites []int
+		next          []string // Ziel-IDs der NextRules
+	}
+	tests := []struct {
+		name       string
+		challenges []spec
+		want       []int
+		wantRules  bool
+	}{
+		{name: "keine Verweise", challenges: []spec{{id: 1}, {id: 2}}},
+		{name: "Kette", challenges: []spec{{id: 1}, {id: 2, prerequisites: []int{1}}, {id: 3, prerequisites: []int{2}}}},
+		{name: "unbekannte Voraussetzung", challenges: []spec{{id: 1, prerequisites: []int{9}}}},
+		{name: "Verzweigung ohne Schleife", challenges: []spec{{id: 1, next: []string{"3"}}, {id: 2, next: []string{"3"}}, {id: 3}}},
+		{name: "sich selbst vorausgesetzt", challenges: []spec{{id: 1, prerequisites: []int{1}}}, want: []int{1, 1}},
+		{
+			name:       "zwei Challenges",
+			challenges: []spec{{id: 1, prerequisites: []int{2}}, {id: 2, prerequisites: []int{1}}},
+			want:       []int{1, 2, 1},
+		},
+		{
+			name:       "drei Challenges",
+			challenges: []spec{{id: 1, prerequisites: []int{3}}, {id: 2, prerequisites: []int{1}}, {id: 3, prerequisites: []int{2}}, {id: 4}},
+			want:       []int{1, 3, 2, 1},
+		},
+		{
+			name:       "nur NextRules",
+			challenges: []spec{{id: 1, next: []string{"2"}}, {id: 2, next: []string{"1"}}},
+			want:       []int{1, 2, 1},
+			wantRules:  true,
+		},
+		{
+			name:       "NextRules und Prerequisites",
+			challenges: []spec{{id: 1, prerequisites: []int{2}, next: []string{"2"}}, {id: 2}},
+			want:       []int{1, 2, 1},
+			wantRules:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var challenges []*Challenge
+			for _, s := range tt.challenges {
+				challenge := &Challenge{ID: s.id, PageID: fmt.Sprintf("page-%d", s.id)}
+				for _, id := range s.prerequisites {
+					challenge.Prerequisites = append(challenge.Prerequisites, fmt.Sprintf("page-%d", id))
+				}
+				for _, id := range s.next {
+					challenge.NextRules = append(challenge.NextRules, nextRule{Answer: "*", ChallengeID: id})
+				}
+				challenges = append(challenges, challenge)
+			}
+
+			cycle := findPrerequisiteCycle(challenges)
+			if tt.want == nil {
+				if cycle != nil {
+					t.Errorf("Zyklus %v gemeldet", cycle)
+				}
+				return
+			}
+			if cycle == nil {
+				t.Fatalf("kein Zyklus gefunden, erwartet %v", tt.want)
+			}
+			if !slices.Equal(cycle.IDs, tt.want) || cycle.ViaNextRules != tt.wantRules {
+				t.Errorf("Zyklus = %v (NextRules %v), erwartet %v (%v)", cycle.IDs, cycle.ViaNextRules, tt.want, tt.wantRules)
+			}
+		})
+	}
+}
+
+func TestAdminRouteRejectsCycle(t *testing.T) {
+	tests := []struct {
+		name       string
+		route      string
+		wantStatus int
+	}{
+		{name: "Route mit Challenge im Zyklus", route: `["1", "3"]`, wantStatus: http.StatusBadRequest},
+		{name: "Route ohne Challenge im Zyklus", route: `["3", "4"]`, wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			// Brunnen und Kirchturm setzen sich gegenseitig voraus
+			setPrerequisites(t, ta, "demo-brunnen", "demo-kirchturm")
+			setPrerequisites(t, ta, "demo-kirchturm", "demo-brunnen")
+
+			w := ta.admin(http.MethodPost, "/admin/route/Demo%20Team", tt.route)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, erwartet %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusBadRequest {
+				if cycle := fmt.Sprint(decodeJSON(t, w)["cycle"]); cycle != "[1 2 1]" {
+					t.Errorf("cycle = %s, erwartet [1 2 1]", cycle)
+				}
+			}
+		})
+	}
+}
+
+// truncatingNotion kürzt Relations in Query-Ergebnissen auf notionRelationLimit, wie Notion es tut
+type truncatingNotion struct {
+	notionService
+}
+
+func (n truncatingNotion) QueryDatabase(ctx context.Context, dbID string, req *notionapi.DatabaseQueryRequest) (*notionapi.DatabaseQueryResponse, error) {
+	resp, err := n.notionService.QueryDatabase(ctx, dbID, req)
+	if err != nil {
+		return nil, err
+	}
+	truncated := *resp
+	truncated.Results = make([]notionapi.Page, len(resp.Results))
+	for i, page := range resp.Results {
+		props := make(notionapi.Properties, len(page.Properties))
+		for name, prop := range page.Properties {
+			if relation, ok := prop.(*notionapi.RelationProperty); ok && len(relation.Relation) > notionRelationLimit {
+				short := *relation
+				short.Relation = relation.Relation[:notionRelationLimit]
+				prop = &short
+			}
+			props[name] = prop
+		}
+		page.Properties = props
+		truncated.Results[i] = page
+	}
+	return &truncated, nil
+}
+
+func TestTruncatedPrerequisites(t *testing.T) {
+	tests := []struct {
+		name  string
+		extra int // Voraussetzungen zwischen Challenge 1 und Challenge 3
+	}{
+		{name: "kurze Relation", extra: 3},
+		{name: "genau am Limit", extra: notionRelationLimit - 1},
+		{name: "gekürzt", extra: notionRelationLimit + 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			ta.notion = truncatingNotion{notionService: ta.store}
+
+			// Challenge 1 ist erledigt, die letzte Voraussetzung (Challenge 3) steht hinter den zusätzlichen
+			prerequisites := []string{"demo-brunnen"}
+			for i := range tt.extra {
+				prerequisites = append(prerequisites, string(addDemoChallenge(ta.store, fmt.Sprintf("demo-extra-%d", i), 100+i).ID))
+			}
+			prerequisites = append(prerequisites, "demo-stadtpark")
+			setPrerequisites(t, ta, "demo-kirchturm", prerequisites...)
+
+			challenge, err := ta.getChallenge(t.Context(), "2")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(challenge.Prerequisites, prerequisites) {
+				t.Fatalf("%d Voraussetzungen, erwartet %d", len(challenge.Prerequisites), len(prerequisites))
+			}
+
+			// In einer gekürzten Relation fehlte Challenge 3; die Zusatz-Challenges (ab ID 100) zählen hier nicht
+			unmet, err := ta.unmetPrerequisites(t.Context(), challenge, map[int]time.Time{1: time.Now()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []int
+			for _, c := range unmet {
+				if c.ID < 100 {
+					ids = append(ids, c.ID)
+				}
+			}
+			if !slices.Equal(ids, []int{3}) {
+				t.Errorf("offene Voraussetzungen = %v, erwartet [3]", ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// progressEntry enthält die Daten für einen Fortschrittsbalken
+type progressEntry struct {
+	Team      string `json:"team"`
+	Completed int    `json:"completed"`
+	Total     int    `json:"total"`
+}
+
+// handleAPIProgress liefert Abschlüsse und Gesamtzahl der Challenges für alle Teams
+func (app *App) handleAPIProgress(c *gin.Context) {
+	progress, err := app.getProgress(c.Request.Context())
+	if err != nil {
+		errorf("Fehler beim Ermitteln des Fortschritts: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fehler beim Abrufen der Team-Daten"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"teams": progress})
+}
+
+// getProgress lädt alle Team-Pages in einem Durchgang. Die Gesamtzahl ist die Länge
+// der Route, bei FINISH_MODE=count die geforderte Anzahl an Abschlüssen.
+func (app *App) getProgress(ctx context.Context) ([]progressEntry, error) {
+	pages, err := app.listTeamPages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var progress []progressEntry
+	for i := range pages {
+		entry := app.teamProgress(&pages[i])
+		if entry.Team == "" {
+			continue
+		}
+		total := routeLength(&pages[i])
+		if app.finishMode == finishModeCount {
+			total = app.finishCount
+		}
+		completed := entry.Completed
+		if completed > total {
+			completed = total
+		}
+		progress = append(progress, progressEntry{Team: entry.Team, Completed: completed, Total: total})
+	}
+
+	// Weiteste Teams zuerst: nach Anteil, dann Anzahl, dann Name
+	sort.SliceStable(progress, func(i, j int) bool {
+		a, b := progress[i], progress[j]
+		if ra, rb := progressRatio(a), progressRatio(b); ra != rb {
+			return ra > rb
+		}
+		if a.Completed != b.Completed {
+			return a.Completed > b.Completed
+		}
+		return a.Team < b.Team
+	})
+
+	return progress, nil
+}
+
+func progressRatio(p progressEntry) float64 {
+	if p.Total == 0 {
+		return 0
+	}
+	return float64(p.Completed) / float64(p.Total)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestAPIProgress(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		shortRoute  bool                // zusätzliches Team "Kurz" mit Brunnen und Kirchturm
+		completions map[string][]string // Teamname → abgeschlossene Challenges
+		want        []string
+	}{
+		{
+			name: "noch nichts abgeschlossen",
+			want: []string{"Demo Team 0/4", "Die Füchse 0/4"},
+		},
+		{
+			name:        "nach Anzahl",
+			completions: map[string][]string{"Demo Team": {"1"}, "Die Füchse": {"1", "3"}},
+			want:        []string{"Die Füchse 2/4", "Demo Team 1/4"},
+		},
+		{
+			name:        "nach Anteil vor Anzahl",
+			shortRoute:  true,
+			completions: map[string][]string{"Kurz": {"1", "2"}, "Die Füchse": {"1", "3", "2"}},
+			want:        []string{"Kurz 2/2", "Die Füchse 3/4", "Demo Team 0/4"},
+		},
+		{
+			name:        "FINISH_COUNT begrenzt die Gesamtzahl",
+			env:         map[string]string{"FINISH_MODE": "count", "FINISH_COUNT": "2"},
+			completions: map[string][]string{"Demo Team": {"1", "2", "3"}, "Die Füchse": {"1"}},
+			want:        []string{"Demo Team 2/2", "Die Füchse 1/2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, tt.env)
+			pageIDs := map[string]string{"Demo Team": demoTeamPageID, "Die Füchse": foxesTeamPageID}
+			if tt.shortRoute {
+				pageIDs["Kurz"] = addDemoTeam(ta.store, "Kurz", notionapi.Properties{
+					"Challenge1": notionapi.RelationProperty{Relation: []notionapi.Relation{{ID: "demo-brunnen"}}},
+					"Challenge2": notionapi.RelationProperty{Relation: []notionapi.Relation{{ID: "demo-kirchturm"}}},
+				})
+			}
+			for team, ids := range tt.completions {
+				for _, id := range ids {
+					if err := ta.recordCompletion(t.Context(), pageIDs[team], id, false, ""); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			w := ta.do(http.MethodGet, "/api/progress", nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+			var got []string
+			for _, entry := range decodeJSON(t, w)["teams"].([]any) {
+				entry := entry.(map[string]any)
+				got = append(got, fmt.Sprintf("%s %v/%v", entry["team"], entry["completed"], entry["total"]))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Fortschritt = %v, erwartet %v", got, tt.want)
+			}
+		})
+	}
+}
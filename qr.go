@@ -0,0 +1,81 @@
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/skip2/go-qrcode"
+)
+
+// qrCodeSize ist die Kantenlänge der QR-PNGs in Pixeln (groß genug für A4-Aushänge)
+const qrCodeSize = 512
+
+// challengeQRCode erzeugt ein QR-PNG, das auf das Formular /next/:id der Challenge verlinkt
+func challengeQRCode(baseURL string, challenge *Challenge) ([]byte, error) {
+	png, err := qrcode.Encode(baseURL+challenge.NextURL, qrcode.Medium, qrCodeSize)
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Erzeugen des QR-Codes für Challenge %d: %w", challenge.ID, err)
+	}
+	return png, nil
+}
+
+// publicBaseURL liefert die öffentliche Basis-URL für Links in QR-Codes.
+// Ohne PUBLIC_BASE_URL wird sie aus dem aktuellen Request abgeleitet.
+func (app *App) publicBaseURL(c *gin.Context) string {
+	if app.baseURL != "" {
+		return strings.TrimSuffix(app.baseURL, "/")
+	}
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+// handleAdminQRZip liefert QR-Codes aller Challenges als ZIP (challenge-<id>.png).
+// Die PNGs werden einzeln erzeugt und direkt in die Antwort gestreamt.
+func (app *App) handleAdminQRZip(c *gin.Context) {
+	challenges, err := app.getAllChallenges(c.Request.Context())
+	if err != nil {
+		errorf("Fehler beim Laden der Challenges: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": app.userError("Fehler beim Laden der Challenges", err)})
+		return
+	}
+
+	baseURL := app.publicBaseURL(c)
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="challenge-qr-codes.zip"`)
+	c.Status(http.StatusOK)
+
+	zw := zip.NewWriter(c.Writer)
+	for _, challenge := range challenges {
+		png, err := challengeQRCode(baseURL, challenge)
+		if err != nil {
+			// Header sind schon gesendet, daher nur loggen und abbrechen
+			errorf("QR-ZIP abgebrochen: %v", err)
+			return
+		}
+
+		// PNGs sind bereits komprimiert
+		w, err := zw.CreateHeader(&zip.FileHeader{
+			Name:   fmt.Sprintf("challenge-%d.png", challenge.ID),
+			Method: zip.Store,
+		})
+		if err == nil {
+			_, err = w.Write(png)
+		}
+		if err != nil {
+			errorf("QR-ZIP abgebrochen: %v", err)
+			return
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		errorf("Fehler beim Abschließen des QR-ZIPs: %v", err)
+		return
+	}
+	infof("QR-ZIP mit %d Challenges ausgeliefert", len(challenges))
+}
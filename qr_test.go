@@ -0,0 +1,82 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAdminQRZip(t *testing.T) {
+	ta := newTestApp(t, nil)
+	addDemoChallenge(ta.store, "demo-extra-7", 7)
+
+	w := ta.admin(http.MethodGet, "/admin/qr.zip", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/zip" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		buf.ReadFrom(rc)
+		rc.Close()
+		if !bytes.HasPrefix(buf.Bytes(), []byte("\x89PNG")) {
+			t.Errorf("%s ist kein PNG", f.Name)
+		}
+	}
+
+	want := []string{"challenge-1.png", "challenge-2.png", "challenge-3.png", "challenge-4.png", "challenge-7.png"}
+	if !slices.Equal(names, want) {
+		t.Errorf("Einträge = %v, erwartet %v", names, want)
+	}
+}
+
+func TestPublicBaseURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		tls     bool
+		proto   string
+		want    string
+	}{
+		{name: "konfiguriert", baseURL: "https://jagd.example/", want: "https://jagd.example"},
+		{name: "aus dem Request", want: "http://example.com"},
+		{name: "TLS", tls: true, want: "https://example.com"},
+		{name: "hinter Proxy", proto: "https", want: "https://example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &App{baseURL: tt.baseURL}
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/admin/qr.zip", nil)
+			if tt.tls {
+				c.Request.TLS = &tls.ConnectionState{}
+			}
+			if tt.proto != "" {
+				c.Request.Header.Set("X-Forwarded-Proto", tt.proto)
+			}
+			if got := app.publicBaseURL(c); got != tt.want {
+				t.Errorf("publicBaseURL = %q, erwartet %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Verhalten, wenn ein eingereichtes Team nicht gefunden wird (TEAM_NOT_FOUND_ACTION)
+const (
+	teamNotFoundError    = "error"
+	teamNotFoundLink     = "link"
+	teamNotFoundRedirect = "redirect"
+)
+
+// parseTeamNotFoundAction liest TEAM_NOT_FOUND_ACTION (Standard: nur Fehlermeldung)
+func parseTeamNotFoundAction(s string) (string, error) {
+	switch s {
+	case "", teamNotFoundError:
+		return teamNotFoundError, nil
+	case teamNotFoundLink, teamNotFoundRedirect:
+		return s, nil
+	default:
+		return "", fmt.Errorf("TEAM_NOT_FOUND_ACTION %q ist ungültig (error, link, redirect)", s)
+	}
+}
+
+// registerURL baut den Link zum Registrierungsformular, vorausgefüllt mit dem eingegebenen Namen
+func registerURL(name, challengeID string) string {
+	query := url.Values{}
+	query.Set("name", name)
+	if challengeID != "" {
+		query.Set("challenge", challengeID)
+	}
+	return "/register?" + query.Encode()
+}
+
+// handleRegisterForm zeigt das Formular zum Anlegen eines neuen Teams
+func (app *App) handleRegisterForm(c *gin.Context) {
+	app.renderRegister(c, http.StatusOK, gin.H{
+		"name":        c.Query("name"),
+		"challengeID": c.Query("challenge"),
+	})
+}
+
+// handleRegister legt ein neues Team an und zeigt den Join-Code
+func (app *App) handleRegister(c *gin.Context) {
+	ctx := c.Request.Context()
+	name := trimTeamName(c.PostForm("name"))
+	challengeID := c.PostForm("challenge")
+	data := gin.H{"name": name, "challengeID": challengeID}
+
+	if err := validateTeamName(name); err != nil {
+		data["error"] = err.Error()
+		app.renderRegister(c, http.StatusBadRequest, data)
+		return
+	}
+
+	// Exakter Vergleich statt findTeamPage, damit die Fuzzy-Suche ähnliche Namen nicht blockiert
+	names, err := app.getAllTeamNames(ctx)
+	if err != nil && !errors.Is(err, errNoTeams) {
+		errorf("Fehler beim Abrufen der Teamnamen: %v", err)
+	}
+	for _, existing := range names {
+		if normalizeTeamName(existing) == normalizeTeamName(name) {
+			data["error"] = "Ein Team mit diesem Namen existiert bereits"
+			app.renderRegister(c, http.StatusConflict, data)
+			return
+		}
+	}
+
+	_, code, err := app.createTeam(ctx, name, nil)
+	if err != nil {
+		errorf("Fehler bei der Registrierung von Team %s: %v", name, err)
+		data["error"] = "Team konnte nicht angelegt werden"
+		app.renderRegister(c, http.StatusInternalServerError, data)
+		return
+	}
+
+	infof("Team %s registriert", name)
+	data["code"] = code
+	app.renderRegister(c, http.StatusCreated, data)
+}
+
+func (app *App) renderRegister(c *gin.Context, status int, data gin.H) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(status)
+	if err := app.templates.ExecuteTemplate(c.Writer, "register.html", data); err != nil {
+		app.templateFailed(c, err)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestTeamNotFoundAction(t *testing.T) {
+	target := registerURL("Neue Crew", "1")
+	tests := []struct {
+		name         string
+		action       string
+		registration string
+		wantStatus   int
+		wantLocation string
+		wantLink     bool
+	}{
+		{name: "nur Fehler", action: "error", registration: "true", wantStatus: http.StatusOK},
+		{name: "Link", action: "link", registration: "true", wantStatus: http.StatusOK, wantLink: true},
+		{name: "Weiterleitung", action: "redirect", registration: "true", wantStatus: http.StatusSeeOther, wantLocation: target},
+		{name: "Link ohne Registrierung", action: "link", registration: "false", wantStatus: http.StatusOK},
+		{name: "Weiterleitung ohne Registrierung", action: "redirect", registration: "false", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{
+				"TEAM_NOT_FOUND_ACTION": tt.action,
+				"FEATURE_REGISTRATION":  tt.registration,
+			})
+			w := ta.advance("1", "Neue Crew")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, erwartet %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if got := w.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf("Location = %q, erwartet %q", got, tt.wantLocation)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			body := w.Body.String()
+			if !strings.Contains(body, "Team nicht gefunden") {
+				t.Errorf("Fehlermeldung fehlt:\n%s", body)
+			}
+			if got := strings.Contains(body, `href="/register?challenge=1&amp;name=Neue`); got != tt.wantLink {
+				t.Errorf("Registrierungslink = %v, erwartet %v:\n%s", got, tt.wantLink, body)
+			}
+		})
+	}
+}
+
+func TestTeamNotFoundRedirectWithSuggestions(t *testing.T) {
+	// Bei Vorschlägen ist das Team vermutlich vertippt, daher keine Weiterleitung
+	ta := newTestApp(t, map[string]string{
+		"TEAM_NOT_FOUND_ACTION": "redirect",
+		"FEATURE_REGISTRATION":  "true",
+		"FUZZY_MATCH_DISTANCE":  "2",
+		"FUZZY_AUTO_ACCEPT":     "false",
+	})
+	w := ta.advance("1", "Demo Taem")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Demo Team") {
+		t.Errorf("Status = %d, Vorschlag fehlt:\n%s", w.Code, w.Body.String())
+	}
+}
+
+func TestParseTeamNotFoundAction(t *testing.T) {
+	for _, tt := range []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "", want: teamNotFoundError},
+		{in: "link", want: teamNotFoundLink},
+		{in: "redirect", want: teamNotFoundRedirect},
+		{in: "register", wantErr: true},
+	} {
+		got, err := parseTeamNotFoundAction(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("parseTeamNotFoundAction(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Wiederholungen bei Rate-Limits (429) der Notion-API
+const (
+	notionMaxRetries  = 3
+	notionBaseBackoff = 500 * time.Millisecond
+)
+
+// rateLimitTransport wiederholt Notion-Requests nach einem 429. Gewartet wird genau so
+// lange, wie Notion im Retry-After Header verlangt; fehlt der Header, greift ein
+// exponentielles Backoff. Das Warten bricht ab, sobald der Request-Context endet.
+type rateLimitTransport struct {
+	next        http.RoundTripper
+	maxRetries  int
+	baseBackoff time.Duration
+}
+
+// newNotionHTTPClient liefert den HTTP-Client für den Notion-Client
+func newNotionHTTPClient() *http.Client {
+	return &http.Client{Transport: &rateLimitTransport{
+		next:        http.DefaultTransport,
+		maxRetries:  notionMaxRetries,
+		baseBackoff: notionBaseBackoff,
+	}}
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || attempt >= t.maxRetries {
+			return resp, err
+		}
+		// Ohne GetBody lässt sich der Body nicht erneut senden
+		if req.Body != nil && req.GetBody == nil {
+			return resp, nil
+		}
+
+		wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if !ok {
+			wait = t.baseBackoff << attempt
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		warnf("Notion Rate-Limit bei %s %s, Versuch %d/%d in %v", req.Method, req.URL.Path, attempt+1, t.maxRetries, wait)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		retry := req.Clone(ctx)
+		if req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			retry.Body = body
+		}
+		req = retry
+	}
+}
+
+// parseRetryAfter liest den Retry-After Header (Sekunden oder HTTP-Datum)
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := at.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
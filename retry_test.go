@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{value: "", wantOK: false},
+		{value: "0", want: 0, wantOK: true},
+		{value: "3", want: 3 * time.Second, wantOK: true},
+		{value: "-1", wantOK: false},
+		{value: "bald", wantOK: false},
+		{value: now.Add(90 * time.Second).Format(http.TimeFormat), want: 90 * time.Second, wantOK: true},
+		{value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0, wantOK: true},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseRetryAfter(%q) = %v, %v, erwartet %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+// scriptedTransport antwortet nacheinander mit den vorgegebenen Status-Codes und merkt sich die Requests
+type scriptedTransport struct {
+	responses []scriptedResponse
+	calls     []time.Time
+	bodies    []string
+}
+
+type scriptedResponse struct {
+	status     int
+	retryAfter string
+}
+
+func (s *scriptedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.calls = append(s.calls, time.Now())
+	if req.Body != nil {
+		body, _ := io.ReadAll(req.Body)
+		s.bodies = append(s.bodies, string(body))
+	}
+	r := s.responses[min(len(s.calls), len(s.responses))-1]
+	header := http.Header{}
+	if r.retryAfter != "" {
+		header.Set("Retry-After", r.retryAfter)
+	}
+	return &http.Response{StatusCode: r.status, Header: header, Body: io.NopCloser(strings.NewReader("{}")), Request: req}, nil
+}
+
+func TestRateLimitTransport(t *testing.T) {
+	const backoff = 20 * time.Millisecond
+	tests := []struct {
+		name       string
+		responses  []scriptedResponse
+		wantStatus int
+		wantCalls  int
+		wantWaits  []time.Duration // Mindestwartezeit vor jedem weiteren Versuch
+	}{
+		{
+			name:       "ohne Rate-Limit",
+			responses:  []scriptedResponse{{status: http.StatusOK}},
+			wantStatus: http.StatusOK,
+			wantCalls:  1,
+		},
+		{
+			name:       "Retry-After wird abgewartet",
+			responses:  []scriptedResponse{{status: http.StatusTooManyRequests, retryAfter: "1"}, {status: http.StatusOK}},
+			wantStatus: http.StatusOK,
+			wantCalls:  2,
+			wantWaits:  []time.Duration{time.Second},
+		},
+		{
+			name:       "ohne Header exponentielles Backoff",
+			responses:  []scriptedResponse{{status: http.StatusTooManyRequests}, {status: http.StatusTooManyRequests}, {status: http.StatusOK}},
+			wantStatus: http.StatusOK,
+			wantCalls:  3,
+			wantWaits:  []time.Duration{backoff, 2 * backoff},
+		},
+		{
+			name:       "Versuche erschöpft",
+			responses:  []scriptedResponse{{status: http.StatusTooManyRequests, retryAfter: "0"}},
+			wantStatus: http.StatusTooManyRequests,
+			wantCalls:  notionMaxRetries + 1,
+		},
+		{
+			name:       "andere Fehler ohne Wiederholung",
+			responses:  []scriptedResponse{{status: http.StatusBadGateway}},
+			wantStatus: http.StatusBadGateway,
+			wantCalls:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &scriptedTransport{responses: tt.responses}
+			client := &http.Client{Transport: &rateLimitTransport{next: next, maxRetries: notionMaxRetries, baseBackoff: backoff}}
+
+			req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, "https://api.notion.com/v1/databases/x/query", strings.NewReader(`{"page_size":1}`))
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("Status = %d, erwartet %d", resp.StatusCode, tt.wantStatus)
+			}
+			if len(next.calls) != tt.wantCalls {
+				t.Fatalf("%d Aufrufe, erwartet %d", len(next.calls), tt.wantCalls)
+			}
+			for i, want := range tt.wantWaits {
+				if got := next.calls[i+1].Sub(next.calls[i]); got < want {
+					t.Errorf("Wartezeit vor Versuch %d = %v, erwartet mindestens %v", i+2, got, want)
+				}
+			}
+			// Der Body wird bei jeder Wiederholung erneut gesendet
+			for i, body := range next.bodies {
+				if body != `{"page_size":1}` {
+					t.Errorf("Body bei Versuch %d = %q", i+1, body)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitTransportContextCanceled(t *testing.T) {
+	next := &scriptedTransport{responses: []scriptedResponse{{status: http.StatusTooManyRequests, retryAfter: "60"}}}
+	transport := &rateLimitTransport{next: next, maxRetries: notionMaxRetries, baseBackoff: time.Millisecond}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.notion.com/v1/pages/x", nil)
+
+	start := time.Now()
+	if _, err := transport.RoundTrip(req); err != context.DeadlineExceeded {
+		t.Errorf("Fehler = %v, erwartet DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Warten nicht abgebrochen (%v)", elapsed)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/jomei/notionapi"
+)
+
+// errRouteTooLong wird geliefert, wenn die Team-Page zu wenige ChallengeN-Properties hat
+var errRouteTooLong = errors.New("route ist länger als die Challenge-Properties der Team-Page")
+
+// challengeSlots liefert die Nummern aller ChallengeN-Relations einer Team-Page
+func challengeSlots(page *notionapi.Page) map[int]bool {
+	slots := make(map[int]bool)
+	for propName, prop := range page.Properties {
+		var num int
+		if _, err := fmt.Sscanf(propName, "Challenge%d", &num); err != nil {
+			continue
+		}
+		if _, ok := prop.(*notionapi.RelationProperty); ok && propName == fmt.Sprintf("Challenge%d", num) {
+			slots[num] = true
+		}
+	}
+	return slots
+}
+
+// routeRelation liest die Challenge-Page einer ChallengeN-Relation.
+//
+// Annahme: Jede ChallengeN-Property verweist auf genau eine Challenge, die Position
+// auf der Route ergibt sich allein aus N. Mehrere Einträge sind ein Datenfehler (siehe
+// ambiguousRouteSlots), es zählt dann der erste. Eine von Notion gekürzte Relation
+// (siehe relationPageIDs) muss dafür nicht nachgeladen werden.
+func routeRelation(prop notionapi.Property) (string, bool) {
+	relation, ok := prop.(*notionapi.RelationProperty)
+	if !ok || len(relation.Relation) == 0 {
+		return "", false
+	}
+	return string(relation.Relation[0].ID), true
+}
+
+// ambiguousRouteSlots liefert die ChallengeN-Positionen, deren Relation mehr als eine
+// Challenge enthält, aufsteigend sortiert. Gekürzte Relations enthalten immer mehr als eine.
+func ambiguousRouteSlots(page *notionapi.Page) []int {
+	var slots []int
+	for num := range challengeSlots(page) {
+		relation := page.Properties[fmt.Sprintf("Challenge%d", num)].(*notionapi.RelationProperty)
+		if len(relation.Relation) > 1 {
+			slots = append(slots, num)
+		}
+	}
+	sort.Ints(slots)
+	return slots
+}
+
+// routeLength zählt die belegten ChallengeN-Relations einer Team-Page
+func routeLength(page *notionapi.Page) int {
+	n := 0
+	for num := range challengeSlots(page) {
+		if _, ok := routeRelation(page.Properties[fmt.Sprintf("Challenge%d", num)]); ok {
+			n++
+		}
+	}
+	return n
+}
+
+// setTeamRoute schreibt die Challenges in der gegebenen Reihenfolge in die
+// ChallengeN-Relations der Team-Page. Überzählige Relations werden geleert.
+func (app *App) setTeamRoute(ctx context.Context, teamPageID string, route []*Challenge) error {
+	page, err := app.getPage(ctx, teamPageID)
+	if err != nil {
+		return fmt.Errorf("fehler beim Laden der Team-Page: %w", err)
+	}
+
+	slots := challengeSlots(page)
+	for pos := 1; pos <= len(route); pos++ {
+		if !slots[pos] {
+			return fmt.Errorf("%w: Challenge%d fehlt", errRouteTooLong, pos)
+		}
+	}
+
+	props := notionapi.Properties{}
+	for pos := range slots {
+		relation := []notionapi.Relation{}
+		if pos <= len(route) {
+			relation = append(relation, notionapi.Relation{ID: notionapi.PageID(route[pos-1].PageID)})
+		}
+		props[fmt.Sprintf("Challenge%d", pos)] = notionapi.RelationProperty{Relation: relation}
+	}
+
+	_, err = app.updatePage(ctx, teamPageID, &notionapi.PageUpdateRequest{Properties: props})
+	if err != nil {
+		return fmt.Errorf("fehler beim Speichern der Route: %w", err)
+	}
+	return nil
+}
+
+// Verhalten, wenn einem Team keine Challenges zugewiesen sind (EMPTY_ROUTE_ACTION)
+const (
+	emptyRouteNotice   = "notice"   // Hinweisseite "bitte an die Orga wenden" (Standard)
+	emptyRouteFinished = "finished" // wie bisher direkt die Zielseite
+)
+
+// parseEmptyRouteAction liest EMPTY_ROUTE_ACTION
+func parseEmptyRouteAction(s string) (string, error) {
+	switch s {
+	case "", emptyRouteNotice:
+		return emptyRouteNotice, nil
+	case emptyRouteFinished:
+		return s, nil
+	default:
+		return "", fmt.Errorf("EMPTY_ROUTE_ACTION %q ist ungültig (notice, finished)", s)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestParseEmptyRouteAction(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "", want: emptyRouteNotice},
+		{in: "notice", want: emptyRouteNotice},
+		{in: "finished", want: emptyRouteFinished},
+		{in: "error", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseEmptyRouteAction(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("parseEmptyRouteAction(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestEmptyRoute(t *testing.T) {
+	emptySlots := notionapi.Properties{
+		"Challenge1": &notionapi.RelationProperty{Relation: []notionapi.Relation{}},
+		"Challenge2": &notionapi.RelationProperty{Relation: []notionapi.Relation{}},
+	}
+	tests := []struct {
+		name        string
+		action      string
+		props       notionapi.Properties
+		wantTitle   string
+		wantOutcome string
+	}{
+		{name: "ohne ChallengeN-Properties", action: "", wantTitle: "<title>No challenges</title>", wantOutcome: outcomeNoRoute},
+		{name: "leere Relations", action: "notice", props: emptySlots, wantTitle: "<title>No challenges</title>", wantOutcome: outcomeNoRoute},
+		{name: "wie bisher Zielseite", action: "finished", wantTitle: "<title>Scavenger Hunt Completed!</title>", wantOutcome: outcomeFinished},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"EMPTY_ROUTE_ACTION": tt.action})
+			var events bytes.Buffer
+			ta.events = newJSONEventLogger(&events)
+			pageID := addDemoTeam(ta.store, "Ohne Route", tt.props)
+
+			w := ta.advance("1", "Ohne Route")
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.wantTitle) {
+				t.Errorf("%s fehlt:\n%s", tt.wantTitle, w.Body.String())
+			}
+			if logged := readEvents(t, events.Bytes()); len(logged) != 1 || logged[0].Outcome != tt.wantOutcome {
+				t.Errorf("Events = %+v, erwartet %s", logged, tt.wantOutcome)
+			}
+			if completed := ta.completedChallenges(ta.teamPage(t, pageID)); len(completed) != 0 {
+				t.Errorf("Abschlüsse ohne Route gespeichert: %v", completed)
+			}
+		})
+	}
+}
+
+func TestMultiRelationRoute(t *testing.T) {
+	relation := func(pageIDs ...string) *notionapi.RelationProperty {
+		r := &notionapi.RelationProperty{Relation: []notionapi.Relation{}}
+		for _, id := range pageIDs {
+			r.Relation = append(r.Relation, notionapi.Relation{ID: notionapi.PageID(id)})
+		}
+		return r
+	}
+	tests := []struct {
+		name          string
+		props         notionapi.Properties
+		wantRoute     map[int]string
+		wantAmbiguous []int
+	}{
+		{
+			name:      "eine Challenge pro Position",
+			props:     notionapi.Properties{"Challenge1": relation("demo-brunnen"), "Challenge2": relation("demo-kirchturm")},
+			wantRoute: map[int]string{1: "1", 2: "2"},
+		},
+		{
+			name:          "mehrere Challenges, die erste zählt",
+			props:         notionapi.Properties{"Challenge1": relation("demo-stadtpark", "demo-brunnen"), "Challenge2": relation("demo-kirchturm")},
+			wantRoute:     map[int]string{1: "3", 2: "2"},
+			wantAmbiguous: []int{1},
+		},
+		{
+			name:          "Lücke und mehrere Einträge",
+			props:         notionapi.Properties{"Challenge1": relation("demo-brunnen"), "Challenge2": relation(), "Challenge3": relation("demo-rathaus", "demo-kirchturm", "demo-stadtpark")},
+			wantRoute:     map[int]string{1: "1", 3: "4"},
+			wantAmbiguous: []int{3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			pageID := addDemoTeam(ta.store, "Mehrfach", tt.props)
+
+			route, err := ta.getTeamChallenges(t.Context(), pageID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !maps.Equal(route, tt.wantRoute) {
+				t.Errorf("Route = %v, erwartet %v", route, tt.wantRoute)
+			}
+			page := ta.teamPage(t, pageID)
+			if got := ambiguousRouteSlots(page); !slices.Equal(got, tt.wantAmbiguous) {
+				t.Errorf("ambiguousRouteSlots = %v, erwartet %v", got, tt.wantAmbiguous)
+			}
+			if got := routeLength(page); got != len(tt.wantRoute) {
+				t.Errorf("routeLength = %d, erwartet %d", got, len(tt.wantRoute))
+			}
+		})
+	}
+}
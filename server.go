@@ -0,0 +1,153 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Standardwerte für den HTTP-Server. Kiosk-Displays halten Verbindungen lange offen,
+// daher ist der Idle-Timeout großzügig, Header müssen aber zügig ankommen.
+const (
+	defaultIdleTimeout          = 120 * time.Second
+	defaultReadHeaderTimeout    = 10 * time.Second
+	defaultMaxHeaderBytes       = 64 << 10
+	defaultMaxConcurrentStreams = 100
+)
+
+// serverConfig enthält die per Umgebung einstellbaren Server-Parameter
+type serverConfig struct {
+	certFile             string
+	keyFile              string
+	idleTimeout          time.Duration
+	readHeaderTimeout    time.Duration
+	maxHeaderBytes       int
+	maxConcurrentStreams int
+}
+
+// tlsEnabled meldet, ob Zertifikat und Schlüssel konfiguriert sind
+func (sc serverConfig) tlsEnabled() bool {
+	return sc.certFile != "" && sc.keyFile != ""
+}
+
+// parseServerConfig liest TLS_CERT_FILE, TLS_KEY_FILE und die HTTP_* Variablen über get
+func parseServerConfig(get func(string) string) (serverConfig, error) {
+	sc := serverConfig{
+		certFile:             get("TLS_CERT_FILE"),
+		keyFile:              get("TLS_KEY_FILE"),
+		idleTimeout:          defaultIdleTimeout,
+		readHeaderTimeout:    defaultReadHeaderTimeout,
+		maxHeaderBytes:       defaultMaxHeaderBytes,
+		maxConcurrentStreams: defaultMaxConcurrentStreams,
+	}
+	if (sc.certFile == "") != (sc.keyFile == "") {
+		return sc, fmt.Errorf("TLS_CERT_FILE und TLS_KEY_FILE müssen gemeinsam gesetzt sein")
+	}
+
+	durations := []struct {
+		name   string
+		target *time.Duration
+	}{
+		{"HTTP_IDLE_TIMEOUT", &sc.idleTimeout},
+		{"HTTP_READ_HEADER_TIMEOUT", &sc.readHeaderTimeout},
+	}
+	for _, d := range durations {
+		if v := get(d.name); v != "" {
+			parsed, err := time.ParseDuration(v)
+			if err != nil || parsed <= 0 {
+				return sc, fmt.Errorf("%s muss eine positive Dauer sein (z.B. 90s), ist aber %q", d.name, v)
+			}
+			*d.target = parsed
+		}
+	}
+
+	ints := []struct {
+		name   string
+		target *int
+	}{
+		{"HTTP_MAX_HEADER_BYTES", &sc.maxHeaderBytes},
+		{"HTTP2_MAX_CONCURRENT_STREAMS", &sc.maxConcurrentStreams},
+	}
+	for _, i := range ints {
+		if v := get(i.name); v != "" {
+			parsed, err := strconv.Atoi(v)
+			if err != nil || parsed <= 0 {
+				return sc, fmt.Errorf("%s muss eine positive ganze Zahl sein, ist aber %q", i.name, v)
+			}
+			*i.target = parsed
+		}
+	}
+
+	return sc, nil
+}
+
+// parseTrustedProxies liest TRUSTED_PROXIES: IP-Adressen oder CIDR-Bereiche der Reverse-Proxies,
+// deren X-Forwarded-For die Client-IP bestimmt. Ohne Angabe zählt nur die Adresse der Verbindung,
+// sonst könnte jeder Client seine IP selbst wählen (z.B. um die PIN-Sperre zu umgehen).
+func parseTrustedProxies(s string) ([]string, error) {
+	var proxies []string
+	for _, proxy := range strings.Split(s, ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if _, err := netip.ParsePrefix(proxy); err != nil {
+			if _, err := netip.ParseAddr(proxy); err != nil {
+				return nil, fmt.Errorf("TRUSTED_PROXIES enthält ungültige Adresse %q (erwartet z.B. 10.0.0.1 oder 10.0.0.0/8)", proxy)
+			}
+		}
+		proxies = append(proxies, proxy)
+	}
+	return proxies, nil
+}
+
+// newHTTPServer baut den http.Server. Mit TLS wird HTTP/2 per ALPN angeboten,
+// ohne TLS bleibt es bei HTTP/1.1 mit Keep-Alive.
+func newHTTPServer(addr string, handler http.Handler, sc serverConfig) *http.Server {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		IdleTimeout:       sc.idleTimeout,
+		ReadHeaderTimeout: sc.readHeaderTimeout,
+		MaxHeaderBytes:    sc.maxHeaderBytes,
+		HTTP2: &http.HTTP2Config{
+			MaxConcurrentStreams: sc.maxConcurrentStreams,
+		},
+	}
+	srv.SetKeepAlivesEnabled(true)
+
+	srv.Protocols = new(http.Protocols)
+	srv.Protocols.SetHTTP1(true)
+	if sc.tlsEnabled() {
+		srv.Protocols.SetHTTP2(true)
+		srv.TLSConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			NextProtos: []string{"h2", "http/1.1"},
+		}
+	}
+	return srv
+}
+
+// checkHTTP2 stellt beim Start sicher, dass HTTP/2 mit TLS tatsächlich angeboten wird
+func checkHTTP2(srv *http.Server) error {
+	if srv.TLSConfig == nil {
+		return nil
+	}
+	if !srv.Protocols.HTTP2() || !slices.Contains(srv.TLSConfig.NextProtos, "h2") {
+		return fmt.Errorf("HTTP/2 ist trotz TLS nicht aktiviert")
+	}
+	return nil
+}
+
+// serve startet den Server mit oder ohne TLS
+func serve(srv *http.Server, sc serverConfig) error {
+	if sc.tlsEnabled() {
+		return srv.ListenAndServeTLS(sc.certFile, sc.keyFile)
+	}
+	return srv.ListenAndServe()
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseServerConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    serverConfig
+		wantErr bool
+	}{
+		{
+			name: "Standardwerte",
+			want: serverConfig{
+				idleTimeout:          defaultIdleTimeout,
+				readHeaderTimeout:    defaultReadHeaderTimeout,
+				maxHeaderBytes:       defaultMaxHeaderBytes,
+				maxConcurrentStreams: defaultMaxConcurrentStreams,
+			},
+		},
+		{
+			name: "alles gesetzt",
+			env: map[string]string{
+				"TLS_CERT_FILE":                "cert.pem",
+				"TLS_KEY_FILE":                 "key.pem",
+				"HTTP_IDLE_TIMEOUT":            "5m",
+				"HTTP_READ_HEADER_TIMEOUT":     "2s",
+				"HTTP_MAX_HEADER_BYTES":        "8192",
+				"HTTP2_MAX_CONCURRENT_STREAMS": "250",
+			},
+			want: serverConfig{
+				certFile:             "cert.pem",
+				keyFile:              "key.pem",
+				idleTimeout:          5 * time.Minute,
+				readHeaderTimeout:    2 * time.Second,
+				maxHeaderBytes:       8192,
+				maxConcurrentStreams: 250,
+			},
+		},
+		{name: "Zertifikat ohne Schlüssel", env: map[string]string{"TLS_CERT_FILE": "cert.pem"}, wantErr: true},
+		{name: "ungültige Dauer", env: map[string]string{"HTTP_IDLE_TIMEOUT": "lang"}, wantErr: true},
+		{name: "negative Dauer", env: map[string]string{"HTTP_READ_HEADER_TIMEOUT": "-1s"}, wantErr: true},
+		{name: "ungültige Zahl", env: map[string]string{"HTTP_MAX_HEADER_BYTES": "0"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseServerConfig(func(name string) string { return tt.env[name] })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseServerConfig: %v", err)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("Konfiguration = %+v, erwartet %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// writeTestCert legt ein selbstsigniertes Zertifikat für 127.0.0.1 an
+func writeTestCert(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "treasure-hunt-test"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{in: "", want: nil},
+		{in: "10.0.0.1", want: []string{"10.0.0.1"}},
+		{in: " 10.0.0.0/8 , ::1,", want: []string{"10.0.0.0/8", "::1"}},
+		{in: "proxy.example.org", wantErr: true},
+		{in: "10.0.0.0/33", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseTrustedProxies(tt.in)
+		if (err != nil) != tt.wantErr || !slices.Equal(got, tt.want) {
+			t.Errorf("parseTrustedProxies(%q) = %v, %v, erwartet %v (Fehler: %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestHTTP2Negotiation(t *testing.T) {
+	certFile, keyFile := writeTestCert(t)
+	tests := []struct {
+		name      string
+		tls       bool
+		wantProto int
+	}{
+		{name: "mit TLS", tls: true, wantProto: 2},
+		{name: "ohne TLS", tls: false, wantProto: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, err := parseServerConfig(func(string) string { return "" })
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.tls {
+				sc.certFile, sc.keyFile = certFile, keyFile
+			}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			srv := newHTTPServer("127.0.0.1:0", handler, sc)
+			if err := checkHTTP2(srv); err != nil {
+				t.Fatal(err)
+			}
+
+			ln, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
+				t.Skipf("kein lokaler Listener möglich: %v", err)
+			}
+			go func() {
+				var err error
+				if tt.tls {
+					err = srv.ServeTLS(ln, certFile, keyFile)
+				} else {
+					err = srv.Serve(ln)
+				}
+				if !errors.Is(err, http.ErrServerClosed) {
+					t.Errorf("Server: %v", err)
+				}
+			}()
+			t.Cleanup(func() { srv.Close() })
+
+			scheme := "http"
+			transport := &http.Transport{ForceAttemptHTTP2: true}
+			if tt.tls {
+				scheme = "https"
+				transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+			}
+			client := &http.Client{Transport: transport, Timeout: 5 * time.Second}
+			resp, err := client.Get(scheme + "://" + ln.Addr().String() + "/")
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.ProtoMajor != tt.wantProto {
+				t.Errorf("Protokoll = %s, erwartet HTTP/%d", resp.Proto, tt.wantProto)
+			}
+		})
+	}
+}
+
+func TestCheckHTTP2(t *testing.T) {
+	srv := newHTTPServer(":0", http.NotFoundHandler(), serverConfig{certFile: "cert.pem", keyFile: "key.pem"})
+	if err := checkHTTP2(srv); err != nil {
+		t.Fatalf("checkHTTP2 = %v", err)
+	}
+
+	srv.TLSConfig.NextProtos = []string{"http/1.1"}
+	if err := checkHTTP2(srv); err == nil {
+		t.Error("fehlendes h2 in NextProtos nicht erkannt")
+	}
+}
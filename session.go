@@ -0,0 +1,99 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Mit REMEMBER_TEAM merkt sich der Browser das Team in einem signierten Cookie,
+// damit es an der nächsten Station vorausgewählt ist. Gespeichert wird nur der Teamname.
+const (
+	teamCookieName   = "team"
+	teamCookieMaxAge = 12 * time.Hour
+)
+
+// newSessionSecret liefert SESSION_SECRET oder einen zufälligen Schlüssel.
+// Ohne festen Schlüssel werden gemerkte Teams bei jedem Neustart vergessen.
+func newSessionSecret(configured string) (string, error) {
+	if configured != "" {
+		return configured, nil
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("fehler beim Erzeugen des Session-Schlüssels: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(key), nil
+}
+
+// signTeamCookie erzeugt den Cookie-Wert <payload>.<signatur> (beides base64url)
+func signTeamCookie(secret, team string, expires time.Time) string {
+	payload := team + "|" + strconv.FormatInt(expires.Unix(), 10)
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(sessionSignature(secret, encoded))
+}
+
+func sessionSignature(secret, encodedPayload string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("team-cookie|" + encodedPayload))
+	return mac.Sum(nil)
+}
+
+// verifyTeamCookie prüft Signatur und Ablauf und liefert den Teamnamen
+func verifyTeamCookie(secret, value string, now time.Time) (string, bool) {
+	encoded, sig, ok := strings.Cut(value, ".")
+	if !ok || secret == "" {
+		return "", false
+	}
+	gotSig, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(gotSig, sessionSignature(secret, encoded)) {
+		return "", false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", false
+	}
+	team, expiresStr, ok := strings.Cut(string(payload), "|")
+	if !ok {
+		return "", false
+	}
+	expires, err := strconv.ParseInt(expiresStr, 10, 64)
+	if err != nil || now.After(time.Unix(expires, 0)) {
+		return "", false
+	}
+	return team, true
+}
+
+// rememberTeamCookie setzt bzw. verlängert das Team-Cookie (nur mit REMEMBER_TEAM)
+func (app *App) rememberTeamCookie(c *gin.Context, teamName string) {
+	if !app.rememberTeam {
+		return
+	}
+	value := signTeamCookie(app.sessionSecret, teamName, time.Now().Add(teamCookieMaxAge))
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(teamCookieName, value, int(teamCookieMaxAge.Seconds()), "/", "", c.Request.TLS != nil, true)
+}
+
+// rememberedTeam liest das Team aus dem Cookie (leer ohne gültiges Cookie)
+func (app *App) rememberedTeam(c *gin.Context) string {
+	if !app.rememberTeam {
+		return ""
+	}
+	value, err := c.Cookie(teamCookieName)
+	if err != nil {
+		return ""
+	}
+	team, ok := verifyTeamCookie(app.sessionSecret, value, time.Now())
+	if !ok {
+		return ""
+	}
+	return team
+}
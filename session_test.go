@@ -0,0 +1,111 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyTeamCookie(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	valid := signTeamCookie("geheim", "Die Füchse", now.Add(time.Hour))
+	payload, sig, _ := strings.Cut(valid, ".")
+	// Payload eines anderen Teams mit der Signatur des gültigen Cookies
+	otherPayload, _, _ := strings.Cut(signTeamCookie("geheim", "Demo Team", now.Add(time.Hour)), ".")
+
+	tests := []struct {
+		name     string
+		secret   string
+		value    string
+		now      time.Time
+		wantTeam string
+		wantOK   bool
+	}{
+		{name: "gültig", secret: "geheim", value: valid, now: now, wantTeam: "Die Füchse", wantOK: true},
+		{name: "abgelaufen", secret: "geheim", value: valid, now: now.Add(2 * time.Hour)},
+		{name: "anderer Schlüssel", secret: "anders", value: valid, now: now},
+		{name: "ohne Schlüssel", secret: "", value: valid, now: now},
+		{name: "fremder Payload", secret: "geheim", value: otherPayload + "." + sig, now: now},
+		{name: "ohne Signatur", secret: "geheim", value: payload, now: now},
+		{name: "leer", secret: "geheim", value: "", now: now},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			team, ok := verifyTeamCookie(tt.secret, tt.value, tt.now)
+			if team != tt.wantTeam || ok != tt.wantOK {
+				t.Errorf("verifyTeamCookie = %q, %v, erwartet %q, %v", team, ok, tt.wantTeam, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRememberTeam(t *testing.T) {
+	const selected = `<option value="Die Füchse" selected>`
+	tests := []struct {
+		name         string
+		remember     string
+		cookie       func(*testApp, *http.Cookie) *http.Cookie // verändert das Cookie vor dem nächsten Formular
+		wantCookie   bool
+		wantSelected bool
+	}{
+		{name: "REMEMBER_TEAM aus", remember: "false"},
+		{name: "Team wird vorausgewählt", remember: "true", wantCookie: true, wantSelected: true},
+		{
+			name:       "manipuliertes Cookie",
+			remember:   "true",
+			wantCookie: true,
+			cookie: func(ta *testApp, c *http.Cookie) *http.Cookie {
+				return &http.Cookie{Name: c.Name, Value: signTeamCookie("falscher-schlüssel", "Die Füchse", time.Now().Add(time.Hour))}
+			},
+		},
+		{
+			name:       "Team nicht mehr vorhanden",
+			remember:   "true",
+			wantCookie: true,
+			cookie: func(ta *testApp, c *http.Cookie) *http.Cookie {
+				return &http.Cookie{Name: c.Name, Value: signTeamCookie(ta.sessionSecret, "Aufgelöst", time.Now().Add(time.Hour))}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"REMEMBER_TEAM": tt.remember, "SESSION_SECRET": "test-session"})
+
+			w := ta.advance("1", "Die Füchse")
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+			var cookie *http.Cookie
+			for _, c := range w.Result().Cookies() {
+				if c.Name == teamCookieName {
+					cookie = c
+				}
+			}
+			if (cookie != nil) != tt.wantCookie {
+				t.Fatalf("Cookie gesetzt = %v, erwartet %v", cookie != nil, tt.wantCookie)
+			}
+			if cookie == nil {
+				cookie = &http.Cookie{Name: teamCookieName, Value: signTeamCookie("test-session", "Die Füchse", time.Now().Add(time.Hour))}
+			} else if !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode {
+				t.Errorf("Cookie nicht HttpOnly/SameSite=Lax: %+v", cookie)
+			}
+			if tt.cookie != nil {
+				cookie = tt.cookie(ta, cookie)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/next/3", nil)
+			req.AddCookie(cookie)
+			form := httptest.NewRecorder()
+			ta.handler.ServeHTTP(form, req)
+			if form.Code != http.StatusOK {
+				t.Fatalf("Formular: Status = %d", form.Code)
+			}
+			if got := strings.Contains(form.Body.String(), selected); got != tt.wantSelected {
+				t.Errorf("Team vorausgewählt = %v, erwartet %v", got, tt.wantSelected)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// simulationStep ist eine Station im simulierten Durchlauf eines Teams
+type simulationStep struct {
+	Position int    `json:"position"`
+	ID       int    `json:"id,omitempty"`
+	Title    string `json:"title,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Problem  string `json:"problem,omitempty"`
+	// Branches listet die NextRules der Challenge (Antwort → Challenge-ID)
+	Branches map[string]string `json:"branches,omitempty"`
+}
+
+// handleAdminSimulate spielt die Route eines Teams von Anfang bis Ende durch, ohne
+// Abschlüsse zu schreiben, und meldet fehlende Challenges und ungültige Links
+func (app *App) handleAdminSimulate(c *gin.Context) {
+	ctx := c.Request.Context()
+	teamName := c.Param("team")
+
+	teamPageID, err := app.findTeamPage(ctx, teamName)
+	if err != nil || teamPageID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team nicht gefunden"})
+		return
+	}
+
+	route, err := app.getTeamChallenges(ctx, teamPageID)
+	if err != nil {
+		errorf("Fehler beim Abrufen der Challenges: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fehler beim Abrufen der Team-Daten"})
+		return
+	}
+
+	steps, problems := app.simulateRun(ctx, route)
+	c.JSON(http.StatusOK, gin.H{
+		"team":     teamName,
+		"steps":    steps,
+		"problems": problems,
+		"ok":       len(problems) == 0,
+	})
+}
+
+// simulateRun folgt der Route wie handleNextChallenge über findNextChallenge, beginnend
+// vor der ersten Position. Positionen, die dabei nie erreicht werden, gelten als Problem.
+// NextRules werden nicht verfolgt, sondern je Station geprüft (siehe checkBranches);
+// Schleifen über NextRules meldet findPrerequisiteCycle.
+func (app *App) simulateRun(ctx context.Context, route map[int]string) ([]simulationStep, []string) {
+	steps := []simulationStep{}
+	problems := []string{}
+	if len(route) == 0 {
+		return steps, append(problems, "dem Team sind keine Challenges zugewiesen")
+	}
+
+	visited := make(map[int]bool)
+	current := ""
+	for len(steps) < len(route) {
+		pos := currentPosition(route, current) + 1
+		id, onRoute := route[pos]
+		if !onRoute {
+			break
+		}
+		visited[pos] = true
+
+		next, err := app.findNextChallenge(ctx, route, current)
+		if err != nil || next == nil {
+			step := simulationStep{Position: pos, Problem: fmt.Sprintf("Challenge %s nicht gefunden", id)}
+			if err != nil && !errors.Is(err, errChallengeNotFound) {
+				step.Problem = fmt.Sprintf("Challenge %s nicht ladbar: %v", id, err)
+			}
+			steps = append(steps, step)
+			problems = append(problems, fmt.Sprintf("Position %d: %s", pos, step.Problem))
+			break
+		}
+
+		step := simulationStep{Position: pos, ID: next.ID, Title: next.Title, URL: next.URL}
+		if problem := checkChallengeURL(next.URL); problem != "" {
+			step.Problem = problem
+			problems = append(problems, fmt.Sprintf("Position %d (Challenge %d): %s", pos, next.ID, problem))
+		}
+		if len(next.NextRules) > 0 {
+			step.Branches = make(map[string]string, len(next.NextRules))
+			for _, rule := range next.NextRules {
+				step.Branches[rule.Answer] = rule.ChallengeID
+			}
+			for _, problem := range app.checkBranches(ctx, route, next) {
+				problems = append(problems, fmt.Sprintf("Position %d (Challenge %d): %s", pos, next.ID, problem))
+			}
+		}
+		steps = append(steps, step)
+		current = strconv.Itoa(next.ID)
+	}
+
+	// Lücken in den ChallengeN-Properties beenden die Route vorzeitig
+	var unreached []int
+	for pos := range route {
+		if !visited[pos] {
+			unreached = append(unreached, pos)
+		}
+	}
+	sort.Ints(unreached)
+	for _, pos := range unreached {
+		problems = append(problems, fmt.Sprintf("Position %d (Challenge %s) wird nie erreicht", pos, route[pos]))
+	}
+
+	return steps, problems
+}
+
+// checkBranches prüft die Ziele der NextRules einer Challenge: Sie müssen existieren und
+// auf der Route liegen, sonst geht es nach der Verzweigung nicht linear weiter.
+func (app *App) checkBranches(ctx context.Context, route map[int]string, challenge *Challenge) []string {
+	var problems []string
+	for _, rule := range challenge.NextRules {
+		if _, err := app.getChallenge(ctx, rule.ChallengeID); err != nil {
+			problems = append(problems, fmt.Sprintf("NextRules-Ziel %s für %q nicht ladbar: %v", rule.ChallengeID, rule.Answer, err))
+			continue
+		}
+		if !routeContains(route, rule.ChallengeID) {
+			problems = append(problems, fmt.Sprintf("NextRules-Ziel %s für %q liegt nicht auf der Route", rule.ChallengeID, rule.Answer))
+		}
+	}
+	return problems
+}
+
+// checkChallengeURL prüft, ob eine Challenge-URL absolut ist und auf http(s) zeigt.
+// Die URL wird nicht abgerufen.
+func checkChallengeURL(raw string) string {
+	if raw == "" {
+		return "keine URL"
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Sprintf("ungültige URL %q", raw)
+	}
+	return ""
+}
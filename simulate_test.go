@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jomei/notionapi"
+)
+
+// simulationResult ist die Antwort von /admin/simulate
+type simulationResult struct {
+	Steps    []simulationStep `json:"steps"`
+	Problems []string         `json:"problems"`
+	OK       bool             `json:"ok"`
+}
+
+// simulate ruft /admin/simulate für ein Team auf
+func simulate(t *testing.T, ta *testApp, team string) simulationResult {
+	t.Helper()
+	w := ta.admin(http.MethodPost, "/admin/simulate/"+team, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+	}
+	var result simulationResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestSimulateBranches(t *testing.T) {
+	tests := []struct {
+		name         string
+		rules        string // NextRules des Brunnens (Challenge 1)
+		wantBranches map[string]string
+		wantProblem  string
+	}{
+		{name: "Ziele auf der Route", rules: "links => 3; * => 2", wantBranches: map[string]string{"links": "3", "*": "2"}},
+		{name: "unbekanntes Ziel", rules: "links => 9", wantBranches: map[string]string{"links": "9"}, wantProblem: `NextRules-Ziel 9 für "links" nicht ladbar`},
+		{name: "Ziel nicht auf der Route", rules: "* => 5", wantBranches: map[string]string{"*": "5"}, wantProblem: `NextRules-Ziel 5 für "*" liegt nicht auf der Route`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			addDemoChallenge(ta.store, "demo-extra-5", 5)
+			setNextRules(t, ta, "demo-brunnen", tt.rules)
+
+			result := simulate(t, ta, "Demo%20Team")
+			if len(result.Steps) != 4 {
+				t.Fatalf("%d Stationen, erwartet 4: %+v", len(result.Steps), result.Steps)
+			}
+			first := result.Steps[0]
+			if len(first.Branches) != len(tt.wantBranches) {
+				t.Errorf("Branches = %v, erwartet %v", first.Branches, tt.wantBranches)
+			}
+			for answer, id := range tt.wantBranches {
+				if first.Branches[answer] != id {
+					t.Errorf("Branch %q = %q, erwartet %q", answer, first.Branches[answer], id)
+				}
+			}
+
+			if tt.wantProblem == "" {
+				if !result.OK {
+					t.Errorf("Probleme: %v", result.Problems)
+				}
+				return
+			}
+			if result.OK || !strings.Contains(strings.Join(result.Problems, "\n"), "Position 1 (Challenge 1): "+tt.wantProblem) {
+				t.Errorf("Problem %q fehlt: %v", tt.wantProblem, result.Problems)
+			}
+		})
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	tests := []struct {
+		name         string
+		team         string
+		route        []string // Challenge-Relations eines neuen Teams (nil: Demo-Team)
+		hidden       string   // Challenge-Page, die Abfragen der Challenge-DB nicht liefern
+		queryErr     error    // Fehler der Abfragen der Challenge-DB
+		wantIDs      []int
+		wantProblems []string
+	}{
+		{name: "vollständige Route", team: "Demo Team", wantIDs: []int{1, 2, 3, 4}},
+		{name: "eigene Reihenfolge", team: "Die Füchse", wantIDs: []int{1, 3, 2, 4}},
+		{
+			name:         "Lücke in der Route",
+			team:         "Lückenteam",
+			route:        []string{"demo-brunnen", "", "demo-rathaus"},
+			wantIDs:      []int{1},
+			wantProblems: []string{"Position 3 (Challenge 4) wird nie erreicht"},
+		},
+		{
+			name:         "Challenge nicht auffindbar",
+			team:         "Demo Team",
+			hidden:       "demo-kirchturm",
+			wantIDs:      []int{1, 0},
+			wantProblems: []string{"Position 2: Challenge 2 nicht gefunden", "Position 3 (Challenge 3) wird nie erreicht", "Position 4 (Challenge 4) wird nie erreicht"},
+		},
+		{name: "keine Challenges", team: "Leeres Team", route: []string{}, wantProblems: []string{"dem Team sind keine Challenges zugewiesen"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			if tt.route != nil {
+				props := notionapi.Properties{}
+				for i, pageID := range tt.route {
+					if pageID != "" {
+						props[fmt.Sprintf("Challenge%d", i+1)] = &notionapi.RelationProperty{Relation: []notionapi.Relation{{ID: notionapi.PageID(pageID)}}}
+					}
+				}
+				addDemoTeam(ta.store, tt.team, props)
+			}
+			if tt.hidden != "" {
+				ta.notion = &stubNotion{notionService: ta.store, query: func(ctx context.Context, dbID string, req *notionapi.DatabaseQueryRequest) (*notionapi.DatabaseQueryResponse, error) {
+					resp, err := ta.store.QueryDatabase(ctx, dbID, req)
+					if err != nil || dbID != demoChallengesDBID {
+						return resp, err
+					}
+					resp.Results = slices.DeleteFunc(resp.Results, func(page notionapi.Page) bool { return string(page.ID) == tt.hidden })
+					return resp, nil
+				}}
+			}
+
+			result := simulate(t, ta, url.PathEscape(tt.team))
+			var ids []int
+			for _, step := range result.Steps {
+				ids = append(ids, step.ID)
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("Stationen = %v, erwartet %v", ids, tt.wantIDs)
+			}
+			if !slices.Equal(result.Problems, tt.wantProblems) {
+				t.Errorf("Probleme = %q, erwartet %q", result.Problems, tt.wantProblems)
+			}
+			if result.OK != (len(tt.wantProblems) == 0) {
+				t.Errorf("ok = %v bei Problemen %v", result.OK, result.Problems)
+			}
+
+			// Die Simulation schreibt keine Abschlüsse
+			if completed := ta.completedChallenges(ta.teamPage(t, demoTeamPageID)); len(completed) > 0 {
+				t.Errorf("Abschlüsse geschrieben: %v", completed)
+			}
+		})
+	}
+}
+
+func TestSimulateUnknownTeam(t *testing.T) {
+	ta := newTestApp(t, nil)
+	if w := ta.admin(http.MethodPost, "/admin/simulate/Unbekannt", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Status = %d, erwartet %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestCheckChallengeURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{url: "https://example.notion.site/abc"},
+		{url: "http://localhost:8080/next/demo-brunnen"},
+		{url: "", wantErr: true},
+		{url: "/next/demo-brunnen", wantErr: true},
+		{url: "javascript:alert(1)", wantErr: true},
+		{url: "https://", wantErr: true},
+		{url: "https://exa mple.org/%zz", wantErr: true},
+	}
+	for _, tt := range tests {
+		if problem := checkChallengeURL(tt.url); (problem != "") != tt.wantErr {
+			t.Errorf("checkChallengeURL(%q) = %q, erwartet Problem = %v", tt.url, problem, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"html/template"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// teamOptionsCache hält das gerenderte <option>-Fragment des Team-Dropdowns.
+// Die Version steigt nur, wenn sich die Teamliste tatsächlich ändert; erst dann
+// wird das Fragment neu gebaut. Bei großen Teamlisten spart das das Ausführen
+// der Optionen bei jedem Formularaufruf.
+type teamOptionsCache struct {
+	mu       sync.Mutex
+	names    []string
+	version  uint64 // Version von names, 0: noch keine Teamliste
+	rendered uint64 // Version, zu der html gehört
+	html     string
+}
+
+// update übernimmt eine frisch geladene Teamliste und liefert deren Version
+func (oc *teamOptionsCache) update(names []string) uint64 {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
+	if oc.version == 0 || !slices.Equal(oc.names, names) {
+		oc.names = slices.Clone(names)
+		oc.version++
+	}
+	return oc.version
+}
+
+// reset verwirft Teamliste und Fragment; bis zum nächsten update ist das Fragment leer
+func (oc *teamOptionsCache) reset() {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
+	oc.names = nil
+	oc.html = ""
+	oc.version = 0
+	oc.rendered = 0
+}
+
+// fragment liefert die <option>-Elemente der aktuellen Teamliste, bei Bedarf neu gerendert
+func (oc *teamOptionsCache) fragment() string {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
+	if oc.rendered != oc.version {
+		oc.html = renderTeamOptions(oc.names)
+		oc.rendered = oc.version
+	}
+	return oc.html
+}
+
+// renderTeamOptions baut je Team ein <option>-Element; Namen werden HTML-escaped
+func renderTeamOptions(names []string) string {
+	var b strings.Builder
+	for _, name := range names {
+		escaped := template.HTMLEscapeString(name)
+		b.WriteString(`<option value="`)
+		b.WriteString(escaped)
+		b.WriteString(`">`)
+		b.WriteString(escaped)
+		b.WriteString("</option>\n")
+	}
+	return b.String()
+}
+
+// selectTeamOption markiert das Team selected im gecachten Fragment, ohne es neu zu rendern.
+// Unbekannte oder leere Teams lassen das Fragment unverändert.
+func selectTeamOption(fragment, team string) template.HTML {
+	if team != "" {
+		option := `<option value="` + template.HTMLEscapeString(team) + `">`
+		fragment = strings.Replace(fragment, option, strings.TrimSuffix(option, ">")+" selected>", 1)
+	}
+	// Das Fragment besteht nur aus escapten Teamnamen (renderTeamOptions)
+	return template.HTML(fragment)
+}
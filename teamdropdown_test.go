@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRenderTeamOptions(t *testing.T) {
+	got := renderTeamOptions([]string{"Die Füchse", `<script>"A" & 'B'</script>`})
+	want := `<option value="Die Füchse">Die Füchse</option>` + "\n" +
+		`<option value="&lt;script&gt;&#34;A&#34; &amp; &#39;B&#39;&lt;/script&gt;">&lt;script&gt;&#34;A&#34; &amp; &#39;B&#39;&lt;/script&gt;</option>` + "\n"
+	if got != want {
+		t.Errorf("renderTeamOptions =\n%s\nerwartet\n%s", got, want)
+	}
+	if got := renderTeamOptions(nil); got != "" {
+		t.Errorf("ohne Teams: %q", got)
+	}
+}
+
+func TestSelectTeamOption(t *testing.T) {
+	fragment := renderTeamOptions([]string{"Demo", "Demo Team", `A & "B"`})
+	tests := []struct {
+		team string
+		want string // Option mit selected ("": keine)
+	}{
+		{team: "Demo Team", want: `<option value="Demo Team" selected>`},
+		{team: "Demo", want: `<option value="Demo" selected>`},
+		{team: `A & "B"`, want: `<option value="A &amp; &#34;B&#34;" selected>`},
+		{team: "Unbekannt"},
+		{team: ""},
+	}
+	for _, tt := range tests {
+		got := string(selectTeamOption(fragment, tt.team))
+		wantSelected := 0
+		if tt.want != "" {
+			wantSelected = 1
+		}
+		if n := strings.Count(got, " selected>"); n != wantSelected {
+			t.Errorf("selectTeamOption(%q): %d Optionen ausgewählt, erwartet %d", tt.team, n, wantSelected)
+		}
+		if tt.want != "" && !strings.Contains(got, tt.want) {
+			t.Errorf("selectTeamOption(%q): %q fehlt:\n%s", tt.team, tt.want, got)
+		}
+	}
+}
+
+func TestTeamOptionsCache(t *testing.T) {
+	var oc teamOptionsCache
+	if got := oc.fragment(); got != "" {
+		t.Fatalf("Fragment ohne Teamliste: %q", got)
+	}
+
+	first := oc.update([]string{"Adler", "Bären"})
+	if got := oc.update([]string{"Adler", "Bären"}); got != first {
+		t.Errorf("gleiche Teamliste: Version %d, erwartet %d", got, first)
+	}
+	if got := oc.fragment(); !strings.Contains(got, "Bären") {
+		t.Errorf("Fragment = %q", got)
+	}
+
+	if got := oc.update([]string{"Adler", "Bären", "Chamäleons"}); got == first {
+		t.Error("geänderte Teamliste ohne neue Version")
+	}
+	if got := oc.fragment(); !strings.Contains(got, "Chamäleons") {
+		t.Errorf("Fragment nach Änderung = %q", got)
+	}
+
+	oc.reset()
+	if got := oc.fragment(); got != "" {
+		t.Errorf("Fragment nach reset = %q", got)
+	}
+	if oc.update([]string{"Adler"}); !strings.Contains(oc.fragment(), "Adler") {
+		t.Error("Fragment nach reset und update leer")
+	}
+}
+
+func TestTeamOptionsFragmentUpdates(t *testing.T) {
+	const newOption = `<option value="Neu &lt;&amp;&gt; Team">Neu &lt;&amp;&gt; Team</option>`
+	tests := []struct {
+		name       string
+		invalidate func(ta *testApp)
+	}{
+		{name: "Teamliste invalidiert", invalidate: func(ta *testApp) { ta.cache.invalidateTeamNames() }},
+		{name: "alle Caches geleert", invalidate: func(ta *testApp) {
+			if w := ta.admin(http.MethodPost, "/admin/cache/clear", ""); w.Code != http.StatusOK {
+				t.Fatalf("Cache leeren: %d %s", w.Code, w.Body.String())
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			form := func() string {
+				t.Helper()
+				w := ta.do(http.MethodGet, "/next/1", nil)
+				if w.Code != http.StatusOK {
+					t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+				}
+				return w.Body.String()
+			}
+
+			if body := form(); !strings.Contains(body, `<option value="Die Füchse">`) {
+				t.Fatalf("Demo-Teams fehlen:\n%s", body)
+			}
+			addDemoTeam(ta.store, "Neu <&> Team")
+
+			// Bis zur Invalidierung bleibt das gecachte Fragment
+			if body := form(); strings.Contains(body, "Neu &lt;") {
+				t.Error("neues Team vor der Invalidierung im Dropdown")
+			}
+			tt.invalidate(ta)
+			body := form()
+			if !strings.Contains(body, newOption) {
+				t.Errorf("%s fehlt:\n%s", newOption, body)
+			}
+			if strings.Contains(body, "Neu <&> Team") {
+				t.Error("Teamname nicht escaped")
+			}
+			if !strings.Contains(body, `<option value="Die Füchse">`) {
+				t.Error("bisherige Teams fehlen nach der Aktualisierung")
+			}
+		})
+	}
+}
+
+func TestTeamNamesBeyondFirstPage(t *testing.T) {
+	ta := newTestApp(t, nil)
+	for i := 1; i <= 150; i++ {
+		addDemoTeam(ta.store, fmt.Sprintf("Zusatzteam %03d", i))
+	}
+
+	names, err := ta.getAllTeamNames(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(names, "Zusatzteam 150") {
+		t.Fatalf("%d Teamnamen, Zusatzteam 150 fehlt", len(names))
+	}
+	if body := ta.do(http.MethodGet, "/next/1", nil).Body.String(); !strings.Contains(body, `<option value="Zusatzteam 150">`) {
+		t.Error("Zusatzteam 150 fehlt im Dropdown")
+	}
+
+	// Auch der Import erkennt Teams jenseits der ersten 100 als vorhanden
+	w := ta.admin(http.MethodPost, "/admin/import-teams", "zusatzteam 150\n")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Import: Status = %d: %s", w.Code, w.Body.String())
+	}
+	if body := decodeJSON(t, w); body["created"] != float64(0) {
+		t.Errorf("Import: created = %v, erwartet 0 (Duplikat)", body["created"])
+	}
+}
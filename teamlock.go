@@ -0,0 +1,49 @@
+package main
+
+import "sync"
+
+// teamLocks serialisiert Weiterleitungen desselben Teams (z.B. zwei Handys an einer
+// Station), damit sich das Schreiben der Abschlüsse nicht überschneidet. Locks werden
+// nach dem letzten Nutzer wieder entfernt. Ein nil-Wert schaltet das Sperren ab.
+type teamLocks struct {
+	mu    sync.Mutex
+	locks map[string]*teamLock
+}
+
+type teamLock struct {
+	mu      sync.Mutex
+	waiters int // Halter plus Wartende, 0 bedeutet entfernbar
+}
+
+func newTeamLocks() *teamLocks {
+	return &teamLocks{locks: make(map[string]*teamLock)}
+}
+
+// lock sperrt das Team und liefert die Freigabe-Funktion
+func (tl *teamLocks) lock(teamPageID string) func() {
+	if tl == nil {
+		return func() {}
+	}
+	key := normalizePageID(teamPageID)
+
+	tl.mu.Lock()
+	l := tl.locks[key]
+	if l == nil {
+		l = &teamLock{}
+		tl.locks[key] = l
+	}
+	l.waiters++
+	tl.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+
+		tl.mu.Lock()
+		l.waiters--
+		if l.waiters == 0 {
+			delete(tl.locks, key)
+		}
+		tl.mu.Unlock()
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestTeamLocks(t *testing.T) {
+	locks := newTeamLocks()
+
+	// Gleiches Team (auch in anderer Schreibweise der Page-ID): nacheinander
+	var wg sync.WaitGroup
+	counter := 0
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id := "abcd-1234"
+			if i%2 == 0 {
+				id = "ABCD1234"
+			}
+			unlock := locks.lock(id)
+			defer unlock()
+			counter++
+		}()
+	}
+	wg.Wait()
+	if counter != 50 {
+		t.Errorf("counter = %d, erwartet 50", counter)
+	}
+
+	// Andere Teams warten nicht
+	unlock := locks.lock("team-a")
+	done := make(chan struct{})
+	go func() {
+		locks.lock("team-b")()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("team-b wartet auf team-a")
+	}
+	unlock()
+
+	locks.mu.Lock()
+	defer locks.mu.Unlock()
+	if len(locks.locks) != 0 {
+		t.Errorf("nicht aufgeräumte Locks: %v", locks.locks)
+	}
+}
+
+func TestTeamLocksDisabled(t *testing.T) {
+	var locks *teamLocks
+	unlock := locks.lock("team-a")
+	locks.lock("team-a")()
+	unlock()
+}
+
+// slowWriteNotion lässt Schreibzugriffe auf sich warten. Ohne Sperre lesen zwei
+// gleichzeitige Requests so beide den alten Stand, bevor einer davon schreibt.
+type slowWriteNotion struct {
+	notionService
+	delay time.Duration
+}
+
+func (n *slowWriteNotion) UpdatePage(ctx context.Context, pageID string, req *notionapi.PageUpdateRequest) (*notionapi.Page, error) {
+	time.Sleep(n.delay)
+	return n.notionService.UpdatePage(ctx, pageID, req)
+}
+
+func TestConcurrentAdvance(t *testing.T) {
+	ta := newTestApp(t, nil)
+	var buf bytes.Buffer
+	ta.events = newJSONEventLogger(&buf)
+	ta.notion = &slowWriteNotion{notionService: ta.store, delay: 20 * time.Millisecond}
+
+	// Zwei Handys desselben Teams schicken gleichzeitig verschiedene Stationen ab
+	var wg sync.WaitGroup
+	codes := make([]int, 2)
+	for i, id := range []string{"1", "2"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes[i] = ta.advance(id, "Demo Team").Code
+		}()
+	}
+	wg.Wait()
+
+	for i, code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("Request %d: Status = %d", i+1, code)
+		}
+	}
+	for _, event := range readEvents(t, buf.Bytes()) {
+		if event.Outcome != outcomeAdvanced {
+			t.Errorf("Outcome von %s = %q, erwartet %q", event.From, event.Outcome, outcomeAdvanced)
+		}
+	}
+
+	page := ta.teamPage(t, demoTeamPageID)
+	completed := ta.completedChallenges(page)
+	if _, done := completed[1]; !done {
+		t.Error("Challenge 1 nicht abgeschlossen")
+	}
+	if _, done := completed[2]; !done {
+		t.Error("Challenge 2 nicht abgeschlossen")
+	}
+	// Brunnen (10) und Kirchturm (20): kein Request hat den Score des anderen überschrieben
+	if score := teamScore(page); score != 30 {
+		t.Errorf("Score = %v, erwartet 30", score)
+	}
+}
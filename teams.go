@@ -0,0 +1,494 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jomei/notionapi"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Property-Namen für neu angelegte Teams
+const (
+	teamTitleProperty   = "Name"
+	teamAliasesProperty = "Aliases"
+	teamCodeProperty    = "Code"
+)
+
+// Join-Codes bestehen aus gut unterscheidbaren Zeichen (kein 0/O, 1/I)
+const (
+	joinCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	joinCodeLength   = 6
+)
+
+// maxTeamNameLength begrenzt die Länge eines Teamnamens
+const maxTeamNameLength = 100
+
+// errNoTeams wird geliefert, wenn die Team-DB leer ist
+var errNoTeams = errors.New("keine Teams in der Datenbank gefunden")
+
+// validateTeamName prüft einen Teamnamen vor dem Anlegen
+func validateTeamName(name string) error {
+	if name == "" {
+		return errors.New("Teamname darf nicht leer sein")
+	}
+	if utf8.RuneCountInString(name) > maxTeamNameLength {
+		return fmt.Errorf("Teamname ist länger als %d Zeichen", maxTeamNameLength)
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return errors.New("Teamname enthält Steuerzeichen")
+		}
+	}
+	return nil
+}
+
+// createTeam legt ein neues Team in der Team-DB an und liefert Page-ID und Join-Code
+func (app *App) createTeam(ctx context.Context, name string, aliases []string) (string, string, error) {
+	name = trimTeamName(name)
+	if err := validateTeamName(name); err != nil {
+		return "", "", err
+	}
+
+	code, err := generateJoinCode()
+	if err != nil {
+		return "", "", err
+	}
+
+	props := notionapi.Properties{
+		teamTitleProperty: notionapi.TitleProperty{
+			Title: []notionapi.RichText{{Text: &notionapi.Text{Content: name}}},
+		},
+		teamCodeProperty: notionapi.RichTextProperty{
+			RichText: []notionapi.RichText{{Text: &notionapi.Text{Content: code}}},
+		},
+	}
+	if len(aliases) > 0 {
+		props[teamAliasesProperty] = notionapi.RichTextProperty{
+			RichText: []notionapi.RichText{{Text: &notionapi.Text{Content: strings.Join(aliases, ", ")}}},
+		}
+	}
+
+	page, err := app.createPage(ctx, &notionapi.PageCreateRequest{
+		Parent: notionapi.Parent{
+			Type:       notionapi.ParentTypeDatabaseID,
+			DatabaseID: notionapi.DatabaseID(app.teamsDBID),
+		},
+		Properties: props,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("fehler beim Anlegen des Teams %q: %w", name, err)
+	}
+
+	app.cache.invalidateTeamNames()
+	return string(page.ID), code, nil
+}
+
+// generateJoinCode erzeugt einen zufälligen Join-Code für ein Team
+func generateJoinCode() (string, error) {
+	code := make([]byte, joinCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(joinCodeAlphabet))))
+		if err != nil {
+			return "", fmt.Errorf("fehler beim Erzeugen des Join-Codes: %w", err)
+		}
+		code[i] = joinCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// findTeamByCode sucht ein Team über seinen Join-Code und liefert Page-ID und Teamnamen
+func (app *App) findTeamByCode(ctx context.Context, code string) (string, string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" || app.cache.teamCodeAbsent() {
+		return "", "", nil
+	}
+
+	result, err := app.queryDatabase(ctx, app.teamsDBID, &notionapi.DatabaseQueryRequest{
+		Filter: &notionapi.PropertyFilter{
+			Property: teamCodeProperty,
+			RichText: &notionapi.TextFilterCondition{
+				Equals: code,
+			},
+		},
+	})
+	if isMissingPropertyError(err) {
+		// Ohne Join-Codes (z.B. Teams ohne Registrierung) nicht bei jeder Eingabe erneut fragen
+		debugf("Team-DB hat keine Property %q, Suche nach Join-Codes ausgesetzt", teamCodeProperty)
+		app.cache.storeTeamCodeAbsent()
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+	if len(result.Results) == 0 {
+		return "", "", nil
+	}
+
+	page := result.Results[0]
+	return string(page.ID), pageTitle(page), nil
+}
+
+// resolveTeam findet ein Team anhand eines Join-Codes oder Teamnamens.
+// Der Code hat Vorrang, danach wird der Wert als Teamname gesucht.
+func (app *App) resolveTeam(ctx context.Context, value string) (string, string, error) {
+	pageID, teamName, err := app.findTeamByCode(ctx, value)
+	if err != nil {
+		errorf("Fehler bei der Suche nach Join-Code %s: %v", value, err)
+	}
+	if pageID != "" {
+		return pageID, teamName, nil
+	}
+
+	page, err := app.findTeam(ctx, value)
+	if page == nil {
+		return "", value, err
+	}
+	// Alias, Tippfehler oder andere Schreibweise: weiter geht es unter dem Namen aus Notion
+	return string(page.ID), teamTitle(page, value), err
+}
+
+// maxTeamLookupRetry begrenzt TEAM_LOOKUP_RETRY, damit Teilnehmende nicht ewig warten
+const maxTeamLookupRetry = 5 * time.Second
+
+// parseTeamLookupRetry liest TEAM_LOOKUP_RETRY (leer oder 0: aus)
+func parseTeamLookupRetry(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 || d > maxTeamLookupRetry {
+		return 0, fmt.Errorf("TEAM_LOOKUP_RETRY muss eine Dauer zwischen 0 und %v sein (z.B. 500ms), ist aber %q", maxTeamLookupRetry, s)
+	}
+	return d, nil
+}
+
+// resolveTeamWithRetry sucht ein Team und versucht es bei einem Fehlschlag nach
+// TEAM_LOOKUP_RETRY ein zweites Mal. Frisch registrierte Teams tauchen in Notion
+// manchmal erst verzögert in Abfragen auf.
+func (app *App) resolveTeamWithRetry(ctx context.Context, value string) (string, string, error) {
+	pageID, teamName, err := app.resolveTeam(ctx, value)
+	if pageID != "" || app.teamLookupRetry <= 0 {
+		return pageID, teamName, err
+	}
+
+	select {
+	case <-ctx.Done():
+		return pageID, teamName, err
+	case <-time.After(app.teamLookupRetry):
+	}
+
+	debugf("Team %s nicht gefunden, zweiter Versuch nach %v", value, app.teamLookupRetry)
+	return app.resolveTeam(ctx, value)
+}
+
+// listTeamPages holt alle Pages der Team-DB (über mehrere Notion-Seiten hinweg)
+func (app *App) listTeamPages(ctx context.Context) ([]notionapi.Page, error) {
+	var pages []notionapi.Page
+
+	query := &notionapi.DatabaseQueryRequest{PageSize: 100}
+	for {
+		result, err := app.queryDatabase(ctx, app.teamsDBID, query)
+		if err != nil {
+			return nil, fmt.Errorf("fehler beim Abfragen der Team-Datenbank: %w", err)
+		}
+		pages = append(pages, result.Results...)
+
+		if !result.HasMore {
+			break
+		}
+		query.StartCursor = result.NextCursor
+	}
+
+	return pages, nil
+}
+
+// normalizeTeamName vereinheitlicht Teamnamen für Vergleiche: wie collapseTeamName,
+// zusätzlich mit Case-Folding statt reinem ToLower (z.B. "ς" und "Σ" werden beide zu "σ").
+func normalizeTeamName(name string) string {
+	return strings.ToLower(strings.ToUpper(collapseTeamName(name)))
+}
+
+// collapseTeamName bringt einen Teamnamen in Unicode-Normalform NFC (ein "é" ist ein
+// Zeichen, egal wie es getippt wurde), entfernt Emoji-Varianten-Selektoren und fasst
+// Leerraum jeder Art zu einem Leerzeichen zusammen. Groß-/Kleinschreibung bleibt.
+func collapseTeamName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if isInvisibleRune(r) {
+			return -1
+		}
+		return r
+	}, norm.NFC.String(name))
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// trimTeamName entfernt Leerraum am Rand, auch geschützte und unsichtbare Leerzeichen
+// (z.B. U+00A0, U+200B aus kopierten Namen). Das Innere bleibt unverändert.
+func trimTeamName(name string) string {
+	return strings.TrimFunc(name, func(r rune) bool {
+		return unicode.IsSpace(r) || isInvisibleRune(r)
+	})
+}
+
+// isInvisibleRune erkennt Zeichen ohne eigene Darstellung, die Teamnamen nicht unterscheiden
+// sollen. Zero-Width-Joiner (U+200D) bleibt erhalten, er setzt Emoji wie 👨‍👩‍👧 zusammen.
+func isInvisibleRune(r rune) bool {
+	switch r {
+	case '\u200B', '\u2060', '\uFEFF', '\uFE0E', '\uFE0F':
+		return true
+	}
+	return false
+}
+
+// sortTeamNames sortiert Teamnamen nach Unicode-Collation statt nach Bytes, damit
+// "Élan" neben "Eule" steht und nicht hinter "Zebra". Gleichrangige Namen werden
+// per Byte-Vergleich geordnet, damit die Reihenfolge stabil ist.
+func sortTeamNames(names []string) {
+	collator := collate.New(language.Und, collate.IgnoreCase)
+	sort.SliceStable(names, func(i, j int) bool {
+		if cmp := collator.CompareString(names[i], names[j]); cmp != 0 {
+			return cmp < 0
+		}
+		return names[i] < names[j]
+	})
+}
+
+// duplicateTeam beschreibt mehrere Team-Pages mit demselben (normalisierten) Namen
+type duplicateTeam struct {
+	Name    string   `json:"name"`
+	PageIDs []string `json:"pageIDs"`
+}
+
+// findDuplicateTeams gruppiert alle Teams nach normalisiertem Namen und liefert die Dubletten
+func (app *App) findDuplicateTeams(ctx context.Context) ([]duplicateTeam, error) {
+	pages, err := app.listTeamPages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string]*duplicateTeam)
+	var order []string
+	for _, page := range pages {
+		name := pageTitle(page)
+		if name == "" {
+			continue
+		}
+		key := normalizeTeamName(name)
+		if groups[key] == nil {
+			groups[key] = &duplicateTeam{Name: name}
+			order = append(order, key)
+		}
+		groups[key].PageIDs = append(groups[key].PageIDs, string(page.ID))
+	}
+
+	var duplicates []duplicateTeam
+	for _, key := range order {
+		if len(groups[key].PageIDs) > 1 {
+			duplicates = append(duplicates, *groups[key])
+		}
+	}
+	return duplicates, nil
+}
+
+// warnDuplicateTeams loggt beim Start eine Warnung für doppelte Teamnamen
+func (app *App) warnDuplicateTeams(ctx context.Context) {
+	duplicates, err := app.findDuplicateTeams(ctx)
+	if err != nil {
+		errorf("Prüfung auf doppelte Teamnamen fehlgeschlagen: %v", err)
+		return
+	}
+	for _, d := range duplicates {
+		warnf("Teamname %q kommt %d-mal vor (Pages: %s)", d.Name, len(d.PageIDs), strings.Join(d.PageIDs, ", "))
+	}
+}
+
+// teamSuggestionsError wird geliefert, wenn ein Teamname nur ungefähr zu einem
+// oder mehreren Teams passt und nicht automatisch übernommen wird
+type teamSuggestionsError struct {
+	Suggestions []string
+}
+
+func (e *teamSuggestionsError) Error() string {
+	return fmt.Sprintf("team nicht eindeutig, meintest du: %s", strings.Join(e.Suggestions, ", "))
+}
+
+// parseFuzzyDistance liest FUZZY_MATCH_DISTANCE (leer oder 0: Fuzzy-Suche aus)
+func parseFuzzyDistance(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	distance, err := strconv.Atoi(s)
+	if err != nil || distance < 0 {
+		return 0, fmt.Errorf("FUZZY_MATCH_DISTANCE muss eine nicht-negative ganze Zahl sein, ist aber %q", s)
+	}
+	return distance, nil
+}
+
+// Modi für MATCH_MODE: Treffer am Namensanfang oder irgendwo im Namen
+const (
+	matchModePrefix    = "prefix"
+	matchModeSubstring = "substring"
+)
+
+// Eingabemodi für TEAM_INPUT_MODE
+const (
+	teamInputDropdown = "dropdown" // alle Teams als <option> im HTML (Standard)
+	teamInputSearch   = "search"   // Eingabefeld mit Vorschlägen über /api/teams/search
+)
+
+// parseTeamInputMode liest TEAM_INPUT_MODE
+func parseTeamInputMode(s string) (string, error) {
+	switch s {
+	case "", teamInputDropdown:
+		return teamInputDropdown, nil
+	case teamInputSearch:
+		return s, nil
+	default:
+		return "", fmt.Errorf("TEAM_INPUT_MODE %q ist ungültig (dropdown, search)", s)
+	}
+}
+
+// minPartialMatchLength verhindert, dass ein einzelner Buchstabe in der Fuzzy-Suche als Treffer zählt
+const minPartialMatchLength = 3
+
+// parseMatchMode liest MATCH_MODE (Standard: prefix)
+func parseMatchMode(s string) (string, error) {
+	switch strings.ToLower(s) {
+	case "", matchModePrefix:
+		return matchModePrefix, nil
+	case matchModeSubstring:
+		return matchModeSubstring, nil
+	default:
+		return "", fmt.Errorf("MATCH_MODE %q ist ungültig (prefix, substring)", s)
+	}
+}
+
+// teamNameMatches prüft einen Teilstring-Treffer nach MATCH_MODE. Beide Seiten werden
+// wie bei der Namenssuche normalisiert, Groß-/Kleinschreibung spielt keine Rolle.
+func teamNameMatches(name, query, mode string) bool {
+	name, query = normalizeTeamName(name), normalizeTeamName(query)
+	if query == "" {
+		return false
+	}
+	if mode == matchModeSubstring {
+		return strings.Contains(name, query)
+	}
+	return strings.HasPrefix(name, query)
+}
+
+// handleAPITeamSearch liefert Teamnamen für die Autovervollständigung (Treffer nach MATCH_MODE)
+func (app *App) handleAPITeamSearch(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Suchbegriff q fehlt"})
+		return
+	}
+
+	// Im Suchmodus soll sich die Teamliste nicht Buchstabe für Buchstabe abfragen lassen
+	if app.teamInputMode == teamInputSearch && utf8.RuneCountInString(query) < minPartialMatchLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Suchbegriff q muss mindestens %d Zeichen haben", minPartialMatchLength)})
+		return
+	}
+
+	names, err := app.getAllTeamNames(c.Request.Context())
+	if err != nil && !errors.Is(err, errNoTeams) {
+		errorf("Fehler beim Abrufen der Teamnamen: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fehler beim Laden der Teamliste"})
+		return
+	}
+
+	results, truncated := searchTeamNames(names, query, app.matchMode, maxSearchResults)
+	c.JSON(http.StatusOK, gin.H{
+		"query":     query,
+		"mode":      app.matchMode,
+		"results":   results,
+		"truncated": truncated,
+	})
+}
+
+// searchTeamNames filtert Teamnamen nach MATCH_MODE und liefert höchstens limit Treffer
+func searchTeamNames(names []string, query, mode string, limit int) ([]string, bool) {
+	results := []string{}
+	for _, name := range names {
+		if !teamNameMatches(name, query, mode) {
+			continue
+		}
+		if len(results) == limit {
+			return results, true
+		}
+		results = append(results, name)
+	}
+	return results, false
+}
+
+// fuzzyMatchTeam sucht Teams, deren Name oder Alias höchstens fuzzyMaxDistance
+// Änderungen vom eingegebenen Namen entfernt ist oder nach MATCH_MODE passt.
+// Ein einzelner Treffer wird bei fuzzyAutoAccept übernommen, sonst werden die
+// Treffer als Vorschläge geliefert.
+func (app *App) fuzzyMatchTeam(pages []notionapi.Page, teamName string) (*notionapi.Page, error) {
+	input := normalizeTeamName(teamName)
+
+	var match *notionapi.Page
+	var names []string
+	for i := range pages {
+		name := pageTitle(pages[i])
+		if name == "" {
+			continue
+		}
+		for _, candidate := range append([]string{name}, teamAliases(pages[i])...) {
+			partial := utf8.RuneCountInString(input) >= minPartialMatchLength && teamNameMatches(candidate, input, app.matchMode)
+			if partial || editDistance(input, normalizeTeamName(candidate)) <= app.fuzzyMaxDistance {
+				match = &pages[i]
+				names = append(names, name)
+				break
+			}
+		}
+	}
+
+	switch {
+	case len(names) == 0:
+		return nil, nil
+	case len(names) == 1 && app.fuzzyAutoAccept:
+		infof("Fuzzy-Treffer: %q als Team %q erkannt", teamName, names[0])
+		return match, nil
+	default:
+		sortTeamNames(names)
+		return nil, &teamSuggestionsError{Suggestions: names}
+	}
+}
+
+// editDistance berechnet die Levenshtein-Distanz zweier Strings (auf Runen-Ebene)
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,629 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestTeamAliases(t *testing.T) {
+	tests := []struct {
+		name string
+		prop notionapi.Property
+		want []string
+	}{
+		{name: "ohne Property", want: nil},
+		{name: "Rich-Text kommagetrennt", prop: &notionapi.RichTextProperty{RichText: demoText("Füchse, Fuchsbande ,, Rotfell")}, want: []string{"Füchse", "Fuchsbande", "Rotfell"}},
+		{name: "Multi-Select", prop: &notionapi.MultiSelectProperty{MultiSelect: []notionapi.Option{{Name: "Füchse"}, {Name: "Rotfell"}}}, want: []string{"Füchse", "Rotfell"}},
+		{name: "anderer Typ", prop: &notionapi.NumberProperty{Number: 3}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := notionapi.Page{Properties: notionapi.Properties{}}
+			if tt.prop != nil {
+				page.Properties[teamAliasesProperty] = tt.prop
+			}
+			if got := teamAliases(page); !slices.Equal(got, tt.want) {
+				t.Errorf("teamAliases = %q, erwartet %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveTeamAlias(t *testing.T) {
+	tests := []struct {
+		input      string
+		wantPageID string
+		wantName   string
+	}{
+		{input: "Demo Team", wantPageID: demoTeamPageID, wantName: "Demo Team"},
+		{input: "Demo", wantPageID: demoTeamPageID, wantName: "Demo Team"},
+		{input: "  demo ", wantPageID: demoTeamPageID, wantName: "Demo Team"},
+		{input: "FÜCHSE", wantPageID: foxesTeamPageID, wantName: "Die Füchse"},
+		{input: "Hasen", wantPageID: "", wantName: "Hasen"},
+	}
+
+	ta := newTestApp(t, nil)
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			pageID, name, err := ta.resolveTeam(context.Background(), tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pageID != tt.wantPageID || name != tt.wantName {
+				t.Errorf("resolveTeam(%q) = %q, %q, erwartet %q, %q", tt.input, pageID, name, tt.wantPageID, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestGenerateJoinCode(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		code, err := generateJoinCode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(code) != joinCodeLength || strings.Trim(code, joinCodeAlphabet) != "" {
+			t.Fatalf("Join-Code %q passt nicht zu Länge %d und Alphabet %q", code, joinCodeLength, joinCodeAlphabet)
+		}
+		seen[code] = true
+	}
+	if len(seen) < 45 {
+		t.Errorf("nur %d verschiedene Codes aus 50 Versuchen", len(seen))
+	}
+}
+
+func TestResolveTeamByCode(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantPageID string
+		wantName   string
+	}{
+		{name: "Code", input: "FUCHS1", wantPageID: foxesTeamPageID, wantName: "Die Füchse"},
+		{name: "Code klein und mit Leerraum", input: " demo01 ", wantPageID: demoTeamPageID, wantName: "Demo Team"},
+		{name: "unbekannter Code", input: "ZZZZ99", wantPageID: "", wantName: "ZZZZ99"},
+		{name: "Name statt Code", input: "Die Füchse", wantPageID: foxesTeamPageID, wantName: "Die Füchse"},
+	}
+
+	ta := newTestApp(t, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pageID, name, err := ta.resolveTeam(context.Background(), tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pageID != tt.wantPageID || name != tt.wantName {
+				t.Errorf("resolveTeam(%q) = %q, %q, erwartet %q, %q", tt.input, pageID, name, tt.wantPageID, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestResolveTeamWithoutCodeProperty(t *testing.T) {
+	ta := newTestApp(t, nil)
+	codeQueries := 0
+	ta.notion = &stubNotion{notionService: ta.store, query: func(ctx context.Context, dbID string, req *notionapi.DatabaseQueryRequest) (*notionapi.DatabaseQueryResponse, error) {
+		if f, ok := req.Filter.(*notionapi.PropertyFilter); ok && f.Property == teamCodeProperty {
+			codeQueries++
+			return nil, notionError(http.StatusBadRequest, "validation_error", "Could not find property with name or id: Code")
+		}
+		return ta.store.QueryDatabase(ctx, dbID, req)
+	}}
+
+	resolve := func() {
+		t.Helper()
+		pageID, name, err := ta.resolveTeam(t.Context(), "Die Füchse")
+		if err != nil || pageID != foxesTeamPageID || name != "Die Füchse" {
+			t.Fatalf("resolveTeam = %q, %q, %v", pageID, name, err)
+		}
+	}
+
+	// Die fehlende Property fällt einmal auf, danach wird nicht mehr nach Codes gesucht
+	for range 3 {
+		resolve()
+	}
+	if codeQueries != 1 {
+		t.Errorf("%d Abfragen nach Join-Codes, erwartet 1", codeQueries)
+	}
+
+	// Nach dem Leeren der Caches wird die Property erneut geprüft
+	ta.cache.clearAll()
+	resolve()
+	if codeQueries != 2 {
+		t.Errorf("%d Abfragen nach Join-Codes nach clearAll, erwartet 2", codeQueries)
+	}
+}
+
+func TestAdvanceWithJoinCode(t *testing.T) {
+	ta := newTestApp(t, nil)
+	// Der Code hat Vorrang vor dem Dropdown
+	w := ta.do(http.MethodPost, "/next/1", url.Values{"code": {"fuchs1"}, "team": {"Demo Team"}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), demoChallengeURL+"demo-stadtpark") {
+		t.Errorf("Weiterleitung nicht auf der Route der Füchse:\n%s", w.Body.String())
+	}
+	if _, done := ta.completedChallenges(ta.teamPage(t, foxesTeamPageID))[1]; !done {
+		t.Error("Abschluss fehlt bei den Füchsen")
+	}
+}
+
+func TestFindDuplicateTeams(t *testing.T) {
+	tests := []struct {
+		name  string
+		extra []string // zusätzliche Teams neben den Demo-Teams
+		want  map[string]int
+	}{
+		{name: "keine Duplikate", extra: []string{"Hasen"}, want: map[string]int{}},
+		{name: "gleicher Name", extra: []string{"Demo Team"}, want: map[string]int{"Demo Team": 2}},
+		{
+			name:  "Schreibweisen wie bei der Team-Suche",
+			extra: []string{"demo team", "Demo  Team", "DIE FÜCHSE"},
+			want:  map[string]int{"Demo Team": 3, "Die Füchse": 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			for _, name := range tt.extra {
+				addDemoTeam(ta.store, name)
+			}
+
+			w := ta.admin(http.MethodGet, "/admin/duplicate-teams", "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+			var body struct {
+				Duplicates []duplicateTeam `json:"duplicates"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[string]int)
+			for _, d := range body.Duplicates {
+				got[d.Name] = len(d.PageIDs)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("Duplikate = %v, erwartet %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"demo team", "demo team", 0},
+		{"demo team", "demo tem", 1},
+		{"demo team", "demo taem", 2},
+		{"füchse", "fuchse", 1},
+		{"", "abc", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, erwartet %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestFuzzyTeamMatch(t *testing.T) {
+	tests := []struct {
+		name            string
+		env             map[string]string
+		input           string
+		want            string
+		wantSuggestions []string
+	}{
+		{
+			name:  "ein naher Treffer wird übernommen",
+			env:   map[string]string{"FUZZY_MATCH_DISTANCE": "2"},
+			input: "Demo Taem",
+			want:  "Demo Team",
+		},
+		{
+			name:            "ohne Auto-Accept nur Vorschlag",
+			env:             map[string]string{"FUZZY_MATCH_DISTANCE": "2", "FUZZY_AUTO_ACCEPT": "false"},
+			input:           "Demo Taem",
+			wantSuggestions: []string{"Demo Team"},
+		},
+		{
+			name:            "mehrere nahe Treffer",
+			env:             map[string]string{"FUZZY_MATCH_DISTANCE": "1"},
+			input:           "Team Rat",
+			wantSuggestions: []string{"Team Rad", "Team Rot"},
+		},
+		{
+			name:  "zu weit entfernt",
+			env:   map[string]string{"FUZZY_MATCH_DISTANCE": "1"},
+			input: "Demo Taem",
+		},
+		{
+			name:  "Fuzzy-Suche aus",
+			env:   map[string]string{"FUZZY_MATCH_DISTANCE": "0"},
+			input: "Demo Tem",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, tt.env)
+			addDemoTeam(ta.store, "Team Rot")
+			addDemoTeam(ta.store, "Team Rad")
+
+			page, err := ta.findTeam(t.Context(), tt.input)
+			var suggestions *teamSuggestionsError
+			if errors.As(err, &suggestions) {
+				if !slices.Equal(suggestions.Suggestions, tt.wantSuggestions) {
+					t.Errorf("Vorschläge = %v, erwartet %v", suggestions.Suggestions, tt.wantSuggestions)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			} else if tt.wantSuggestions != nil {
+				t.Errorf("keine Vorschläge, erwartet %v", tt.wantSuggestions)
+			}
+
+			got := ""
+			if page != nil {
+				got = pageTitle(*page)
+			}
+			if got != tt.want {
+				t.Errorf("Team = %q, erwartet %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFuzzySuggestionsOnForm(t *testing.T) {
+	ta := newTestApp(t, map[string]string{"FUZZY_MATCH_DISTANCE": "1"})
+	addDemoTeam(ta.store, "Team Rot")
+	addDemoTeam(ta.store, "Team Rad")
+
+	body := ta.advance("1", "Team Rat").Body.String()
+	for _, name := range []string{"Team Rot", "Team Rad"} {
+		if !strings.Contains(body, name) {
+			t.Errorf("Vorschlag %q fehlt:\n%s", name, body)
+		}
+	}
+	for _, id := range []string{demoTeamPageID, foxesTeamPageID} {
+		if len(ta.completedChallenges(ta.teamPage(t, id))) > 0 {
+			t.Errorf("Abschluss für %s gespeichert", id)
+		}
+	}
+}
+
+func TestTeamSearchMatchMode(t *testing.T) {
+	tests := []struct {
+		mode  string
+		query string
+		want  []string
+	}{
+		{mode: "prefix", query: "river", want: []string{"Riverside"}},
+		{mode: "substring", query: "river", want: []string{"Riverside", "The River Rats"}},
+		{mode: "prefix", query: "RATS", want: []string{}},
+		{mode: "substring", query: "RATS", want: []string{"The River Rats"}},
+		{mode: "prefix", query: "de", want: []string{"Demo Team"}},
+		{mode: "substring", query: "de", want: []string{"Demo Team", "Riverside"}},
+		{mode: "substring", query: "river  rats", want: []string{"The River Rats"}},
+		{mode: "", query: "the", want: []string{"The River Rats"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode+" "+tt.query, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"MATCH_MODE": tt.mode})
+			addDemoTeam(ta.store, "The River Rats")
+			addDemoTeam(ta.store, "Riverside")
+
+			w := ta.do(http.MethodGet, "/api/teams/search?q="+url.QueryEscape(tt.query), nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+			var body struct {
+				Mode    string   `json:"mode"`
+				Results []string `json:"results"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			slices.Sort(body.Results)
+			if !slices.Equal(body.Results, tt.want) {
+				t.Errorf("Treffer = %v, erwartet %v", body.Results, tt.want)
+			}
+			if want := cmp.Or(tt.mode, matchModePrefix); body.Mode != want {
+				t.Errorf("mode = %q, erwartet %q", body.Mode, want)
+			}
+		})
+	}
+}
+
+func TestTeamSearchConsistentWithFuzzy(t *testing.T) {
+	// Die Vorschläge beim Absenden folgen denselben Treffern wie die Autovervollständigung;
+	// nur die Fuzzy-Stufe, damit Aliase wie "Demo" nicht vorher greifen
+	for _, mode := range []string{matchModePrefix, matchModeSubstring} {
+		for _, query := range []string{"river", "rats", "demo"} {
+			t.Run(mode+" "+query, func(t *testing.T) {
+				ta := newTestApp(t, map[string]string{
+					"MATCH_MODE":           mode,
+					"FUZZY_MATCH_DISTANCE": "1",
+					"FUZZY_AUTO_ACCEPT":    "false",
+					"TEAM_MATCH_PIPELINE":  "fuzzy",
+				})
+				addDemoTeam(ta.store, "The River Rats")
+				addDemoTeam(ta.store, "Riverside")
+
+				names, err := ta.getAllTeamNames(t.Context())
+				if err != nil {
+					t.Fatal(err)
+				}
+				want, _ := searchTeamNames(names, query, mode, maxSearchResults)
+				slices.Sort(want)
+
+				got := []string{}
+				_, err = ta.findTeam(t.Context(), query)
+				var suggestions *teamSuggestionsError
+				if errors.As(err, &suggestions) {
+					got = suggestions.Suggestions
+				} else if err != nil {
+					t.Fatal(err)
+				}
+				slices.Sort(got)
+				if !slices.Equal(got, want) {
+					t.Errorf("Vorschläge = %v, Autovervollständigung = %v", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestParseMatchMode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "", want: matchModePrefix},
+		{in: "prefix", want: matchModePrefix},
+		{in: "Substring", want: matchModeSubstring},
+		{in: "fuzzy", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseMatchMode(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("parseMatchMode(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestParseTeamLookupRetry(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "", want: 0},
+		{in: "0", want: 0},
+		{in: "500ms", want: 500 * time.Millisecond},
+		{in: "5s", want: maxTeamLookupRetry},
+		{in: "6s", wantErr: true},
+		{in: "-1s", wantErr: true},
+		{in: "kurz", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseTeamLookupRetry(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTeamLookupRetry(%q): Fehler = %v, erwartet Fehler = %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseTeamLookupRetry(%q) = %v, erwartet %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// lateTeamNotion verhält sich wie Notion kurz nach einer Registrierung: beim ersten
+// Suchdurchgang liefert die Team-DB nichts. Jeder Durchgang von resolveTeam beginnt mit
+// der Abfrage nach dem Join-Code.
+type lateTeamNotion struct {
+	notionService
+	lookups int
+}
+
+func (n *lateTeamNotion) QueryDatabase(ctx context.Context, dbID string, req *notionapi.DatabaseQueryRequest) (*notionapi.DatabaseQueryResponse, error) {
+	if dbID == demoTeamsDBID {
+		if filter, ok := req.Filter.(*notionapi.PropertyFilter); ok && filter.Property == teamCodeProperty {
+			n.lookups++
+		}
+		if n.lookups <= 1 {
+			return &notionapi.DatabaseQueryResponse{}, nil
+		}
+	}
+	return n.notionService.QueryDatabase(ctx, dbID, req)
+}
+
+func TestTeamLookupRetry(t *testing.T) {
+	tests := []struct {
+		name        string
+		retry       string
+		wantFound   bool
+		wantLookups int
+	}{
+		{name: "ohne TEAM_LOOKUP_RETRY", retry: "", wantLookups: 1},
+		{name: "zweiter Versuch findet das Team", retry: "10ms", wantFound: true, wantLookups: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"TEAM_LOOKUP_RETRY": tt.retry})
+			late := &lateTeamNotion{notionService: ta.store}
+			ta.notion = late
+
+			w := ta.advance("1", "Demo Team")
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+			if found := !strings.Contains(w.Body.String(), "Team nicht gefunden"); found != tt.wantFound {
+				t.Errorf("Team gefunden = %v, erwartet %v:\n%s", found, tt.wantFound, w.Body.String())
+			}
+			if late.lookups != tt.wantLookups {
+				t.Errorf("Suchdurchgänge = %d, erwartet %d", late.lookups, tt.wantLookups)
+			}
+			_, done := ta.completedChallenges(ta.teamPage(t, demoTeamPageID))[1]
+			if done != tt.wantFound {
+				t.Errorf("Challenge 1 abgeschlossen = %v, erwartet %v", done, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestParseTeamInputMode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "", want: teamInputDropdown},
+		{in: "dropdown", want: teamInputDropdown},
+		{in: "search", want: teamInputSearch},
+		{in: "Search", wantErr: true},
+		{in: "liste", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseTeamInputMode(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseTeamInputMode(%q) = %q, %v; erwartet %q, Fehler = %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestTeamInputMode(t *testing.T) {
+	tests := []struct {
+		mode            string
+		wantTeamsInHTML bool
+		wantShortQuery  int // Status für /api/teams/search?q=De
+	}{
+		{mode: "dropdown", wantTeamsInHTML: true, wantShortQuery: http.StatusOK},
+		{mode: "search", wantTeamsInHTML: false, wantShortQuery: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"TEAM_INPUT_MODE": tt.mode})
+
+			w := ta.do(http.MethodGet, "/next/1", nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+			body := w.Body.String()
+			for _, team := range []string{"Demo Team", "Die Füchse"} {
+				if got := strings.Contains(body, team); got != tt.wantTeamsInHTML {
+					t.Errorf("%q im Formular = %v, erwartet %v", team, got, tt.wantTeamsInHTML)
+				}
+			}
+			if !tt.wantTeamsInHTML && !strings.Contains(body, "/api/teams/search") {
+				t.Errorf("Formular ohne Anbindung an /api/teams/search:\n%s", body)
+			}
+
+			if w := ta.do(http.MethodGet, "/api/teams/search?q=De", nil); w.Code != tt.wantShortQuery {
+				t.Errorf("kurzer Suchbegriff: Status = %d, erwartet %d", w.Code, tt.wantShortQuery)
+			}
+			w = ta.do(http.MethodGet, "/api/teams/search?q=Demo", nil)
+			if results, _ := decodeJSON(t, w)["results"].([]any); !slices.Equal(results, []any{"Demo Team"}) {
+				t.Errorf("Suche nach Demo = %v", results)
+			}
+		})
+	}
+}
+
+func TestNormalizeTeamName(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{a: "Élan", b: "E\u0301lan", same: true},                      // vorkomponiert und zerlegt
+		{a: "ÉLAN", b: "élan", same: true},                            // Groß-/Kleinschreibung außerhalb von ASCII
+		{a: "ΟΔΟΣ", b: "οδος", same: true},                            // Schluss-Sigma
+		{a: "🚀 Rockets", b: "🚀\uFE0F  rockets", same: true},           // Varianten-Selektor, doppeltes Leerzeichen
+		{a: "Null\u200BBreite", b: "NullBreite", same: true},          // Zero-Width-Space
+		{a: "👨\u200D👩\u200D👧 Familie", b: "👨👩👧 Familie", same: false}, // Zero-Width-Joiner bleibt
+		{a: "Élan", b: "Elan", same: false},
+		{a: "🚀 Rockets", b: "Rockets", same: false},
+	}
+	for _, tt := range tests {
+		if got := normalizeTeamName(tt.a) == normalizeTeamName(tt.b); got != tt.same {
+			t.Errorf("normalizeTeamName(%q) == normalizeTeamName(%q): %v, erwartet %v", tt.a, tt.b, got, tt.same)
+		}
+	}
+}
+
+func TestTrimTeamName(t *testing.T) {
+	tests := map[string]string{
+		"  Die Füchse ":                 "Die Füchse",
+		"\u00A0🚀 Rockets\u200B":         "🚀 Rockets",
+		"\uFEFF\u3000Élan\t":            "Élan",
+		"Zwei  Leerzeichen\u00A0innen ": "Zwei  Leerzeichen\u00A0innen",
+	}
+	for in, want := range tests {
+		if got := trimTeamName(in); got != want {
+			t.Errorf("trimTeamName(%q) = %q, erwartet %q", in, got, want)
+		}
+	}
+}
+
+func TestSortTeamNames(t *testing.T) {
+	names := []string{"Zebra", "Élan", "eule", "Eule", "Ärmel", "Adler", "Emil"}
+	sortTeamNames(names)
+	want := []string{"Adler", "Ärmel", "Élan", "Emil", "Eule", "eule", "Zebra"}
+	if !slices.Equal(names, want) {
+		t.Errorf("sortTeamNames = %q, erwartet %q", names, want)
+	}
+}
+
+func TestUnicodeTeamLookup(t *testing.T) {
+	ta := newTestApp(t, map[string]string{"TEAM_MATCH_PIPELINE": "exact,trimmed,casefold"})
+	rockets := addDemoTeam(ta.store, "🚀 Rockets")
+	elan := addDemoTeam(ta.store, "Élan")
+
+	tests := []struct {
+		input string
+		want  string // Page-ID ("": kein Treffer)
+	}{
+		{input: "🚀 Rockets", want: rockets},
+		{input: "\u00A0🚀\uFE0F rockets\u200B", want: rockets},
+		{input: "E\u0301LAN", want: elan},
+		{input: " élan ", want: elan},
+		{input: "Rockets"},
+		{input: "Elan"},
+	}
+	for _, tt := range tests {
+		got, err := ta.findTeamPage(t.Context(), tt.input)
+		if err != nil {
+			t.Fatalf("findTeamPage(%q): %v", tt.input, err)
+		}
+		if got != tt.want {
+			t.Errorf("findTeamPage(%q) = %q, erwartet %q", tt.input, got, tt.want)
+		}
+	}
+
+	names, err := ta.getAllTeamNames(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i := slices.Index(names, "Élan"); i < 0 || i > slices.Index(names, "Die Füchse")+1 {
+		t.Errorf("Teamliste = %q, erwartet Élan direkt hinter Die Füchse", names)
+	}
+}
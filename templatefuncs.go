@@ -0,0 +1,45 @@
+package main
+
+import (
+	"html/template"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// templateFuncs stehen in allen Templates zur Verfügung:
+//
+//	formatTime  .Zeit "15:04"   formatiert einen Zeitpunkt (Nullwert ergibt "")
+//	upper       .Text           Großbuchstaben
+//	lower       .Text           Kleinbuchstaben
+//	safeURL     .URL            markiert http(s)- und relative URLs als sicher für href/src;
+//	                            andere Schemata (z.B. javascript:) ergeben "#"
+//	t           .lang "key" …   übersetzter Text (siehe translations), optional mit Argumenten
+var templateFuncs = template.FuncMap{
+	"formatTime": formatTime,
+	"upper":      strings.ToUpper,
+	"lower":      strings.ToLower,
+	"safeURL":    safeURL,
+	"t":          translate,
+}
+
+// formatTime formatiert t mit layout; ein nicht gesetzter Zeitpunkt bleibt leer
+func formatTime(t time.Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(layout)
+}
+
+// safeURL lässt nur http(s)- und relative URLs ungefiltert ins Template
+func safeURL(s string) template.URL {
+	u, err := url.Parse(s)
+	if err != nil {
+		return "#"
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https":
+		return template.URL(s)
+	}
+	return "#"
+}
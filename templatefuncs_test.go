@@ -0,0 +1,46 @@
+package main
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	at := time.Date(2026, 6, 1, 14, 5, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		tmpl string
+		data any
+		want string
+	}{
+		{name: "formatTime", tmpl: `{{formatTime . "15:04"}}`, data: at, want: "14:05"},
+		{name: "formatTime ohne Zeit", tmpl: `[{{formatTime . "15:04"}}]`, data: time.Time{}, want: "[]"},
+		{name: "upper", tmpl: `{{upper .}}`, data: "Die Füchse", want: "DIE FÜCHSE"},
+		{name: "lower", tmpl: `{{lower .}}`, data: "Demo TEAM", want: "demo team"},
+		{name: "safeURL https", tmpl: `<a href="{{safeURL .}}">`, data: "https://example.org/next/1?x=1", want: `<a href="https://example.org/next/1?x=1">`},
+		{name: "safeURL relativ", tmpl: `<a href="{{safeURL .}}">`, data: "/leaderboard", want: `<a href="/leaderboard">`},
+		{name: "safeURL javascript", tmpl: `<a href="{{safeURL .}}">`, data: "javascript:alert(1)", want: `<a href="#">`},
+		{name: "safeURL Großschreibung", tmpl: `<a href="{{safeURL .}}">`, data: "JavaScript:alert(1)", want: `<a href="#">`},
+		{name: "t deutsch", tmpl: `{{t . "finished.points"}}`, data: "de", want: "Punkte"},
+		{name: "t unbekannte Sprache", tmpl: `{{t . "finished.points"}}`, data: "xx", want: "points"},
+		{name: "t unbekannter Schlüssel", tmpl: `{{t . "gibt.es.nicht"}}`, data: "de", want: "gibt.es.nicht"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := template.New("test").Funcs(templateFuncs).Parse(tt.tmpl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out strings.Builder
+			if err := tmpl.Execute(&out, tt.data); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("Ausgabe = %q, erwartet %q", out.String(), tt.want)
+			}
+		})
+	}
+}
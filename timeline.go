@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jomei/notionapi"
+)
+
+// timelineEntry ist ein Schritt im Verlauf eines Teams
+type timelineEntry struct {
+	ChallengeID int        `json:"challengeID"`
+	Title       string     `json:"title"`
+	Position    int        `json:"position,omitempty"`
+	CompletedAt *time.Time `json:"completedAt"`
+	Skipped     bool       `json:"skipped,omitempty"`
+}
+
+// handleAPITeamTimeline liefert die abgeschlossenen Challenges eines Teams in zeitlicher Reihenfolge
+func (app *App) handleAPITeamTimeline(c *gin.Context) {
+	ctx := c.Request.Context()
+	teamName := c.Param("team")
+
+	teamPageID, err := app.findTeamPage(ctx, teamName)
+	if err != nil || teamPageID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team nicht gefunden"})
+		return
+	}
+
+	timeline, err := app.getTeamTimeline(ctx, teamPageID)
+	if err != nil {
+		errorf("Fehler beim Erstellen der Timeline: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fehler beim Abrufen der Team-Daten"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"team":     teamName,
+		"timeline": timeline,
+	})
+}
+
+// getTeamTimeline baut den Verlauf aus den Abschluss-Markern der Team-Page.
+// Challenges der Route, die vor der letzten abgeschlossenen liegen, aber keinen
+// Zeitstempel haben, werden ohne completedAt anhand ihrer Position eingeordnet.
+func (app *App) getTeamTimeline(ctx context.Context, teamPageID string) ([]timelineEntry, error) {
+	page, err := app.getPage(ctx, teamPageID)
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Laden der Team-Page: %w", err)
+	}
+
+	challenges, err := app.getAllChallenges(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]*Challenge, len(challenges))
+	byPageID := make(map[string]*Challenge, len(challenges))
+	for _, challenge := range challenges {
+		byID[challenge.ID] = challenge
+		byPageID[normalizePageID(challenge.PageID)] = challenge
+	}
+
+	positions := make(map[int]int)
+	route := routeChallenges(*page, byPageID)
+	for i, challenge := range route {
+		positions[challenge.ID] = i + 1
+	}
+
+	completed := app.completedChallenges(page)
+	var timed []timelineEntry
+	lastPosition := 0
+	for id, at := range completed {
+		entry := timelineEntry{ChallengeID: id, Position: positions[id], CompletedAt: &at, Skipped: isSkipped(page, id)}
+		if challenge, ok := byID[id]; ok {
+			entry.Title = challenge.Title
+		}
+		timed = append(timed, entry)
+		lastPosition = max(lastPosition, entry.Position)
+	}
+	sort.Slice(timed, func(i, j int) bool {
+		a, b := timed[i], timed[j]
+		if !a.CompletedAt.Equal(*b.CompletedAt) {
+			return a.CompletedAt.Before(*b.CompletedAt)
+		}
+		// Gleiche Zeitstempel (z.B. reine Datumswerte) nach Position
+		if a.Position != b.Position {
+			return a.Position < b.Position
+		}
+		return a.ChallengeID < b.ChallengeID
+	})
+
+	// Ohne Zeitstempel: vor den ersten Eintrag mit höherer Position einsortieren
+	timeline := make([]timelineEntry, 0, len(timed))
+	next := 0
+	for i, challenge := range route {
+		position := i + 1
+		if position >= lastPosition {
+			break
+		}
+		if _, done := completed[challenge.ID]; done {
+			continue
+		}
+		for next < len(timed) && (timed[next].Position == 0 || timed[next].Position < position) {
+			timeline = append(timeline, timed[next])
+			next++
+		}
+		timeline = append(timeline, timelineEntry{ChallengeID: challenge.ID, Title: challenge.Title, Position: position})
+	}
+	timeline = append(timeline, timed[next:]...)
+
+	return timeline, nil
+}
+
+// isSkipped prüft, ob auf der Team-Page ein Skipped-Marker für die Challenge gesetzt ist
+func isSkipped(page *notionapi.Page, num int) bool {
+	p, ok := page.Properties[fmt.Sprintf(skippedPropertyFormat, num)].(*notionapi.RichTextProperty)
+	return ok && len(p.RichText) > 0
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestAPITeamTimeline(t *testing.T) {
+	base := time.Date(2026, 5, 9, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		completions map[int]time.Duration
+		// want listet die Einträge als "<id>" (mit Zeitstempel) oder "<id>?" (ohne)
+		want []string
+	}{
+		{name: "nichts abgeschlossen", want: []string{}},
+		{
+			name:        "vollständige Zeitstempel nach Zeit",
+			completions: map[int]time.Duration{1: 0, 3: 10 * time.Minute, 2: 25 * time.Minute},
+			want:        []string{"1", "3", "2"},
+		},
+		{
+			name:        "gleiche Zeitstempel nach Position",
+			completions: map[int]time.Duration{3: 0, 2: 0, 1: 0},
+			want:        []string{"1", "2", "3"},
+		},
+		{
+			name:        "fehlender Zeitstempel nach Position",
+			completions: map[int]time.Duration{1: 0, 3: 20 * time.Minute},
+			want:        []string{"1", "2?", "3"},
+		},
+		{
+			name:        "mehrere Lücken",
+			completions: map[int]time.Duration{4: 0},
+			want:        []string{"1?", "2?", "3?", "4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			props := notionapi.Properties{}
+			for num, offset := range tt.completions {
+				at := notionapi.Date(base.Add(offset))
+				props[ta.completionProperty(num)] = notionapi.DateProperty{Date: &notionapi.DateObject{Start: &at}}
+			}
+			if _, err := ta.store.UpdatePage(t.Context(), demoTeamPageID, &notionapi.PageUpdateRequest{Properties: props}); err != nil {
+				t.Fatal(err)
+			}
+
+			w := ta.do(http.MethodGet, "/api/teams/Demo%20Team/timeline", nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+			}
+			body := decodeJSON(t, w)
+			if body["team"] != "Demo Team" {
+				t.Errorf("team = %v", body["team"])
+			}
+
+			got := []string{}
+			for _, entry := range body["timeline"].([]any) {
+				entry := entry.(map[string]any)
+				id := fmt.Sprint(entry["challengeID"])
+				if entry["title"] == "" {
+					t.Errorf("Challenge %s ohne Titel", id)
+				}
+				if entry["completedAt"] == nil {
+					id += "?"
+				}
+				got = append(got, id)
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("Timeline = %v, erwartet %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPITeamTimelineUnknownTeam(t *testing.T) {
+	ta := newTestApp(t, nil)
+	if w := ta.do(http.MethodGet, "/api/teams/Niemand/timeline", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Status = %d, erwartet 404", w.Code)
+	}
+}
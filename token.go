@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jomei/notionapi"
+)
+
+// notionClientAttempts schaltet die eingebaute Wiederholung von notionapi ab: WithRetry
+// zählt Versuche einschließlich des ersten, 1 heißt also kein zweiter Versuch (0 hieße
+// unbegrenzt). Wiederholt wird allein in rateLimitTransport.
+const notionClientAttempts = 1
+
+// newNotionClient baut den Notion-Client für ein Integration-Token. 429er wiederholt
+// rateLimitTransport anhand von Retry-After, die eingebaute Wiederholung des Clients bleibt daher aus.
+func newNotionClient(token string) notionClient {
+	httpClient := newNotionHTTPClient()
+	return notionClient{
+		client: notionapi.NewClient(notionapi.Token(token),
+			notionapi.WithHTTPClient(httpClient),
+			notionapi.WithRetry(notionClientAttempts),
+		),
+		http: httpClient,
+	}
+}
+
+// swappableNotion reicht alle Aufrufe an den aktuellen notionService weiter, der
+// zur Laufzeit ausgetauscht werden kann (Token-Rotation ohne Neustart)
+type swappableNotion struct {
+	mu      sync.RWMutex
+	current notionService
+}
+
+func newSwappableNotion(service notionService) *swappableNotion {
+	return &swappableNotion{current: service}
+}
+
+func (s *swappableNotion) service() notionService {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.current
+}
+
+func (s *swappableNotion) swap(service notionService) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.current = service
+}
+
+func (s *swappableNotion) QueryDatabase(ctx context.Context, dbID string, req *notionapi.DatabaseQueryRequest) (*notionapi.DatabaseQueryResponse, error) {
+	return s.service().QueryDatabase(ctx, dbID, req)
+}
+
+func (s *swappableNotion) GetPage(ctx context.Context, pageID string) (*notionapi.Page, error) {
+	return s.service().GetPage(ctx, pageID)
+}
+
+func (s *swappableNotion) UpdatePage(ctx context.Context, pageID string, req *notionapi.PageUpdateRequest) (*notionapi.Page, error) {
+	return s.service().UpdatePage(ctx, pageID, req)
+}
+
+func (s *swappableNotion) CreatePage(ctx context.Context, req *notionapi.PageCreateRequest) (*notionapi.Page, error) {
+	return s.service().CreatePage(ctx, req)
+}
+
+func (s *swappableNotion) GetBlockChildren(ctx context.Context, blockID string, pagination *notionapi.Pagination) (*notionapi.GetChildrenResponse, error) {
+	return s.service().GetBlockChildren(ctx, blockID, pagination)
+}
+
+func (s *swappableNotion) GetDatabase(ctx context.Context, dbID string) (*notionapi.Database, error) {
+	return s.service().GetDatabase(ctx, dbID)
+}
+
+func (s *swappableNotion) GetPropertyItems(ctx context.Context, pageID, propertyID, cursor string) (*propertyItemList, error) {
+	return s.service().GetPropertyItems(ctx, pageID, propertyID, cursor)
+}
+
+// handleAdminRotateToken tauscht das Notion-Token zur Laufzeit aus. Das neue Token
+// kommt im Body ({"token": "..."}) und muss die Team-DB lesen können, sonst bleibt das alte aktiv.
+func (app *App) handleAdminRotateToken(c *gin.Context) {
+	if app.notionSwap == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Token-Rotation ist im DEMO_MODE nicht verfügbar"})
+		return
+	}
+
+	var body struct {
+		Token string `json:"token" form:"token"`
+	}
+	if err := c.ShouldBind(&body); err != nil || strings.TrimSpace(body.Token) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token fehlt"})
+		return
+	}
+
+	// Testabfrage mit dem neuen Client, bevor er die laufenden Requests bedient
+	candidate := newNotionClient(strings.TrimSpace(body.Token))
+	if err := app.checkNotionAccess(c.Request.Context(), candidate); err != nil {
+		warnf("Neues Notion-Token abgelehnt: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token ungültig oder ohne Zugriff auf Team- und Challenge-Datenbanken"})
+		return
+	}
+
+	app.notionSwap.swap(candidate)
+	infof("Notion-Token rotiert")
+	c.JSON(http.StatusOK, gin.H{"rotated": true})
+}
+
+// checkNotionAccess prüft, ob service die Team-Datenbank und jede Challenge-DB lesen kann.
+// Der Fehler nennt die erste Datenbank ohne Zugriff.
+func (app *App) checkNotionAccess(ctx context.Context, service notionService) error {
+	if _, err := service.QueryDatabase(ctx, app.teamsDBID, &notionapi.DatabaseQueryRequest{PageSize: 1}); err != nil {
+		return fmt.Errorf("die Team-Datenbank: %w", err)
+	}
+	for _, dbID := range app.challengeDBIDs {
+		if _, err := service.QueryDatabase(ctx, dbID, &notionapi.DatabaseQueryRequest{PageSize: 1}); err != nil {
+			return fmt.Errorf("die Challenge-DB %s: %w", dbID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestCheckNotionAccess(t *testing.T) {
+	tests := []struct {
+		name     string
+		deniedDB string // Datenbank, für die das Token keinen Zugriff hat
+		wantErr  string
+	}{
+		{name: "alle Datenbanken lesbar"},
+		{name: "Team-DB gesperrt", deniedDB: demoTeamsDBID, wantErr: "die Team-Datenbank"},
+		{name: "zweite Challenge-DB gesperrt", deniedDB: "challenges-b", wantErr: "die Challenge-DB challenges-b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, nil)
+			ta.challengeDBIDs = []string{demoChallengesDBID, "challenges-b"}
+			var queried []string
+			stub := &stubNotion{notionService: ta.store, query: func(_ context.Context, dbID string, req *notionapi.DatabaseQueryRequest) (*notionapi.DatabaseQueryResponse, error) {
+				queried = append(queried, dbID)
+				if req.PageSize != 1 {
+					t.Errorf("Testabfrage auf %s mit PageSize %d", dbID, req.PageSize)
+				}
+				if dbID == tt.deniedDB {
+					return nil, notionError(http.StatusNotFound, "object_not_found", "Could not find database")
+				}
+				return &notionapi.DatabaseQueryResponse{}, nil
+			}}
+
+			err := ta.checkNotionAccess(t.Context(), stub)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("checkNotionAccess: %v", err)
+				}
+				if len(queried) != 3 {
+					t.Errorf("geprüfte Datenbanken = %v", queried)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Fehler = %v, erwartet %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// fakeNotionAPI beantwortet Datenbankabfragen wie die Notion-API: gültig ist nur token,
+// auf deniedDB hat auch token keinen Zugriff
+type fakeNotionAPI struct {
+	token    string
+	deniedDB string
+}
+
+func (f *fakeNotionAPI) RoundTrip(req *http.Request) (*http.Response, error) {
+	status, body := http.StatusOK, `{"object":"list","results":[],"has_more":false}`
+	switch {
+	case req.Header.Get("Authorization") != "Bearer "+f.token:
+		status, body = http.StatusUnauthorized, `{"object":"error","status":401,"code":"unauthorized","message":"API token is invalid."}`
+	case f.deniedDB != "" && strings.Contains(req.URL.Path, "/databases/"+f.deniedDB+"/"):
+		status, body = http.StatusNotFound, `{"object":"error","status":404,"code":"object_not_found","message":"Could not find database."}`
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestAdminRotateToken(t *testing.T) {
+	tests := []struct {
+		name        string
+		demo        bool // ohne austauschbaren Client wie im DEMO_MODE
+		body        string
+		deniedDB    string
+		wantStatus  int
+		wantRotated bool
+	}{
+		{name: "gültiges Token", body: `{"token": " neu "}`, wantStatus: http.StatusOK, wantRotated: true},
+		{name: "ungültiges Token", body: `{"token": "falsch"}`, wantStatus: http.StatusBadRequest},
+		{name: "ohne Zugriff auf eine Challenge-DB", body: `{"token": "neu"}`, deniedDB: demoChallengesDBID, wantStatus: http.StatusBadRequest},
+		{name: "Token fehlt", body: `{"token": "  "}`, wantStatus: http.StatusBadRequest},
+		{name: "kein JSON", body: `token`, wantStatus: http.StatusBadRequest},
+		{name: "DEMO_MODE", demo: true, body: `{"token": "neu"}`, wantStatus: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defaultTransport := http.DefaultTransport
+			http.DefaultTransport = &fakeNotionAPI{token: "neu", deniedDB: tt.deniedDB}
+			t.Cleanup(func() { http.DefaultTransport = defaultTransport })
+
+			ta := newTestApp(t, nil)
+			ta.challengeDBIDs = []string{demoChallengesDBID}
+			if !tt.demo {
+				ta.notionSwap = newSwappableNotion(ta.store)
+				ta.notion = ta.notionSwap
+			}
+
+			w := ta.admin(http.MethodPost, "/admin/rotate-token", tt.body)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, erwartet %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.demo {
+				return
+			}
+			_, rotated := ta.notionSwap.service().(notionClient)
+			if rotated != tt.wantRotated {
+				t.Errorf("Client ausgetauscht = %v, erwartet %v", rotated, tt.wantRotated)
+			}
+			// Der neue Client bedient die laufenden Requests, der alte bleibt bei einer Ablehnung
+			_, err := ta.notion.QueryDatabase(t.Context(), demoTeamsDBID, &notionapi.DatabaseQueryRequest{})
+			if err != nil {
+				t.Errorf("Abfrage nach der Rotation: %v", err)
+			}
+		})
+	}
+}
+
+func TestAdminRotateTokenRequiresToken(t *testing.T) {
+	ta := newTestApp(t, nil)
+	ta.notionSwap = newSwappableNotion(ta.store)
+
+	w := ta.do(http.MethodPost, "/admin/rotate-token", nil)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("Status = %d, erwartet %d", w.Code, http.StatusUnauthorized)
+	}
+	if _, ok := ta.notionSwap.service().(*demoStore); !ok {
+		t.Error("Client ohne ADMIN_TOKEN ausgetauscht")
+	}
+}
+
+func TestSwappableNotion(t *testing.T) {
+	first := &stubNotion{query: func(context.Context, string, *notionapi.DatabaseQueryRequest) (*notionapi.DatabaseQueryResponse, error) {
+		return nil, errors.New("altes Token")
+	}}
+	second := &stubNotion{query: func(context.Context, string, *notionapi.DatabaseQueryRequest) (*notionapi.DatabaseQueryResponse, error) {
+		return &notionapi.DatabaseQueryResponse{}, nil
+	}}
+
+	swappable := newSwappableNotion(first)
+	if _, err := swappable.QueryDatabase(t.Context(), "db", nil); err == nil {
+		t.Fatal("Abfrage ging nicht an den ersten Client")
+	}
+	swappable.swap(second)
+	if _, err := swappable.QueryDatabase(t.Context(), "db", nil); err != nil {
+		t.Fatalf("Abfrage nach swap: %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName ist der Instrumentierungsname aller Spans dieser Anwendung
+const tracerName = "treasure-hunt"
+
+// tracer liefert Spans über den globalen TracerProvider.
+// Ohne setupTracing ist das der No-op-Provider von OpenTelemetry.
+var tracer = otel.Tracer(tracerName)
+
+// setupTracing aktiviert den OTLP-Export, wenn OTEL_EXPORTER_OTLP_ENDPOINT gesetzt ist.
+// Die restliche Exporter-Konfiguration (Header, Protokoll, ...) liest das SDK selbst aus den OTEL_* Variablen.
+// Die zurückgegebene Funktion schreibt offene Spans beim Beenden weg.
+func setupTracing(ctx context.Context) (func(context.Context) error, error) {
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Anlegen des OTLP-Exporters: %w", err)
+	}
+
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = tracerName
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", serviceName),
+			attribute.String("service.version", version),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+// tracingMiddleware startet pro HTTP-Request einen Span. Ein eingehender
+// traceparent-Header wird übernommen, damit sich der Trace über Dienste hinweg fortsetzt.
+func tracingMiddleware(c *gin.Context) {
+	ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+	route := c.FullPath()
+	if route == "" {
+		route = "unbekannt"
+	}
+
+	ctx, span := tracer.Start(ctx, c.Request.Method+" "+route,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("http.request.method", c.Request.Method),
+			attribute.String("http.route", route),
+			attribute.String("url.path", c.Request.URL.Path),
+		),
+	)
+	defer span.End()
+
+	c.Request = c.Request.WithContext(ctx)
+	c.Next()
+
+	status := c.Writer.Status()
+	span.SetAttributes(attribute.Int("http.response.status_code", status))
+	if status >= 500 {
+		span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", status))
+	}
+}
+
+// startNotionSpan startet einen Kind-Span für einen Notion-Aufruf
+func startNotionSpan(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attribute.String("notion.operation", operation))
+	return tracer.Start(ctx, "notion."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// endSpan beendet einen Span und markiert ihn bei einem Fehler als fehlgeschlagen
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// traceAttributes ergänzt den aktuellen Request-Span, z.B. um Team und Challenge
+func traceAttributes(ctx context.Context, attrs ...attribute.KeyValue) {
+	trace.SpanFromContext(ctx).SetAttributes(attrs...)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans leitet tracer für die Dauer des Tests in einen In-Memory-Exporter um
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := tracer
+	tracer = provider.Tracer(tracerName)
+	t.Cleanup(func() {
+		tracer = previous
+		provider.Shutdown(t.Context())
+	})
+	return exporter
+}
+
+// spanAttribute liefert den Wert eines Span-Attributs als String ("" wenn nicht gesetzt)
+func spanAttribute(span tracetest.SpanStub, key attribute.Key) string {
+	for _, attr := range span.Attributes {
+		if attr.Key == key {
+			return attr.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func TestTracingSpans(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		form          url.Values
+		wantSpan      string
+		wantStatus    string
+		wantAttrs     map[attribute.Key]string
+		wantNotionOps []string // Notion-Operationen, die als Kind-Spans auftauchen müssen
+	}{
+		{
+			name:          "Teamformular abgeschickt",
+			method:        http.MethodPost,
+			path:          "/next/1",
+			form:          url.Values{"team": {"Demo Team"}},
+			wantSpan:      "POST /next/:id",
+			wantStatus:    "200",
+			wantAttrs:     map[attribute.Key]string{"team": "Demo Team", "challenge.id": "1", "http.route": "/next/:id"},
+			wantNotionOps: []string{"query", "page.get", "page.update"},
+		},
+		{
+			name:       "unbekannte Route",
+			method:     http.MethodGet,
+			path:       "/gibt-es-nicht",
+			wantSpan:   "GET unbekannt",
+			wantStatus: "404",
+			wantAttrs:  map[attribute.Key]string{"url.path": "/gibt-es-nicht"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := recordSpans(t)
+			ta := newTestApp(t, nil)
+
+			ta.do(tt.method, tt.path, tt.form)
+
+			spans := exporter.GetSpans()
+			var server *tracetest.SpanStub
+			for i := range spans {
+				if spans[i].SpanKind == trace.SpanKindServer {
+					server = &spans[i]
+				}
+			}
+			if server == nil {
+				t.Fatalf("kein Server-Span unter %d Spans", len(spans))
+			}
+			if server.Name != tt.wantSpan {
+				t.Errorf("Span-Name = %q, erwartet %q", server.Name, tt.wantSpan)
+			}
+			if got := spanAttribute(*server, "http.response.status_code"); got != tt.wantStatus {
+				t.Errorf("Status-Attribut = %q, erwartet %q", got, tt.wantStatus)
+			}
+			for key, want := range tt.wantAttrs {
+				if got := spanAttribute(*server, key); got != want {
+					t.Errorf("Attribut %s = %q, erwartet %q", key, got, want)
+				}
+			}
+
+			ops := make(map[string]bool)
+			for _, span := range spans {
+				if span.SpanKind != trace.SpanKindClient {
+					continue
+				}
+				if span.Parent.SpanID() != server.SpanContext.SpanID() {
+					t.Errorf("Notion-Span %s hängt nicht am Request-Span", span.Name)
+				}
+				ops[strings.TrimPrefix(span.Name, "notion.")] = true
+			}
+			for _, op := range tt.wantNotionOps {
+				if !ops[op] {
+					t.Errorf("Kind-Span notion.%s fehlt, vorhanden: %v", op, ops)
+				}
+			}
+		})
+	}
+}
+
+func TestNotionSpanError(t *testing.T) {
+	exporter := recordSpans(t)
+	ta := newTestApp(t, nil)
+
+	if _, err := ta.getPage(t.Context(), "fehlt"); err == nil {
+		t.Fatal("getPage ohne Fehler")
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("%d Spans, erwartet 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name != "notion.page.get" || span.Status.Code != codes.Error {
+		t.Errorf("Span = %s mit Status %v", span.Name, span.Status)
+	}
+	if got := spanAttribute(span, "notion.page_id"); got != "fehlt" {
+		t.Errorf("notion.page_id = %q", got)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultUpdatesBuffer ist die Anzahl der Events, die für /api/updates vorgehalten werden
+const defaultUpdatesBuffer = 500
+
+// recentUpdatesLimit begrenzt die Antwort ohne since auf die jüngsten Fortschritte
+const recentUpdatesLimit = 20
+
+// parseUpdatesBuffer liest UPDATES_BUFFER (Standard 500, 0 schaltet /api/updates ab)
+func parseUpdatesBuffer(s string) (int, error) {
+	if s == "" {
+		return defaultUpdatesBuffer, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("UPDATES_BUFFER muss eine nicht-negative ganze Zahl sein, ist aber %q", s)
+	}
+	return n, nil
+}
+
+// isAdvancement prüft, ob ein Event ein Team weiterbringt (für Anzeige-Bildschirme relevant)
+func isAdvancement(outcome string) bool {
+	switch outcome {
+	case outcomeAdvanced, outcomeFinished, outcomeAdminGoto:
+		return true
+	}
+	return false
+}
+
+// eventBuffer hält die letzten Fortschritts-Events im Speicher, damit /api/updates
+// unabhängig von EVENT_LOG (auch bei stdout) abgefragt werden kann.
+// Andere Ergebnisse wie team_not_found werden nicht aufgenommen.
+type eventBuffer struct {
+	mu     sync.Mutex
+	events []advancementEvent
+	size   int
+}
+
+func newEventBuffer(size int) *eventBuffer {
+	return &eventBuffer{size: size}
+}
+
+func (b *eventBuffer) Log(event advancementEvent) error {
+	if !isAdvancement(event.Outcome) {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.events = append(b.events, event)
+	if len(b.events) > b.size {
+		b.events = b.events[len(b.events)-b.size:]
+	}
+	return nil
+}
+
+// since liefert alle Events nach t in zeitlicher Reihenfolge. Ohne t (Nullwert)
+// kommen nur die letzten limit Events.
+func (b *eventBuffer) since(t time.Time, limit int) []advancementEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	result := []advancementEvent{}
+	if t.IsZero() {
+		start := max(len(b.events)-limit, 0)
+		return append(result, b.events[start:]...)
+	}
+	for _, event := range b.events {
+		if event.Time.After(t) {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+// teeEventLogger reicht jedes Event an mehrere Logger weiter
+type teeEventLogger []EventLogger
+
+func (t teeEventLogger) Log(event advancementEvent) error {
+	var firstErr error
+	for _, logger := range t {
+		if err := logger.Log(event); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// handleAPIUpdates liefert die Fortschritte seit ?since= (RFC 3339). Ohne since kommen die
+// letzten Fortschritte. "latest" ist der Wert für since bei der nächsten Abfrage.
+func (app *App) handleAPIUpdates(c *gin.Context) {
+	var since time.Time
+	if s := c.Query("since"); s != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339Nano, s); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "since muss ein Zeitstempel im Format RFC 3339 sein"})
+			return
+		}
+	}
+
+	events := app.updates.since(since, recentUpdatesLimit)
+	latest := since
+	if len(events) > 0 {
+		latest = events[len(events)-1].Time
+	}
+
+	response := gin.H{"events": events}
+	if !latest.IsZero() {
+		response["latest"] = latest.Format(time.RFC3339Nano)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// updatesBufferSize liefert UPDATES_BUFFER (0, wenn /api/updates abgeschaltet ist)
+func (app *App) updatesBufferSize() int {
+	if app.updates == nil {
+		return 0
+	}
+	return app.updates.size
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseUpdatesBuffer(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{in: "", want: defaultUpdatesBuffer},
+		{in: "0", want: 0},
+		{in: "50", want: 50},
+		{in: "-1", wantErr: true},
+		{in: "viele", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseUpdatesBuffer(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseUpdatesBuffer(%q) = %d, %v; erwartet %d, Fehler = %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestEventBuffer(t *testing.T) {
+	start := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	at := func(minute int) time.Time { return start.Add(time.Duration(minute) * time.Minute) }
+
+	b := newEventBuffer(4)
+	outcomes := []string{outcomeAdvanced, outcomeTeamNotFound, outcomeAdvanced, outcomeWrongAnswer, outcomeAdminGoto, outcomeAdvanced, outcomeFinished}
+	for i, outcome := range outcomes {
+		if err := b.Log(advancementEvent{Time: at(i), Team: "Demo Team", From: fmt.Sprint(i), Outcome: outcome}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Gepuffert sind die letzten vier Fortschritte: Minute 2, 4, 5 und 6
+	tests := []struct {
+		name  string
+		since time.Time
+		limit int
+		want  []string // From der gelieferten Events
+	}{
+		{name: "ohne since", limit: 20, want: []string{"2", "4", "5", "6"}},
+		{name: "ohne since mit Limit", limit: 2, want: []string{"5", "6"}},
+		{name: "seit Minute 4", since: at(4), limit: 1, want: []string{"5", "6"}},
+		{name: "zwischen zwei Events", since: at(3).Add(30 * time.Second), limit: 20, want: []string{"4", "5", "6"}},
+		{name: "vor dem Puffer", since: at(-1), limit: 20, want: []string{"2", "4", "5", "6"}},
+		{name: "nach dem letzten Event", since: at(6), limit: 20, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, event := range b.since(tt.since, tt.limit) {
+				got = append(got, event.From)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("since = %v, erwartet %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIUpdates(t *testing.T) {
+	ta := newTestApp(t, nil)
+	type updates struct {
+		Events []advancementEvent `json:"events"`
+		Latest string             `json:"latest"`
+	}
+	poll := func(since string) updates {
+		t.Helper()
+		path := "/api/updates"
+		if since != "" {
+			path += "?since=" + url.QueryEscape(since)
+		}
+		w := ta.do(http.MethodGet, path, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+		}
+		var body updates
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		return body
+	}
+	teams := func(u updates) []string {
+		var names []string
+		for _, event := range u.Events {
+			names = append(names, event.Team)
+		}
+		return names
+	}
+
+	if first := poll(""); len(first.Events) != 0 || first.Latest != "" {
+		t.Fatalf("ohne Fortschritte: %+v", first)
+	}
+
+	ta.advance("1", "Demo Team")
+	ta.advance("1", "Niemand")
+	ta.advance("1", "Die Füchse")
+	all := poll("")
+	if want := []string{"Demo Team", "Die Füchse"}; !slices.Equal(teams(all), want) {
+		t.Fatalf("ohne since: %v, erwartet %v", teams(all), want)
+	}
+
+	// Mit latest als since kommen nur neue Fortschritte, latest bleibt ohne neue stehen
+	if again := poll(all.Latest); len(again.Events) != 0 || again.Latest != all.Latest {
+		t.Errorf("ohne neue Fortschritte: %+v, latest erwartet %s", again, all.Latest)
+	}
+	ta.advance("2", "Demo Team")
+	next := poll(all.Latest)
+	if len(next.Events) != 1 || next.Events[0].From != "2" || next.Events[0].Outcome != outcomeAdvanced {
+		t.Errorf("seit latest: %+v", next.Events)
+	}
+	if next.Latest == all.Latest {
+		t.Error("latest nicht fortgeschrieben")
+	}
+	if since := poll(all.Events[0].Time.Format(time.RFC3339Nano)); !slices.Equal(teams(since), []string{"Die Füchse", "Demo Team"}) {
+		t.Errorf("seit dem ersten Fortschritt: %v", teams(since))
+	}
+}
+
+func TestAPIUpdatesErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		buffer     string // UPDATES_BUFFER
+		query      string
+		wantStatus int
+	}{
+		{name: "ungültiges since", query: "?since=gestern", wantStatus: http.StatusBadRequest},
+		{name: "Datum ohne Uhrzeit", query: "?since=2026-06-01", wantStatus: http.StatusBadRequest},
+		{name: "abgeschaltet", buffer: "0", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"UPDATES_BUFFER": tt.buffer})
+			if w := ta.do(http.MethodGet, "/api/updates"+tt.query, nil); w.Code != tt.wantStatus {
+				t.Errorf("Status = %d, erwartet %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
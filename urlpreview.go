@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// urlPreview stellt die aktuelle und die künftige URL einer Challenge gegenüber
+type urlPreview struct {
+	ID      int    `json:"id"`
+	Title   string `json:"title"`
+	Current string `json:"current"`
+	Preview string `json:"preview"`
+}
+
+// rebaseURLTemplate ersetzt Schema und Host (samt Pfad-Präfix aus base) eines URL-Templates,
+// z.B. "https://alt.notion.site/{id}" mit base "https://neu.notion.site" zu "https://neu.notion.site/{id}"
+func rebaseURLTemplate(tmpl, base string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" {
+		return "", fmt.Errorf("base %q muss eine http(s)-URL ohne Query sein", base)
+	}
+
+	_, rest, ok := strings.Cut(tmpl, "://")
+	if !ok {
+		return "", fmt.Errorf("URL-Template %q hat kein Schema", tmpl)
+	}
+	path := ""
+	if i := strings.IndexAny(rest, "/?#"); i >= 0 {
+		path = rest[i:]
+	}
+	return strings.TrimRight(base, "/") + path, nil
+}
+
+// previewChallengeURLs berechnet alle Challenge-URLs unter einem anderen Template
+func previewChallengeURLs(challenges []*Challenge, tmpl string) []urlPreview {
+	previews := make([]urlPreview, 0, len(challenges))
+	for _, challenge := range challenges {
+		previews = append(previews, urlPreview{
+			ID:      challenge.ID,
+			Title:   challenge.Title,
+			Current: challenge.URL,
+			Preview: expandURLTemplate(tmpl, challenge.PageID),
+		})
+	}
+	return previews
+}
+
+// handleAdminURLPreview zeigt, wie die Challenge-URLs unter einer neuen Basis (?base=) oder
+// einem neuen CHALLENGE_URL_TEMPLATE (?template=) aussähen. Da die URLs aus dem Template
+// abgeleitet und nicht in Notion gespeichert sind, wird nichts verändert.
+func (app *App) handleAdminURLPreview(c *gin.Context) {
+	tmpl := c.Query("template")
+	if base := c.Query("base"); tmpl == "" && base != "" {
+		var err error
+		if tmpl, err = rebaseURLTemplate(app.challengeURLTemplate, base); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if tmpl == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "base oder template muss angegeben werden"})
+		return
+	}
+	if err := validateURLTemplate(tmpl); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	challenges, err := app.getAllChallenges(c.Request.Context())
+	if err != nil {
+		errorf("Fehler beim Laden der Challenges: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": app.userError("Fehler beim Laden der Challenges", err)})
+		return
+	}
+
+	previews := previewChallengeURLs(challenges, tmpl)
+	changed := 0
+	for _, preview := range previews {
+		if preview.Preview != preview.Current {
+			changed++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"currentTemplate": app.challengeURLTemplate,
+		"previewTemplate": tmpl,
+		"changed":         changed,
+		"challenges":      previews,
+	})
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestRebaseURLTemplate(t *testing.T) {
+	tests := []struct {
+		tmpl, base string
+		want       string
+		wantErr    bool
+	}{
+		{tmpl: "https://alt.notion.site/{id}", base: "https://neu.notion.site", want: "https://neu.notion.site/{id}"},
+		{tmpl: "https://alt.notion.site/{id}", base: "https://neu.notion.site/", want: "https://neu.notion.site/{id}"},
+		{tmpl: "https://alt.notion.site/hunt/{id}?pvs=4", base: "http://localhost:8080", want: "http://localhost:8080/hunt/{id}?pvs=4"},
+		{tmpl: "https://alt.notion.site/{id}", base: "https://example.org/schatz", want: "https://example.org/schatz/{id}"},
+		{tmpl: "https://alt.notion.site/{id}", base: "neu.notion.site", wantErr: true},
+		{tmpl: "https://alt.notion.site/{id}", base: "ftp://neu.notion.site", wantErr: true},
+		{tmpl: "https://alt.notion.site/{id}", base: "https://neu.notion.site?x=1", wantErr: true},
+		{tmpl: "alt.notion.site/{id}", base: "https://neu.notion.site", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := rebaseURLTemplate(tt.tmpl, tt.base)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("rebaseURLTemplate(%q, %q) = %q, %v; erwartet %q, Fehler = %v", tt.tmpl, tt.base, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestAdminURLPreview(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       url.Values
+		wantStatus  int
+		wantPreview string // Vorschau für den Brunnen
+		wantChanged int
+	}{
+		{name: "neue Basis", query: url.Values{"base": {"https://neu.notion.site"}}, wantStatus: http.StatusOK, wantPreview: "https://neu.notion.site/demobrunnen", wantChanged: 4},
+		{name: "gleiche Basis", query: url.Values{"base": {"https://alt.notion.site/"}}, wantStatus: http.StatusOK, wantPreview: "https://alt.notion.site/demobrunnen"},
+		{name: "neues Template", query: url.Values{"template": {"https://neu.notion.site/p/{uuid}"}}, wantStatus: http.StatusOK, wantPreview: "https://neu.notion.site/p/demo-brunnen", wantChanged: 4},
+		{name: "Template vor Basis", query: url.Values{"template": {"https://a.example/{id}"}, "base": {"https://b.example"}}, wantStatus: http.StatusOK, wantPreview: "https://a.example/demobrunnen", wantChanged: 4},
+		{name: "ungültige Basis", query: url.Values{"base": {"neu.notion.site"}}, wantStatus: http.StatusBadRequest},
+		{name: "Template ohne ID", query: url.Values{"template": {"https://neu.notion.site/"}}, wantStatus: http.StatusBadRequest},
+		{name: "ohne Angabe", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"CHALLENGE_URL_TEMPLATE": "https://alt.notion.site/{id}"})
+			before := ta.teamPage(t, "demo-brunnen").LastEditedTime
+
+			w := ta.admin(http.MethodGet, "/admin/url-preview?"+tt.query.Encode(), "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, erwartet %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				Changed    int          `json:"changed"`
+				Challenges []urlPreview `json:"challenges"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Changed != tt.wantChanged || len(body.Challenges) != 4 {
+				t.Errorf("changed = %d von %d, erwartet %d von 4", body.Changed, len(body.Challenges), tt.wantChanged)
+			}
+			for _, preview := range body.Challenges {
+				if preview.ID != 1 {
+					continue
+				}
+				if preview.Current != "https://alt.notion.site/demobrunnen" || preview.Preview != tt.wantPreview {
+					t.Errorf("Brunnen: %+v, erwartet Vorschau %s", preview, tt.wantPreview)
+				}
+			}
+			if after := ta.teamPage(t, "demo-brunnen").LastEditedTime; !after.Equal(before) {
+				t.Error("Vorschau hat die Challenge-Page verändert")
+			}
+		})
+	}
+}
+
+func TestAdminURLPreviewRequiresToken(t *testing.T) {
+	ta := newTestApp(t, nil)
+	if w := ta.do(http.MethodGet, "/admin/url-preview?base=https://neu.notion.site", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("Status = %d, erwartet %d", w.Code, http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// version wird beim Build gesetzt: go build -ldflags "-X main.version=v1.2.3"
+var version = "dev"
+
+// handleVersion liefert Build-Version, Laufzeit und eine Übersicht der Konfiguration.
+// Secrets (Notion-Token, Admin-Token) und Datenbank-IDs werden bewusst nicht ausgegeben.
+func (app *App) handleVersion(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"version":   version,
+		"goVersion": runtime.Version(),
+		"startedAt": app.startedAt.Format(time.RFC3339),
+		"uptime":    time.Since(app.startedAt).Round(time.Second).String(),
+		"config":    app.configSummary(),
+	})
+}
+
+// configSummary fasst die wirksame Konfiguration ohne Secrets zusammen
+func (app *App) configSummary() gin.H {
+	return gin.H{
+		"debug":                app.debug,
+		"production":           app.production,
+		"demoMode":             app.demoMode,
+		"warmup":               app.warmup,
+		"adminEnabled":         app.adminToken != "",
+		"showDescription":      app.showDescription,
+		"confirmAdvance":       app.confirmAdvance,
+		"honeypot":             app.honeypot,
+		"rememberTeam":         app.rememberTeam,
+		"eventPIN":             app.eventPIN != "",
+		"optimisticWrites":     app.pendingWrites != nil,
+		"teamLock":             app.teamLocks != nil,
+		"answerMaxAttempts":    app.answerConfig.MaxAttempts,
+		"answerHintAfter":      app.answerConfig.HintAfter,
+		"answerLimitAction":    app.answerConfig.LimitAction,
+		"defaultLanguage":      app.defaultLanguage,
+		"metrics":              app.metrics,
+		"confetti":             app.confetti,
+		"finishRedirectURL":    app.finishRedirectURL,
+		"finishRedirectDelay":  app.finishRedirectDelay,
+		"updatesBuffer":        app.updatesBufferSize(),
+		"featureMVP":           app.featureMVP,
+		"mvpIdeas":             len(app.mvpIdeas),
+		"mvpStateFile":         app.mvpUsed.path != "",
+		"featureLeaderboard":   app.featureLeaderboard,
+		"featureRegistration":  app.featureRegistration,
+		"teamNotFoundAction":   app.teamNotFoundAction,
+		"emptyRouteAction":     app.emptyRouteAction,
+		"fuzzyMaxDistance":     app.fuzzyMaxDistance,
+		"fuzzyAutoAccept":      app.fuzzyAutoAccept,
+		"teamLookupRetry":      app.teamLookupRetry.String(),
+		"matchMode":            app.matchMode,
+		"teamMatchPipeline":    app.matchPipeline,
+		"teamInputMode":        app.teamInputMode,
+		"positionMode":         app.positionMode,
+		"eventStart":           formatTime(app.eventWindow.start, time.RFC3339),
+		"eventEnd":             formatTime(app.eventWindow.end, time.RFC3339),
+		"finishMode":           app.finishMode,
+		"finishCount":          app.finishCount,
+		"startChallengeID":     app.startChallengeID,
+		"redirectDelaySeconds": app.redirectDelay,
+		"completionFormat":     app.completionFormat,
+		"challengeURLTemplate": app.challengeURLTemplate,
+		"publicBaseURL":        app.baseURL,
+		"allowedOrigins":       app.allowedOrigins,
+		"cacheTTL":             cacheTTL.String(),
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestVersionEndpoint(t *testing.T) {
+	secrets := map[string]string{
+		"EVENT_PIN":      "468213",
+		"SESSION_SECRET": "sitzungs-geheimnis-0123456789abcdef",
+	}
+	env := map[string]string{
+		"REDIRECT_DELAY_SECONDS": "4",
+		"FEATURE_LEADERBOARD":    "true",
+	}
+	for name, value := range secrets {
+		env[name] = value
+	}
+	ta := newTestApp(t, env)
+
+	w := ta.do(http.MethodGet, "/version", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d: %s", w.Code, w.Body.String())
+	}
+	body := decodeJSON(t, w)
+	if body["version"] != version {
+		t.Errorf("version = %v, erwartet %q", body["version"], version)
+	}
+
+	config := body["config"].(map[string]any)
+	tests := []struct {
+		key  string
+		want any
+	}{
+		{"redirectDelaySeconds", float64(4)},
+		{"featureLeaderboard", true},
+		{"demoMode", true},
+		{"adminEnabled", true},
+		{"eventPIN", true},
+		{"completionFormat", defaultCompletionPropertyFormat},
+	}
+	for _, tt := range tests {
+		if got := config[tt.key]; got != tt.want {
+			t.Errorf("config.%s = %v, erwartet %v", tt.key, got, tt.want)
+		}
+	}
+
+	for name, value := range secrets {
+		if strings.Contains(w.Body.String(), value) {
+			t.Errorf("/version verrät %s", name)
+		}
+	}
+	if strings.Contains(w.Body.String(), testAdminToken) {
+		t.Error("/version verrät ADMIN_TOKEN")
+	}
+}
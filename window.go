@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// eventWindow ist der offizielle Zeitraum der Schnitzeljagd. Ein Nullwert an
+// Start oder Ende lässt die jeweilige Seite offen.
+type eventWindow struct {
+	start time.Time
+	end   time.Time
+}
+
+// parseEventWindow liest EVENT_START und EVENT_END (RFC3339, beide optional)
+func parseEventWindow(start, end string) (eventWindow, error) {
+	var w eventWindow
+	var err error
+	if start != "" {
+		if w.start, err = time.Parse(time.RFC3339, start); err != nil {
+			return w, fmt.Errorf("EVENT_START %q ist kein RFC3339-Zeitpunkt (z.B. 2025-06-14T10:00:00+02:00)", start)
+		}
+	}
+	if end != "" {
+		if w.end, err = time.Parse(time.RFC3339, end); err != nil {
+			return w, fmt.Errorf("EVENT_END %q ist kein RFC3339-Zeitpunkt (z.B. 2025-06-14T18:00:00+02:00)", end)
+		}
+	}
+	if !w.start.IsZero() && !w.end.IsZero() && !w.end.After(w.start) {
+		return w, fmt.Errorf("EVENT_END muss nach EVENT_START liegen")
+	}
+	return w, nil
+}
+
+// inactiveReason liefert eine Meldung für Teams, wenn now außerhalb des Zeitraums liegt,
+// sonst einen leeren String
+func (w eventWindow) inactiveReason(now time.Time) string {
+	switch {
+	case !w.start.IsZero() && now.Before(w.start):
+		return fmt.Sprintf("Die Schnitzeljagd hat noch nicht begonnen. Start ist am %s.", w.start.Local().Format("02.01.2006 um 15:04"))
+	case !w.end.IsZero() && !now.Before(w.end):
+		return fmt.Sprintf("Die Schnitzeljagd ist seit %s beendet.", w.end.Local().Format("02.01.2006 15:04"))
+	}
+	return ""
+}
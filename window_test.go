@@ -0,0 +1,99 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseEventWindow(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end string
+		wantErr    bool
+	}{
+		{name: "ohne Zeitraum"},
+		{name: "nur Start", start: "2026-06-14T10:00:00+02:00"},
+		{name: "nur Ende", end: "2026-06-14T18:00:00+02:00"},
+		{name: "Start und Ende", start: "2026-06-14T10:00:00+02:00", end: "2026-06-14T18:00:00+02:00"},
+		{name: "Ende vor Start", start: "2026-06-14T18:00:00+02:00", end: "2026-06-14T10:00:00+02:00", wantErr: true},
+		{name: "Ende gleich Start", start: "2026-06-14T10:00:00Z", end: "2026-06-14T10:00:00Z", wantErr: true},
+		{name: "ohne Zeitzone", start: "2026-06-14T10:00:00", wantErr: true},
+		{name: "nur Datum", end: "2026-06-14", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseEventWindow(tt.start, tt.end); (err != nil) != tt.wantErr {
+				t.Errorf("parseEventWindow: Fehler = %v, erwartet Fehler = %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInactiveReason(t *testing.T) {
+	start := time.Date(2026, 6, 14, 10, 0, 0, 0, time.UTC)
+	end := start.Add(8 * time.Hour)
+	tests := []struct {
+		name   string
+		window eventWindow
+		now    time.Time
+		want   string // Anfang der Meldung, "" für aktiv
+	}{
+		{name: "ohne Zeitraum", now: start},
+		{name: "vor dem Start", window: eventWindow{start: start, end: end}, now: start.Add(-time.Minute), want: "Die Schnitzeljagd hat noch nicht begonnen"},
+		{name: "genau zum Start", window: eventWindow{start: start, end: end}, now: start},
+		{name: "während", window: eventWindow{start: start, end: end}, now: start.Add(time.Hour)},
+		{name: "genau zum Ende", window: eventWindow{start: start, end: end}, now: end, want: "Die Schnitzeljagd ist seit"},
+		{name: "nach dem Ende", window: eventWindow{start: start, end: end}, now: end.Add(time.Hour), want: "Die Schnitzeljagd ist seit"},
+		{name: "offenes Ende", window: eventWindow{start: start}, now: end.Add(24 * time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.window.inactiveReason(tt.now)
+			if (got == "") != (tt.want == "") || !strings.HasPrefix(got, tt.want) {
+				t.Errorf("inactiveReason = %q, erwartet %q…", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventWindowAdvance(t *testing.T) {
+	now := time.Now()
+	rfc := func(d time.Duration) string { return now.Add(d).Format(time.RFC3339) }
+
+	tests := []struct {
+		name       string
+		start, end string
+		bypass     bool
+		wantStatus int
+		wantDone   bool
+	}{
+		{name: "vor dem Start", start: rfc(time.Hour), wantStatus: http.StatusForbidden},
+		{name: "während", start: rfc(-time.Hour), end: rfc(time.Hour), wantStatus: http.StatusOK, wantDone: true},
+		{name: "nach dem Ende", start: rfc(-2 * time.Hour), end: rfc(-time.Hour), wantStatus: http.StatusForbidden},
+		{name: "nach dem Ende mit Bypass", end: rfc(-time.Hour), bypass: true, wantStatus: http.StatusOK, wantDone: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, map[string]string{"EVENT_START": tt.start, "EVENT_END": tt.end})
+			form := url.Values{"team": {"Demo Team"}}
+			if tt.bypass {
+				form.Set(bypassFormField, signBypassToken(testAdminToken, "Demo Team", "1", now.Add(time.Minute)))
+			}
+
+			w := ta.do(http.MethodPost, "/next/1", form)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, erwartet %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusForbidden && !strings.Contains(w.Body.String(), "Die Schnitzeljagd") {
+				t.Errorf("Hinweis auf den Zeitraum fehlt:\n%s", w.Body.String())
+			}
+			if _, done := ta.completedChallenges(ta.teamPage(t, demoTeamPageID))[1]; done != tt.wantDone {
+				t.Errorf("Abschluss gespeichert = %v, erwartet %v", done, tt.wantDone)
+			}
+		})
+	}
+}